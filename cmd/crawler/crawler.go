@@ -0,0 +1,449 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord"
+	"chainmaker.org/chainmaker/net-liquid/discovery/protocoldiscovery/pb"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// discoveryProtocolIDPrefix is the prefix of protocols of protocol based discovery,
+	// see discovery/protocoldiscovery.
+	discoveryProtocolIDPrefix = "/chain-discovery/v0.0.1/"
+
+	// DefaultMaxDepth is the default max count of hops from the bootstrap peer that will be crawled.
+	DefaultMaxDepth = 3
+	// DefaultRate is the default max count of dials and queries sent per second.
+	DefaultRate = 10
+	// DefaultConcurrency is the default count of peers crawled at the same time.
+	DefaultConcurrency = 4
+	// DefaultQueryTimeout is the default timeout of waiting for a find response or the protocols of peer.
+	DefaultQueryTimeout = 5 * time.Second
+	// DefaultMaxPeers is the default max count of peers in the graph.
+	DefaultMaxPeers = 1000
+
+	// querySize is the count of peers asked for in each find request, larger ones will be limited by the others.
+	querySize = 10
+	// maxPagesPerService is the max count of find requests sent to a peer for each service.
+	maxPagesPerService = 100
+	// protocolsPollInterval is the interval of checking whether the protocols of a peer dialed have been known.
+	protocolsPollInterval = 100 * time.Millisecond
+)
+
+var (
+	// ErrNoService will be returned if no discovery service is supported by the bootstrap peer.
+	ErrNoService = errors.New("no discovery service supported by the bootstrap peer")
+	// ErrQueryTimeout will be returned if a peer does not respond in time.
+	ErrQueryTimeout = errors.New("query timeout")
+)
+
+// Config is the configuration of Crawler.
+type Config struct {
+	// Services is the list of discovery service names (chain ids) to walk through.
+	// If it is empty, all services that the bootstrap peer supports will be walked through.
+	Services []string
+	// MaxDepth is the max count of hops from the bootstrap peer that will be crawled.
+	// The peers found by the ones at MaxDepth will be listed in the graph without being queried.
+	MaxDepth int
+	// Rate is the max count of dials and queries sent per second. If it is not greater than 0, no limit.
+	Rate float64
+	// Concurrency is the count of peers crawled at the same time.
+	Concurrency int
+	// QueryTimeout is the timeout of waiting for a find response or the protocols of peer.
+	QueryTimeout time.Duration
+	// MaxPeers is the max count of peers in the graph, the others found will be dropped.
+	MaxPeers int
+}
+
+func (c *Config) setDefaults() {
+	if c.MaxDepth < 0 {
+		c.MaxDepth = DefaultMaxDepth
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = DefaultConcurrency
+	}
+	if c.QueryTimeout <= 0 {
+		c.QueryTimeout = DefaultQueryTimeout
+	}
+	if c.MaxPeers <= 0 {
+		c.MaxPeers = DefaultMaxPeers
+	}
+}
+
+type waiterKey struct {
+	pid     peer.ID
+	service string
+}
+
+// Crawler walks through the network from a bootstrap peer with the find requests of protocol based discovery,
+// and draws the topology as a graph whose edges point from the peers queried to the peers they returned.
+// Only the peers with a valid signed peer record will be followed.
+// The crawler never sends its own peer record, so that it will not be spread to the others.
+type Crawler struct {
+	host host.Host
+	cfg  Config
+
+	limiter <-chan time.Time
+
+	waitersMu sync.Mutex
+	waiters   map[waiterKey]chan *pb.DiscoveryMsg
+
+	graph *Graph
+
+	logger api.Logger
+}
+
+// NewCrawler create a new Crawler with the host given, which should have been started.
+func NewCrawler(h host.Host, cfg Config, log api.Logger) *Crawler {
+	cfg.setDefaults()
+	if log == nil {
+		log = logger.NilLogger
+	}
+	return &Crawler{
+		host:    h,
+		cfg:     cfg,
+		waiters: make(map[waiterKey]chan *pb.DiscoveryMsg),
+		graph:   newGraph(cfg.MaxPeers),
+		logger:  log,
+	}
+}
+
+// Crawl walk through the network from the bootstrap address given, which should contain the peer id,
+// until all peers within the max depth crawled or ctx done. The graph crawled will be returned even if ctx done.
+func (c *Crawler) Crawl(ctx context.Context, bootstrap ma.Multiaddr) (*Graph, error) {
+	netAddr, pid := util.GetNetAddrAndPidFromNormalMultiAddr(bootstrap)
+	if netAddr == nil || pid == "" {
+		return nil, fmt.Errorf("invalid bootstrap address, the multiaddr containing peer id required: %s",
+			bootstrap.String())
+	}
+	if c.cfg.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / c.cfg.Rate))
+		defer ticker.Stop()
+		c.limiter = ticker.C
+	}
+	services, err := c.registerServices(ctx, pid, netAddr)
+	if err != nil {
+		return nil, err
+	}
+	defer c.unregisterServices(services)
+
+	c.graph.addNode(pid, []ma.Multiaddr{netAddr}, 0)
+	level := []peer.ID{pid}
+	for depth := 0; depth <= c.cfg.MaxDepth && len(level) > 0 && ctx.Err() == nil; depth++ {
+		level = c.crawlLevel(ctx, level, services, depth)
+	}
+	return c.graph, nil
+}
+
+// registerServices resolve the services to walk through, then register the handlers of them.
+// If no services configured, the ones supported by the bootstrap peer will be used.
+// The handlers are registered before dialing any peer to crawl,
+// so that the peers know that we support the services once connected.
+func (c *Crawler) registerServices(ctx context.Context, pid peer.ID, netAddr ma.Multiaddr) ([]string, error) {
+	services := c.cfg.Services
+	if len(services) == 0 {
+		if err := c.connect(ctx, pid, []ma.Multiaddr{netAddr}); err != nil {
+			return nil, err
+		}
+		protocols, err := c.waitProtocols(ctx, pid)
+		// reconnect later, so that the handlers registered will be pushed to the peer when connecting
+		c.disconnect(pid)
+		if err == nil {
+			err = c.waitDisconnected(ctx, pid)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range protocols {
+			if strings.HasPrefix(string(p), discoveryProtocolIDPrefix) {
+				services = append(services, strings.TrimPrefix(string(p), discoveryProtocolIDPrefix))
+			}
+		}
+		if len(services) == 0 {
+			return nil, ErrNoService
+		}
+	}
+	for i, service := range services {
+		service := service
+		err := c.host.RegisterMsgPayloadHandler(protocolID(service), func(senderPID peer.ID, msgPayload []byte) {
+			c.handleMsg(service, senderPID, msgPayload)
+		})
+		if err != nil {
+			c.unregisterServices(services[:i])
+			return nil, err
+		}
+	}
+	c.logger.Infof("[Crawler] services to walk through: %s", strings.Join(services, ", "))
+	return services, nil
+}
+
+func (c *Crawler) unregisterServices(services []string) {
+	for _, service := range services {
+		_ = c.host.UnregisterMsgPayloadHandler(protocolID(service))
+	}
+}
+
+// crawlLevel crawl the peers given concurrently, then return the peers newly found for the next level.
+func (c *Crawler) crawlLevel(ctx context.Context, level []peer.ID, services []string, depth int) []peer.ID {
+	var (
+		mu   sync.Mutex
+		next []peer.ID
+		wg   sync.WaitGroup
+	)
+	sem := make(chan struct{}, c.cfg.Concurrency)
+	for _, pid := range level {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(pid peer.ID) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				found := c.crawlPeer(ctx, pid, services, depth)
+				mu.Lock()
+				next = append(next, found...)
+				mu.Unlock()
+			}(pid)
+		}
+	}
+	wg.Wait()
+	return next
+}
+
+// crawlPeer dial to the peer, query the peers it knows for each service, then disconnect from it.
+// The peers found first time will be added to the graph and returned.
+func (c *Crawler) crawlPeer(ctx context.Context, pid peer.ID, services []string, depth int) []peer.ID {
+	err := c.connect(ctx, pid, c.graph.addrs(pid))
+	if err != nil {
+		c.logger.Debugf("[Crawler] connect to peer failed, %s (pid: %s)", err.Error(), pid)
+		c.graph.setResult(pid, nil, "", err)
+		return nil
+	}
+	defer c.disconnect(pid)
+	protocols, err := c.waitProtocols(ctx, pid)
+	c.graph.setResult(pid, protocols, c.host.PeerStore().AgentVersion(pid), err)
+	if err != nil {
+		return nil
+	}
+	var found []peer.ID
+	for _, service := range services {
+		if !c.host.IsPeerSupportProtocol(pid, protocolID(service)) {
+			continue
+		}
+		records, err := c.queryService(ctx, pid, service)
+		if err != nil {
+			c.logger.Debugf("[Crawler] query peer failed, %s (pid: %s, service: %s)", err.Error(), pid, service)
+		}
+		for _, record := range records {
+			if record.PeerID == c.host.ID() {
+				continue
+			}
+			if c.graph.addNode(record.PeerID, record.Addrs, depth+1) && depth < c.cfg.MaxDepth {
+				found = append(found, record.PeerID)
+			}
+			c.graph.addEdge(pid, record.PeerID, service)
+		}
+	}
+	c.logger.Infof("[Crawler] peer crawled, %d new peers found. (pid: %s, depth: %d)", len(found), pid, depth)
+	return found
+}
+
+// queryService send find requests to the peer until it returns no more peers,
+// each request carries the peers returned before, so that the peer will return the others.
+func (c *Crawler) queryService(ctx context.Context, pid peer.ID, service string) ([]*peerrecord.PeerRecord, error) {
+	key := waiterKey{pid: pid, service: service}
+	resC := make(chan *pb.DiscoveryMsg, 1)
+	c.waitersMu.Lock()
+	c.waiters[key] = resC
+	c.waitersMu.Unlock()
+	defer func() {
+		c.waitersMu.Lock()
+		delete(c.waiters, key)
+		c.waitersMu.Unlock()
+	}()
+
+	var records []*peerrecord.PeerRecord
+	known := make(map[peer.ID]struct{})
+	for page := 0; page < maxPagesPerService; page++ {
+		msg := &pb.DiscoveryMsg{
+			Type:   pb.DiscoveryMsg_FindReq,
+			PInfos: make([]*pb.PeerInfo, 0, len(known)),
+			Size_:  querySize,
+		}
+		for p := range known {
+			msg.PInfos = append(msg.PInfos, &pb.PeerInfo{Pid: p.ToString()})
+		}
+		msgBytes, err := proto.Marshal(msg)
+		if err != nil {
+			return records, err
+		}
+		if err = c.wait(ctx); err != nil {
+			return records, err
+		}
+		if err = c.host.SendMsg(protocolID(service), pid, msgBytes); err != nil {
+			return records, err
+		}
+		res, err := c.waitResponse(ctx, resC)
+		if err != nil {
+			return records, err
+		}
+		newFound := 0
+		for _, pInfo := range res.PInfos {
+			record, err := peerrecord.OpenBytes(pInfo.SignedRecord)
+			if err != nil || record.PeerID != peer.ID(pInfo.Pid) {
+				c.logger.Debugf("[Crawler] invalid peer record returned, ignored. (pid: %s, from: %s)",
+					pInfo.Pid, pid)
+				continue
+			}
+			if _, ok := known[record.PeerID]; ok {
+				continue
+			}
+			known[record.PeerID] = struct{}{}
+			records = append(records, record)
+			newFound++
+		}
+		if newFound == 0 {
+			break
+		}
+	}
+	return records, nil
+}
+
+func (c *Crawler) waitResponse(ctx context.Context, resC <-chan *pb.DiscoveryMsg) (*pb.DiscoveryMsg, error) {
+	timer := time.NewTimer(c.cfg.QueryTimeout)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, ErrQueryTimeout
+	case res := <-resC:
+		return res, nil
+	}
+}
+
+// handleMsg push the find responses to the queries waiting for them, the other msgs are ignored.
+func (c *Crawler) handleMsg(service string, senderPID peer.ID, msgPayload []byte) {
+	msg := &pb.DiscoveryMsg{}
+	if err := proto.Unmarshal(msgPayload, msg); err != nil {
+		c.logger.Debugf("[Crawler] unmarshal discovery msg failed, %s (remote pid: %s)", err.Error(), senderPID)
+		return
+	}
+	if msg.Type != pb.DiscoveryMsg_FindRes {
+		return
+	}
+	c.waitersMu.Lock()
+	resC, ok := c.waiters[waiterKey{pid: senderPID, service: service}]
+	c.waitersMu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case resC <- msg:
+	default:
+		// a late response of the query timeout, drop it
+	}
+}
+
+// connect dial to the addresses of peer one by one until connected.
+func (c *Crawler) connect(ctx context.Context, pid peer.ID, addrs []ma.Multiaddr) error {
+	if c.host.ConnMgr().IsConnected(pid) {
+		return nil
+	}
+	if len(addrs) == 0 {
+		return errors.New("no address known")
+	}
+	var err error
+	for _, addr := range addrs {
+		if err = c.wait(ctx); err != nil {
+			return err
+		}
+		if _, err = c.host.Dial(util.CreateMultiAddrWithPidAndNetAddr(pid, addr)); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (c *Crawler) disconnect(pid peer.ID) {
+	for _, conn := range c.host.ConnMgr().GetPeerAllConn(pid) {
+		_ = conn.Close()
+	}
+}
+
+// waitDisconnected wait until all connections of peer removed from the connection manager.
+func (c *Crawler) waitDisconnected(ctx context.Context, pid peer.ID) error {
+	timer := time.NewTimer(c.cfg.QueryTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(protocolsPollInterval)
+	defer ticker.Stop()
+	for c.host.ConnMgr().IsConnected(pid) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return ErrQueryTimeout
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// waitProtocols wait until the protocols supported by peer connected pushed to us.
+func (c *Crawler) waitProtocols(ctx context.Context, pid peer.ID) ([]protocol.ID, error) {
+	timer := time.NewTimer(c.cfg.QueryTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(protocolsPollInterval)
+	defer ticker.Stop()
+	for {
+		if protocols := c.host.ProtocolMgr().GetPeerSupportedProtocols(pid); len(protocols) > 0 {
+			return protocols, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return nil, ErrQueryTimeout
+		case <-ticker.C:
+		}
+	}
+}
+
+// wait until the rate limiter allows the next dial or query.
+func (c *Crawler) wait(ctx context.Context) error {
+	if c.limiter == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.limiter:
+		return nil
+	}
+}
+
+func protocolID(service string) protocol.ID {
+	return protocol.ID(discoveryProtocolIDPrefix + service)
+}
@@ -0,0 +1,115 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/protocoldiscovery"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+const testService = "chain1"
+
+// startChainNodes start hosts announcing the test service, connected one by one as a line.
+func startChainNodes(t *testing.T, count int) []host.Host {
+	hosts := make([]host.Host, count)
+	discoveries := make([]*protocoldiscovery.ProtocolBasedDiscovery, count)
+	for i := 0; i < count; i++ {
+		h, err := newCrawlerHost("", ma.StringCast("/ip4/127.0.0.1/tcp/0"), logger.NilLogger)
+		require.Nil(t, err)
+		require.Nil(t, h.Start())
+		hosts[i] = h
+		t.Cleanup(func() {
+			_ = h.Stop()
+		})
+		d, err := protocoldiscovery.NewProtocolBasedDiscovery(h)
+		require.Nil(t, err)
+		require.Nil(t, d.Announce(context.Background(), testService))
+		discoveries[i] = d
+		if i == 0 {
+			continue
+		}
+		_, err = h.Dial(util.CreateMultiAddrWithPidAndNetAddr(hosts[i-1].ID(), hosts[i-1].LocalAddresses()[0]))
+		require.Nil(t, err)
+		require.Eventually(t, func() bool {
+			return h.IsPeerSupportProtocol(hosts[i-1].ID(), protocolID(testService)) &&
+				hosts[i-1].IsPeerSupportProtocol(h.ID(), protocolID(testService))
+		}, 5*time.Second, 50*time.Millisecond)
+		// announce again to exchange the signed peer records with the peer connected
+		require.Nil(t, d.Announce(context.Background(), testService))
+		require.Nil(t, discoveries[i-1].Announce(context.Background(), testService))
+	}
+	return hosts
+}
+
+func crawlFromFirst(t *testing.T, hosts []host.Host, cfg Config) *Graph {
+	h, err := newCrawlerHost("", ma.StringCast("/ip4/127.0.0.1/tcp/0"), logger.NilLogger)
+	require.Nil(t, err)
+	require.Nil(t, h.Start())
+	defer func() {
+		_ = h.Stop()
+	}()
+	bootstrap := util.CreateMultiAddrWithPidAndNetAddr(hosts[0].ID(), hosts[0].LocalAddresses()[0])
+	graph, err := NewCrawler(h, cfg, nil).Crawl(context.Background(), bootstrap)
+	require.Nil(t, err)
+	return graph
+}
+
+func TestCrawl(t *testing.T) {
+	hosts := startChainNodes(t, 3)
+
+	// records are exchanged in background, crawl until all peers found
+	var graph *Graph
+	require.Eventually(t, func() bool {
+		graph = crawlFromFirst(t, hosts, Config{MaxDepth: 2, QueryTimeout: time.Second})
+		return len(graph.Nodes()) == 3 && len(graph.Edges()) == 4
+	}, 20*time.Second, 500*time.Millisecond)
+	nodes := graph.Nodes()
+	for i, n := range nodes {
+		require.Equal(t, hosts[i].ID().ToString(), n.ID)
+		require.Equal(t, i, n.Depth)
+		require.True(t, n.Crawled)
+		require.Empty(t, n.Error)
+		require.Contains(t, n.Protocols, string(protocolID(testService)))
+	}
+
+	// peers beyond the max depth are listed without being queried
+	graph = crawlFromFirst(t, hosts, Config{MaxDepth: 0, Services: []string{testService}})
+	nodes = graph.Nodes()
+	require.Equal(t, 2, len(nodes))
+	require.True(t, nodes[0].Crawled)
+	require.False(t, nodes[1].Crawled)
+	require.Equal(t, hosts[1].ID().ToString(), nodes[1].ID)
+	require.Equal(t, 1, len(graph.Edges()))
+
+	buf := &bytes.Buffer{}
+	require.Nil(t, graph.WriteJSON(buf))
+	doc := struct {
+		Nodes []*Node `json:"nodes"`
+		Edges []*Edge `json:"edges"`
+	}{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &doc))
+	require.Equal(t, 2, len(doc.Nodes))
+	require.Equal(t, []string{testService}, doc.Edges[0].Services)
+
+	buf.Reset()
+	require.Nil(t, graph.WriteDot(buf))
+	dot := buf.String()
+	require.True(t, strings.HasPrefix(dot, "digraph network {"))
+	require.Contains(t, dot, "\""+hosts[0].ID().ToString()+"\" -> \""+hosts[1].ID().ToString()+"\"")
+	require.Contains(t, dot, "style=dashed")
+}
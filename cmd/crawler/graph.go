@@ -0,0 +1,235 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// Node is a peer in the graph.
+type Node struct {
+	ID string `json:"id"`
+	// Depth is the count of hops from the bootstrap peer.
+	Depth int `json:"depth"`
+	// Addrs is the list of net addresses in the signed peer record of the peer.
+	Addrs []string `json:"addrs,omitempty"`
+	// Crawled is whether the peer has been queried, the ones beyond the max depth will not be queried.
+	Crawled      bool     `json:"crawled"`
+	AgentVersion string   `json:"agentVersion,omitempty"`
+	Protocols    []string `json:"protocols,omitempty"`
+	// Error is the reason why the peer could not be crawled, e.g. unreachable.
+	Error string `json:"error,omitempty"`
+
+	addrs []ma.Multiaddr
+}
+
+// Edge means the peer From returned the peer To when queried.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Services is the list of discovery services with which the peer To returned.
+	Services []string `json:"services"`
+}
+
+type edgeKey struct {
+	from, to peer.ID
+}
+
+// Graph is the topology of the network crawled. It is safe for concurrent use.
+type Graph struct {
+	mu       sync.Mutex
+	maxNodes int
+	nodes    map[peer.ID]*Node
+	edges    map[edgeKey]*Edge
+}
+
+func newGraph(maxNodes int) *Graph {
+	return &Graph{
+		maxNodes: maxNodes,
+		nodes:    make(map[peer.ID]*Node),
+		edges:    make(map[edgeKey]*Edge),
+	}
+}
+
+// addNode add a peer to the graph, return true if it is new.
+// If the peer exists, the addresses given will be merged into it.
+// The peer will be dropped if the count of peers reach the max value.
+func (g *Graph) addNode(pid peer.ID, addrs []ma.Multiaddr, depth int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n, ok := g.nodes[pid]
+	if !ok {
+		if len(g.nodes) >= g.maxNodes {
+			return false
+		}
+		n = &Node{ID: pid.ToString(), Depth: depth}
+		g.nodes[pid] = n
+	}
+	for _, addr := range addrs {
+		exist := false
+		for i := range n.addrs {
+			if n.addrs[i].Equal(addr) {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			n.addrs = append(n.addrs, addr)
+			n.Addrs = append(n.Addrs, addr.String())
+		}
+	}
+	return !ok
+}
+
+// addEdge add an edge between the peers in the graph, or add the service to it if exists.
+func (g *Graph) addEdge(from, to peer.ID, service string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.nodes[to]; !ok {
+		return
+	}
+	key := edgeKey{from: from, to: to}
+	e, ok := g.edges[key]
+	if !ok {
+		e = &Edge{From: from.ToString(), To: to.ToString()}
+		g.edges[key] = e
+	}
+	for _, s := range e.Services {
+		if s == service {
+			return
+		}
+	}
+	e.Services = append(e.Services, service)
+}
+
+// addrs return the net addresses of peer.
+func (g *Graph) addrs(pid peer.ID) []ma.Multiaddr {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n, ok := g.nodes[pid]
+	if !ok {
+		return nil
+	}
+	res := make([]ma.Multiaddr, len(n.addrs))
+	copy(res, n.addrs)
+	return res
+}
+
+// setResult record the result of crawling the peer.
+func (g *Graph) setResult(pid peer.ID, protocols []protocol.ID, agentVersion string, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n, ok := g.nodes[pid]
+	if !ok {
+		return
+	}
+	n.Crawled = true
+	n.AgentVersion = agentVersion
+	n.Protocols = sortedProtocols(protocols)
+	if err != nil {
+		n.Error = err.Error()
+	}
+}
+
+// Nodes return the peers in the graph ordered by depth and id.
+func (g *Graph) Nodes() []*Node {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	res := make([]*Node, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		res = append(res, n)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Depth != res[j].Depth {
+			return res[i].Depth < res[j].Depth
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// Edges return the edges in the graph ordered by the ids of peers.
+func (g *Graph) Edges() []*Edge {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	res := make([]*Edge, 0, len(g.edges))
+	for _, e := range g.edges {
+		res = append(res, e)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].From != res[j].From {
+			return res[i].From < res[j].From
+		}
+		return res[i].To < res[j].To
+	})
+	return res
+}
+
+// WriteJSON write the graph as a JSON document with "nodes" and "edges".
+func (g *Graph) WriteJSON(w io.Writer) error {
+	doc := struct {
+		Nodes []*Node `json:"nodes"`
+		Edges []*Edge `json:"edges"`
+	}{
+		Nodes: g.Nodes(),
+		Edges: g.Edges(),
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// WriteDot write the graph in the Graphviz dot language.
+// The peers not crawled are drawn dashed, and the ones failed to crawl are drawn red.
+func (g *Graph) WriteDot(w io.Writer) error {
+	b := &strings.Builder{}
+	b.WriteString("digraph network {\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes() {
+		label := fmt.Sprintf("%s\\ndepth: %d", n.ID, n.Depth)
+		if n.AgentVersion != "" {
+			label += "\\n" + dotEscape(n.AgentVersion)
+		}
+		attrs := fmt.Sprintf("label=\"%s\"", label)
+		if !n.Crawled {
+			attrs += ", style=dashed"
+		} else if n.Error != "" {
+			attrs += ", color=red"
+		}
+		fmt.Fprintf(b, "  \"%s\" [%s];\n", n.ID, attrs)
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(b, "  \"%s\" -> \"%s\" [label=\"%s\"];\n",
+			e.From, e.To, dotEscape(strings.Join(e.Services, ",")))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// sortedProtocols return the strings of protocols given in order.
+func sortedProtocols(protocols []protocol.ID) []string {
+	res := make([]string, len(protocols))
+	for i := range protocols {
+		res[i] = string(protocols[i])
+	}
+	sort.Strings(res)
+	return res
+}
@@ -0,0 +1,184 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Command crawler walks through a liquid network from a bootstrap peer with the protocol based discovery,
+// then writes the topology found as a JSON document or a Graphviz dot graph.
+//
+// Usage:
+//
+//	crawler -bootstrap /ip4/127.0.0.1/tcp/11301/p2p/QmXXX -depth 3 -rate 10 -json net.json -dot net.dot
+//
+// The dot graph could be rendered with "dot -Tsvg net.dot -o net.svg".
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"chainmaker.org/chainmaker/common/v2/crypto"
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	host2 "chainmaker.org/chainmaker/net-liquid/host"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	"chainmaker.org/chainmaker/net-liquid/tlssupport"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+func main() {
+	var (
+		bootstrap   = flag.String("bootstrap", "", "address of the bootstrap peer containing the peer id (required)")
+		services    = flag.String("services", "", "comma separated chain ids to walk through, all if empty")
+		depth       = flag.Int("depth", DefaultMaxDepth, "max count of hops from the bootstrap peer to crawl")
+		rate        = flag.Float64("rate", DefaultRate, "max count of dials and queries per second, 0 for no limit")
+		concurrency = flag.Int("concurrency", DefaultConcurrency, "count of peers crawled at the same time")
+		timeout     = flag.Duration("timeout", DefaultQueryTimeout, "timeout of each query")
+		maxPeers    = flag.Int("max-peers", DefaultMaxPeers, "max count of peers in the graph")
+		keyFile     = flag.String("key", "", "PEM file of the private key of crawler, a random one will be used if empty")
+		listen      = flag.String("listen", "/ip4/0.0.0.0/tcp/0", "listen address of crawler")
+		jsonFile    = flag.String("json", "", "file that the graph written to as JSON, \"-\" for stdout")
+		dotFile     = flag.String("dot", "", "file that the graph written to as Graphviz dot, \"-\" for stdout")
+		verbose     = flag.Bool("v", false, "print logs")
+	)
+	flag.Parse()
+	if *bootstrap == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *jsonFile == "" && *dotFile == "" {
+		*jsonFile = "-"
+	}
+	cfg := Config{
+		MaxDepth:     *depth,
+		Rate:         *rate,
+		Concurrency:  *concurrency,
+		QueryTimeout: *timeout,
+		MaxPeers:     *maxPeers,
+	}
+	for _, s := range strings.Split(*services, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			cfg.Services = append(cfg.Services, s)
+		}
+	}
+	if err := run(*bootstrap, *listen, *keyFile, cfg, *jsonFile, *dotFile, *verbose); err != nil {
+		fmt.Fprintf(os.Stderr, "crawler: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(bootstrap, listen, keyFile string, cfg Config, jsonFile, dotFile string, verbose bool) error {
+	bootstrapAddr, err := ma.NewMultiaddr(bootstrap)
+	if err != nil {
+		return err
+	}
+	listenAddr, err := ma.NewMultiaddr(listen)
+	if err != nil {
+		return err
+	}
+	var log api.Logger = logger.NilLogger
+	if verbose {
+		log = logger.NewLogPrinter("CRAWLER")
+	}
+	// stop crawling when interrupted, the graph crawled will still be written
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigC)
+	go func() {
+		select {
+		case <-sigC:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	h, err := newCrawlerHost(keyFile, listenAddr, log)
+	if err != nil {
+		return err
+	}
+	if err = h.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = h.Stop()
+	}()
+
+	start := time.Now()
+	graph, err := NewCrawler(h, cfg, log).Crawl(ctx, bootstrapAddr)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "crawler: %d peers found in %s\n",
+		len(graph.Nodes()), time.Since(start).Round(time.Millisecond))
+	if err = writeGraph(jsonFile, graph.WriteJSON); err != nil {
+		return err
+	}
+	return writeGraph(dotFile, graph.WriteDot)
+}
+
+// newCrawlerHost create a host with the private key in the file given, or a random one.
+// The host does not ping or probe others, for it only lives during crawling.
+func newCrawlerHost(keyFile string, listenAddr ma.Multiaddr, log api.Logger) (host.Host, error) {
+	var (
+		privateKey crypto.PrivateKey
+		err        error
+	)
+	if keyFile != "" {
+		var keyPEM []byte
+		if keyPEM, err = ioutil.ReadFile(keyFile); err != nil {
+			return nil, err
+		}
+		privateKey, err = asym.PrivateKeyFromPEM(keyPEM, nil)
+	} else {
+		privateKey, err = asym.GenerateKeyPair(crypto.ECC_NISTP256)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg, loadPidFunc, err := tlssupport.MakeTlsConfigAndLoadPeerIdFuncWithPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	hostCfg := &host2.HostConfig{
+		TlsCfg:                    tlsCfg,
+		LoadPidFunc:               loadPidFunc,
+		SendStreamPoolInitSize:    1,
+		SendStreamPoolCap:         4,
+		PeerReceiveStreamMaxCount: 16,
+		ListenAddresses:           []ma.Multiaddr{listenAddr},
+		PrivateKey:                privateKey,
+		PingInterval:              -1,
+		AutoNATInterval:           -1,
+	}
+	return hostCfg.NewHost(host2.TcpNetwork, context.Background(), log)
+}
+
+func writeGraph(file string, write func(w io.Writer) error) error {
+	if file == "" {
+		return nil
+	}
+	if file == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err = write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -26,4 +26,6 @@ type BlackList interface {
 	RemoveIPAndPort(ipAndPort string)
 	// IsBlack check whether the remote peer id or the remote net address of the connection given exist in blacklist.
 	IsBlack(conn network.Conn) bool
+	// IsBlackPeer check whether the peer id exist in blacklist.
+	IsBlackPeer(pid peer.ID) bool
 }
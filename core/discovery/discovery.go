@@ -37,6 +37,12 @@ type Announcer interface {
 	Announce(ctx context.Context, serviceName string, opts ...Option) error
 }
 
+// Unannouncer provides a way to withdraw the service announced before.
+type Unannouncer interface {
+	// Unannounce tells others that we no longer support the service which name is the given.
+	Unannounce(ctx context.Context, serviceName string, opts ...Option) error
+}
+
 // Discoverer provides a way to find peers who support the service which name is the given.
 type Discoverer interface {
 	// FindPeers find peers who support the service which name is the given.
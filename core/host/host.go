@@ -8,6 +8,7 @@ package host
 
 import (
 	"context"
+	"time"
 
 	"chainmaker.org/chainmaker/common/v2/crypto"
 	"chainmaker.org/chainmaker/net-liquid/core/basic"
@@ -51,8 +52,16 @@ type Host interface {
 	// to the receiver whose peer.ID is the given receiverPID.
 	SendMsg(protocolID protocol.ID, receiverPID peer.ID, msgPayload []byte) error
 
+	// SendMsgRouted will send a msg to the peer directly if connected,
+	// otherwise forward it through the peers connected if overlay routing enabled.
+	SendMsgRouted(protocolID protocol.ID, receiverPID peer.ID, msgPayload []byte) error
+
 	// Dial try to establish a connection with peer whose address is the given.
 	Dial(remoteAddr ma.Multiaddr) (network.Conn, error)
+	// DialPeer try to establish a connection with peer whose id is the given.
+	// The addresses of peer stored in PeerStore will be dialed concurrently with staggered starts,
+	// and the first connection established will be returned.
+	DialPeer(ctx context.Context, pid peer.ID) (network.Conn, error)
 
 	// CheckClosedConnWithErr return whether the connection has closed.
 	// If conn.IsClosed() is true, return true.
@@ -88,9 +97,21 @@ type Host interface {
 	// AddDirectPeer append a direct peer.
 	AddDirectPeer(dp ma.Multiaddr)
 
+	// RemoveDirectPeer remove a direct peer.
+	RemoveDirectPeer(pid peer.ID)
+
 	// ClearDirectPeers remove all direct peers.
 	ClearDirectPeers()
 
 	// LocalAddresses return the list of net addresses for listener listening.
 	LocalAddresses() []ma.Multiaddr
+
+	// AnnounceAddrs return the list of net addresses advertised to others.
+	AnnounceAddrs() []ma.Multiaddr
+
+	// CanAnnounceAddr return whether the address could be advertised to others.
+	CanAnnounceAddr(addr ma.Multiaddr) bool
+
+	// Ping send a ping to the peer connected and return the round-trip time.
+	Ping(ctx context.Context, pid peer.ID) (time.Duration, error)
 }
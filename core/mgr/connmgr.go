@@ -8,6 +8,7 @@ package mgr
 
 import (
 	"io"
+	"time"
 
 	"chainmaker.org/chainmaker/net-liquid/core/basic"
 	"chainmaker.org/chainmaker/net-liquid/core/network"
@@ -29,6 +30,82 @@ type ConnMgr interface {
 	MaxPeerCountAllowed() int
 	PeerCount() int
 	AllPeer() []peer.ID
+	// TagPeer tags a peer with a string, associating a weight with the tag.
+	// Peers with higher total weight of tags will be trimmed later.
+	TagPeer(pid peer.ID, tag string, weight int)
+	// UntagPeer removes the tagged value from the peer.
+	UntagPeer(pid peer.ID, tag string)
+	// Protect protects a peer from having its connections trimmed.
+	// Protection is tracked by tag, a peer is protected as long as any tag protecting it remains.
+	Protect(pid peer.ID, tag string)
+	// Unprotect removes a protection that may have been placed on a peer under the tag given.
+	// The return value indicates whether the peer continues to be protected after this call.
+	Unprotect(pid peer.ID, tag string) bool
+	// IsProtected returns true if the peer is protected for the tag given.
+	// If tag is empty, returns true if the peer is protected by any tag.
+	IsProtected(pid peer.ID, tag string) bool
+}
+
+// PeerTrimmingHook is a function that will be called before the connections of a peer trimmed by ConnMgr.
+type PeerTrimmingHook func(pid peer.ID)
+
+// TrimNotifier is an optional interface of ConnMgr, which notifies the peers going to be trimmed.
+type TrimNotifier interface {
+	// OnPeerTrimming register a hook that will be called before the connections of a peer trimmed.
+	// Hooks should return quickly, connections will be closed after all hooks returned or timeout.
+	OnPeerTrimming(hook PeerTrimmingHook)
+}
+
+// DirectPeerState is the connection state of a direct peer maintained by ConnSupervisor.
+type DirectPeerState int
+
+const (
+	// DirectPeerStateDisconnected means the peer is not connected and no dialing is running.
+	DirectPeerStateDisconnected DirectPeerState = iota
+	// DirectPeerStateDialing means the supervisor is dialing to the peer.
+	DirectPeerStateDialing
+	// DirectPeerStateBackoff means the last dialing failed and the supervisor is waiting for the next one.
+	DirectPeerStateBackoff
+	// DirectPeerStateConnected means the peer is connected.
+	DirectPeerStateConnected
+	// DirectPeerStateGaveUp means the supervisor has given up dialing to the peer after too many failures.
+	DirectPeerStateGaveUp
+)
+
+// String return the name of state.
+func (s DirectPeerState) String() string {
+	switch s {
+	case DirectPeerStateDisconnected:
+		return "disconnected"
+	case DirectPeerStateDialing:
+		return "dialing"
+	case DirectPeerStateBackoff:
+		return "backoff"
+	case DirectPeerStateConnected:
+		return "connected"
+	case DirectPeerStateGaveUp:
+		return "gave-up"
+	default:
+		return "unknown"
+	}
+}
+
+// DirectPeerStatus is the status of a direct peer reported by ConnSupervisor.
+type DirectPeerStatus struct {
+	// PeerID is the id of the direct peer.
+	PeerID peer.ID
+	// Addrs is the list of addresses of the direct peer.
+	Addrs []ma.Multiaddr
+	// State is the connection state of the direct peer.
+	State DirectPeerState
+	// Attempts is the count of dialing failed since the peer disconnected.
+	Attempts int
+	// LastError is the error of the last dialing, nil if no dialing failed.
+	LastError error
+	// LastAttempt is the time of the last dialing.
+	LastAttempt time.Time
+	// NextAttempt is the time of the next dialing when in DirectPeerStateBackoff.
+	NextAttempt time.Time
 }
 
 // ConnSupervisor maintains the connection state of the necessary peers.
@@ -36,9 +113,14 @@ type ConnMgr interface {
 type ConnSupervisor interface {
 	basic.Switcher
 	// SetPeerAddr will set a peer as a necessary peer and store the peer's address.
+	// The addresses of the peer stored before will be replaced.
 	SetPeerAddr(pid peer.ID, addr ma.Multiaddr)
+	// AddPeerAddr will set a peer as a necessary peer and append the addresses to the peer's addresses.
+	AddPeerAddr(pid peer.ID, addrs ...ma.Multiaddr)
 	// RemovePeerAddr will unset a necessary peer.
 	RemovePeerAddr(pid peer.ID)
 	// RemoveAllPeer clean all necessary peers.
 	RemoveAllPeer()
+	// Status return the status of all necessary peers.
+	Status() []DirectPeerStatus
 }
@@ -8,6 +8,7 @@ package mgr
 
 import (
 	"io"
+	"time"
 
 	"chainmaker.org/chainmaker/net-liquid/core/network"
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
@@ -47,6 +48,9 @@ type SendStreamPoolManager interface {
 	RemovePeerConnAndCloseSendStreamPool(pid peer.ID, conn network.Conn) error
 	// GetPeerBestConnSendStreamPool return a stream pool for the best connection of peer.
 	GetPeerBestConnSendStreamPool(pid peer.ID) SendStreamPool
+	// RecordConnLatency record a round-trip time measured on a connection of peer,
+	// the connections with lower latency will be preferred when choosing the best one.
+	RecordConnLatency(pid peer.ID, conn network.Conn, rtt time.Duration)
 }
 
 // ReceiveStreamManager manage all receive streams.
@@ -7,9 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package network
 
 import (
+	"context"
 	"io"
+	"net"
 
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
 )
 
 // ConnHandler is a function for handling connections.
@@ -29,3 +32,14 @@ type Network interface {
 	// LocalPeerID return the local peer id.
 	LocalPeerID() peer.ID
 }
+
+// Upgrader is an optional interface of Network.
+// It upgrades a raw connection, e.g. a circuit relayed by another peer, to a Conn secured and multiplexed
+// the same way as the connections dialed or accepted by the Network.
+type Upgrader interface {
+	// Upgrade the net.Conn given to a Conn with the local and remote multi-addresses given,
+	// then call the ConnHandler of the Network with it.
+	// If rPID is not empty, the remote peer id will be checked after handshaking.
+	// The ctx only limits the handshaking, the Conn upgraded will live longer than it.
+	Upgrade(ctx context.Context, c net.Conn, dir Direction, laddr, raddr ma.Multiaddr, rPID peer.ID) (Conn, error)
+}
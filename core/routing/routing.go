@@ -25,6 +25,19 @@ type ProtocolRouting interface {
 	FindPeerSupportProtocolsAsync(context.Context, int, ...protocol.ID) <-chan ma.Multiaddr
 }
 
+// ContentRouting provides a way to announce and find the providers of contents, e.g. "who has block X".
+// Keys are arbitrary strings, e.g. a protocol id, a service name or the hash of a block.
+type ContentRouting interface {
+	// Provide announce that the local peer provides the content of key given,
+	// the announcement will be refreshed until StopProviding called.
+	Provide(ctx context.Context, key string) error
+	// StopProviding stop refreshing the announcement of key given, which will expire after its ttl.
+	StopProviding(key string)
+	// FindProviders find the providers of the content of key given, and push the addresses of them to the chan
+	// returned, which will be closed when finding finished or ctx done.
+	FindProviders(ctx context.Context, key string) (<-chan ma.Multiaddr, error)
+}
+
 type Routing interface {
 	PeerRouting
 	ProtocolRouting
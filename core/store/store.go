@@ -7,31 +7,126 @@ SPDX-License-Identifier: Apache-2.0
 package store
 
 import (
+	"math"
+	"time"
+
+	"chainmaker.org/chainmaker/common/v2/crypto"
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
 	"chainmaker.org/chainmaker/net-liquid/core/protocol"
 	ma "github.com/multiformats/go-multiaddr"
 )
 
-// PeerStore is an interface wrapped AddrBook and ProtocolBook.
+// PeerStore is an interface wrapped AddrBook, ProtocolBook, MetricsBook, IdentifyBook and MetadataBook.
 type PeerStore interface {
 	AddrBook
 	ProtocolBook
+	MetricsBook
+	IdentifyBook
+	MetadataBook
+	// ForgetPeer remove all records of peer in all books,
+	// then call all the hooks registered with OnPeerForgotten.
+	ForgetPeer(pid peer.ID)
+	// OnPeerForgotten register a hook that will be called when a peer forgotten.
+	OnPeerForgotten(hook PeerForgottenHook)
+}
+
+// PeerForgottenHook is a function that will be called when a peer forgotten by PeerStore.
+type PeerForgottenHook func(pid peer.ID)
+
+// AddrSource is the source that a net address of peer learned from.
+type AddrSource uint8
+
+const (
+	// AddrSourceUnknown means the source of address is unknown, e.g. the address added with AddAddr.
+	AddrSourceUnknown AddrSource = iota
+	// AddrSourceConfig means the address is configured by user, e.g. the address of direct peers or seeds.
+	AddrSourceConfig
+	// AddrSourceIdentify means the address is a listen address told by the peer itself with identify protocol.
+	AddrSourceIdentify
+	// AddrSourceDiscovery means the address is found by discovery services.
+	AddrSourceDiscovery
+	// AddrSourceInbound means the address is the remote address observed on an inbound connection.
+	AddrSourceInbound
+	// AddrSourcePeerExchange means the address is in a signed peer record exchanged by neighbours.
+	AddrSourcePeerExchange
+)
+
+// String return the name of the source.
+func (s AddrSource) String() string {
+	switch s {
+	case AddrSourceConfig:
+		return "config"
+	case AddrSourceIdentify:
+		return "identify"
+	case AddrSourceDiscovery:
+		return "discovery"
+	case AddrSourceInbound:
+		return "inbound"
+	case AddrSourcePeerExchange:
+		return "peer-exchange"
+	default:
+		return "unknown"
+	}
+}
+
+const (
+	// PermanentAddrTTL is the ttl for the addresses that never expire.
+	PermanentAddrTTL time.Duration = math.MaxInt64
+	// ConnectedAddrTTL is the ttl for the addresses of peers connected.
+	// It should be replaced with RecentlyConnectedAddrTTL by SetAddrTTL when peer disconnected.
+	ConnectedAddrTTL = PermanentAddrTTL - 1
+	// RecentlyConnectedAddrTTL is the ttl for the addresses of peers disconnected recently.
+	RecentlyConnectedAddrTTL = time.Hour
+	// DiscoveryAddrTTL is the ttl for the addresses found by discovery services.
+	DiscoveryAddrTTL = 10 * time.Minute
+)
+
+// AddrInfo is the detail of a net address of peer.
+type AddrInfo struct {
+	// Addr is the net address.
+	Addr ma.Multiaddr
+	// Source is where the address learned from.
+	Source AddrSource
+	// Expires is the time when the address expires. Zero value means never expire.
+	Expires time.Time
+	// DialSuccess is the count of successful dialing to the address.
+	DialSuccess int
+	// DialFailure is the count of failed dialing to the address.
+	DialFailure int
+	// LastSuccess is the time of the last successful dialing.
+	LastSuccess time.Time
+	// LastFailure is the time of the last failed dialing.
+	LastFailure time.Time
 }
 
 // AddrBook is a store that manage the net addresses of peers.
 type AddrBook interface {
 	// AddAddr append some net addresses of peer.
+	// The addresses added will be with AddrSourceUnknown and PermanentAddrTTL.
 	AddAddr(pid peer.ID, addr ...ma.Multiaddr)
+	// AddAddrWithTTL append some net addresses of peer learned from the source given, which expire after ttl.
+	// If an address exists, its ttl will be extended if the new one is longer,
+	// and its source will be replaced if the new one is more trusted.
+	AddAddrWithTTL(pid peer.ID, source AddrSource, ttl time.Duration, addr ...ma.Multiaddr)
 	// SetAddrs record some addresses of peer.
 	// This function will clean all addresses that not in list.
 	SetAddrs(pid peer.ID, addrs []ma.Multiaddr)
+	// SetAddrTTL reset the ttl of some net addresses of peer. If no address given, all addresses of peer will be reset.
+	// The ttl of addresses with AddrSourceConfig will not be changed.
+	SetAddrTTL(pid peer.ID, ttl time.Duration, addr ...ma.Multiaddr)
 	// RemoveAddr remove some net addresses of peer.
 	RemoveAddr(pid peer.ID, addr ...ma.Multiaddr)
-	// GetFirstAddr return first net address of peer.
+	// RecordDialResult record the result of dialing to a net address of peer.
+	RecordDialResult(pid peer.ID, addr ma.Multiaddr, success bool)
+	// GetFirstAddr return the best-ranked net address of peer.
 	// If no address stored, return nil.
 	GetFirstAddr(pid peer.ID) ma.Multiaddr
-	// GetAddrs return all net address of peer.
+	// GetAddrs return all net address of peer not expired, ranked by likelihood of dialing success.
 	GetAddrs(pid peer.ID) []ma.Multiaddr
+	// AddrInfos return the details of all net address of peer not expired, ranked as GetAddrs.
+	AddrInfos(pid peer.ID) []*AddrInfo
+	// GCAddrs remove all addresses expired.
+	GCAddrs()
 }
 
 // ProtocolBook is a store that manage the protocols supported by peers.
@@ -54,3 +149,60 @@ type ProtocolBook interface {
 	// AllSupportProtocolPeers return the list of peer id which is the id of peers who support all protocols given.
 	AllSupportProtocolPeers(protocol ...protocol.ID) []peer.ID
 }
+
+// MetricsBook is a store that manage the metrics of peers.
+type MetricsBook interface {
+	// RecordLatency record a new latency measured of peer, the EWMA latency of peer will be updated.
+	RecordLatency(pid peer.ID, latency time.Duration)
+	// LatencyEWMA return the exponentially-weighted moving average latency of peer.
+	// If no latency recorded, return 0.
+	LatencyEWMA(pid peer.ID) time.Duration
+	// RemovePeerMetrics remove all metrics records of peer.
+	RemovePeerMetrics(pid peer.ID)
+}
+
+// IdentifyBook is a store that manage the information of peers learned from identify protocol.
+type IdentifyBook interface {
+	// SetPubKey record the public key of peer.
+	SetPubKey(pid peer.ID, pubKey crypto.PublicKey)
+	// PubKey return the public key of peer. If no public key recorded, return nil.
+	PubKey(pid peer.ID) crypto.PublicKey
+	// SetAgentVersion record the agent version string of peer.
+	SetAgentVersion(pid peer.ID, agentVersion string)
+	// AgentVersion return the agent version string of peer. If nothing recorded, return "".
+	AgentVersion(pid peer.ID) string
+	// RemovePeerIdentify remove all identify records of peer.
+	RemovePeerIdentify(pid peer.ID)
+}
+
+// MetadataCodec encode the metadata values of a key into bytes and decode them back.
+// The codec registered with a key decides the type of the values stored with the key.
+type MetadataCodec interface {
+	// Encode return the bytes of the value given.
+	// An error will be returned if the value is not of the type supported by the codec.
+	Encode(val interface{}) ([]byte, error)
+	// Decode return the value decoded from the bytes given.
+	Decode(data []byte) (interface{}, error)
+}
+
+// MetadataBook is a store that manage the metadata key/value pairs of peers.
+// It is used by upper layers to attach information of peers, e.g. chain membership, certs and public keys.
+// Each key should be registered with a MetadataCodec before used, which decides the type of its values.
+type MetadataBook interface {
+	// RegisterMetadataKey register a metadata key with the codec of its values.
+	// If the key has been registered, the codec will be replaced.
+	RegisterMetadataKey(key string, codec MetadataCodec)
+	// Put store a metadata value of peer with the key given. If the key exists, the value will be replaced.
+	// An error will be returned if the key is not registered or the value could not be encoded by its codec.
+	Put(pid peer.ID, key string, val interface{}) error
+	// Get return the metadata value of peer with the key given decoded by its codec, and whether it exists.
+	Get(pid peer.ID, key string) (interface{}, bool)
+	// Delete remove the metadata value of peer with the key given.
+	Delete(pid peer.ID, key string)
+	// ListKeys return all metadata keys of peer.
+	ListKeys(pid peer.ID) []string
+	// PeersWithKey return the metadata values with the key given of all peers who have it.
+	PeersWithKey(key string) map[peer.ID]interface{}
+	// RemovePeerMetadata remove all metadata of peer.
+	RemovePeerMetadata(pid peer.ID)
+}
@@ -0,0 +1,339 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/discovery"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/fsnotify/fsnotify"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// DefaultReloadDelay is the default delay of reloading after the bootstrap file changed,
+	// the events during the delay will be merged, so that a file being written will not be loaded.
+	DefaultReloadDelay = 500 * time.Millisecond
+
+	// findingChanSize is the extra size of finding chan besides the seeds applied,
+	// seeds added will be dropped if chan is full.
+	findingChanSize = 16
+)
+
+var (
+	// ErrInvalidSeed will be returned if a seed is not a multiaddr containing the peer id.
+	ErrInvalidSeed = errors.New("invalid seed, the multiaddr containing peer id required")
+	// ErrInvalidFile will be returned if the bootstrap file can not be parsed.
+	ErrInvalidFile = errors.New("invalid bootstrap file")
+	// ErrStarted will be returned if Start called more than once.
+	ErrStarted = errors.New("bootstrap discovery has been started")
+)
+
+// Option is a function to apply properties for bootstrap discovery service.
+type Option func(*Bootstrap) error
+
+func (b *Bootstrap) applyOptions(opts ...Option) error {
+	for _, opt := range opts {
+		if err := opt(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithLogger set a logger.
+func WithLogger(logger api.Logger) Option {
+	return func(b *Bootstrap) error {
+		b.logger = logger
+		return nil
+	}
+}
+
+// WithStaticPeers set the seeds that always applied, each of them must contain the peer id.
+func WithStaticPeers(addrs ...ma.Multiaddr) Option {
+	return func(b *Bootstrap) error {
+		for _, addr := range addrs {
+			if err := b.static.add(addr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// WithFile set the path of bootstrap file, which will be reloaded when changed.
+// The file whose extension is ".yaml" or ".yml" will be parsed as a YAML document,
+// otherwise one seed per line, see parseSeeds.
+func WithFile(path string) Option {
+	return func(b *Bootstrap) error {
+		b.path = path
+		return nil
+	}
+}
+
+// WithReloadDelay set the delay of reloading after the bootstrap file changed.
+func WithReloadDelay(delay time.Duration) Option {
+	return func(b *Bootstrap) error {
+		if delay > 0 {
+			b.reloadDelay = delay
+		}
+		return nil
+	}
+}
+
+var _ discovery.Discovery = (*Bootstrap)(nil)
+
+// Bootstrap provides a discovery service with the static seeds and the seeds listed in a local file.
+// The seeds are applied as the direct peers of host, so that the ConnSupervisor keeps connecting to them.
+// The file is watched, once it changed, the seeds added will be applied and the ones deleted will be removed,
+// so that operators could rotate the seeds by editing the file instead of redeploying.
+// A broken file will be ignored with the seeds applied kept.
+// Notice that a direct peer configured in other ways will also be removed if it is deleted from the file.
+type Bootstrap struct {
+	host        host.Host
+	static      *seedList
+	path        string
+	reloadDelay time.Duration
+
+	startOnce sync.Once
+	ctx       context.Context
+	cancel    context.CancelFunc
+	watcher   *fsnotify.Watcher
+
+	mu      sync.Mutex
+	applied *seedList                      // the seeds applied to host
+	finding map[chan ma.Multiaddr]struct{} // the finding tasks running, the seeds added will be pushed to them
+
+	logger api.Logger
+}
+
+// NewBootstrap create a new Bootstrap instance. Start should be called before using it.
+func NewBootstrap(host host.Host, opts ...Option) (*Bootstrap, error) {
+	b := &Bootstrap{
+		host:        host,
+		static:      newSeedList(),
+		reloadDelay: DefaultReloadDelay,
+		applied:     newSeedList(),
+		finding:     make(map[chan ma.Multiaddr]struct{}),
+		logger:      logger.NilLogger,
+	}
+	if err := b.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+	b.ctx, b.cancel = context.WithCancel(host.Context())
+	return b, nil
+}
+
+// Start apply the static seeds and the seeds in bootstrap file, then start watching the file.
+// An error will be returned if the file can not be loaded.
+func (b *Bootstrap) Start() error {
+	err := ErrStarted
+	b.startOnce.Do(func() {
+		err = b.start()
+	})
+	return err
+}
+
+func (b *Bootstrap) start() error {
+	if b.path == "" {
+		b.apply(b.static)
+		return nil
+	}
+	seeds, err := b.load()
+	if err != nil {
+		return err
+	}
+	// watch the directory instead of the file, for the file may be replaced by renaming,
+	// e.g. saved by editors or updated as a mounted ConfigMap.
+	b.watcher, err = fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err = b.watcher.Add(filepath.Dir(b.path)); err != nil {
+		_ = b.watcher.Close()
+		return err
+	}
+	b.apply(seeds)
+	go b.watchLoop()
+	return nil
+}
+
+// Stop watching the bootstrap file and close all finding chan. The seeds applied will be kept.
+func (b *Bootstrap) Stop() error {
+	b.cancel()
+	if b.watcher != nil {
+		return b.watcher.Close()
+	}
+	return nil
+}
+
+// load read the bootstrap file, then return the seeds in it with the static seeds.
+func (b *Bootstrap) load() (*seedList, error) {
+	data, err := ioutil.ReadFile(b.path)
+	if err != nil {
+		return nil, err
+	}
+	fileSeeds, err := parseSeeds(data, isYAMLFile(b.path))
+	if err != nil {
+		return nil, err
+	}
+	seeds := newSeedList()
+	seeds.merge(b.static)
+	seeds.merge(fileSeeds)
+	return seeds, nil
+}
+
+func (b *Bootstrap) watchLoop() {
+	// any change in the directory triggers reloading after the delay,
+	// for the file may be a link whose target changed, e.g. a mounted ConfigMap.
+	reloadTimer := time.NewTimer(b.reloadDelay)
+	reloadTimer.Stop()
+	defer reloadTimer.Stop()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case _, ok := <-b.watcher.Events:
+			if !ok {
+				return
+			}
+			reloadTimer.Reset(b.reloadDelay)
+		case err, ok := <-b.watcher.Errors:
+			if !ok {
+				return
+			}
+			b.logger.Warnf("[Bootstrap] watch bootstrap file failed, %s", err.Error())
+		case <-reloadTimer.C:
+			b.reload()
+		}
+	}
+}
+
+func (b *Bootstrap) reload() {
+	seeds, err := b.load()
+	if err != nil {
+		if os.IsNotExist(err) {
+			b.logger.Warnf("[Bootstrap] bootstrap file not found, seeds applied kept. (path: %s)", b.path)
+			return
+		}
+		b.logger.Warnf("[Bootstrap] load bootstrap file failed, seeds applied kept, %s (path: %s)",
+			err.Error(), b.path)
+		return
+	}
+	b.apply(seeds)
+}
+
+// apply the seeds given to host, the seeds applied before but not in the list given will be removed.
+func (b *Bootstrap) apply(seeds *seedList) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	removed, added := 0, 0
+	for _, pid := range b.applied.pids {
+		if _, ok := seeds.addrs[pid]; !ok {
+			b.host.RemoveDirectPeer(pid)
+			removed++
+		}
+	}
+	for _, pid := range seeds.pids {
+		addrs := seeds.addrs[pid]
+		old, ok := b.applied.addrs[pid]
+		if ok && sameAddrs(old, addrs) {
+			continue
+		}
+		if ok {
+			// the addresses of peer changed, replace them
+			b.host.RemoveDirectPeer(pid)
+		}
+		for _, addr := range addrs {
+			b.host.AddDirectPeer(addr)
+			b.push(addr)
+		}
+		added++
+	}
+	b.applied = seeds
+	if removed > 0 || added > 0 {
+		b.logger.Infof("[Bootstrap] seeds applied, %d added or changed, %d removed, %d in total.",
+			added, removed, len(seeds.pids))
+	}
+}
+
+// push the seed to all finding chan, should be called when b.mu locked.
+func (b *Bootstrap) push(addr ma.Multiaddr) {
+	for c := range b.finding {
+		select {
+		case c <- addr:
+		default:
+			b.logger.Debugf("[Bootstrap] finding chan is full, seed dropped. (addr: %s)", addr.String())
+		}
+	}
+}
+
+func sameAddrs(a, b []ma.Multiaddr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Announce does nothing, for the seeds are static.
+func (b *Bootstrap) Announce(_ context.Context, _ string, _ ...discovery.Option) error {
+	return nil
+}
+
+// FindPeers return a chan pushing the seeds applied, then the seeds added when the bootstrap file changed.
+// The seeds serve all services, so the service name is ignored.
+// The chan will be closed when ctx given done or Stop called.
+func (b *Bootstrap) FindPeers(ctx context.Context, _ string, _ ...discovery.Option) (<-chan ma.Multiaddr, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	total := 0
+	for _, pid := range b.applied.pids {
+		total += len(b.applied.addrs[pid])
+	}
+	c := make(chan ma.Multiaddr, total+findingChanSize)
+	for _, pid := range b.applied.pids {
+		for _, addr := range b.applied.addrs[pid] {
+			c <- addr
+		}
+	}
+	b.finding[c] = struct{}{}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-b.ctx.Done():
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.finding, c)
+		close(c)
+	}()
+	return c, nil
+}
+
+// Seeds return the peer ids of the seeds applied.
+func (b *Bootstrap) Seeds() []peer.ID {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := make([]peer.ID, len(b.applied.pids))
+	copy(res, b.applied.pids)
+	return res
+}
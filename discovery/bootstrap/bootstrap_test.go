@@ -0,0 +1,70 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bootstrap_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/discovery/bootstrap"
+	lHost "chainmaker.org/chainmaker/net-liquid/host"
+	"chainmaker.org/chainmaker/net-liquid/host/hosttest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBootstrapFile(t *testing.T) {
+	// hosts[1] bootstraps with the seeds in file, which rotated from hosts[0] to hosts[2]
+	hosts := make([]host.Host, 3)
+	for i := range hosts {
+		hosts[i] = hosttest.NewHost(t)
+	}
+	seed0 := hosttest.Addr(hosts[0])
+	seed2 := hosttest.Addr(hosts[2])
+	path := filepath.Join(t.TempDir(), "seeds.txt")
+	require.Nil(t, ioutil.WriteFile(path, []byte(seed0.String()+"\n"), 0600))
+
+	bs, err := bootstrap.NewBootstrap(hosts[1], bootstrap.WithFile(path),
+		bootstrap.WithReloadDelay(100*time.Millisecond))
+	require.Nil(t, err)
+	require.Nil(t, bs.Start())
+	defer func() {
+		_ = bs.Stop()
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	findingC, err := bs.FindPeers(ctx, "chain1")
+	require.Nil(t, err)
+	require.True(t, seed0.Equal(<-findingC))
+	require.Eventually(t, func() bool {
+		return hosts[1].ConnMgr().IsConnected(hosts[0].ID())
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// rotate the seeds
+	require.Nil(t, ioutil.WriteFile(path, []byte("# rotated\n"+seed2.String()+"\n"), 0600))
+	select {
+	case addr := <-findingC:
+		require.True(t, seed2.Equal(addr))
+	case <-time.After(5 * time.Second):
+		t.Fatal("seed added not found")
+	}
+	require.Eventually(t, func() bool {
+		return hosts[1].ConnMgr().IsConnected(hosts[2].ID())
+	}, 5*time.Second, 50*time.Millisecond)
+	status := hosts[1].(*lHost.BasicHost).DirectPeerStatus()
+	require.Len(t, status, 1)
+	require.Equal(t, hosts[2].ID(), status[0].PeerID)
+
+	// a broken file is ignored
+	require.Nil(t, ioutil.WriteFile(path, []byte("broken\n"), 0600))
+	time.Sleep(300 * time.Millisecond)
+	require.Equal(t, []peer.ID{hosts[2].ID()}, bs.Seeds())
+}
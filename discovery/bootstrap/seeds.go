@@ -0,0 +1,116 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bootstrap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	ma "github.com/multiformats/go-multiaddr"
+	"gopkg.in/yaml.v3"
+)
+
+// seedList stores the addresses of bootstrap peers grouped by peer id, the order of peers in the source is kept.
+type seedList struct {
+	pids  []peer.ID
+	addrs map[peer.ID][]ma.Multiaddr
+}
+
+func newSeedList() *seedList {
+	return &seedList{addrs: make(map[peer.ID][]ma.Multiaddr)}
+}
+
+// add a seed address, which must contain the peer id, e.g. "/ip4/127.0.0.1/tcp/8081/p2p/QmXXX".
+func (l *seedList) add(addr ma.Multiaddr) error {
+	netAddr, pid := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+	if pid == "" || netAddr == nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSeed, addr.String())
+	}
+	exists, ok := l.addrs[pid]
+	if !ok {
+		l.pids = append(l.pids, pid)
+	}
+	for i := range exists {
+		if exists[i].Equal(addr) {
+			return nil
+		}
+	}
+	l.addrs[pid] = append(exists, addr)
+	return nil
+}
+
+// merge the seeds of another list into this one.
+func (l *seedList) merge(other *seedList) {
+	for _, pid := range other.pids {
+		for _, addr := range other.addrs[pid] {
+			_ = l.add(addr)
+		}
+	}
+}
+
+// isYAMLFile return whether the bootstrap file should be parsed as a YAML document.
+func isYAMLFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// parseSeeds parse the content of a bootstrap file.
+// A YAML document should be either a list of seeds or a map with the list under "seeds" key,
+// e.g. "seeds: [/ip4/127.0.0.1/tcp/8081/p2p/QmXXX]".
+// Otherwise, the content should be one seed per line, blank lines and lines starting with '#' are ignored.
+// Any invalid seed fails the whole parsing, so that a broken file will never remove the seeds applied.
+func parseSeeds(data []byte, isYAML bool) (*seedList, error) {
+	var lines []string
+	if isYAML {
+		var err error
+		if lines, err = parseYAMLSeeds(data); err != nil {
+			return nil, err
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	res := newSeedList()
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addr, err := ma.NewMultiaddr(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d, %s", ErrInvalidSeed, i+1, err.Error())
+		}
+		if err = res.add(addr); err != nil {
+			return nil, fmt.Errorf("line %d, %w", i+1, err)
+		}
+	}
+	return res, nil
+}
+
+func parseYAMLSeeds(data []byte) ([]string, error) {
+	doc := struct {
+		Seeds []string `yaml:"seeds"`
+	}{}
+	if err := yaml.Unmarshal(data, &doc); err == nil {
+		return doc.Seeds, nil
+	}
+	var list []string
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err.Error())
+	}
+	return list, nil
+}
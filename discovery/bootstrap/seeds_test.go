@@ -0,0 +1,63 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package bootstrap
+
+import (
+	"errors"
+	"testing"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPID1 = "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4"
+	testPID2 = "QmeyNRs2DwWjcHTpcVHoUSaDAAif4VQZ2wQDQAUNDP33gH"
+)
+
+func TestParseSeeds(t *testing.T) {
+	lines := `
+# seeds of chain1
+/ip4/127.0.0.1/tcp/8081/p2p/` + testPID1 + `
+/ip4/127.0.0.1/tcp/8082/p2p/` + testPID2 + `
+/ip4/192.168.1.1/tcp/8081/p2p/` + testPID1 + `
+/ip4/127.0.0.1/tcp/8081/p2p/` + testPID1 + `
+`
+	seeds, err := parseSeeds([]byte(lines), false)
+	require.Nil(t, err)
+	require.Equal(t, []peer.ID{testPID1, testPID2}, seeds.pids)
+	require.Equal(t, 2, len(seeds.addrs[testPID1]))
+	require.Equal(t, 1, len(seeds.addrs[testPID2]))
+
+	doc := `
+seeds:
+  - /ip4/127.0.0.1/tcp/8081/p2p/` + testPID1 + `
+  - /ip4/127.0.0.1/tcp/8082/p2p/` + testPID2 + `
+`
+	seeds, err = parseSeeds([]byte(doc), true)
+	require.Nil(t, err)
+	require.Equal(t, []peer.ID{testPID1, testPID2}, seeds.pids)
+
+	list := `
+- /ip4/127.0.0.1/tcp/8081/p2p/` + testPID1 + `
+`
+	seeds, err = parseSeeds([]byte(list), true)
+	require.Nil(t, err)
+	require.Equal(t, []peer.ID{testPID1}, seeds.pids)
+
+	seeds, err = parseSeeds(nil, false)
+	require.Nil(t, err)
+	require.Equal(t, 0, len(seeds.pids))
+
+	// any invalid seed fails the whole parsing
+	_, err = parseSeeds([]byte(lines+"/ip4/127.0.0.1/tcp/8083\n"), false)
+	require.True(t, errors.Is(err, ErrInvalidSeed))
+	_, err = parseSeeds([]byte(lines+"not a multiaddr\n"), false)
+	require.True(t, errors.Is(err, ErrInvalidSeed))
+	_, err = parseSeeds([]byte("seeds: 1"), true)
+	require.True(t, errors.Is(err, ErrInvalidFile))
+}
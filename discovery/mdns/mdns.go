@@ -0,0 +1,436 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mdns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/discovery"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/reuse"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/net/ipv4"
+)
+
+const (
+	// DefaultQueryInterval is the default interval of sending queries when finding peers.
+	DefaultQueryInterval = 30 * time.Second
+	// DefaultRecordTTL is the default ttl of records in responses.
+	DefaultRecordTTL = 2 * time.Minute
+
+	mdnsPort = 5353
+	// maxPacketSize is the max size of mDNS packets, see RFC 6762 section 17.
+	maxPacketSize = 9000
+	// findingChanSize is the size of finding chan, peers found will be dropped if chan is full.
+	findingChanSize = 16
+	// maxServiceNameLength is the max length of service name that fits in a TXT string.
+	maxServiceNameLength = maxTXTLength - len(txtKeyService)
+)
+
+var (
+	// ErrFinding will be returned if there is already a finding task running when calling FindPeers method.
+	ErrFinding = errors.New("there is already a finding task running, try it again later")
+	// ErrInvalidServiceName will be returned if the service name is empty or too long.
+	ErrInvalidServiceName = errors.New("invalid service name")
+	// ErrNoInterface will be returned if no interface up and supporting multicast selected.
+	ErrNoInterface = errors.New("no multicast interface available")
+	// ErrStarted will be returned if Start called more than once.
+	ErrStarted = errors.New("mdns discovery has been started")
+
+	mdnsGroupIPv4 = net.IPv4(224, 0, 0, 251)
+	mdnsGroupAddr = &net.UDPAddr{IP: mdnsGroupIPv4, Port: mdnsPort}
+)
+
+// Option is a function to apply properties for mDNS discovery service.
+type Option func(*MdnsDiscovery) error
+
+func (d *MdnsDiscovery) applyOptions(opts ...Option) error {
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithLogger set a logger.
+func WithLogger(logger api.Logger) Option {
+	return func(d *MdnsDiscovery) error {
+		d.logger = logger
+		return nil
+	}
+}
+
+// WithInterfaces set the names of network interfaces that mDNS working on.
+// If not set, all interfaces up and supporting multicast will be used.
+func WithInterfaces(names ...string) Option {
+	return func(d *MdnsDiscovery) error {
+		d.ifaceNames = names
+		return nil
+	}
+}
+
+// WithQueryInterval set the interval of sending queries when finding peers.
+func WithQueryInterval(interval time.Duration) Option {
+	return func(d *MdnsDiscovery) error {
+		if interval > 0 {
+			d.queryInterval = interval
+		}
+		return nil
+	}
+}
+
+var _ discovery.Discovery = (*MdnsDiscovery)(nil)
+var _ discovery.Unannouncer = (*MdnsDiscovery)(nil)
+
+// MdnsDiscovery provides a discovery service finding peers in the local network with multicast DNS.
+// Each host announced answers the queries for liquid service type with its addresses and services announced,
+// and the hosts finding peers query periodically and push the peers announcing the same service to finding chan.
+// No bootstrap peer is needed, so it is useful for development or private deployment in a LAN.
+type MdnsDiscovery struct {
+	host          host.Host
+	ifaceNames    []string
+	queryInterval time.Duration
+
+	startOnce sync.Once
+	ctx       context.Context
+	cancel    context.CancelFunc
+	conn      *ipv4.PacketConn
+	ifaces    []net.Interface
+	ifaceIPs  []net.IP
+
+	servicesMu sync.RWMutex
+	services   map[string]struct{}
+	findingMap sync.Map // map[string]chan ma.Multiaddr, stores task of finding services
+
+	logger api.Logger
+}
+
+// NewMdnsDiscovery create a new MdnsDiscovery instance. Start should be called before using it.
+func NewMdnsDiscovery(host host.Host, opts ...Option) (*MdnsDiscovery, error) {
+	d := &MdnsDiscovery{
+		host:          host,
+		queryInterval: DefaultQueryInterval,
+		services:      make(map[string]struct{}),
+		logger:        logger.NilLogger,
+	}
+	if err := d.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+	d.ctx, d.cancel = context.WithCancel(host.Context())
+	return d, nil
+}
+
+// Start listen on mDNS port, join the multicast group on the interfaces selected, then start receiving msg.
+func (d *MdnsDiscovery) Start() error {
+	err := ErrStarted
+	d.startOnce.Do(func() {
+		err = d.start()
+	})
+	return err
+}
+
+func (d *MdnsDiscovery) start() error {
+	ifaces, ips, err := selectInterfaces(d.ifaceNames)
+	if err != nil {
+		return err
+	}
+	lc := net.ListenConfig{Control: reuse.Control}
+	pc, err := lc.ListenPacket(d.ctx, "udp4", (&net.UDPAddr{IP: net.IPv4zero, Port: mdnsPort}).String())
+	if err != nil {
+		return err
+	}
+	conn := ipv4.NewPacketConn(pc)
+	joined := make([]net.Interface, 0, len(ifaces))
+	for i := range ifaces {
+		if e := conn.JoinGroup(&ifaces[i], &net.UDPAddr{IP: mdnsGroupIPv4}); e != nil {
+			d.logger.Warnf("[MdnsDiscovery] join multicast group failed, %s (interface: %s)",
+				e.Error(), ifaces[i].Name)
+			continue
+		}
+		joined = append(joined, ifaces[i])
+	}
+	if len(joined) == 0 {
+		_ = conn.Close()
+		return ErrNoInterface
+	}
+	// loopback is needed for the hosts running on the same machine
+	if err = conn.SetMulticastLoopback(true); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if err = conn.SetMulticastTTL(255); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if err = conn.SetControlMessage(ipv4.FlagInterface, true); err != nil {
+		_ = conn.Close()
+		return err
+	}
+	d.conn, d.ifaces, d.ifaceIPs = conn, joined, ips
+	go d.receiveLoop()
+	go func() {
+		<-d.ctx.Done()
+		_ = d.conn.Close()
+	}()
+	d.logger.Infof("[MdnsDiscovery] started, working on %d interface(s)", len(joined))
+	return nil
+}
+
+// Close stop all the tasks of finding peers and close the mDNS socket.
+func (d *MdnsDiscovery) Close() error {
+	d.cancel()
+	return nil
+}
+
+// selectInterfaces return the interfaces up and supporting multicast with IPv4 addresses,
+// and the IPv4 addresses of them. If names given, only interfaces with these names will be selected.
+func selectInterfaces(names []string) ([]net.Interface, []net.IP, error) {
+	all, err := net.Interfaces()
+	if err != nil {
+		return nil, nil, err
+	}
+	ifaces := make([]net.Interface, 0, len(all))
+	ips := make([]net.IP, 0)
+	for _, ifi := range all {
+		if ifi.Flags&net.FlagUp == 0 || ifi.Flags&net.FlagMulticast == 0 {
+			continue
+		}
+		if len(names) > 0 && !containsString(names, ifi.Name) {
+			continue
+		}
+		addrs, e := ifi.Addrs()
+		if e != nil {
+			continue
+		}
+		ifaceIPs := make([]net.IP, 0, len(addrs))
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if ok && ipNet.IP.To4() != nil {
+				ifaceIPs = append(ifaceIPs, ipNet.IP)
+			}
+		}
+		if len(ifaceIPs) == 0 {
+			continue
+		}
+		ifaces = append(ifaces, ifi)
+		ips = append(ips, ifaceIPs...)
+	}
+	if len(ifaces) == 0 {
+		return nil, nil, ErrNoInterface
+	}
+	return ifaces, ips, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *MdnsDiscovery) receiveLoop() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, cm, _, err := d.conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-d.ctx.Done():
+				return
+			default:
+			}
+			d.logger.Debugf("[MdnsDiscovery] read msg failed, %s", err.Error())
+			continue
+		}
+		if cm != nil && !d.isSelectedInterface(cm.IfIndex) {
+			continue
+		}
+		var msg dnsmessage.Message
+		if err = msg.Unpack(buf[:n]); err != nil {
+			continue
+		}
+		if isQuery(&msg) {
+			d.respond()
+			continue
+		}
+		for _, entry := range parseResponse(&msg) {
+			d.handleEntry(entry)
+		}
+	}
+}
+
+func (d *MdnsDiscovery) isSelectedInterface(index int) bool {
+	for i := range d.ifaces {
+		if d.ifaces[i].Index == index {
+			return true
+		}
+	}
+	return false
+}
+
+// send the msg to mDNS group through every interface selected.
+func (d *MdnsDiscovery) send(msg []byte) {
+	if d.conn == nil {
+		return
+	}
+	for i := range d.ifaces {
+		_, err := d.conn.WriteTo(msg, &ipv4.ControlMessage{IfIndex: d.ifaces[i].Index}, mdnsGroupAddr)
+		if err != nil {
+			d.logger.Debugf("[MdnsDiscovery] send msg failed, %s (interface: %s)", err.Error(), d.ifaces[i].Name)
+		}
+	}
+}
+
+// respond send a response with local addresses and services announced if any service announced.
+func (d *MdnsDiscovery) respond() {
+	d.servicesMu.RLock()
+	services := make([]string, 0, len(d.services))
+	for name := range d.services {
+		services = append(services, name)
+	}
+	d.servicesMu.RUnlock()
+	if len(services) == 0 {
+		return
+	}
+	addrs := d.localAddrs()
+	if len(addrs) == 0 {
+		return
+	}
+	msg, err := buildResponse(d.host.ID(), addrs, services, uint32(DefaultRecordTTL/time.Second))
+	if err != nil {
+		d.logger.Warnf("[MdnsDiscovery] build response failed, %s", err.Error())
+		return
+	}
+	d.send(msg)
+}
+
+// localAddrs return the listen addresses of host that reachable in the local network,
+// e.g. the loopback addresses and the addresses on the interfaces selected.
+func (d *MdnsDiscovery) localAddrs() []ma.Multiaddr {
+	res := make([]ma.Multiaddr, 0)
+	for _, addr := range d.host.LocalAddresses() {
+		ip, err := manet.ToIP(addr)
+		if err != nil {
+			continue
+		}
+		if ip.IsLoopback() || d.isInterfaceIP(ip) {
+			res = append(res, addr)
+		}
+	}
+	return res
+}
+
+func (d *MdnsDiscovery) isInterfaceIP(ip net.IP) bool {
+	for _, ifaceIP := range d.ifaceIPs {
+		if ifaceIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleEntry record the addresses of peer found, then push the first one to the finding chan of
+// each service the peer announced.
+func (d *MdnsDiscovery) handleEntry(entry *peerEntry) {
+	if entry.pid == d.host.ID() || len(entry.addrs) == 0 {
+		return
+	}
+	d.host.PeerStore().AddAddrWithTTL(entry.pid, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, entry.addrs...)
+	addr := util.CreateMultiAddrWithPidAndNetAddr(entry.pid, entry.addrs[0])
+	for _, name := range entry.services {
+		v, ok := d.findingMap.Load(name)
+		if !ok {
+			continue
+		}
+		// never block the receiving loop
+		select {
+		case v.(chan ma.Multiaddr) <- addr:
+		default:
+		}
+	}
+}
+
+// Announce tell the hosts in the local network that we are providing the service,
+// by answering their queries from now on and sending an unsolicited response immediately.
+func (d *MdnsDiscovery) Announce(_ context.Context, serviceName string, _ ...discovery.Option) error {
+	name := strings.TrimSpace(serviceName)
+	if !validServiceName(name) {
+		return ErrInvalidServiceName
+	}
+	d.servicesMu.Lock()
+	d.services[name] = struct{}{}
+	d.servicesMu.Unlock()
+	d.respond()
+	return nil
+}
+
+// Unannounce stop answering the queries for the service. The others will forget it after the ttl of records.
+func (d *MdnsDiscovery) Unannounce(_ context.Context, serviceName string, _ ...discovery.Option) error {
+	d.servicesMu.Lock()
+	delete(d.services, strings.TrimSpace(serviceName))
+	d.servicesMu.Unlock()
+	return nil
+}
+
+// FindPeers run a loop task sending queries in the local network periodically, and addresses of peers
+// announcing the service will be push to result chan.
+// If you want to quit finding task, the ctx should be canceled.
+func (d *MdnsDiscovery) FindPeers(ctx context.Context, serviceName string, _ ...discovery.Option) (
+	<-chan ma.Multiaddr, error) {
+	name := strings.TrimSpace(serviceName)
+	if !validServiceName(name) {
+		return nil, ErrInvalidServiceName
+	}
+	findingC := make(chan ma.Multiaddr, findingChanSize)
+	_, ok := d.findingMap.LoadOrStore(name, findingC)
+	if ok {
+		return nil, ErrFinding
+	}
+
+	go d.queryTask(ctx, name)
+
+	return findingC, nil
+}
+
+func (d *MdnsDiscovery) queryTask(ctx context.Context, name string) {
+	defer d.findingMap.Delete(name)
+	query, err := buildQuery()
+	if err != nil {
+		d.logger.Errorf("[MdnsDiscovery] build query failed, %s", err.Error())
+		return
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.ctx.Done():
+			return
+		case <-timer.C:
+		}
+		d.send(query)
+		timer.Reset(d.queryInterval)
+	}
+}
+
+func validServiceName(name string) bool {
+	return name != "" && len(name) <= maxServiceNameLength
+}
@@ -0,0 +1,55 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mdns_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/mdns"
+	"chainmaker.org/chainmaker/net-liquid/host/hosttest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMdnsDiscovery(t *testing.T) {
+	hosts := make([]host.Host, 2)
+	mds := make([]*mdns.MdnsDiscovery, 2)
+	for i := range hosts {
+		hosts[i] = hosttest.NewHost(t)
+		md, err := mdns.NewMdnsDiscovery(hosts[i], mdns.WithQueryInterval(time.Second))
+		require.Nil(t, err)
+		mds[i] = md
+		t.Cleanup(func() {
+			_ = md.Close()
+		})
+	}
+	for i := range mds {
+		if err := mds[i].Start(); err != nil {
+			t.Skipf("multicast not available, %s", err.Error())
+		}
+	}
+
+	require.Nil(t, mds[0].Announce(context.Background(), "chain1"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	findingC, err := mds[1].FindPeers(ctx, "chain1")
+	require.Nil(t, err)
+	_, err = mds[1].FindPeers(ctx, "chain1")
+	require.Equal(t, mdns.ErrFinding, err)
+	select {
+	case addr := <-findingC:
+		netAddr, pid := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+		require.Equal(t, hosts[0].ID(), pid)
+		require.True(t, netAddr.Equal(hosts[0].LocalAddresses()[0]))
+	case <-time.After(5 * time.Second):
+		t.Fatal("peer announced not found")
+	}
+	require.NotEmpty(t, hosts[1].PeerStore().GetAddrs(hosts[0].ID()))
+}
@@ -0,0 +1,148 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mdns
+
+import (
+	"strings"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	ma "github.com/multiformats/go-multiaddr"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// serviceType is the DNS-SD service type of liquid hosts.
+	serviceType = "_liquid._udp.local."
+
+	txtKeyAddr    = "dnsaddr="
+	txtKeyService = "svc="
+	// maxTXTLength is the max length of each string in TXT record.
+	maxTXTLength = 255
+)
+
+// peerEntry is the info of peer parsed from a mDNS response.
+type peerEntry struct {
+	pid      peer.ID
+	addrs    []ma.Multiaddr
+	services []string
+}
+
+// buildQuery create a mDNS query asking for the instances of liquid service type.
+func buildQuery() ([]byte, error) {
+	name, err := dnsmessage.NewName(serviceType)
+	if err != nil {
+		return nil, err
+	}
+	msg := dnsmessage.Message{
+		Questions: []dnsmessage.Question{
+			{Name: name, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET},
+		},
+	}
+	return msg.Pack()
+}
+
+// buildResponse create a mDNS response that tells the instance of peer with a PTR record,
+// and the addresses and the services announced of peer with a TXT record.
+func buildResponse(pid peer.ID, addrs []ma.Multiaddr, services []string, ttl uint32) ([]byte, error) {
+	svcName, err := dnsmessage.NewName(serviceType)
+	if err != nil {
+		return nil, err
+	}
+	instanceName, err := dnsmessage.NewName(pid.ToString() + "." + serviceType)
+	if err != nil {
+		return nil, err
+	}
+	txt := make([]string, 0, len(addrs)+len(services))
+	for i := range addrs {
+		s := txtKeyAddr + util.CreateMultiAddrWithPidAndNetAddr(pid, addrs[i]).String()
+		if len(s) <= maxTXTLength {
+			txt = append(txt, s)
+		}
+	}
+	for i := range services {
+		s := txtKeyService + services[i]
+		if len(s) <= maxTXTLength {
+			txt = append(txt, s)
+		}
+	}
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{Response: true, Authoritative: true},
+		Answers: []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{
+					Name: svcName, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET, TTL: ttl,
+				},
+				Body: &dnsmessage.PTRResource{PTR: instanceName},
+			},
+		},
+		Additionals: []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{
+					Name: instanceName, Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassINET, TTL: ttl,
+				},
+				Body: &dnsmessage.TXTResource{TXT: txt},
+			},
+		},
+	}
+	return msg.Pack()
+}
+
+// isQuery return whether the mDNS msg is a query asking for liquid service type.
+func isQuery(msg *dnsmessage.Message) bool {
+	if msg.Header.Response {
+		return false
+	}
+	for _, q := range msg.Questions {
+		if strings.EqualFold(q.Name.String(), serviceType) &&
+			(q.Type == dnsmessage.TypePTR || q.Type == dnsmessage.TypeALL) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseResponse parse the peer entries from the TXT records of liquid instances in the mDNS response.
+// The addresses whose peer id is not the one of instance will be ignored.
+func parseResponse(msg *dnsmessage.Message) []*peerEntry {
+	if !msg.Header.Response {
+		return nil
+	}
+	entries := make([]*peerEntry, 0)
+	resources := make([]dnsmessage.Resource, 0, len(msg.Answers)+len(msg.Additionals))
+	resources = append(resources, msg.Answers...)
+	resources = append(resources, msg.Additionals...)
+	for _, r := range resources {
+		txt, ok := r.Body.(*dnsmessage.TXTResource)
+		if !ok {
+			continue
+		}
+		name := r.Header.Name.String()
+		if !strings.HasSuffix(strings.ToLower(name), "."+serviceType) {
+			continue
+		}
+		entry := &peerEntry{pid: peer.ID(name[:len(name)-len(serviceType)-1])}
+		for _, s := range txt.TXT {
+			switch {
+			case strings.HasPrefix(s, txtKeyAddr):
+				addr, err := ma.NewMultiaddr(s[len(txtKeyAddr):])
+				if err != nil {
+					continue
+				}
+				netAddr, pid := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+				if netAddr == nil || pid != entry.pid {
+					continue
+				}
+				entry.addrs = append(entry.addrs, netAddr)
+			case strings.HasPrefix(s, txtKeyService):
+				entry.services = append(entry.services, s[len(txtKeyService):])
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
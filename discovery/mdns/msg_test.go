@@ -0,0 +1,68 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mdns
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker/common/v2/crypto"
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestBuildAndParse(t *testing.T) {
+	sk, err := asym.GenerateKeyPair(crypto.ECC_NISTP256)
+	require.Nil(t, err)
+	pid, err := util.ResolvePIDFromPubKey(sk.PublicKey())
+	require.Nil(t, err)
+	addrs := []ma.Multiaddr{ma.StringCast("/ip4/127.0.0.1/tcp/8081"), ma.StringCast("/ip4/192.168.1.2/tcp/8081")}
+
+	// query
+	b, err := buildQuery()
+	require.Nil(t, err)
+	var msg dnsmessage.Message
+	require.Nil(t, msg.Unpack(b))
+	require.True(t, isQuery(&msg))
+	require.Len(t, parseResponse(&msg), 0)
+
+	// response
+	b, err = buildResponse(pid, addrs, []string{"chain1", "chain2"}, 120)
+	require.Nil(t, err)
+	msg = dnsmessage.Message{}
+	require.Nil(t, msg.Unpack(b))
+	require.False(t, isQuery(&msg))
+	entries := parseResponse(&msg)
+	require.Len(t, entries, 1)
+	require.Equal(t, pid, entries[0].pid)
+	require.Len(t, entries[0].addrs, 2)
+	require.True(t, addrs[0].Equal(entries[0].addrs[0]))
+	require.True(t, addrs[1].Equal(entries[0].addrs[1]))
+	require.Equal(t, []string{"chain1", "chain2"}, entries[0].services)
+
+	// addresses of another peer will be ignored
+	sk2, err := asym.GenerateKeyPair(crypto.ECC_NISTP256)
+	require.Nil(t, err)
+	pid2, err := util.ResolvePIDFromPubKey(sk2.PublicKey())
+	require.Nil(t, err)
+	name, err := dnsmessage.NewName(pid.ToString() + "." + serviceType)
+	require.Nil(t, err)
+	msg = dnsmessage.Message{
+		Header: dnsmessage.Header{Response: true},
+		Answers: []dnsmessage.Resource{{
+			Header: dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassINET},
+			Body: &dnsmessage.TXTResource{
+				TXT: []string{txtKeyAddr + util.CreateMultiAddrWithPidAndNetAddr(pid2, addrs[0]).String()},
+			},
+		}},
+	}
+	entries = parseResponse(&msg)
+	require.Len(t, entries, 1)
+	require.Len(t, entries[0].addrs, 0)
+}
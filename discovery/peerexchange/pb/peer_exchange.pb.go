@@ -0,0 +1,443 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: peer_exchange.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type PeerExchangeMsg_Type int32
+
+const (
+	PeerExchangeMsg_Swap    PeerExchangeMsg_Type = 0
+	PeerExchangeMsg_SwapRes PeerExchangeMsg_Type = 1
+	PeerExchangeMsg_Prune   PeerExchangeMsg_Type = 2
+)
+
+var PeerExchangeMsg_Type_name = map[int32]string{
+	0: "Swap",
+	1: "SwapRes",
+	2: "Prune",
+}
+
+var PeerExchangeMsg_Type_value = map[string]int32{
+	"Swap":    0,
+	"SwapRes": 1,
+	"Prune":   2,
+}
+
+func (x PeerExchangeMsg_Type) String() string {
+	return proto.EnumName(PeerExchangeMsg_Type_name, int32(x))
+}
+
+func (PeerExchangeMsg_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_25f68f8212a6a7dd, []int{0, 0}
+}
+
+type PeerExchangeMsg struct {
+	Type         PeerExchangeMsg_Type `protobuf:"varint,1,opt,name=type,proto3,enum=peerexchange.PeerExchangeMsg_Type" json:"type,omitempty"`
+	SignedRecord []byte               `protobuf:"bytes,2,opt,name=signedRecord,proto3" json:"signedRecord,omitempty"`
+	Records      [][]byte             `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
+}
+
+func (m *PeerExchangeMsg) Reset()         { *m = PeerExchangeMsg{} }
+func (m *PeerExchangeMsg) String() string { return proto.CompactTextString(m) }
+func (*PeerExchangeMsg) ProtoMessage()    {}
+func (*PeerExchangeMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_25f68f8212a6a7dd, []int{0}
+}
+func (m *PeerExchangeMsg) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PeerExchangeMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PeerExchangeMsg.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PeerExchangeMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerExchangeMsg.Merge(m, src)
+}
+func (m *PeerExchangeMsg) XXX_Size() int {
+	return m.Size()
+}
+func (m *PeerExchangeMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerExchangeMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerExchangeMsg proto.InternalMessageInfo
+
+func (m *PeerExchangeMsg) GetType() PeerExchangeMsg_Type {
+	if m != nil {
+		return m.Type
+	}
+	return PeerExchangeMsg_Swap
+}
+
+func (m *PeerExchangeMsg) GetSignedRecord() []byte {
+	if m != nil {
+		return m.SignedRecord
+	}
+	return nil
+}
+
+func (m *PeerExchangeMsg) GetRecords() [][]byte {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("peerexchange.PeerExchangeMsg_Type", PeerExchangeMsg_Type_name, PeerExchangeMsg_Type_value)
+	proto.RegisterType((*PeerExchangeMsg)(nil), "peerexchange.PeerExchangeMsg")
+}
+
+func init() { proto.RegisterFile("peer_exchange.proto", fileDescriptor_25f68f8212a6a7dd) }
+
+var fileDescriptor_25f68f8212a6a7dd = []byte{
+	// 261 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x90, 0x41, 0x4a, 0xc4, 0x30,
+	0x14, 0x86, 0x9b, 0x99, 0xea, 0x68, 0x2c, 0x5a, 0xe2, 0xa6, 0xab, 0x50, 0xba, 0xea, 0xc6, 0x06,
+	0x14, 0x3c, 0x80, 0x20, 0xb8, 0x11, 0x86, 0xea, 0xca, 0x8d, 0xb4, 0xcd, 0x23, 0x13, 0x9c, 0x49,
+	0x62, 0xd2, 0xaa, 0xbd, 0x85, 0x57, 0xf1, 0x16, 0x2e, 0x67, 0xe9, 0x52, 0xda, 0x8b, 0x48, 0x8b,
+	0x83, 0xe3, 0xec, 0xfe, 0xff, 0xe3, 0x7b, 0x0f, 0xde, 0xc3, 0xa7, 0x06, 0xc0, 0x3e, 0xc2, 0x5b,
+	0xb5, 0x28, 0x94, 0x80, 0xcc, 0x58, 0x5d, 0x6b, 0x12, 0x0c, 0x70, 0xc3, 0x92, 0x0f, 0x84, 0x4f,
+	0xe6, 0x00, 0xf6, 0xfa, 0x17, 0xdc, 0x3a, 0x41, 0x2e, 0xb1, 0x5f, 0xb7, 0x06, 0x22, 0x14, 0xa3,
+	0xf4, 0xf8, 0x3c, 0xc9, 0xb6, 0x07, 0xb2, 0x1d, 0x39, 0xbb, 0x6f, 0x0d, 0xe4, 0xa3, 0x4f, 0x12,
+	0x1c, 0x38, 0x29, 0x14, 0xf0, 0x1c, 0x2a, 0x6d, 0x79, 0x34, 0x89, 0x51, 0x1a, 0xe4, 0xff, 0x18,
+	0x89, 0xf0, 0xcc, 0x8e, 0xc9, 0x45, 0xd3, 0x78, 0x9a, 0x06, 0xf9, 0xa6, 0x26, 0x29, 0xf6, 0x87,
+	0x5d, 0xe4, 0x00, 0xfb, 0x77, 0xaf, 0x85, 0x09, 0x3d, 0x72, 0x84, 0x67, 0x43, 0xca, 0xc1, 0x85,
+	0x88, 0x1c, 0xe2, 0xbd, 0xb9, 0x6d, 0x14, 0x84, 0x93, 0xab, 0xf2, 0xb3, 0xa3, 0x68, 0xdd, 0x51,
+	0xf4, 0xdd, 0x51, 0xf4, 0xde, 0x53, 0x6f, 0xdd, 0x53, 0xef, 0xab, 0xa7, 0xde, 0xc3, 0x4d, 0xb5,
+	0x28, 0xa4, 0x5a, 0x15, 0x4f, 0x60, 0x33, 0x6d, 0x05, 0xfb, 0xab, 0x67, 0x42, 0xb3, 0x95, 0xe6,
+	0xcd, 0x12, 0x98, 0x82, 0x9a, 0x2d, 0xe5, 0x73, 0x23, 0x39, 0xe3, 0xd2, 0x55, 0xfa, 0x05, 0x6c,
+	0xcb, 0xb6, 0x6f, 0x64, 0xa6, 0x2c, 0xf7, 0xc7, 0x67, 0x5d, 0xfc, 0x0c, 0x00, 0x7f, 0x79, 0xf4,
+	0xd7, 0x43, 0x01, 0x00, 0x00,
+}
+
+func (m *PeerExchangeMsg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PeerExchangeMsg) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PeerExchangeMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Records) > 0 {
+		for iNdEx := len(m.Records) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Records[iNdEx])
+			copy(dAtA[i:], m.Records[iNdEx])
+			i = encodeVarintPeerExchange(dAtA, i, uint64(len(m.Records[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.SignedRecord) > 0 {
+		i -= len(m.SignedRecord)
+		copy(dAtA[i:], m.SignedRecord)
+		i = encodeVarintPeerExchange(dAtA, i, uint64(len(m.SignedRecord)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Type != 0 {
+		i = encodeVarintPeerExchange(dAtA, i, uint64(m.Type))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintPeerExchange(dAtA []byte, offset int, v uint64) int {
+	offset -= sovPeerExchange(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *PeerExchangeMsg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovPeerExchange(uint64(m.Type))
+	}
+	l = len(m.SignedRecord)
+	if l > 0 {
+		n += 1 + l + sovPeerExchange(uint64(l))
+	}
+	if len(m.Records) > 0 {
+		for _, b := range m.Records {
+			l = len(b)
+			n += 1 + l + sovPeerExchange(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovPeerExchange(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozPeerExchange(x uint64) (n int) {
+	return sovPeerExchange(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *PeerExchangeMsg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPeerExchange
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PeerExchangeMsg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PeerExchangeMsg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerExchange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= PeerExchangeMsg_Type(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SignedRecord", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerExchange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPeerExchange
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPeerExchange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SignedRecord = append(m.SignedRecord[:0], dAtA[iNdEx:postIndex]...)
+			if m.SignedRecord == nil {
+				m.SignedRecord = []byte{}
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Records", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPeerExchange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPeerExchange
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPeerExchange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Records = append(m.Records, make([]byte, postIndex-iNdEx))
+			copy(m.Records[len(m.Records)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPeerExchange(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthPeerExchange
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipPeerExchange(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowPeerExchange
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPeerExchange
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPeerExchange
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthPeerExchange
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupPeerExchange
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthPeerExchange
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthPeerExchange        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowPeerExchange          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupPeerExchange = fmt.Errorf("proto: unexpected end of group")
+)
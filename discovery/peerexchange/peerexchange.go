@@ -0,0 +1,362 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peerexchange
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/mgr"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerexchange/pb"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// ProtocolID is the protocol.ID for peer exchange.
+	ProtocolID protocol.ID = "/peer-exchange/v0.0.1"
+	// DefaultSwapInterval is the default interval of swapping peer records with a random neighbour.
+	DefaultSwapInterval = 5 * time.Minute
+	// DefaultSampleSize is the default max count of peer records sent in each msg.
+	DefaultSampleSize = 8
+	// DefaultPruneBackoff is the default duration that a peer pruned us will not be dialed by us.
+	DefaultPruneBackoff = 10 * time.Minute
+
+	// maxRecordsPerMsg is the max count of peer records accepted in each msg received.
+	maxRecordsPerMsg = 32
+)
+
+// Option is a function to apply properties for peer exchange service.
+type Option func(*PeerExchange) error
+
+func (px *PeerExchange) applyOptions(opts ...Option) error {
+	for _, opt := range opts {
+		if err := opt(px); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithLogger set a logger.
+func WithLogger(logger api.Logger) Option {
+	return func(px *PeerExchange) error {
+		px.logger = logger
+		return nil
+	}
+}
+
+// WithSwapInterval set the interval of swapping peer records with a random neighbour.
+func WithSwapInterval(interval time.Duration) Option {
+	return func(px *PeerExchange) error {
+		if interval > 0 {
+			px.swapInterval = interval
+		}
+		return nil
+	}
+}
+
+// WithSampleSize set the max count of peer records sent in each msg.
+func WithSampleSize(size int) Option {
+	return func(px *PeerExchange) error {
+		if size > 0 {
+			px.sampleSize = size
+		}
+		return nil
+	}
+}
+
+// WithPruneBackoff set the duration that a peer pruned us will not be dialed by us.
+func WithPruneBackoff(backoff time.Duration) Option {
+	return func(px *PeerExchange) error {
+		if backoff > 0 {
+			px.pruneBackoff = backoff
+		}
+		return nil
+	}
+}
+
+// WithRecordStore set the store of signed peer records, which could be shared with other services.
+// If not set, a new one will be created.
+func WithRecordStore(records *peerrecord.Store) Option {
+	return func(px *PeerExchange) error {
+		px.records = records
+		return nil
+	}
+}
+
+// PeerExchange provides a generic peer exchange protocol healing the topology when neighbours churn.
+// It swaps a random sample of signed peer records of the peers connected with a random neighbour periodically,
+// and sends the peer going to be trimmed by ConnMgr a list of alternatives before its connections closed.
+// Records exchanged are verified, then merged into the AddrBook with store.AddrSourcePeerExchange,
+// and the peers in them will be dialed if the count of peers connected does not reach the max value.
+type PeerExchange struct {
+	host   host.Host
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	records *peerrecord.Store
+
+	pruneMu  sync.Mutex
+	prunedBy map[peer.ID]time.Time // stores the peers pruned us with the time until which we back off
+
+	swapInterval time.Duration
+	sampleSize   int
+	pruneBackoff time.Duration
+
+	logger api.Logger
+}
+
+// NewPeerExchange create a new PeerExchange instance.
+func NewPeerExchange(h host.Host, opts ...Option) (*PeerExchange, error) {
+	px := &PeerExchange{
+		host:         h,
+		prunedBy:     make(map[peer.ID]time.Time),
+		swapInterval: DefaultSwapInterval,
+		sampleSize:   DefaultSampleSize,
+		pruneBackoff: DefaultPruneBackoff,
+		logger:       logger.NilLogger,
+	}
+	if err := px.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+	if px.records == nil {
+		px.records = peerrecord.NewStore(peerrecord.DefaultStoreCapacity, h.ConnMgr().IsConnected)
+	}
+	return px, nil
+}
+
+// Start register peer exchange protocol to host, hook the trimming of ConnMgr, then start the swapping loop.
+func (px *PeerExchange) Start() error {
+	px.ctx, px.cancel = context.WithCancel(px.host.Context())
+	if err := px.host.RegisterMsgPayloadHandler(ProtocolID, px.handleMsg); err != nil {
+		return err
+	}
+	if notifier, ok := px.host.ConnMgr().(mgr.TrimNotifier); ok {
+		notifier.OnPeerTrimming(px.prune)
+	}
+	// swap with the new neighbour at once, so that its record is known before pruning.
+	// only the one with the smaller peer id starts swapping, the other learns the record from the swap msg.
+	ctx := px.ctx
+	px.host.Notify(&host.NotifieeBundle{
+		PeerProtocolSupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
+			if protocolID != ProtocolID || px.host.ID() > pid {
+				return
+			}
+			select {
+			case <-ctx.Done():
+			default:
+				go px.sendMsg(pid, pb.PeerExchangeMsg_Swap)
+			}
+		},
+	})
+	go px.swapLoop()
+	return nil
+}
+
+// Stop the swapping loop, then unregister peer exchange protocol.
+func (px *PeerExchange) Stop() error {
+	if px.cancel != nil {
+		px.cancel()
+	}
+	return px.host.UnregisterMsgPayloadHandler(ProtocolID)
+}
+
+func (px *PeerExchange) swapLoop() {
+	ticker := time.NewTicker(px.swapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-px.ctx.Done():
+			return
+		case <-ticker.C:
+			px.swap()
+			px.gcPrunedBy()
+		}
+	}
+}
+
+// gcPrunedBy remove the peers pruned us whose backoff expired.
+func (px *PeerExchange) gcPrunedBy() {
+	px.pruneMu.Lock()
+	defer px.pruneMu.Unlock()
+	now := time.Now()
+	for pid, until := range px.prunedBy {
+		if now.After(until) {
+			delete(px.prunedBy, pid)
+		}
+	}
+}
+
+// swap send a sample of peer records to a random neighbour supporting peer exchange protocol,
+// who will reply with its own sample.
+func (px *PeerExchange) swap() {
+	neighbours, err := px.host.PeerProtocols([]protocol.ID{ProtocolID})
+	if err != nil || len(neighbours) == 0 {
+		return
+	}
+	pid := neighbours[rand.Intn(len(neighbours))].PID
+	px.sendMsg(pid, pb.PeerExchangeMsg_Swap)
+}
+
+// prune send the peer going to be trimmed a sample of peer records as the alternatives.
+func (px *PeerExchange) prune(pid peer.ID) {
+	select {
+	case <-px.ctx.Done():
+		return
+	default:
+	}
+	if !px.host.IsPeerSupportProtocol(pid, ProtocolID) {
+		return
+	}
+	px.sendMsg(pid, pb.PeerExchangeMsg_Prune)
+}
+
+// sample return the signed peer records of at most sampleSize peers connected randomly, except the peer given.
+func (px *PeerExchange) sample(except peer.ID) [][]byte {
+	pids := px.host.ConnMgr().AllPeer()
+	rand.Shuffle(len(pids), func(i, j int) {
+		pids[i], pids[j] = pids[j], pids[i]
+	})
+	res := make([][]byte, 0, px.sampleSize)
+	for _, pid := range pids {
+		if len(res) >= px.sampleSize {
+			break
+		}
+		if pid == except {
+			continue
+		}
+		if record, _ := px.records.Get(pid); record != nil {
+			res = append(res, record)
+		}
+	}
+	return res
+}
+
+func (px *PeerExchange) sendMsg(receiver peer.ID, msgType pb.PeerExchangeMsg_Type) {
+	signed, err := peerrecord.Seal(px.host.PrivateKey(), px.host.AnnounceAddrs())
+	if err != nil {
+		px.logger.Errorf("[PeerExchange] seal peer record failed, %s", err.Error())
+		return
+	}
+	record, err := proto.Marshal(signed)
+	if err != nil {
+		px.logger.Errorf("[PeerExchange] marshal peer record failed, %s", err.Error())
+		return
+	}
+	msgBytes, err := proto.Marshal(&pb.PeerExchangeMsg{
+		Type:         msgType,
+		SignedRecord: record,
+		Records:      px.sample(receiver),
+	})
+	if err != nil {
+		px.logger.Errorf("[PeerExchange] marshal msg failed, %s", err.Error())
+		return
+	}
+	if err = px.host.SendMsg(ProtocolID, receiver, msgBytes); err != nil {
+		px.logger.Debugf("[PeerExchange] send %s msg failed, %s (remote pid: %s)",
+			msgType.String(), err.Error(), receiver)
+	}
+}
+
+func (px *PeerExchange) handleMsg(senderPID peer.ID, msgPayload []byte) {
+	msg := &pb.PeerExchangeMsg{}
+	if err := proto.Unmarshal(msgPayload, msg); err != nil {
+		px.logger.Warnf("[PeerExchange] unmarshal msg failed, %s (remote pid: %s)", err.Error(), senderPID)
+		return
+	}
+	// the record of sender must be the one of itself
+	record, err := peerrecord.OpenBytes(msg.SignedRecord)
+	if err != nil || record.PeerID != senderPID {
+		px.logger.Warnf("[PeerExchange] invalid peer record of sender (remote pid: %s)", senderPID)
+		return
+	}
+	if addrs, e := px.records.Update(record, msg.SignedRecord); e == nil {
+		px.addAddrs(senderPID, addrs)
+	}
+	switch msg.Type {
+	case pb.PeerExchangeMsg_Swap:
+		px.sendMsg(senderPID, pb.PeerExchangeMsg_SwapRes)
+	case pb.PeerExchangeMsg_SwapRes:
+	case pb.PeerExchangeMsg_Prune:
+		px.pruneMu.Lock()
+		px.prunedBy[senderPID] = time.Now().Add(px.pruneBackoff)
+		px.pruneMu.Unlock()
+		px.logger.Debugf("[PeerExchange] pruned with %d alternatives (remote pid: %s)", len(msg.Records), senderPID)
+	default:
+		px.logger.Warnf("[PeerExchange] unknown msg type (remote pid: %s)", senderPID)
+		return
+	}
+	px.merge(senderPID, msg.Records)
+}
+
+// merge verify the peer records received, store the newer ones and add the addresses in them to AddrBook,
+// then dial the peers not connected if the count of peers connected does not reach the max value.
+func (px *PeerExchange) merge(senderPID peer.ID, records [][]byte) {
+	if len(records) > maxRecordsPerMsg {
+		records = records[:maxRecordsPerMsg]
+	}
+	for _, raw := range records {
+		record, err := peerrecord.OpenBytes(raw)
+		if err != nil {
+			px.logger.Debugf("[PeerExchange] invalid peer record, %s (remote pid: %s)", err.Error(), senderPID)
+			continue
+		}
+		pid := record.PeerID
+		if pid == px.host.ID() || pid == senderPID {
+			continue
+		}
+		if addrs, e := px.records.Update(record, raw); e == nil {
+			px.addAddrs(pid, addrs)
+		}
+		if px.shouldDial(pid) {
+			go func() {
+				_, _ = px.host.DialPeer(simple.WithDialPriority(px.ctx, simple.DialPriorityLow), pid)
+			}()
+		}
+	}
+}
+
+func (px *PeerExchange) addAddrs(pid peer.ID, addrs []ma.Multiaddr) {
+	if len(addrs) == 0 {
+		return
+	}
+	px.host.PeerStore().AddAddrWithTTL(pid, store.AddrSourcePeerExchange, store.DiscoveryAddrTTL, addrs...)
+}
+
+// shouldDial return whether the peer should be dialed, e.g. not connected, not pruned us recently,
+// and the count of peers connected does not reach the max value.
+func (px *PeerExchange) shouldDial(pid peer.ID) bool {
+	cm := px.host.ConnMgr()
+	if cm.IsConnected(pid) || cm.PeerCount() >= cm.MaxPeerCountAllowed() {
+		return false
+	}
+	if px.host.PeerStore().GetFirstAddr(pid) == nil {
+		return false
+	}
+	px.pruneMu.Lock()
+	defer px.pruneMu.Unlock()
+	until, ok := px.prunedBy[pid]
+	if !ok {
+		return true
+	}
+	if time.Now().After(until) {
+		delete(px.prunedBy, pid)
+		return true
+	}
+	return false
+}
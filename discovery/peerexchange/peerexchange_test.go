@@ -0,0 +1,58 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peerexchange_test
+
+import (
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerexchange"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord"
+	"chainmaker.org/chainmaker/net-liquid/host/hosttest"
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerExchange(t *testing.T) {
+	// hosts[1] and hosts[2] connect to hosts[0] only, then connect to each other with the peer records swapped
+	hosts := make([]host.Host, 3)
+	records := make([]*peerrecord.Store, 3)
+	for i := range hosts {
+		hosts[i] = hosttest.NewHost(t)
+		if i > 0 {
+			hosts[i].AddDirectPeer(hosttest.Addr(hosts[0]))
+		}
+		records[i] = peerrecord.NewStore(peerrecord.DefaultStoreCapacity, hosts[i].ConnMgr().IsConnected)
+		px, err := peerexchange.NewPeerExchange(hosts[i],
+			peerexchange.WithSwapInterval(200*time.Millisecond),
+			peerexchange.WithRecordStore(records[i]),
+		)
+		require.Nil(t, err)
+		require.Nil(t, px.Start())
+		// peer exchange stopped before the host, so that no peer dialed while stopping the host
+		t.Cleanup(func() {
+			_ = px.Stop()
+		})
+	}
+
+	require.Eventually(t, func() bool {
+		return hosts[1].ConnMgr().IsConnected(hosts[2].ID()) && hosts[2].ConnMgr().IsConnected(hosts[1].ID())
+	}, 10*time.Second, 50*time.Millisecond)
+	// the records swapped are kept in the store given
+	raw, _ := records[1].Get(hosts[2].ID())
+	require.NotNil(t, raw)
+
+	// hosts[0] trims one of them
+	cm := hosts[0].ConnMgr().(*simple.LevelConnManager)
+	cm.SetWatermarks(1, 1)
+	cm.SetGracePeriod(0)
+	cm.TrimOpenConns()
+	require.Eventually(t, func() bool {
+		return hosts[0].ConnMgr().PeerCount() == 1
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,175 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peerrecord
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/common/v2/crypto"
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord/pb"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+var (
+	// ErrPubKeyMismatch will be returned if the peer id of record is not the one resolved from the public key.
+	ErrPubKeyMismatch = errors.New("peer id of record mismatch the public key")
+	// ErrSignatureInvalid will be returned if the signature of record is invalid.
+	ErrSignatureInvalid = errors.New("invalid signature of peer record")
+	// ErrEmptyRecord will be returned if the signed record is nil or empty.
+	ErrEmptyRecord = errors.New("empty peer record")
+	// ErrRecordExpired will be returned if the record was created more than MaxRecordAge ago.
+	ErrRecordExpired = errors.New("peer record expired")
+	// ErrRecordFromFuture will be returned if the record was created more than MaxClockSkew later than now.
+	ErrRecordFromFuture = errors.New("peer record from the future")
+)
+
+const (
+	// MaxRecordAge is the max age of the records accepted,
+	// which covers the default max ttl of rendezvous registrations carrying the records.
+	MaxRecordAge = 72 * time.Hour
+	// MaxClockSkew is the max difference between the clocks of peers tolerated when checking the records.
+	MaxClockSkew = time.Minute
+)
+
+var (
+	seqMu   sync.Mutex
+	lastSeq uint64
+)
+
+// nextSeq return a sequence number increasing strictly, based on the current unix time in nanoseconds,
+// so that the records created later always have greater sequence numbers, even if the process restarted.
+func nextSeq() uint64 {
+	seqMu.Lock()
+	defer seqMu.Unlock()
+	seq := uint64(time.Now().UnixNano())
+	if seq <= lastSeq {
+		seq = lastSeq + 1
+	}
+	lastSeq = seq
+	return seq
+}
+
+// PeerRecord is a peer record opened and verified.
+type PeerRecord struct {
+	// PeerID is the id of peer, which is resolved from PubKey.
+	PeerID peer.ID
+	// PubKey is the public key of peer.
+	PubKey crypto.PublicKey
+	// Addrs are the net addresses of peer, without the /p2p part.
+	Addrs []ma.Multiaddr
+	// Seq is the sequence number of record, the record with greater one is newer.
+	Seq uint64
+	// Timestamp is the time when the record created.
+	Timestamp time.Time
+}
+
+// Seal create a peer record with the net addresses given, then sign it with the private key given.
+func Seal(sk crypto.PrivateKey, addrs []ma.Multiaddr) (*pb.SignedPeerRecord, error) {
+	pubKeyBytes, err := sk.PublicKey().Bytes()
+	if err != nil {
+		return nil, err
+	}
+	pid, err := util.ResolvePIDFromPubKey(sk.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	addrStrs := make([]string, 0, len(addrs))
+	for i := range addrs {
+		addrStrs = append(addrStrs, addrs[i].String())
+	}
+	record, err := proto.Marshal(&pb.PeerRecord{
+		Pid:       pid.ToString(),
+		Addrs:     addrStrs,
+		Seq:       nextSeq(),
+		Timestamp: time.Now().UnixNano(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(record)
+	signature, err := sk.Sign(digest[:])
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SignedPeerRecord{
+		PubKey:    pubKeyBytes,
+		Record:    record,
+		Signature: signature,
+	}, nil
+}
+
+// Open verify the signed peer record given, then return the peer record in it.
+// The record created more than MaxRecordAge ago or from the future will be rejected,
+// so that an old record could not be replayed.
+// The addresses that could not be parsed will be ignored.
+func Open(signed *pb.SignedPeerRecord) (*PeerRecord, error) {
+	if signed == nil || len(signed.Record) == 0 {
+		return nil, ErrEmptyRecord
+	}
+	pubKey, err := asym.PublicKeyFromDER(signed.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := util.ResolvePIDFromPubKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	record := &pb.PeerRecord{}
+	if err = proto.Unmarshal(signed.Record, record); err != nil {
+		return nil, err
+	}
+	if peer.ID(record.Pid) != pid {
+		return nil, ErrPubKeyMismatch
+	}
+	digest := sha256.Sum256(signed.Record)
+	ok, err := pubKey.Verify(digest[:], signed.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrSignatureInvalid
+	}
+	timestamp := time.Unix(0, record.Timestamp)
+	now := time.Now()
+	if timestamp.Before(now.Add(-MaxRecordAge)) {
+		return nil, ErrRecordExpired
+	}
+	if timestamp.After(now.Add(MaxClockSkew)) {
+		return nil, ErrRecordFromFuture
+	}
+	addrs := make([]ma.Multiaddr, 0, len(record.Addrs))
+	for i := range record.Addrs {
+		addr, e := ma.NewMultiaddr(record.Addrs[i])
+		if e != nil {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return &PeerRecord{
+		PeerID:    pid,
+		PubKey:    pubKey,
+		Addrs:     addrs,
+		Seq:       record.Seq,
+		Timestamp: timestamp,
+	}, nil
+}
+
+// OpenBytes unmarshal the bytes given as a signed peer record, then open it.
+func OpenBytes(bytes []byte) (*PeerRecord, error) {
+	signed := &pb.SignedPeerRecord{}
+	if err := proto.Unmarshal(bytes, signed); err != nil {
+		return nil, err
+	}
+	return Open(signed)
+}
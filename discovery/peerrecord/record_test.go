@@ -0,0 +1,91 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peerrecord
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/common/v2/crypto"
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord/pb"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	sk, err := asym.GenerateKeyPair(crypto.ECC_NISTP256)
+	require.Nil(t, err)
+	pid, err := util.ResolvePIDFromPubKey(sk.PublicKey())
+	require.Nil(t, err)
+	addr := ma.StringCast("/ip4/127.0.0.1/tcp/8081")
+
+	signed, err := Seal(sk, []ma.Multiaddr{addr})
+	require.Nil(t, err)
+	record, err := Open(signed)
+	require.Nil(t, err)
+	require.Equal(t, pid, record.PeerID)
+	require.Len(t, record.Addrs, 1)
+	require.True(t, addr.Equal(record.Addrs[0]))
+
+	// the record sealed later is newer
+	signed2, err := Seal(sk, nil)
+	require.Nil(t, err)
+	bytes, err := proto.Marshal(signed2)
+	require.Nil(t, err)
+	record2, err := OpenBytes(bytes)
+	require.Nil(t, err)
+	require.Greater(t, record2.Seq, record.Seq)
+
+	// record tampered
+	r := &pb.PeerRecord{}
+	require.Nil(t, proto.Unmarshal(signed.Record, r))
+	r.Addrs = []string{"/ip4/1.2.3.4/tcp/8081"}
+	signed.Record, err = proto.Marshal(r)
+	require.Nil(t, err)
+	_, err = Open(signed)
+	require.Equal(t, ErrSignatureInvalid, err)
+
+	// record of another peer signed with my key
+	r.Pid = "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4"
+	signed.Record, err = proto.Marshal(r)
+	require.Nil(t, err)
+	_, err = Open(signed)
+	require.Equal(t, ErrPubKeyMismatch, err)
+
+	_, err = Open(&pb.SignedPeerRecord{})
+	require.Equal(t, ErrEmptyRecord, err)
+}
+
+func TestOpenTimestamp(t *testing.T) {
+	sk, err := asym.GenerateKeyPair(crypto.ECC_NISTP256)
+	require.Nil(t, err)
+	pid, err := util.ResolvePIDFromPubKey(sk.PublicKey())
+	require.Nil(t, err)
+	pubKeyBytes, err := sk.PublicKey().Bytes()
+	require.Nil(t, err)
+	signAt := func(timestamp time.Time) *pb.SignedPeerRecord {
+		record, e := proto.Marshal(&pb.PeerRecord{Pid: pid.ToString(), Seq: 1, Timestamp: timestamp.UnixNano()})
+		require.Nil(t, e)
+		digest := sha256.Sum256(record)
+		signature, e := sk.Sign(digest[:])
+		require.Nil(t, e)
+		return &pb.SignedPeerRecord{PubKey: pubKeyBytes, Record: record, Signature: signature}
+	}
+
+	_, err = Open(signAt(time.Now().Add(-MaxRecordAge + time.Minute)))
+	require.Nil(t, err)
+	_, err = Open(signAt(time.Now().Add(MaxClockSkew / 2)))
+	require.Nil(t, err)
+	_, err = Open(signAt(time.Now().Add(-MaxRecordAge - time.Minute)))
+	require.Equal(t, ErrRecordExpired, err)
+	_, err = Open(signAt(time.Now().Add(MaxClockSkew + time.Minute)))
+	require.Equal(t, ErrRecordFromFuture, err)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peerrecord
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// ErrStaleRecord will be returned if the peer record is older than the one stored.
+var ErrStaleRecord = errors.New("stale peer record")
+
+// DefaultStoreCapacity is the default max count of signed peer records stored.
+const DefaultStoreCapacity = 4096
+
+type storedRecord struct {
+	raw       []byte
+	seq       uint64
+	timestamp time.Time
+	addrs     []ma.Multiaddr
+	updated   time.Time
+}
+
+// Store stores the latest signed peer record verified of each peer,
+// which could be sent to the others as it is, so that they could verify it by themselves.
+// If full, the record of a peer not connected will be evicted for the new one,
+// or the oldest one if all of them connected.
+// A Store is safe to be shared by the services exchanging peer records, e.g. discovery and peer exchange.
+type Store struct {
+	capacity    int
+	isConnected func(peer.ID) bool
+
+	mu      sync.RWMutex
+	records map[peer.ID]*storedRecord
+}
+
+// NewStore create a new *Store instance storing at most capacity records.
+// isConnected tells whether a peer is connected, which decides the record to be evicted when full.
+func NewStore(capacity int, isConnected func(peer.ID) bool) *Store {
+	return &Store{
+		capacity:    capacity,
+		isConnected: isConnected,
+		records:     make(map[peer.ID]*storedRecord),
+	}
+}
+
+// Update store the signed peer record given if it is newer than the one stored,
+// then return the addresses in the record stored.
+// If it has the same sequence number as the one stored, e.g. relayed by another peer,
+// the one created later will be kept and the addresses in it will be returned.
+// The record should have been opened from raw bytes given.
+func (s *Store) Update(record *PeerRecord, raw []byte) ([]ma.Multiaddr, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, ok := s.records[record.PeerID]
+	if ok && record.Seq < old.seq {
+		return nil, ErrStaleRecord
+	}
+	if ok && record.Seq == old.seq && !record.Timestamp.After(old.timestamp) {
+		return old.addrs, nil
+	}
+	if !ok && len(s.records) >= s.capacity {
+		s.evictLocked()
+	}
+	s.records[record.PeerID] = &storedRecord{
+		raw:       raw,
+		seq:       record.Seq,
+		timestamp: record.Timestamp,
+		addrs:     record.Addrs,
+		updated:   time.Now(),
+	}
+	return record.Addrs, nil
+}
+
+// evictLocked remove the oldest record of the peers not connected, or the oldest one if all peers connected.
+// It should be called when s.mu locked.
+func (s *Store) evictLocked() {
+	var (
+		victim          peer.ID
+		victimUpdated   time.Time
+		victimConnected bool
+	)
+	for pid, r := range s.records {
+		connected := s.isConnected(pid)
+		if victim == "" || (victimConnected && !connected) ||
+			(victimConnected == connected && r.updated.Before(victimUpdated)) {
+			victim, victimUpdated, victimConnected = pid, r.updated, connected
+		}
+	}
+	delete(s.records, victim)
+}
+
+// Get return the signed peer record of peer stored with the addresses in it, or nil if not found.
+func (s *Store) Get(pid peer.ID) ([]byte, []ma.Multiaddr) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.records[pid]
+	if !ok {
+		return nil, nil
+	}
+	return r.raw, r.addrs
+}
@@ -0,0 +1,109 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package peerrecord
+
+import (
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/common/v2/crypto"
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func sealRecordBytes(t *testing.T, sk crypto.PrivateKey, addrs ...ma.Multiaddr) ([]byte, *PeerRecord) {
+	signed, err := Seal(sk, addrs)
+	require.Nil(t, err)
+	raw, err := proto.Marshal(signed)
+	require.Nil(t, err)
+	record, err := OpenBytes(raw)
+	require.Nil(t, err)
+	return raw, record
+}
+
+func TestStore(t *testing.T) {
+	sk, err := asym.GenerateKeyPair(crypto.ECC_NISTP256)
+	require.Nil(t, err)
+	s := NewStore(16, func(peer.ID) bool { return false })
+
+	raw1, record1 := sealRecordBytes(t, sk, ma.StringCast("/ip4/127.0.0.1/tcp/8081"))
+	raw2, record2 := sealRecordBytes(t, sk, ma.StringCast("/ip4/127.0.0.1/tcp/8082"))
+	raw, _ := s.Get(record1.PeerID)
+	require.Nil(t, raw)
+
+	// newer record replaces the older one
+	addrs, err := s.Update(record1, raw1)
+	require.Nil(t, err)
+	require.Equal(t, record1.Addrs, addrs)
+	raw, addrs = s.Get(record1.PeerID)
+	require.Equal(t, raw1, raw)
+	require.Equal(t, record1.Addrs, addrs)
+	_, err = s.Update(record2, raw2)
+	require.Nil(t, err)
+	raw, addrs = s.Get(record1.PeerID)
+	require.Equal(t, raw2, raw)
+	require.Equal(t, record2.Addrs, addrs)
+
+	// older record is stale
+	_, err = s.Update(record1, raw1)
+	require.Equal(t, ErrStaleRecord, err)
+	// the same record, e.g. relayed by another peer, is accepted with the addresses stored
+	addrs, err = s.Update(record2, raw2)
+	require.Nil(t, err)
+	require.Equal(t, record2.Addrs, addrs)
+	raw, _ = s.Get(record1.PeerID)
+	require.Equal(t, raw2, raw)
+
+	// the one created later kept if the sequence numbers tie
+	record3 := *record2
+	record3.Timestamp = record2.Timestamp.Add(time.Second)
+	record3.Addrs = []ma.Multiaddr{ma.StringCast("/ip4/127.0.0.1/tcp/8083")}
+	raw3 := []byte("raw3")
+	addrs, err = s.Update(&record3, raw3)
+	require.Nil(t, err)
+	require.Equal(t, record3.Addrs, addrs)
+	addrs, err = s.Update(record2, raw2)
+	require.Nil(t, err)
+	require.Equal(t, record3.Addrs, addrs)
+	raw, _ = s.Get(record1.PeerID)
+	require.Equal(t, raw3, raw)
+}
+
+func TestStoreEvict(t *testing.T) {
+	records := make([]*PeerRecord, 0, 3)
+	raws := make([][]byte, 0, 3)
+	for i := 0; i < 3; i++ {
+		sk, err := asym.GenerateKeyPair(crypto.ECC_NISTP256)
+		require.Nil(t, err)
+		raw, record := sealRecordBytes(t, sk, ma.StringCast("/ip4/127.0.0.1/tcp/8081"))
+		records = append(records, record)
+		raws = append(raws, raw)
+	}
+	connected := map[peer.ID]bool{records[0].PeerID: true}
+	s := NewStore(2, func(pid peer.ID) bool { return connected[pid] })
+
+	// the record of peer not connected evicted for the new one
+	for i := range records {
+		_, err := s.Update(records[i], raws[i])
+		require.Nil(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	require.Len(t, s.records, 2)
+	raw, _ := s.Get(records[1].PeerID)
+	require.Nil(t, raw)
+
+	// the oldest one evicted if all connected
+	connected[records[2].PeerID] = true
+	_, err := s.Update(records[1], raws[1])
+	require.Nil(t, err)
+	require.Len(t, s.records, 2)
+	raw, _ = s.Get(records[0].PeerID)
+	require.Nil(t, raw)
+}
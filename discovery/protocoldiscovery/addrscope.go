@@ -0,0 +1,102 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocoldiscovery
+
+import (
+	"net"
+
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+// privateRanges is the list of private IP ranges, an address in one of them is reachable only in the same range.
+var privateRanges = parseCIDRs(
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10", // carrier-grade NAT
+	"169.254.0.0/16",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	res := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, ipNet)
+	}
+	return res
+}
+
+// AddrFilter decides whether an address of peer could be returned to the finder,
+// whose remote address is given as finderAddr, nil if unknown.
+type AddrFilter func(finderAddr, addr ma.Multiaddr) bool
+
+// AllowAllAddrs is an AddrFilter returning all addresses to everyone.
+func AllowAllAddrs(_, _ ma.Multiaddr) bool {
+	return true
+}
+
+// NewScopedAddrFilter create an AddrFilter which returns the loopback addresses only to the finders on loopback,
+// the private addresses only to the finders on loopback or in the same private range,
+// and the public addresses to everyone. The addresses without IP (e.g. /dns4/...) are treated as public,
+// while the finders whose address unknown or without IP (e.g. relayed) are treated as the public ones.
+// The addresses in the publicCIDRs given will be returned to everyone, e.g. the private ranges routed by VPN.
+func NewScopedAddrFilter(publicCIDRs ...string) (AddrFilter, error) {
+	public, err := simple.NewAddrFilters(publicCIDRs...)
+	if err != nil {
+		return nil, err
+	}
+	return func(finderAddr, addr ma.Multiaddr) bool {
+		ip, err := manet.ToIP(addr)
+		if err != nil || public.Blocked(addr) {
+			return true
+		}
+		ipRange := privateRange(ip)
+		if !ip.IsLoopback() && ipRange == nil {
+			return true
+		}
+		if finderAddr == nil {
+			return false
+		}
+		finderIP, err := manet.ToIP(finderAddr)
+		if err != nil {
+			return false
+		}
+		if finderIP.IsLoopback() {
+			// the finder is on the same machine with us, so it could reach whatever we could
+			return true
+		}
+		return ipRange != nil && ipRange.Contains(finderIP)
+	}, nil
+}
+
+// privateRange return the private range containing the IP, or nil if it is not private.
+func privateRange(ip net.IP) *net.IPNet {
+	for _, ipNet := range privateRanges {
+		if ipNet.Contains(ip) {
+			return ipNet
+		}
+	}
+	return nil
+}
+
+// filterAddrs return the addresses that the filter allows to return to the finder.
+func filterAddrs(filter AddrFilter, finderAddr ma.Multiaddr, addrs []ma.Multiaddr) []ma.Multiaddr {
+	res := make([]ma.Multiaddr, 0, len(addrs))
+	for _, addr := range addrs {
+		if filter(finderAddr, addr) {
+			res = append(res, addr)
+		}
+	}
+	return res
+}
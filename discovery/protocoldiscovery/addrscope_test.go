@@ -0,0 +1,124 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocoldiscovery
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker/common/v2/crypto"
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord"
+	"chainmaker.org/chainmaker/net-liquid/discovery/protocoldiscovery/pb"
+	"chainmaker.org/chainmaker/net-liquid/host/hosttest"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopedAddrFilter(t *testing.T) {
+	filter, err := NewScopedAddrFilter()
+	require.Nil(t, err)
+
+	loopback := ma.StringCast("/ip4/127.0.0.1/tcp/8081")
+	private := ma.StringCast("/ip4/10.0.0.2/tcp/8081")
+	public := ma.StringCast("/ip4/1.2.3.4/tcp/8081")
+	dns := ma.StringCast("/dns4/node1.example.com/tcp/8081")
+	addrs := []ma.Multiaddr{loopback, private, public, dns}
+
+	// loopback finder could reach all
+	require.Equal(t, addrs, filterAddrs(filter, ma.StringCast("/ip4/127.0.0.1/tcp/50001"), addrs))
+	// finder in the same private range
+	require.Equal(t, []ma.Multiaddr{private, public, dns},
+		filterAddrs(filter, ma.StringCast("/ip4/10.1.2.3/tcp/50001"), addrs))
+	// finder in another private range
+	require.Equal(t, []ma.Multiaddr{public, dns},
+		filterAddrs(filter, ma.StringCast("/ip4/192.168.1.2/tcp/50001"), addrs))
+	// public finder, unknown finder and relayed finder
+	require.Equal(t, []ma.Multiaddr{public, dns}, filterAddrs(filter, ma.StringCast("/ip4/5.6.7.8/tcp/50001"), addrs))
+	require.Equal(t, []ma.Multiaddr{public, dns}, filterAddrs(filter, nil, addrs))
+	require.Equal(t, []ma.Multiaddr{public, dns}, filterAddrs(filter, ma.StringCast("/p2p-circuit"), addrs))
+
+	// operator override
+	filter, err = NewScopedAddrFilter("10.0.0.0/16")
+	require.Nil(t, err)
+	require.Equal(t, []ma.Multiaddr{private, public, dns}, filterAddrs(filter, nil, addrs))
+	require.Equal(t, addrs, filterAddrs(AllowAllAddrs, nil, addrs))
+	_, err = NewScopedAddrFilter("not a cidr")
+	require.NotNil(t, err)
+}
+
+func TestScopedAddrs(t *testing.T) {
+	pid := peer.ID("QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4")
+	recordAddrs := []ma.Multiaddr{
+		ma.StringCast("/ip4/127.0.0.1/tcp/8081"),
+		ma.StringCast("/ip4/1.2.3.4/tcp/8081"),
+	}
+	// all addresses in record used if none returned
+	require.Equal(t, recordAddrs, scopedAddrs(recordAddrs, nil))
+	// only the addresses both returned and signed are used
+	returned := []string{
+		util.CreateMultiAddrWithPidAndNetAddr(pid, recordAddrs[1]).String(),
+		util.CreateMultiAddrWithPidAndNetAddr(pid, ma.StringCast("/ip4/5.6.7.8/tcp/8081")).String(),
+		"invalid",
+	}
+	require.Equal(t, recordAddrs[1:], scopedAddrs(recordAddrs, returned))
+	require.Empty(t, scopedAddrs(recordAddrs, returned[1:]))
+}
+
+func TestFindPeerInfosRanked(t *testing.T) {
+	h := hosttest.NewHost(t)
+	records := peerrecord.NewStore(peerrecord.DefaultStoreCapacity, h.ConnMgr().IsConnected)
+	d, err := NewProtocolBasedDiscovery(h, WithRecordStore(records))
+	require.Nil(t, err)
+	protoID := d.createProtocolIDWithServiceName("chain1")
+	addr1 := ma.StringCast("/ip4/1.2.3.4/tcp/8081")
+	addr2 := ma.StringCast("/ip4/1.2.3.5/tcp/8081")
+	addr3 := ma.StringCast("/ip4/1.2.3.6/tcp/8081")
+	inbound := ma.StringCast("/ip4/1.2.3.7/tcp/50001")
+
+	// the peer with signed peer record, whose second address dialed successfully
+	sk, err := asym.GenerateKeyPair(crypto.ECC_NISTP256)
+	require.Nil(t, err)
+	signed, err := peerrecord.Seal(sk, []ma.Multiaddr{addr1, addr2, addr3})
+	require.Nil(t, err)
+	raw, err := proto.Marshal(signed)
+	require.Nil(t, err)
+	_, err = d.acceptRecord("", raw)
+	require.Nil(t, err)
+	pid1, err := util.ResolvePIDFromPubKey(sk.PublicKey())
+	require.Nil(t, err)
+	stored, _ := records.Get(pid1)
+	require.Equal(t, raw, stored)
+	h.PeerStore().AddAddrWithTTL(pid1, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, addr1, addr2)
+	h.PeerStore().RecordDialResult(pid1, addr2, true)
+	h.PeerStore().AddProtocol(pid1, protoID)
+
+	// the peer without signed peer record
+	pid2 := peer.ID("QmeyNRs2DwWjcHTpcVHoUSaDAAif4VQZ2wQDQAUNDP33gH")
+	h.PeerStore().AddAddrWithTTL(pid2, store.AddrSourceInbound, store.DiscoveryAddrTTL, inbound)
+	h.PeerStore().AddAddrWithTTL(pid2, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, addr1, addr3)
+	h.PeerStore().RecordDialResult(pid2, addr3, true)
+	h.PeerStore().AddProtocol(pid2, protoID)
+
+	finder := peer.ID("QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4")
+	pInfos := d.findPeerInfos(finder, &pb.DiscoveryMsg{Type: pb.DiscoveryMsg_FindReq, Size_: 10}, protoID)
+	require.Len(t, pInfos, 2)
+	res := make(map[peer.ID]*pb.PeerInfo, len(pInfos))
+	for _, pInfo := range pInfos {
+		res[peer.ID(pInfo.Pid)] = pInfo
+	}
+	// the addresses in record ranked as peer store, followed by the ones not in peer store
+	require.Equal(t, raw, res[pid1].SignedRecord)
+	require.Equal(t, addrStrings(pid1, []ma.Multiaddr{addr2, addr1, addr3}), res[pid1].Addrs)
+	// the addresses in peer store ranked, except the inbound one
+	require.Nil(t, res[pid2].SignedRecord)
+	require.Equal(t, addrStrings(pid2, []ma.Multiaddr{addr3, addr1}), res[pid2].Addrs)
+	require.Equal(t, []ma.Multiaddr{addr3, addr1}, parseAddrs(pid2, res[pid2].Addrs))
+}
@@ -0,0 +1,59 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocoldiscovery
+
+import (
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+)
+
+type announcement struct {
+	pid      peer.ID
+	protocol protocol.ID
+}
+
+// announcementStore records the expiry time of the announcements received from others.
+type announcementStore struct {
+	mu      sync.Mutex
+	expires map[announcement]time.Time
+}
+
+func newAnnouncementStore() *announcementStore {
+	return &announcementStore{expires: make(map[announcement]time.Time)}
+}
+
+// add an announcement of peer for protocol, or renew it if exists.
+func (s *announcementStore) add(pid peer.ID, protocol protocol.ID, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expires[announcement{pid: pid, protocol: protocol}] = time.Now().Add(ttl)
+}
+
+// remove the announcement of peer for protocol.
+func (s *announcementStore) remove(pid peer.ID, protocol protocol.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.expires, announcement{pid: pid, protocol: protocol})
+}
+
+// popExpired remove the announcements expired, then return them.
+func (s *announcementStore) popExpired() []announcement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	res := make([]announcement, 0)
+	for a, expire := range s.expires {
+		if now.After(expire) {
+			res = append(res, a)
+			delete(s.expires, a)
+		}
+	}
+	return res
+}
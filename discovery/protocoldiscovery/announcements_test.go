@@ -0,0 +1,36 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocoldiscovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncementStore(t *testing.T) {
+	s := newAnnouncementStore()
+	s.add("peer1", "/p1", time.Hour)
+	s.add("peer2", "/p1", time.Millisecond)
+	s.add("peer3", "/p1", time.Millisecond)
+	s.remove("peer3", "/p1")
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, []announcement{{pid: "peer2", protocol: "/p1"}}, s.popExpired())
+	require.Len(t, s.popExpired(), 0)
+
+	// renewed
+	s.add("peer1", "/p1", time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, []announcement{{pid: "peer1", protocol: "/p1"}}, s.popExpired())
+}
+
+func TestAnnounceTTL(t *testing.T) {
+	require.Equal(t, DefaultAnnounceTTL, announceTTL(0))
+	require.Equal(t, time.Minute, announceTTL(60))
+	require.Equal(t, MaxAnnounceTTL, announceTTL(int64(MaxAnnounceTTL/time.Second)+1))
+}
@@ -18,7 +18,9 @@ import (
 	"chainmaker.org/chainmaker/net-liquid/core/host"
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
 	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
 	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord"
 	"chainmaker.org/chainmaker/net-liquid/discovery/protocoldiscovery/pb"
 	"chainmaker.org/chainmaker/net-liquid/logger"
 	api "chainmaker.org/chainmaker/protocol/v2"
@@ -34,9 +36,19 @@ const (
 	DefaultMaxQuerySize = 10
 	// DefaultQuerySize is the default size for finding from each others.
 	DefaultQuerySize = 3
+	// DefaultAnnounceTTL is the default ttl of announcements, which will be refreshed every half of it.
+	DefaultAnnounceTTL = 10 * time.Minute
+	// MaxAnnounceTTL is the max ttl of announcements accepted.
+	MaxAnnounceTTL = 24 * time.Hour
+
+	// gcInterval is the interval of removing the announcements expired.
+	gcInterval = time.Minute
+	// defaultUnannounceTimeout is the default timeout for sending unannouncements.
+	defaultUnannounceTimeout = 5 * time.Second
 
 	optKeyTimeout   = "timeout"
 	optKeyQuerySize = "query-size"
+	optKeyTTL       = "ttl"
 )
 
 var (
@@ -49,6 +61,7 @@ type options struct {
 
 	timeout time.Duration
 	size    int
+	ttl     time.Duration
 }
 
 func (o *options) applyDiscoveryOptions(opts ...discovery.Option) error {
@@ -67,10 +80,16 @@ func (o *options) applyDiscoveryOptions(opts ...discovery.Option) error {
 		o.size, _ = v.(int)
 	}
 
+	v, ok = o.Opts[optKeyTTL]
+	if ok {
+		o.ttl, _ = v.(time.Duration)
+	}
+
 	return nil
 }
 
-// WithTimeout set a time.Duration as timeout for finding peers.
+// WithTimeout set a time.Duration as timeout for finding peers,
+// or for sending announcements when announcing and unannouncing.
 func WithTimeout(timeout time.Duration) discovery.Option {
 	return func(options *discovery.Options) error {
 		options.Opts[optKeyTimeout] = timeout
@@ -86,6 +105,14 @@ func WithQuerySize(size int) discovery.Option {
 	}
 }
 
+// WithTTL set a time.Duration as ttl of announcement when announcing.
+func WithTTL(ttl time.Duration) discovery.Option {
+	return func(options *discovery.Options) error {
+		options.Opts[optKeyTTL] = ttl
+		return nil
+	}
+}
+
 // Option is a function to apply properties for discovery service.
 type Option func(*ProtocolBasedDiscovery) error
 
@@ -122,6 +149,17 @@ func WithDefaultQueryTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithAddrFilter set the AddrFilter deciding which addresses of peers could be returned to the finders.
+// NewScopedAddrFilter without public CIDRs is used by default, AllowAllAddrs could be used to disable filtering.
+func WithAddrFilter(filter AddrFilter) Option {
+	return func(d *ProtocolBasedDiscovery) error {
+		if filter != nil {
+			d.addrFilter = filter
+		}
+		return nil
+	}
+}
+
 // WithFindingTickerInterval set a time.Duration as interval for finding task heartbeat.
 func WithFindingTickerInterval(interval time.Duration) Option {
 	return func(d *ProtocolBasedDiscovery) error {
@@ -130,11 +168,30 @@ func WithFindingTickerInterval(interval time.Duration) Option {
 	}
 }
 
+// WithRecordStore set the store of signed peer records, which could be shared with other services.
+// If not set, a new one will be created.
+func WithRecordStore(records *peerrecord.Store) Option {
+	return func(d *ProtocolBasedDiscovery) error {
+		d.records = records
+		return nil
+	}
+}
+
 var _ discovery.Discovery = (*ProtocolBasedDiscovery)(nil)
+var _ discovery.Unannouncer = (*ProtocolBasedDiscovery)(nil)
 
 // ProtocolBasedDiscovery provides a discovery service based on protocols supported.
+// Peers exchange self-signed peer records instead of bare addresses, each msg carries the one of sender,
+// and the records of others are relayed as they are, so that nobody could forge the addresses of the others.
+// Announcements expire after ttl unless refreshed, and could be withdrawn with Unannounce.
 type ProtocolBasedDiscovery struct {
-	host host.Host
+	host          host.Host
+	ctx           context.Context
+	records       *peerrecord.Store
+	announcements *announcementStore
+
+	announcedMu sync.Mutex
+	announced   map[string]context.CancelFunc // stores the refreshing task of services announced by myself
 
 	svcMap       sync.Map // map[string]struct{}, stores service name announced by myself
 	findingMap   sync.Map // map[string]*chan network.AddrInfo, stores task of finding service
@@ -143,34 +200,143 @@ type ProtocolBasedDiscovery struct {
 	maxQuerySize          int
 	defaultQueryTimeout   time.Duration
 	findingTickerInterval time.Duration
+	addrFilter            AddrFilter
 
 	logger api.Logger
 }
 
 // NewProtocolBasedDiscovery create a new ProtocolBasedDiscovery instance.
 func NewProtocolBasedDiscovery(host host.Host, opts ...Option) (*ProtocolBasedDiscovery, error) {
+	scopedAddrFilter, err := NewScopedAddrFilter()
+	if err != nil {
+		return nil, err
+	}
 	d := &ProtocolBasedDiscovery{
 		host:                  host,
+		announcements:         newAnnouncementStore(),
+		announced:             make(map[string]context.CancelFunc),
 		svcMap:                sync.Map{},
 		findingMap:            sync.Map{},
 		findingWgMap:          sync.Map{},
 		maxQuerySize:          DefaultMaxQuerySize,
 		defaultQueryTimeout:   0,
 		findingTickerInterval: DefaultFindingHeartbeat,
+		addrFilter:            scopedAddrFilter,
 		logger:                logger.NilLogger,
 	}
 	if err := d.applyOptions(opts...); err != nil {
 		return nil, err
 	}
+	if d.records == nil {
+		d.records = peerrecord.NewStore(peerrecord.DefaultStoreCapacity, host.ConnMgr().IsConnected)
+	}
+	d.ctx = host.Context()
+	go d.gcLoop()
 	return d, nil
 }
 
+// gcLoop remove the protocols of peers whose announcements expired from peer store,
+// unless the peers connected still support them.
+func (d *ProtocolBasedDiscovery) gcLoop() {
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			for _, a := range d.announcements.popExpired() {
+				if d.host.IsPeerSupportProtocol(a.pid, a.protocol) {
+					continue
+				}
+				d.host.PeerStore().DeleteProtocol(a.pid, a.protocol)
+			}
+		}
+	}
+}
+
 func (d *ProtocolBasedDiscovery) createProtocolIDWithServiceName(serviceName string) protocol.ID {
 	return protocol.ID(discoveryProtocolIDPrefix + serviceName)
 }
 
+// sealRecord create a signed peer record with the announce addresses of myself.
+func (d *ProtocolBasedDiscovery) sealRecord() ([]byte, error) {
+	signed, err := peerrecord.Seal(d.host.PrivateKey(), d.host.AnnounceAddrs())
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(signed)
+}
+
+// marshalMsg set the signed peer record of myself to msg, then marshal it.
+func (d *ProtocolBasedDiscovery) marshalMsg(msg *pb.DiscoveryMsg) ([]byte, error) {
+	record, err := d.sealRecord()
+	if err != nil {
+		d.logger.Errorf("seal peer record failed, %s", err.Error())
+		return nil, err
+	}
+	msg.SignedRecord = record
+	msgBytes, err := proto.Marshal(msg)
+	if err != nil {
+		d.logger.Errorf("marshal discovery msg failed, %s", err.Error())
+		return nil, err
+	}
+	return msgBytes, nil
+}
+
+// acceptRecord verify the signed peer record given and store it if newer,
+// then return the addresses in the record stored of the peer.
+// The record will be rejected if it is not of the peer expected, unless the expected is empty.
+func (d *ProtocolBasedDiscovery) acceptRecord(expected peer.ID, raw []byte) ([]ma.Multiaddr, error) {
+	record, err := peerrecord.OpenBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+	if (expected != "" && record.PeerID != expected) || record.PeerID == d.host.ID() {
+		return nil, peerrecord.ErrPubKeyMismatch
+	}
+	return d.records.Update(record, raw)
+}
+
+// addAddrs record the addresses of peer found to peer store.
+func (d *ProtocolBasedDiscovery) addAddrs(pid peer.ID, addrs []ma.Multiaddr) {
+	if len(addrs) > 0 {
+		d.host.PeerStore().AddAddrWithTTL(pid, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, addrs...)
+	}
+}
+
+// finderAddr return the remote address of the connection with the finder, or nil if not connected.
+func (d *ProtocolBasedDiscovery) finderAddr(pid peer.ID) ma.Multiaddr {
+	conn := d.host.ConnMgr().GetPeerConn(pid)
+	if conn == nil {
+		return nil
+	}
+	return conn.RemoteAddr()
+}
+
 func (d *ProtocolBasedDiscovery) handlerFindReq(senderPID peer.ID, msg *pb.DiscoveryMsg, protocol protocol.ID) {
 	// finding request type msg
+	pInfoList := d.findPeerInfos(senderPID, msg, protocol)
+	// send find-response msg to finder
+	m := &pb.DiscoveryMsg{
+		Type:   pb.DiscoveryMsg_FindRes,
+		PInfos: pInfoList,
+	}
+	msgBytes, e := d.marshalMsg(m)
+	if e != nil {
+		return
+	}
+	e = d.host.SendMsg(protocol, senderPID, msgBytes)
+	if e != nil {
+		d.logger.Debugf("send discovery find response msg failed, %s (remote pid: %s)", e.Error(), senderPID)
+		return
+	}
+}
+
+// findPeerInfos return the infos of peers supporting the protocol but unknown by the finder,
+// with their addresses reachable by the finder, best-ranked first.
+func (d *ProtocolBasedDiscovery) findPeerInfos(senderPID peer.ID, msg *pb.DiscoveryMsg,
+	protocol protocol.ID) []*pb.PeerInfo {
 	// read peers known by finder
 	knownPeersMap := make(map[peer.ID]struct{})
 	knownPeersMap[senderPID] = struct{}{}
@@ -183,6 +349,7 @@ func (d *ProtocolBasedDiscovery) handlerFindReq(senderPID peer.ID, msg *pb.Disco
 		querySize = d.maxQuerySize
 	}
 	foundSize := 0
+	finderAddr := d.finderAddr(senderPID)
 	// get all peers who support protocol
 	peersFound := d.host.PeerStore().AllSupportProtocolPeers(protocol)
 	pInfoList := make([]*pb.PeerInfo, 0, len(peersFound))
@@ -192,16 +359,24 @@ func (d *ProtocolBasedDiscovery) handlerFindReq(senderPID peer.ID, msg *pb.Disco
 			// if known by finder , ignore
 			continue
 		}
-		pNetworkAddr := d.host.PeerStore().GetFirstAddr(pid)
-		if pNetworkAddr == nil {
-			// if no addr found , ignore
+		infos := d.host.PeerStore().AddrInfos(pid)
+		record, addrs := d.records.Get(pid)
+		if record == nil {
+			// if no signed peer record known, return the addresses in peer store
+			addrs = d.announceableAddrs(infos)
+		} else {
+			addrs = rankAddrs(infos, addrs)
+		}
+		// only the addresses reachable by finder will be returned, e.g. loopback ones only to the finder on loopback
+		addrs = filterAddrs(d.addrFilter, finderAddr, addrs)
+		if len(addrs) == 0 {
 			continue
 		}
-		// append peer address to result
-		pNetworkAddr = util.CreateMultiAddrWithPidAndNetAddr(pid, pNetworkAddr)
+		// append peer info with signed peer record if known to result
 		pInfoList = append(pInfoList, &pb.PeerInfo{
-			Pid:  pid.ToString(),
-			Addr: pNetworkAddr.String(),
+			Pid:          pid.ToString(),
+			SignedRecord: record,
+			Addrs:        addrStrings(pid, addrs),
 		})
 		foundSize++
 		// whether enough peers found
@@ -209,21 +384,19 @@ func (d *ProtocolBasedDiscovery) handlerFindReq(senderPID peer.ID, msg *pb.Disco
 			break
 		}
 	}
-	// send find-response msg to finder
-	m := &pb.DiscoveryMsg{
-		Type:   pb.DiscoveryMsg_FindRes,
-		PInfos: pInfoList,
-	}
-	msgBytes, e := proto.Marshal(m)
-	if e != nil {
-		d.logger.Errorf("marshal discovery msg failed, %s", e.Error())
-		return
-	}
-	e = d.host.SendMsg(protocol, senderPID, msgBytes)
-	if e != nil {
-		d.logger.Debugf("send discovery find response msg failed, %s (remote pid: %s)", e.Error(), senderPID)
-		return
+	return pInfoList
+}
+
+// announceableAddrs return the net addresses in the infos given, except the remote addresses of inbound connections
+// whose remote port is ephemeral and the addresses that could not be announced.
+func (d *ProtocolBasedDiscovery) announceableAddrs(infos []*store.AddrInfo) []ma.Multiaddr {
+	res := make([]ma.Multiaddr, 0, len(infos))
+	for i := range infos {
+		if infos[i].Source != store.AddrSourceInbound && d.host.CanAnnounceAddr(infos[i].Addr) {
+			res = append(res, infos[i].Addr)
+		}
 	}
+	return res
 }
 
 func (d *ProtocolBasedDiscovery) handlerFindRes(serviceName string, msg *pb.DiscoveryMsg) {
@@ -248,13 +421,27 @@ func (d *ProtocolBasedDiscovery) handlerFindRes(serviceName string, msg *pb.Disc
 			// if peer connected or not allowed or its myself, ignore.
 			continue
 		}
-		// push addr to finding out chan
-		mAddr, err := ma.NewMultiaddr(pInfo.Addr)
-		if err != nil {
-			d.logger.Errorf("not a effective addr, %s", err.Error())
+		var addrs []ma.Multiaddr
+		if len(pInfo.SignedRecord) == 0 {
+			// no signed peer record known by the responder, take the addresses returned
+			addrs = parseAddrs(pid, pInfo.Addrs)
+		} else {
+			// verify the signed peer record, and ignore it if older than the one known
+			recordAddrs, err := d.acceptRecord(pid, pInfo.SignedRecord)
+			if err != nil {
+				if err != peerrecord.ErrStaleRecord {
+					d.logger.Warnf("invalid peer record found, %s (pid: %s)", err.Error(), pid)
+				}
+				continue
+			}
+			addrs = scopedAddrs(recordAddrs, pInfo.Addrs)
+		}
+		if len(addrs) == 0 {
 			continue
 		}
-		c <- mAddr
+		d.addAddrs(pid, addrs)
+		// push addr to finding out chan
+		c <- util.CreateMultiAddrWithPidAndNetAddr(pid, addrs[0])
 	}
 }
 
@@ -268,20 +455,32 @@ func (d *ProtocolBasedDiscovery) createProtocolMsgHandler(
 			d.logger.Errorf("unmarshal discovery msg failed, %s", err.Error())
 			return
 		}
+		// accept the signed peer record of sender
+		if len(msg.SignedRecord) > 0 {
+			recordAddrs, e := d.acceptRecord(senderPID, msg.SignedRecord)
+			if e == nil {
+				d.addAddrs(senderPID, recordAddrs)
+			} else if e != peerrecord.ErrStaleRecord {
+				d.logger.Warnf("invalid peer record of sender, %s (remote pid: %s)", e.Error(), senderPID)
+			}
+		}
 		// switch msg type
 		switch msg.Type {
 		case pb.DiscoveryMsg_Announce:
-			// announce type msg
-			if len(msg.PInfos) == 0 {
-				d.logger.Warnf("nil or empty pid. (msg type: %s)", pb.DiscoveryMsg_Announce.String())
+			// announce type msg, only the sender itself could be announced
+			if len(msg.PInfos) == 0 || peer.ID(msg.PInfos[0].Pid) != senderPID {
+				d.logger.Warnf("nil, empty or mismatched pid. (msg type: %s)", pb.DiscoveryMsg_Announce.String())
 				return
 			}
-			// load peer.ID
-			newSupportPid := peer.ID(msg.PInfos[0].Pid)
-			// if i have the addr info of peer, add this protocol to the list that peer supported
-			if d.host.PeerStore().GetFirstAddr(newSupportPid) != nil {
-				d.host.PeerStore().AddProtocol(newSupportPid, protocol)
+			// if i have the addr info of peer, add this protocol to the list that peer supported until expired
+			if d.host.PeerStore().GetFirstAddr(senderPID) != nil {
+				d.host.PeerStore().AddProtocol(senderPID, protocol)
+				d.announcements.add(senderPID, protocol, announceTTL(msg.Ttl))
 			}
+		case pb.DiscoveryMsg_Unannounce:
+			// unannounce type msg, remove this protocol from the list that sender supported
+			d.announcements.remove(senderPID, protocol)
+			d.host.PeerStore().DeleteProtocol(senderPID, protocol)
 		case pb.DiscoveryMsg_FindReq:
 			d.handlerFindReq(senderPID, msg, protocol)
 		case pb.DiscoveryMsg_FindRes:
@@ -292,9 +491,19 @@ func (d *ProtocolBasedDiscovery) createProtocolMsgHandler(
 	}
 }
 
-// Announce tell other peers that I have supported a new service with name given.
-func (d *ProtocolBasedDiscovery) Announce(_ context.Context, serviceName string, _ ...discovery.Option) error {
+// Announce tell other peers that I have supported a new service with name given,
+// then refresh the announcement every half of ttl until Unannounce called.
+func (d *ProtocolBasedDiscovery) Announce(ctx context.Context, serviceName string, opts ...discovery.Option) error {
 	serviceName = strings.TrimSpace(serviceName)
+	// apply options
+	os := &options{Options: discovery.Options{Opts: make(map[interface{}]interface{})}}
+	if err := os.applyDiscoveryOptions(opts...); err != nil {
+		return err
+	}
+	ttl := os.ttl
+	if ttl <= 0 {
+		ttl = DefaultAnnounceTTL
+	}
 	// create protocol ID
 	protoID := d.createProtocolIDWithServiceName(serviceName)
 	_, ok := d.svcMap.Load(serviceName)
@@ -310,38 +519,127 @@ func (d *ProtocolBasedDiscovery) Announce(_ context.Context, serviceName string,
 	}
 
 	// send announce msg to all peer which support this protocol
+	if err := d.sendAnnounceMsg(ctx, protoID, pb.DiscoveryMsg_Announce, ttl, os.timeout); err != nil {
+		return err
+	}
+
+	// refresh announcement in background
+	refreshCtx, cancel := context.WithCancel(d.ctx)
+	d.announcedMu.Lock()
+	if oldCancel, exist := d.announced[serviceName]; exist {
+		oldCancel()
+	}
+	d.announced[serviceName] = cancel
+	d.announcedMu.Unlock()
+	go d.refreshTask(refreshCtx, protoID, ttl)
+	return nil
+}
+
+func (d *ProtocolBasedDiscovery) refreshTask(ctx context.Context, protoID protocol.ID, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.sendAnnounceMsg(ctx, protoID, pb.DiscoveryMsg_Announce, ttl, 0); err != nil {
+				d.logger.Warnf("refresh announcement failed, %s (protocol: %s)", err.Error(), protoID)
+			}
+		}
+	}
+}
+
+// Unannounce stop refreshing the announcement of service, tell other peers that I no longer support it,
+// then unregister the discovery protocol of service from host.
+func (d *ProtocolBasedDiscovery) Unannounce(ctx context.Context, serviceName string, opts ...discovery.Option) error {
+	serviceName = strings.TrimSpace(serviceName)
+	// apply options
+	os := &options{Options: discovery.Options{Opts: make(map[interface{}]interface{})}}
+	if err := os.applyDiscoveryOptions(opts...); err != nil {
+		return err
+	}
+	d.announcedMu.Lock()
+	if cancel, ok := d.announced[serviceName]; ok {
+		cancel()
+		delete(d.announced, serviceName)
+	}
+	d.announcedMu.Unlock()
+	if _, ok := d.svcMap.Load(serviceName); !ok {
+		return nil
+	}
+	protoID := d.createProtocolIDWithServiceName(serviceName)
+	timeout := os.timeout
+	if timeout <= 0 {
+		timeout = defaultUnannounceTimeout
+	}
+	err := d.sendAnnounceMsg(ctx, protoID, pb.DiscoveryMsg_Unannounce, 0, timeout)
+	if err != nil {
+		d.logger.Warnf("send unannouncement failed, %s (protocol: %s)", err.Error(), protoID)
+	}
+	d.svcMap.Delete(serviceName)
+	if e := d.host.UnregisterMsgPayloadHandler(protoID); e != nil {
+		return e
+	}
+	return err
+}
+
+// sendAnnounceMsg send an announce or unannounce msg to all peers connected which support the protocol.
+// If timeout given, wait until all msg sent or timeout, otherwise return immediately.
+func (d *ProtocolBasedDiscovery) sendAnnounceMsg(ctx context.Context, protoID protocol.ID,
+	msgType pb.DiscoveryMsg_Type, ttl, timeout time.Duration) error {
 	allPeers, err := d.host.PeerProtocols([]protocol.ID{protoID})
 	if err != nil {
 		return err
 	}
-	if allPeers == nil || len(allPeers) == 0 {
+	if len(allPeers) == 0 {
 		return nil
 	}
 	msg := &pb.DiscoveryMsg{
-		Type: pb.DiscoveryMsg_Announce,
+		Type: msgType,
 		PInfos: []*pb.PeerInfo{
 			{
-				Pid:  d.host.ID().ToString(),
-				Addr: "",
+				Pid:   d.host.ID().ToString(),
+				Addrs: addrStrings(d.host.ID(), d.host.AnnounceAddrs()),
 			},
 		},
+		Ttl: int64(ttl / time.Second),
 	}
-	msgBytes, err := proto.Marshal(msg)
+	msgBytes, err := d.marshalMsg(msg)
 	if err != nil {
-		d.logger.Errorf("marshal discovery msg failed, %s", err.Error())
 		return err
 	}
+	var wg sync.WaitGroup
+	wg.Add(len(allPeers))
 	for i := range allPeers {
-		peerProto := allPeers[i]
-		rPid := peerProto.PID
+		rPid := allPeers[i].PID
 		go func() {
+			defer wg.Done()
 			e := d.host.SendMsg(protoID, rPid, msgBytes)
 			if e != nil {
-				d.logger.Warnf("send discovery announce msg failed, %s", err.Error())
+				d.logger.Warnf("send discovery %s msg failed, %s (remote pid: %s)", msgType.String(), e.Error(), rPid)
 			}
 		}()
 	}
-	return nil
+	if timeout <= 0 {
+		return nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	doneC := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneC)
+	}()
+	select {
+	case <-doneC:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (d *ProtocolBasedDiscovery) sendFindReqMsgToOthers(protocol protocol.ID, querySize int) {
@@ -362,9 +660,8 @@ func (d *ProtocolBasedDiscovery) sendFindReqMsgToOthers(protocol protocol.ID, qu
 		PInfos: pInfos,
 		Size_:  uint32(querySize),
 	}
-	msgBytes, err := proto.Marshal(msg)
+	msgBytes, err := d.marshalMsg(msg)
 	if err != nil {
-		d.logger.Errorf("marshal discovery msg failed, %s", err.Error())
 		return
 	}
 	// send msg to peers found
@@ -372,7 +669,6 @@ func (d *ProtocolBasedDiscovery) sendFindReqMsgToOthers(protocol protocol.ID, qu
 		err = d.host.SendMsg(protocol, peersFound[i], msgBytes)
 		if err != nil {
 			d.logger.Debugf("send discovery find request msg failed, %s, peer:%s", err.Error(), peersFound[i])
-			return
 		}
 	}
 }
@@ -386,51 +682,43 @@ func (d *ProtocolBasedDiscovery) findPeersTask(ctx context.Context, serviceName
 		return
 	}
 	querySize := os.size
-	if querySize == 0 {
+	if querySize <= 0 {
 		querySize = DefaultQuerySize
 	}
+	if querySize > d.maxQuerySize {
+		querySize = d.maxQuerySize
+	}
 	timeout := os.timeout
-	if timeout == 0 {
+	if timeout <= 0 {
 		timeout = d.defaultQueryTimeout
 	}
+	// if timeout was set, use a timer to control loop exit either
+	var timeoutC <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
 
 	// create protocol ID
 	protoID := d.createProtocolIDWithServiceName(serviceName)
 	// run a loop with a ticker
 	ticker := time.NewTicker(time.Second)
-	if timeout > 0 {
-		// if timout option was set, use a timer to control loop exit ether
-		timer := time.NewTimer(timeout)
-		for {
-			select {
-			case <-timer.C:
-				return
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				if d.host.ConnMgr().PeerCount() >= d.host.ConnMgr().MaxPeerCountAllowed() {
-					// if count of peer connected reach the max value, ignore
-					continue
-				}
-				// send finding request msg to others
-				d.sendFindReqMsgToOthers(protoID, querySize)
-				ticker.Reset(d.findingTickerInterval)
-			}
-		}
-	} else {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				if d.host.ConnMgr().PeerCount() >= d.host.ConnMgr().MaxPeerCountAllowed() {
-					// if count of peer connected reach the max value, ignore
-					continue
-				}
-				// send finding request msg to others
-				d.sendFindReqMsgToOthers(protoID, querySize)
-				ticker.Reset(d.findingTickerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-timeoutC:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if d.host.ConnMgr().PeerCount() >= d.host.ConnMgr().MaxPeerCountAllowed() {
+				// if count of peer connected reach the max value, ignore
+				continue
 			}
+			// send finding request msg to others
+			d.sendFindReqMsgToOthers(protoID, querySize)
+			ticker.Reset(d.findingTickerInterval)
 		}
 	}
 }
@@ -450,3 +738,91 @@ func (d *ProtocolBasedDiscovery) FindPeers(ctx context.Context, serviceName stri
 
 	return findingC, nil
 }
+
+// announceTTL return the ttl of announcement in seconds given as time.Duration, limited by MaxAnnounceTTL.
+// The default one will be returned if not given, e.g. the announcement sent by the older version.
+func announceTTL(seconds int64) time.Duration {
+	if seconds <= 0 {
+		return DefaultAnnounceTTL
+	}
+	if seconds > int64(MaxAnnounceTTL/time.Second) {
+		return MaxAnnounceTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// scopedAddrs return the addresses in the signed peer record which the responder returned as scoped for us.
+// The addresses not in the record are ignored, for they are not signed.
+// All addresses in the record will be returned if none returned, e.g. by the responder of older version.
+func scopedAddrs(recordAddrs []ma.Multiaddr, returned []string) []ma.Multiaddr {
+	if len(returned) == 0 {
+		return recordAddrs
+	}
+	res := make([]ma.Multiaddr, 0, len(returned))
+	for _, s := range returned {
+		addr, err := ma.NewMultiaddr(s)
+		if err != nil {
+			continue
+		}
+		netAddr, _ := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+		if netAddr == nil {
+			continue
+		}
+		for _, recordAddr := range recordAddrs {
+			if recordAddr.Equal(netAddr) {
+				res = append(res, recordAddr)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// rankAddrs return the addresses given ranked as the infos given, which are ranked by peer store,
+// followed by the ones not found in the infos in the order given.
+func rankAddrs(infos []*store.AddrInfo, addrs []ma.Multiaddr) []ma.Multiaddr {
+	res := make([]ma.Multiaddr, 0, len(addrs))
+	added := make([]bool, len(addrs))
+	for i := range infos {
+		for j := range addrs {
+			if !added[j] && addrs[j].Equal(infos[i].Addr) {
+				res = append(res, addrs[j])
+				added[j] = true
+				break
+			}
+		}
+	}
+	for j := range addrs {
+		if !added[j] {
+			res = append(res, addrs[j])
+		}
+	}
+	return res
+}
+
+// parseAddrs return the net addresses of peer parsed from the strings given,
+// the ones could not be parsed or of another peer will be ignored.
+func parseAddrs(pid peer.ID, addrStrs []string) []ma.Multiaddr {
+	res := make([]ma.Multiaddr, 0, len(addrStrs))
+	for _, s := range addrStrs {
+		addr, err := ma.NewMultiaddr(s)
+		if err != nil {
+			continue
+		}
+		netAddr, addrPid := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+		if netAddr == nil || addrPid != pid {
+			continue
+		}
+		res = append(res, netAddr)
+	}
+	return res
+}
+
+// addrStrings return the string of net addresses given, with the /p2p part of peer.
+func addrStrings(pid peer.ID, addrs []ma.Multiaddr) []string {
+	res := make([]string, 0, len(addrs))
+	for i := range addrs {
+		res = append(res, util.CreateMultiAddrWithPidAndNetAddr(pid, addrs[i]).String())
+	}
+	return res
+}
@@ -26,21 +26,24 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 type DiscoveryMsg_Type int32
 
 const (
-	DiscoveryMsg_Announce DiscoveryMsg_Type = 0
-	DiscoveryMsg_FindReq  DiscoveryMsg_Type = 1
-	DiscoveryMsg_FindRes  DiscoveryMsg_Type = 2
+	DiscoveryMsg_Announce   DiscoveryMsg_Type = 0
+	DiscoveryMsg_FindReq    DiscoveryMsg_Type = 1
+	DiscoveryMsg_FindRes    DiscoveryMsg_Type = 2
+	DiscoveryMsg_Unannounce DiscoveryMsg_Type = 3
 )
 
 var DiscoveryMsg_Type_name = map[int32]string{
 	0: "Announce",
 	1: "FindReq",
 	2: "FindRes",
+	3: "Unannounce",
 }
 
 var DiscoveryMsg_Type_value = map[string]int32{
-	"Announce": 0,
-	"FindReq":  1,
-	"FindRes":  2,
+	"Announce":   0,
+	"FindReq":    1,
+	"FindRes":    2,
+	"Unannounce": 3,
 }
 
 func (x DiscoveryMsg_Type) String() string {
@@ -52,9 +55,11 @@ func (DiscoveryMsg_Type) EnumDescriptor() ([]byte, []int) {
 }
 
 type DiscoveryMsg struct {
-	Type   DiscoveryMsg_Type `protobuf:"varint,1,opt,name=type,proto3,enum=discovery.DiscoveryMsg_Type" json:"type,omitempty"`
-	PInfos []*PeerInfo       `protobuf:"bytes,2,rep,name=pInfos,proto3" json:"pInfos,omitempty"`
-	Size_  uint32            `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Type         DiscoveryMsg_Type `protobuf:"varint,1,opt,name=type,proto3,enum=discovery.DiscoveryMsg_Type" json:"type,omitempty"`
+	PInfos       []*PeerInfo       `protobuf:"bytes,2,rep,name=pInfos,proto3" json:"pInfos,omitempty"`
+	Size_        uint32            `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	SignedRecord []byte            `protobuf:"bytes,4,opt,name=signedRecord,proto3" json:"signedRecord,omitempty"`
+	Ttl          int64             `protobuf:"varint,5,opt,name=ttl,proto3" json:"ttl,omitempty"`
 }
 
 func (m *DiscoveryMsg) Reset()         { *m = DiscoveryMsg{} }
@@ -111,9 +116,25 @@ func (m *DiscoveryMsg) GetSize_() uint32 {
 	return 0
 }
 
+func (m *DiscoveryMsg) GetSignedRecord() []byte {
+	if m != nil {
+		return m.SignedRecord
+	}
+	return nil
+}
+
+func (m *DiscoveryMsg) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
 type PeerInfo struct {
-	Pid  string `protobuf:"bytes,1,opt,name=pid,proto3" json:"pid,omitempty"`
-	Addr string `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	Pid          string   `protobuf:"bytes,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Addr         string   `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	SignedRecord []byte   `protobuf:"bytes,3,opt,name=signedRecord,proto3" json:"signedRecord,omitempty"`
+	Addrs        []string `protobuf:"bytes,4,rep,name=addrs,proto3" json:"addrs,omitempty"`
 }
 
 func (m *PeerInfo) Reset()         { *m = PeerInfo{} }
@@ -163,6 +184,20 @@ func (m *PeerInfo) GetAddr() string {
 	return ""
 }
 
+func (m *PeerInfo) GetSignedRecord() []byte {
+	if m != nil {
+		return m.SignedRecord
+	}
+	return nil
+}
+
+func (m *PeerInfo) GetAddrs() []string {
+	if m != nil {
+		return m.Addrs
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("discovery.DiscoveryMsg_Type", DiscoveryMsg_Type_name, DiscoveryMsg_Type_value)
 	proto.RegisterType((*DiscoveryMsg)(nil), "discovery.DiscoveryMsg")
@@ -172,25 +207,29 @@ func init() {
 func init() { proto.RegisterFile("discovery_msg.proto", fileDescriptor_5a6010f00700fb32) }
 
 var fileDescriptor_5a6010f00700fb32 = []byte{
-	// 287 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0xcf, 0x4a, 0xc3, 0x30,
-	0x1c, 0xc7, 0x9b, 0x6d, 0xcc, 0x2d, 0x9b, 0x52, 0xb2, 0x4b, 0x0f, 0x12, 0x46, 0x4f, 0x05, 0x31,
-	0x19, 0xf3, 0x09, 0x14, 0x11, 0x3c, 0x0c, 0xa4, 0x78, 0xf2, 0x22, 0x5d, 0x13, 0x6b, 0xb0, 0x4d,
-	0xba, 0xa4, 0x15, 0xea, 0x53, 0xf8, 0x2a, 0xbe, 0x85, 0xc7, 0x1d, 0x3d, 0x4a, 0xfb, 0x22, 0xd2,
-	0xe0, 0xba, 0xde, 0xbe, 0xdf, 0x4f, 0x3e, 0xf9, 0xe5, 0x0f, 0x5c, 0x30, 0x61, 0x62, 0xf5, 0xce,
-	0x75, 0xf5, 0x9c, 0x99, 0x84, 0xe4, 0x5a, 0x15, 0x0a, 0x4d, 0x3b, 0xe8, 0x7f, 0x01, 0x38, 0xbf,
-	0x3d, 0xb4, 0x8d, 0x49, 0xd0, 0x0a, 0x8e, 0x8a, 0x2a, 0xe7, 0x1e, 0x58, 0x82, 0xe0, 0x6c, 0x7d,
-	0x4e, 0x3a, 0x95, 0xf4, 0x35, 0xf2, 0x58, 0xe5, 0x3c, 0xb4, 0x26, 0xba, 0x80, 0xe3, 0xfc, 0x5e,
-	0xbe, 0x28, 0xe3, 0x0d, 0x96, 0xc3, 0x60, 0xb6, 0x5e, 0xf4, 0xf6, 0x3c, 0x70, 0xae, 0xdb, 0xb5,
-	0xf0, 0x5f, 0x41, 0x08, 0x8e, 0x8c, 0xf8, 0xe0, 0xde, 0x70, 0x09, 0x82, 0xd3, 0xd0, 0x66, 0x9f,
-	0xc0, 0x51, 0x3b, 0x0e, 0xcd, 0xe1, 0xe4, 0x5a, 0x4a, 0x55, 0xca, 0x98, 0xbb, 0x0e, 0x9a, 0xc1,
-	0x93, 0x3b, 0x21, 0x59, 0xc8, 0x77, 0x2e, 0x38, 0x16, 0xe3, 0x0e, 0xfc, 0x15, 0x9c, 0x1c, 0xe6,
-	0x22, 0x17, 0x0e, 0x73, 0xc1, 0xec, 0x6d, 0xa7, 0x61, 0x1b, 0xdb, 0x13, 0x22, 0xc6, 0xb4, 0x37,
-	0xb0, 0xc8, 0xe6, 0x9b, 0xe4, 0xbb, 0xc6, 0x60, 0x5f, 0x63, 0xf0, 0x5b, 0x63, 0xf0, 0xd9, 0x60,
-	0x67, 0xdf, 0x60, 0xe7, 0xa7, 0xc1, 0xce, 0xd3, 0x26, 0x7e, 0x8d, 0x84, 0xcc, 0xa2, 0x37, 0xae,
-	0x89, 0xd2, 0x09, 0x3d, 0xd6, 0xcb, 0x44, 0xd1, 0x4c, 0xb1, 0x32, 0xe5, 0x54, 0xf2, 0x82, 0xa6,
-	0x62, 0x57, 0x0a, 0x46, 0xbb, 0xa7, 0x51, 0xfb, 0x95, 0xb1, 0x4a, 0x7b, 0x64, 0xbb, 0x1d, 0x5b,
-	0x7a, 0xf5, 0x17, 0x00, 0x00, 0xff, 0xff, 0x00, 0xab, 0x6f, 0x1b, 0x77, 0x01, 0x00, 0x00,
+	// 342 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0x4f, 0x4b, 0xc3, 0x30,
+	0x18, 0xc6, 0x9b, 0xb5, 0x9b, 0xdb, 0xbb, 0x3a, 0x4a, 0xe6, 0xa1, 0x07, 0x29, 0xa5, 0xa7, 0x82,
+	0xd8, 0xca, 0xbc, 0x0b, 0x8a, 0x08, 0x1e, 0x06, 0x12, 0xf4, 0xe2, 0x45, 0xba, 0x26, 0xd6, 0x68,
+	0x97, 0x74, 0x4d, 0x27, 0xcc, 0x4f, 0xe1, 0xc7, 0xf2, 0xb8, 0xa3, 0x47, 0xd9, 0x3e, 0x84, 0x57,
+	0x69, 0x74, 0x7f, 0x44, 0x6f, 0xcf, 0xf3, 0xe4, 0x97, 0xf0, 0xe4, 0x7d, 0xa1, 0x4f, 0xb9, 0x4a,
+	0xe5, 0x33, 0x2b, 0x67, 0x77, 0x63, 0x95, 0x45, 0x45, 0x29, 0x2b, 0x89, 0x3b, 0xeb, 0x30, 0xf8,
+	0x44, 0x60, 0x9f, 0xaf, 0xdc, 0x50, 0x65, 0xf8, 0x08, 0xac, 0x6a, 0x56, 0x30, 0x17, 0xf9, 0x28,
+	0xec, 0x0d, 0xf6, 0xa3, 0x35, 0x1a, 0x6d, 0x63, 0xd1, 0xf5, 0xac, 0x60, 0x44, 0x93, 0xf8, 0x00,
+	0x5a, 0xc5, 0xa5, 0xb8, 0x97, 0xca, 0x6d, 0xf8, 0x66, 0xd8, 0x1d, 0xf4, 0xb7, 0xee, 0x5c, 0x31,
+	0x56, 0xd6, 0x67, 0xe4, 0x07, 0xc1, 0x18, 0x2c, 0xc5, 0x5f, 0x98, 0x6b, 0xfa, 0x28, 0xdc, 0x25,
+	0x5a, 0xe3, 0x00, 0x6c, 0xc5, 0x33, 0xc1, 0x28, 0x61, 0xa9, 0x2c, 0xa9, 0x6b, 0xf9, 0x28, 0xb4,
+	0xc9, 0xaf, 0x0c, 0x3b, 0x60, 0x56, 0x55, 0xee, 0x36, 0x7d, 0x14, 0x9a, 0xa4, 0x96, 0xc1, 0x09,
+	0x58, 0x75, 0x09, 0x6c, 0x43, 0xfb, 0x54, 0x08, 0x39, 0x15, 0x29, 0x73, 0x0c, 0xdc, 0x85, 0x9d,
+	0x0b, 0x2e, 0x28, 0x61, 0x13, 0x07, 0x6d, 0x8c, 0x72, 0x1a, 0xb8, 0x07, 0x70, 0x23, 0x92, 0x15,
+	0x69, 0x06, 0x8f, 0xd0, 0x5e, 0xb5, 0xab, 0x5f, 0x2f, 0x38, 0xd5, 0x7f, 0xee, 0x90, 0x5a, 0xd6,
+	0x3d, 0x13, 0x4a, 0x4b, 0xb7, 0xa1, 0x23, 0xad, 0xff, 0xf4, 0x34, 0xff, 0xe9, 0xb9, 0x07, 0xcd,
+	0x9a, 0x55, 0xae, 0xe5, 0x9b, 0x61, 0x87, 0x7c, 0x9b, 0xb3, 0xec, 0x6d, 0xe1, 0xa1, 0xf9, 0xc2,
+	0x43, 0x1f, 0x0b, 0x0f, 0xbd, 0x2e, 0x3d, 0x63, 0xbe, 0xf4, 0x8c, 0xf7, 0xa5, 0x67, 0xdc, 0x0e,
+	0xd3, 0x87, 0x84, 0x8b, 0x71, 0xf2, 0xc4, 0xca, 0x48, 0x96, 0x59, 0xbc, 0xb1, 0x87, 0x99, 0x8c,
+	0xc7, 0x92, 0x4e, 0x73, 0x16, 0x0b, 0x56, 0xc5, 0x39, 0x9f, 0x4c, 0x39, 0x8d, 0xd7, 0xa3, 0x8d,
+	0xf5, 0x2a, 0x53, 0x99, 0x6f, 0x25, 0xa3, 0x51, 0x4b, 0xa7, 0xc7, 0x5f, 0x03, 0x00, 0xae, 0x0b,
+	0xf5, 0xfa, 0xf7, 0x01, 0x00, 0x00,
 }
 
 func (m *DiscoveryMsg) Marshal() (dAtA []byte, err error) {
@@ -213,6 +252,18 @@ func (m *DiscoveryMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Ttl != 0 {
+		i = encodeVarintDiscoveryMsg(dAtA, i, uint64(m.Ttl))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.SignedRecord) > 0 {
+		i -= len(m.SignedRecord)
+		copy(dAtA[i:], m.SignedRecord)
+		i = encodeVarintDiscoveryMsg(dAtA, i, uint64(len(m.SignedRecord)))
+		i--
+		dAtA[i] = 0x22
+	}
 	if m.Size_ != 0 {
 		i = encodeVarintDiscoveryMsg(dAtA, i, uint64(m.Size_))
 		i--
@@ -260,6 +311,22 @@ func (m *PeerInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Addrs) > 0 {
+		for iNdEx := len(m.Addrs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Addrs[iNdEx])
+			copy(dAtA[i:], m.Addrs[iNdEx])
+			i = encodeVarintDiscoveryMsg(dAtA, i, uint64(len(m.Addrs[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.SignedRecord) > 0 {
+		i -= len(m.SignedRecord)
+		copy(dAtA[i:], m.SignedRecord)
+		i = encodeVarintDiscoveryMsg(dAtA, i, uint64(len(m.SignedRecord)))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.Addr) > 0 {
 		i -= len(m.Addr)
 		copy(dAtA[i:], m.Addr)
@@ -306,6 +373,13 @@ func (m *DiscoveryMsg) Size() (n int) {
 	if m.Size_ != 0 {
 		n += 1 + sovDiscoveryMsg(uint64(m.Size_))
 	}
+	l = len(m.SignedRecord)
+	if l > 0 {
+		n += 1 + l + sovDiscoveryMsg(uint64(l))
+	}
+	if m.Ttl != 0 {
+		n += 1 + sovDiscoveryMsg(uint64(m.Ttl))
+	}
 	return n
 }
 
@@ -323,6 +397,16 @@ func (m *PeerInfo) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovDiscoveryMsg(uint64(l))
 	}
+	l = len(m.SignedRecord)
+	if l > 0 {
+		n += 1 + l + sovDiscoveryMsg(uint64(l))
+	}
+	if len(m.Addrs) > 0 {
+		for _, s := range m.Addrs {
+			l = len(s)
+			n += 1 + l + sovDiscoveryMsg(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -433,6 +517,59 @@ func (m *DiscoveryMsg) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SignedRecord", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDiscoveryMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDiscoveryMsg
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDiscoveryMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SignedRecord = append(m.SignedRecord[:0], dAtA[iNdEx:postIndex]...)
+			if m.SignedRecord == nil {
+				m.SignedRecord = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ttl", wireType)
+			}
+			m.Ttl = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDiscoveryMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Ttl |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipDiscoveryMsg(dAtA[iNdEx:])
@@ -547,6 +684,72 @@ func (m *PeerInfo) Unmarshal(dAtA []byte) error {
 			}
 			m.Addr = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SignedRecord", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDiscoveryMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDiscoveryMsg
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDiscoveryMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SignedRecord = append(m.SignedRecord[:0], dAtA[iNdEx:postIndex]...)
+			if m.SignedRecord == nil {
+				m.SignedRecord = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addrs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDiscoveryMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDiscoveryMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDiscoveryMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addrs = append(m.Addrs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipDiscoveryMsg(dAtA[iNdEx:])
@@ -0,0 +1,69 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package protocoldiscovery_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/protocoldiscovery"
+	"chainmaker.org/chainmaker/net-liquid/host/hosttest"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtocolBasedDiscoveryPeerRecords(t *testing.T) {
+	// hosts[1] and hosts[2] connect to hosts[0] only, and find each other with the signed peer records relayed by hosts[0]
+	hosts := make([]host.Host, 3)
+	pds := make([]*protocoldiscovery.ProtocolBasedDiscovery, 3)
+	for i := range hosts {
+		hosts[i] = hosttest.NewHost(t)
+		if i > 0 {
+			hosts[i].AddDirectPeer(hosttest.Addr(hosts[0]))
+		}
+		pd, err := protocoldiscovery.NewProtocolBasedDiscovery(hosts[i],
+			protocoldiscovery.WithFindingTickerInterval(200*time.Millisecond))
+		require.Nil(t, err)
+		require.Nil(t, pd.Announce(context.Background(), "chain1"))
+		pds[i] = pd
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	findingCs := make([]<-chan ma.Multiaddr, 0, 2)
+	for _, pd := range pds[1:] {
+		findingC, err := pd.FindPeers(ctx, "chain1")
+		require.Nil(t, err)
+		findingCs = append(findingCs, findingC)
+	}
+	select {
+	case addr := <-findingCs[0]:
+		netAddr, pid := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+		require.Equal(t, hosts[2].ID(), pid)
+		require.True(t, netAddr.Equal(hosts[2].LocalAddresses()[0]))
+	case <-time.After(10 * time.Second):
+		t.Fatal("peer not found")
+	}
+	require.NotEmpty(t, hosts[1].PeerStore().GetAddrs(hosts[2].ID()))
+
+	// hosts[0] forgets hosts[2] supporting chain1 after unannounced
+	protoID := protocol.ID("/chain-discovery/v0.0.1/chain1")
+	require.Contains(t, hosts[0].PeerStore().AllSupportProtocolPeers(protoID), hosts[2].ID())
+	require.Nil(t, pds[2].Unannounce(context.Background(), "chain1"))
+	require.Eventually(t, func() bool {
+		for _, pid := range hosts[0].PeerStore().AllSupportProtocolPeers(protoID) {
+			if pid == hosts[2].ID() {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+}
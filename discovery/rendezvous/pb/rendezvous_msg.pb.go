@@ -0,0 +1,1009 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: rendezvous_msg.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type RendezvousMsg_Type int32
+
+const (
+	RendezvousMsg_Register    RendezvousMsg_Type = 0
+	RendezvousMsg_RegisterRes RendezvousMsg_Type = 1
+	RendezvousMsg_Unregister  RendezvousMsg_Type = 2
+	RendezvousMsg_Discover    RendezvousMsg_Type = 3
+	RendezvousMsg_DiscoverRes RendezvousMsg_Type = 4
+)
+
+var RendezvousMsg_Type_name = map[int32]string{
+	0: "Register",
+	1: "RegisterRes",
+	2: "Unregister",
+	3: "Discover",
+	4: "DiscoverRes",
+}
+
+var RendezvousMsg_Type_value = map[string]int32{
+	"Register":    0,
+	"RegisterRes": 1,
+	"Unregister":  2,
+	"Discover":    3,
+	"DiscoverRes": 4,
+}
+
+func (x RendezvousMsg_Type) String() string {
+	return proto.EnumName(RendezvousMsg_Type_name, int32(x))
+}
+
+func (RendezvousMsg_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_1118ed0ecdd00c19, []int{0, 0}
+}
+
+type RendezvousMsg_Status int32
+
+const (
+	RendezvousMsg_OK                  RendezvousMsg_Status = 0
+	RendezvousMsg_InvalidNamespace    RendezvousMsg_Status = 1
+	RendezvousMsg_InvalidSignedRecord RendezvousMsg_Status = 2
+	RendezvousMsg_InvalidTTL          RendezvousMsg_Status = 3
+	RendezvousMsg_InvalidCookie       RendezvousMsg_Status = 4
+	RendezvousMsg_Unavailable         RendezvousMsg_Status = 5
+)
+
+var RendezvousMsg_Status_name = map[int32]string{
+	0: "OK",
+	1: "InvalidNamespace",
+	2: "InvalidSignedRecord",
+	3: "InvalidTTL",
+	4: "InvalidCookie",
+	5: "Unavailable",
+}
+
+var RendezvousMsg_Status_value = map[string]int32{
+	"OK":                  0,
+	"InvalidNamespace":    1,
+	"InvalidSignedRecord": 2,
+	"InvalidTTL":          3,
+	"InvalidCookie":       4,
+	"Unavailable":         5,
+}
+
+func (x RendezvousMsg_Status) String() string {
+	return proto.EnumName(RendezvousMsg_Status_name, int32(x))
+}
+
+func (RendezvousMsg_Status) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_1118ed0ecdd00c19, []int{0, 1}
+}
+
+type RendezvousMsg struct {
+	Type          RendezvousMsg_Type   `protobuf:"varint,1,opt,name=type,proto3,enum=rendezvous.RendezvousMsg_Type" json:"type,omitempty"`
+	Seq           uint64               `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Namespace     string               `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Ttl           int64                `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	SignedRecord  []byte               `protobuf:"bytes,5,opt,name=signedRecord,proto3" json:"signedRecord,omitempty"`
+	Limit         uint32               `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cookie        []byte               `protobuf:"bytes,7,opt,name=cookie,proto3" json:"cookie,omitempty"`
+	Registrations []*Registration      `protobuf:"bytes,8,rep,name=registrations,proto3" json:"registrations,omitempty"`
+	Status        RendezvousMsg_Status `protobuf:"varint,9,opt,name=status,proto3,enum=rendezvous.RendezvousMsg_Status" json:"status,omitempty"`
+	StatusText    string               `protobuf:"bytes,10,opt,name=statusText,proto3" json:"statusText,omitempty"`
+}
+
+func (m *RendezvousMsg) Reset()         { *m = RendezvousMsg{} }
+func (m *RendezvousMsg) String() string { return proto.CompactTextString(m) }
+func (*RendezvousMsg) ProtoMessage()    {}
+func (*RendezvousMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1118ed0ecdd00c19, []int{0}
+}
+func (m *RendezvousMsg) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RendezvousMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RendezvousMsg.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RendezvousMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RendezvousMsg.Merge(m, src)
+}
+func (m *RendezvousMsg) XXX_Size() int {
+	return m.Size()
+}
+func (m *RendezvousMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_RendezvousMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RendezvousMsg proto.InternalMessageInfo
+
+func (m *RendezvousMsg) GetType() RendezvousMsg_Type {
+	if m != nil {
+		return m.Type
+	}
+	return RendezvousMsg_Register
+}
+
+func (m *RendezvousMsg) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *RendezvousMsg) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *RendezvousMsg) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+func (m *RendezvousMsg) GetSignedRecord() []byte {
+	if m != nil {
+		return m.SignedRecord
+	}
+	return nil
+}
+
+func (m *RendezvousMsg) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *RendezvousMsg) GetCookie() []byte {
+	if m != nil {
+		return m.Cookie
+	}
+	return nil
+}
+
+func (m *RendezvousMsg) GetRegistrations() []*Registration {
+	if m != nil {
+		return m.Registrations
+	}
+	return nil
+}
+
+func (m *RendezvousMsg) GetStatus() RendezvousMsg_Status {
+	if m != nil {
+		return m.Status
+	}
+	return RendezvousMsg_OK
+}
+
+func (m *RendezvousMsg) GetStatusText() string {
+	if m != nil {
+		return m.StatusText
+	}
+	return ""
+}
+
+type Registration struct {
+	SignedRecord []byte `protobuf:"bytes,1,opt,name=signedRecord,proto3" json:"signedRecord,omitempty"`
+	Ttl          int64  `protobuf:"varint,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+}
+
+func (m *Registration) Reset()         { *m = Registration{} }
+func (m *Registration) String() string { return proto.CompactTextString(m) }
+func (*Registration) ProtoMessage()    {}
+func (*Registration) Descriptor() ([]byte, []int) {
+	return fileDescriptor_1118ed0ecdd00c19, []int{1}
+}
+func (m *Registration) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Registration) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Registration.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Registration) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Registration.Merge(m, src)
+}
+func (m *Registration) XXX_Size() int {
+	return m.Size()
+}
+func (m *Registration) XXX_DiscardUnknown() {
+	xxx_messageInfo_Registration.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Registration proto.InternalMessageInfo
+
+func (m *Registration) GetSignedRecord() []byte {
+	if m != nil {
+		return m.SignedRecord
+	}
+	return nil
+}
+
+func (m *Registration) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("rendezvous.RendezvousMsg_Type", RendezvousMsg_Type_name, RendezvousMsg_Type_value)
+	proto.RegisterEnum("rendezvous.RendezvousMsg_Status", RendezvousMsg_Status_name, RendezvousMsg_Status_value)
+	proto.RegisterType((*RendezvousMsg)(nil), "rendezvous.RendezvousMsg")
+	proto.RegisterType((*Registration)(nil), "rendezvous.Registration")
+}
+
+func init() { proto.RegisterFile("rendezvous_msg.proto", fileDescriptor_1118ed0ecdd00c19) }
+
+var fileDescriptor_1118ed0ecdd00c19 = []byte{
+	// 466 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x92, 0xcd, 0x8e, 0xd3, 0x30,
+	0x14, 0x85, 0xeb, 0x26, 0x0d, 0xd3, 0x3b, 0xed, 0x60, 0x4c, 0x05, 0x5e, 0xa0, 0x28, 0xea, 0x2a,
+	0x1b, 0x1a, 0xa9, 0x6c, 0x58, 0xb1, 0x80, 0x11, 0x12, 0xe2, 0x4f, 0xf2, 0x64, 0x36, 0x6c, 0xc0,
+	0x4d, 0xac, 0x60, 0x4d, 0x12, 0x67, 0x6c, 0xb7, 0xa2, 0x3c, 0x05, 0x0f, 0xc4, 0x03, 0xb0, 0x9c,
+	0x25, 0x4b, 0xd4, 0xbe, 0x08, 0x4a, 0x9a, 0x4e, 0x5a, 0xc1, 0xec, 0xee, 0x39, 0xf9, 0x22, 0xfb,
+	0x9e, 0x63, 0x98, 0x68, 0x51, 0xa6, 0xe2, 0xfb, 0x4a, 0x2d, 0xcd, 0xe7, 0xc2, 0x64, 0xb3, 0x4a,
+	0x2b, 0xab, 0x08, 0x74, 0xee, 0xf4, 0xa7, 0x0b, 0x63, 0x76, 0x2b, 0xdf, 0x9b, 0x8c, 0xcc, 0xc1,
+	0xb5, 0xeb, 0x4a, 0x50, 0x14, 0xa0, 0xf0, 0x6c, 0xee, 0xcf, 0x3a, 0x78, 0x76, 0x04, 0xce, 0xe2,
+	0x75, 0x25, 0x58, 0xc3, 0x12, 0x0c, 0x8e, 0x11, 0xd7, 0xb4, 0x1f, 0xa0, 0xd0, 0x65, 0xf5, 0x48,
+	0x9e, 0xc0, 0xb0, 0xe4, 0x85, 0x30, 0x15, 0x4f, 0x04, 0x75, 0x02, 0x14, 0x0e, 0x59, 0x67, 0xd4,
+	0xbc, 0xb5, 0x39, 0x75, 0x03, 0x14, 0x3a, 0xac, 0x1e, 0xc9, 0x14, 0x46, 0x46, 0x66, 0xa5, 0x48,
+	0x99, 0x48, 0x94, 0x4e, 0xe9, 0x20, 0x40, 0xe1, 0x88, 0x1d, 0x79, 0x64, 0x02, 0x83, 0x5c, 0x16,
+	0xd2, 0x52, 0x2f, 0x40, 0xe1, 0x98, 0xed, 0x04, 0x79, 0x04, 0x5e, 0xa2, 0xd4, 0x95, 0x14, 0xf4,
+	0x5e, 0xf3, 0x4f, 0xab, 0xc8, 0x0b, 0x18, 0x6b, 0x91, 0x49, 0x63, 0x35, 0xb7, 0x52, 0x95, 0x86,
+	0x9e, 0x04, 0x4e, 0x78, 0x3a, 0xa7, 0xc7, 0x0b, 0x75, 0x00, 0x3b, 0xc6, 0xc9, 0x73, 0xf0, 0x8c,
+	0xe5, 0x76, 0x69, 0xe8, 0xb0, 0x49, 0x22, 0xb8, 0x3b, 0x89, 0x8b, 0x86, 0x63, 0x2d, 0x4f, 0x7c,
+	0x80, 0xdd, 0x14, 0x8b, 0x6f, 0x96, 0x42, 0xb3, 0xfc, 0x81, 0x33, 0x8d, 0xc1, 0xad, 0xb3, 0x23,
+	0x23, 0x38, 0xd9, 0x5d, 0x40, 0x68, 0xdc, 0x23, 0xf7, 0xe1, 0x74, 0xaf, 0x98, 0x30, 0x18, 0x91,
+	0x33, 0x80, 0xcb, 0x52, 0xef, 0x81, 0x7e, 0x8d, 0x9f, 0x4b, 0x93, 0xa8, 0x95, 0xd0, 0xd8, 0xa9,
+	0xf1, 0xbd, 0xaa, 0x71, 0x77, 0x6a, 0xc0, 0xdb, 0xdd, 0x83, 0x78, 0xd0, 0xff, 0xf8, 0x16, 0xf7,
+	0xc8, 0x04, 0xf0, 0x9b, 0x72, 0xc5, 0x73, 0x99, 0x7e, 0xd8, 0x27, 0x8f, 0x11, 0x79, 0x0c, 0x0f,
+	0x5b, 0xf7, 0xe2, 0x20, 0x5c, 0xdc, 0xaf, 0xcf, 0x6b, 0x3f, 0xc4, 0xf1, 0x3b, 0xec, 0x90, 0x07,
+	0x30, 0x6e, 0xf5, 0xab, 0x26, 0x51, 0xec, 0xd6, 0x87, 0x5e, 0x96, 0x7c, 0xc5, 0x65, 0xce, 0x17,
+	0xb9, 0xc0, 0x83, 0xe9, 0x39, 0x8c, 0x0e, 0x33, 0xfc, 0xa7, 0x46, 0xf4, 0x9f, 0x1a, 0xdb, 0xf2,
+	0xfb, 0xb7, 0xe5, 0xbf, 0xfc, 0xf2, 0x6b, 0xe3, 0xa3, 0x9b, 0x8d, 0x8f, 0xfe, 0x6c, 0x7c, 0xf4,
+	0x63, 0xeb, 0xf7, 0x6e, 0xb6, 0x7e, 0xef, 0xf7, 0xd6, 0xef, 0x7d, 0x7a, 0x9d, 0x7c, 0xe5, 0xb2,
+	0x2c, 0xf8, 0x95, 0xd0, 0x33, 0xa5, 0xb3, 0xa8, 0x93, 0x4f, 0x33, 0x15, 0x15, 0x2a, 0x5d, 0xe6,
+	0x22, 0x2a, 0x85, 0x8d, 0x72, 0x79, 0xbd, 0x94, 0x69, 0x94, 0xb6, 0x91, 0xac, 0xa3, 0xae, 0xac,
+	0xa8, 0x5a, 0x2c, 0xbc, 0xe6, 0xe5, 0x3f, 0xfb, 0x3b, 0x00, 0x9b, 0x68, 0x33, 0x8a, 0x11, 0x03,
+	0x00, 0x00,
+}
+
+func (m *RendezvousMsg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RendezvousMsg) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RendezvousMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.StatusText) > 0 {
+		i -= len(m.StatusText)
+		copy(dAtA[i:], m.StatusText)
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(len(m.StatusText)))
+		i--
+		dAtA[i] = 0x52
+	}
+	if m.Status != 0 {
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(m.Status))
+		i--
+		dAtA[i] = 0x48
+	}
+	if len(m.Registrations) > 0 {
+		for iNdEx := len(m.Registrations) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Registrations[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintRendezvousMsg(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x42
+		}
+	}
+	if len(m.Cookie) > 0 {
+		i -= len(m.Cookie)
+		copy(dAtA[i:], m.Cookie)
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(len(m.Cookie)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.Limit != 0 {
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.SignedRecord) > 0 {
+		i -= len(m.SignedRecord)
+		copy(dAtA[i:], m.SignedRecord)
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(len(m.SignedRecord)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Ttl != 0 {
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(m.Ttl))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.Namespace) > 0 {
+		i -= len(m.Namespace)
+		copy(dAtA[i:], m.Namespace)
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(len(m.Namespace)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Seq != 0 {
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Type != 0 {
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(m.Type))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Registration) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Registration) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Registration) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Ttl != 0 {
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(m.Ttl))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.SignedRecord) > 0 {
+		i -= len(m.SignedRecord)
+		copy(dAtA[i:], m.SignedRecord)
+		i = encodeVarintRendezvousMsg(dAtA, i, uint64(len(m.SignedRecord)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintRendezvousMsg(dAtA []byte, offset int, v uint64) int {
+	offset -= sovRendezvousMsg(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *RendezvousMsg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovRendezvousMsg(uint64(m.Type))
+	}
+	if m.Seq != 0 {
+		n += 1 + sovRendezvousMsg(uint64(m.Seq))
+	}
+	l = len(m.Namespace)
+	if l > 0 {
+		n += 1 + l + sovRendezvousMsg(uint64(l))
+	}
+	if m.Ttl != 0 {
+		n += 1 + sovRendezvousMsg(uint64(m.Ttl))
+	}
+	l = len(m.SignedRecord)
+	if l > 0 {
+		n += 1 + l + sovRendezvousMsg(uint64(l))
+	}
+	if m.Limit != 0 {
+		n += 1 + sovRendezvousMsg(uint64(m.Limit))
+	}
+	l = len(m.Cookie)
+	if l > 0 {
+		n += 1 + l + sovRendezvousMsg(uint64(l))
+	}
+	if len(m.Registrations) > 0 {
+		for _, e := range m.Registrations {
+			l = e.Size()
+			n += 1 + l + sovRendezvousMsg(uint64(l))
+		}
+	}
+	if m.Status != 0 {
+		n += 1 + sovRendezvousMsg(uint64(m.Status))
+	}
+	l = len(m.StatusText)
+	if l > 0 {
+		n += 1 + l + sovRendezvousMsg(uint64(l))
+	}
+	return n
+}
+
+func (m *Registration) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.SignedRecord)
+	if l > 0 {
+		n += 1 + l + sovRendezvousMsg(uint64(l))
+	}
+	if m.Ttl != 0 {
+		n += 1 + sovRendezvousMsg(uint64(m.Ttl))
+	}
+	return n
+}
+
+func sovRendezvousMsg(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozRendezvousMsg(x uint64) (n int) {
+	return sovRendezvousMsg(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *RendezvousMsg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRendezvousMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RendezvousMsg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RendezvousMsg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= RendezvousMsg_Type(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Namespace", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Namespace = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ttl", wireType)
+			}
+			m.Ttl = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Ttl |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SignedRecord", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SignedRecord = append(m.SignedRecord[:0], dAtA[iNdEx:postIndex]...)
+			if m.SignedRecord == nil {
+				m.SignedRecord = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cookie", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Cookie = append(m.Cookie[:0], dAtA[iNdEx:postIndex]...)
+			if m.Cookie == nil {
+				m.Cookie = []byte{}
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Registrations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Registrations = append(m.Registrations, &Registration{})
+			if err := m.Registrations[len(m.Registrations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			m.Status = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Status |= RendezvousMsg_Status(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field StatusText", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.StatusText = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRendezvousMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Registration) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRendezvousMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Registration: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Registration: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SignedRecord", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SignedRecord = append(m.SignedRecord[:0], dAtA[iNdEx:postIndex]...)
+			if m.SignedRecord == nil {
+				m.SignedRecord = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ttl", wireType)
+			}
+			m.Ttl = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Ttl |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRendezvousMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRendezvousMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipRendezvousMsg(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowRendezvousMsg
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowRendezvousMsg
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthRendezvousMsg
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupRendezvousMsg
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthRendezvousMsg
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthRendezvousMsg        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowRendezvousMsg          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupRendezvousMsg = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,151 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rendezvous
+
+import (
+	"encoding/binary"
+	"sort"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+)
+
+// maxNamespaces is the max count of namespaces a rendezvous point serves at the same time.
+const maxNamespaces = 1024
+
+type registration struct {
+	pid     peer.ID
+	record  []byte
+	expire  time.Time
+	counter uint64
+}
+
+// registry is the state of a rendezvous point, storing the registrations of each namespace.
+// Each registration is tagged with a counter increasing, which is used as the cookie for pagination of discovering.
+type registry struct {
+	maxPerNamespace int
+
+	mu         sync.Mutex
+	counter    uint64
+	namespaces map[string]map[peer.ID]*registration
+}
+
+func newRegistry(maxPerNamespace int) *registry {
+	return &registry{
+		maxPerNamespace: maxPerNamespace,
+		namespaces:      make(map[string]map[peer.ID]*registration),
+	}
+}
+
+// add a registration of the peer to namespace, or renew it if exists.
+func (r *registry) add(ns string, pid peer.ID, record []byte, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	regs, ok := r.namespaces[ns]
+	if !ok {
+		if len(r.namespaces) >= maxNamespaces {
+			return ErrTooManyNamespaces
+		}
+		regs = make(map[peer.ID]*registration)
+		r.namespaces[ns] = regs
+	}
+	if _, exist := regs[pid]; !exist {
+		removeExpired(regs)
+		if len(regs) >= r.maxPerNamespace {
+			return ErrNamespaceFull
+		}
+	}
+	r.counter++
+	regs[pid] = &registration{pid: pid, record: record, expire: time.Now().Add(ttl), counter: r.counter}
+	return nil
+}
+
+// remove the registration of the peer from namespace.
+func (r *registry) remove(ns string, pid peer.ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	regs, ok := r.namespaces[ns]
+	if !ok {
+		return
+	}
+	delete(regs, pid)
+	if len(regs) == 0 {
+		delete(r.namespaces, ns)
+	}
+}
+
+// discover return at most limit registrations of namespace registered after the cookie given,
+// and the cookie for the next page.
+func (r *registry) discover(ns string, cookie []byte, limit int) ([]*registration, []byte, error) {
+	after, err := parseCookie(ns, cookie)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	regs := r.namespaces[ns]
+	removeExpired(regs)
+	if len(regs) == 0 {
+		delete(r.namespaces, ns)
+	}
+	res := make([]*registration, 0, len(regs))
+	for _, reg := range regs {
+		if reg.counter > after {
+			res = append(res, reg)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].counter < res[j].counter
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	if len(res) > 0 {
+		after = res[len(res)-1].counter
+	}
+	return res, createCookie(ns, after), nil
+}
+
+// gc remove the registrations expired of all namespaces.
+func (r *registry) gc() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for ns, regs := range r.namespaces {
+		removeExpired(regs)
+		if len(regs) == 0 {
+			delete(r.namespaces, ns)
+		}
+	}
+}
+
+func removeExpired(regs map[peer.ID]*registration) {
+	now := time.Now()
+	for pid, reg := range regs {
+		if now.After(reg.expire) {
+			delete(regs, pid)
+		}
+	}
+}
+
+// createCookie create a cookie with the counter of the last registration returned, followed by the namespace.
+func createCookie(ns string, counter uint64) []byte {
+	cookie := make([]byte, 8+len(ns))
+	binary.BigEndian.PutUint64(cookie, counter)
+	copy(cookie[8:], ns)
+	return cookie
+}
+
+func parseCookie(ns string, cookie []byte) (uint64, error) {
+	if len(cookie) == 0 {
+		return 0, nil
+	}
+	if len(cookie) < 8 || string(cookie[8:]) != ns {
+		return 0, ErrInvalidCookie
+	}
+	return binary.BigEndian.Uint64(cookie), nil
+}
@@ -0,0 +1,54 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rendezvous
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	r := newRegistry(3)
+	for i := 0; i < 3; i++ {
+		require.Nil(t, r.add("ns", peer.ID("peer"+strconv.Itoa(i)), []byte{byte(i)}, time.Hour))
+	}
+	// namespace full, but renewing is allowed
+	require.Equal(t, ErrNamespaceFull, r.add("ns", "peer3", nil, time.Hour))
+	require.Nil(t, r.add("ns", "peer0", []byte{0}, time.Hour))
+
+	// paging, peer0 renewed is the last one
+	regs, cookie, err := r.discover("ns", nil, 2)
+	require.Nil(t, err)
+	require.Len(t, regs, 2)
+	require.Equal(t, peer.ID("peer1"), regs[0].pid)
+	require.Equal(t, peer.ID("peer2"), regs[1].pid)
+	regs, cookie, err = r.discover("ns", cookie, 2)
+	require.Nil(t, err)
+	require.Len(t, regs, 1)
+	require.Equal(t, peer.ID("peer0"), regs[0].pid)
+	regs, _, err = r.discover("ns", cookie, 2)
+	require.Nil(t, err)
+	require.Len(t, regs, 0)
+	_, _, err = r.discover("other", cookie, 2)
+	require.Equal(t, ErrInvalidCookie, err)
+
+	// expired and removed
+	require.Nil(t, r.add("ns", "peer1", nil, -time.Second))
+	r.remove("ns", "peer2")
+	regs, _, err = r.discover("ns", nil, 10)
+	require.Nil(t, err)
+	require.Len(t, regs, 1)
+	require.Nil(t, r.add("ns", "peer3", nil, time.Hour))
+	r.remove("ns", "peer0")
+	r.remove("ns", "peer3")
+	r.gc()
+	require.Len(t, r.namespaces, 0)
+}
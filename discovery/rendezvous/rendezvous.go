@@ -0,0 +1,676 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rendezvous
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/discovery"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord"
+	"chainmaker.org/chainmaker/net-liquid/discovery/rendezvous/pb"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// ProtocolID is the protocol.ID for rendezvous.
+	ProtocolID protocol.ID = "/rendezvous/v0.0.1"
+	// DefaultTTL is the default ttl of registrations.
+	DefaultTTL = 2 * time.Hour
+	// DefaultMaxTTL is the default max ttl of registrations accepted by rendezvous points.
+	DefaultMaxTTL = 72 * time.Hour
+	// DefaultMaxRegistrationsPerNamespace is the default max count of registrations of each namespace
+	// stored by rendezvous points.
+	DefaultMaxRegistrationsPerNamespace = 1000
+	// DefaultDiscoverLimit is the default max count of registrations in each discovering response.
+	DefaultDiscoverLimit = 100
+	// DefaultPollInterval is the default interval of discovering from rendezvous points when finding peers.
+	DefaultPollInterval = time.Minute
+	// DefaultRequestTimeout is the default timeout of waiting for the response of rendezvous points.
+	DefaultRequestTimeout = 10 * time.Second
+
+	// maxNamespaceLength is the max length of namespace.
+	maxNamespaceLength = 255
+	// gcInterval is the interval of removing the registrations expired by rendezvous points.
+	gcInterval = time.Minute
+
+	optKeyTTL   = "ttl"
+	optKeyLimit = "limit"
+)
+
+var (
+	// ErrFinding will be returned if there is already a finding task running when calling FindPeers method.
+	ErrFinding = errors.New("there is already a finding task running, try it again later")
+	// ErrNoRendezvousPoint will be returned if no rendezvous point configured or reachable.
+	ErrNoRendezvousPoint = errors.New("no rendezvous point available")
+	// ErrInvalidRendezvousPoint will be returned if the address of rendezvous point contains no peer id.
+	ErrInvalidRendezvousPoint = errors.New("peer id not contained in the address of rendezvous point")
+	// ErrInvalidNamespace will be returned if the namespace is empty or too long.
+	ErrInvalidNamespace = errors.New("invalid namespace")
+	// ErrInvalidSignedRecord will be returned if the signed peer record registering is invalid,
+	// or it is not the record of the registrant.
+	ErrInvalidSignedRecord = errors.New("invalid signed peer record")
+	// ErrInvalidTTL will be returned if the ttl of registration is negative or greater than the max ttl.
+	ErrInvalidTTL = errors.New("invalid ttl")
+	// ErrInvalidCookie will be returned if the cookie for discovering is malformed or of another namespace.
+	ErrInvalidCookie = errors.New("invalid cookie")
+	// ErrNamespaceFull will be returned if the count of registrations of namespace reach the max value.
+	ErrNamespaceFull = errors.New("namespace full")
+	// ErrTooManyNamespaces will be returned if the count of namespaces of rendezvous point reach the max value.
+	ErrTooManyNamespaces = errors.New("too many namespaces")
+	// ErrUnavailable will be returned if the peer requested is not a rendezvous point.
+	ErrUnavailable = errors.New("rendezvous service unavailable")
+)
+
+type options struct {
+	discovery.Options
+
+	ttl   time.Duration
+	limit int
+}
+
+func (o *options) applyDiscoveryOptions(opts ...discovery.Option) error {
+	err := o.Apply(opts...)
+	if err != nil {
+		return err
+	}
+
+	v, ok := o.Opts[optKeyTTL]
+	if ok {
+		o.ttl, _ = v.(time.Duration)
+	}
+
+	v, ok = o.Opts[optKeyLimit]
+	if ok {
+		o.limit, _ = v.(int)
+	}
+
+	return nil
+}
+
+// WithTTL set a time.Duration as ttl of registration when announcing.
+func WithTTL(ttl time.Duration) discovery.Option {
+	return func(options *discovery.Options) error {
+		options.Opts[optKeyTTL] = ttl
+		return nil
+	}
+}
+
+// WithLimit set an int value as the max count of registrations in each page when finding peers.
+func WithLimit(limit int) discovery.Option {
+	return func(options *discovery.Options) error {
+		options.Opts[optKeyLimit] = limit
+		return nil
+	}
+}
+
+// Option is a function to apply properties for rendezvous discovery service.
+type Option func(*RendezvousDiscovery) error
+
+func (d *RendezvousDiscovery) applyOptions(opts ...Option) error {
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithLogger set a logger.
+func WithLogger(logger api.Logger) Option {
+	return func(d *RendezvousDiscovery) error {
+		d.logger = logger
+		return nil
+	}
+}
+
+// WithRendezvousPoints set the addresses of rendezvous points, each of them should contain the peer id.
+func WithRendezvousPoints(addrs ...ma.Multiaddr) Option {
+	return func(d *RendezvousDiscovery) error {
+		for _, addr := range addrs {
+			netAddr, pid := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+			if pid == "" {
+				return ErrInvalidRendezvousPoint
+			}
+			if netAddr != nil {
+				d.host.PeerStore().AddAddrWithTTL(pid, store.AddrSourceConfig, store.PermanentAddrTTL, netAddr)
+			}
+			d.points = append(d.points, pid)
+		}
+		return nil
+	}
+}
+
+// WithServer set whether the host acts as a rendezvous point accepting the registrations of others.
+func WithServer(enable bool) Option {
+	return func(d *RendezvousDiscovery) error {
+		d.server = enable
+		return nil
+	}
+}
+
+// WithMaxTTL set the max ttl of registrations accepted when acting as a rendezvous point.
+func WithMaxTTL(ttl time.Duration) Option {
+	return func(d *RendezvousDiscovery) error {
+		d.maxTTL = ttl
+		return nil
+	}
+}
+
+// WithMaxRegistrationsPerNamespace set the max count of registrations of each namespace stored
+// when acting as a rendezvous point.
+func WithMaxRegistrationsPerNamespace(max int) Option {
+	return func(d *RendezvousDiscovery) error {
+		d.maxPerNamespace = max
+		return nil
+	}
+}
+
+// WithPollInterval set a time.Duration as interval of discovering from rendezvous points when finding peers.
+func WithPollInterval(interval time.Duration) Option {
+	return func(d *RendezvousDiscovery) error {
+		d.pollInterval = interval
+		return nil
+	}
+}
+
+// WithRequestTimeout set a time.Duration as timeout of waiting for the response of rendezvous points.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(d *RendezvousDiscovery) error {
+		d.requestTimeout = timeout
+		return nil
+	}
+}
+
+type rendezvousWaiter struct {
+	pid       peer.ID
+	responseC chan *pb.RendezvousMsg
+}
+
+var _ discovery.Discovery = (*RendezvousDiscovery)(nil)
+
+// RendezvousDiscovery provides a discovery service based on rendezvous points.
+// Peers register themselves to the rendezvous points with signed peer records under namespaces, e.g. the
+// service names, and discover the others registered under the same namespace from them page by page.
+// Registrations are refreshed in background until unregistered, and expire on rendezvous points after ttl.
+// Every host could act as a rendezvous point if server enabled.
+type RendezvousDiscovery struct {
+	host     host.Host
+	ctx      context.Context
+	cancel   context.CancelFunc
+	points   []peer.ID
+	registry *registry
+
+	seq        uint64
+	waiters    sync.Map // map[uint64]*rendezvousWaiter
+	findingMap sync.Map // map[string]chan ma.Multiaddr, stores task of finding namespaces
+
+	announcedMu sync.Mutex
+	announced   map[string]context.CancelFunc // stores the refreshing task of namespaces registered
+
+	server          bool
+	maxTTL          time.Duration
+	maxPerNamespace int
+	pollInterval    time.Duration
+	requestTimeout  time.Duration
+
+	logger api.Logger
+}
+
+// NewRendezvousDiscovery create a new RendezvousDiscovery instance, then register rendezvous protocol to host.
+func NewRendezvousDiscovery(host host.Host, opts ...Option) (*RendezvousDiscovery, error) {
+	d := &RendezvousDiscovery{
+		host:            host,
+		points:          make([]peer.ID, 0),
+		announced:       make(map[string]context.CancelFunc),
+		maxTTL:          DefaultMaxTTL,
+		maxPerNamespace: DefaultMaxRegistrationsPerNamespace,
+		pollInterval:    DefaultPollInterval,
+		requestTimeout:  DefaultRequestTimeout,
+		logger:          logger.NilLogger,
+	}
+	if err := d.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+	if err := d.host.RegisterMsgPayloadHandler(ProtocolID, d.handleMsg); err != nil {
+		return nil, err
+	}
+	d.ctx, d.cancel = context.WithCancel(host.Context())
+	if d.server {
+		d.registry = newRegistry(d.maxPerNamespace)
+		go d.gcLoop()
+	}
+	return d, nil
+}
+
+// Close stop all the tasks of refreshing registrations and finding peers, then unregister rendezvous protocol.
+func (d *RendezvousDiscovery) Close() error {
+	d.cancel()
+	return d.host.UnregisterMsgPayloadHandler(ProtocolID)
+}
+
+func (d *RendezvousDiscovery) gcLoop() {
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.registry.gc()
+		}
+	}
+}
+
+func (d *RendezvousDiscovery) handleMsg(senderPID peer.ID, msgPayload []byte) {
+	msg := &pb.RendezvousMsg{}
+	err := proto.Unmarshal(msgPayload, msg)
+	if err != nil {
+		d.logger.Errorf("[RendezvousDiscovery] unmarshal rendezvous msg failed, %s (sender id: %s)",
+			err.Error(), senderPID)
+		return
+	}
+	switch msg.Type {
+	case pb.RendezvousMsg_RegisterRes, pb.RendezvousMsg_DiscoverRes:
+		v, ok := d.waiters.Load(msg.Seq)
+		if !ok {
+			return
+		}
+		w, _ := v.(*rendezvousWaiter)
+		if w.pid != senderPID {
+			d.logger.Warnf("[RendezvousDiscovery] response sender mismatch, (sender id: %s, expected: %s)",
+				senderPID, w.pid)
+			return
+		}
+		select {
+		case w.responseC <- msg:
+		default:
+		}
+	case pb.RendezvousMsg_Register:
+		res := &pb.RendezvousMsg{Type: pb.RendezvousMsg_RegisterRes, Seq: msg.Seq}
+		ttl, err := d.handleRegister(senderPID, msg)
+		if err != nil {
+			res.Status, res.StatusText = statusOfError(err), err.Error()
+		} else {
+			res.Ttl = int64(ttl / time.Second)
+		}
+		d.sendMsg(senderPID, res)
+	case pb.RendezvousMsg_Unregister:
+		if d.registry != nil {
+			d.registry.remove(msg.Namespace, senderPID)
+		}
+	case pb.RendezvousMsg_Discover:
+		res := &pb.RendezvousMsg{Type: pb.RendezvousMsg_DiscoverRes, Seq: msg.Seq}
+		res.Registrations, res.Cookie, err = d.handleDiscover(msg)
+		if err != nil {
+			res.Status, res.StatusText = statusOfError(err), err.Error()
+		}
+		d.sendMsg(senderPID, res)
+	default:
+		d.logger.Warnf("[RendezvousDiscovery] unknown rendezvous msg type %s (sender id: %s)",
+			msg.Type.String(), senderPID)
+	}
+}
+
+func (d *RendezvousDiscovery) handleRegister(senderPID peer.ID, msg *pb.RendezvousMsg) (time.Duration, error) {
+	if d.registry == nil {
+		return 0, ErrUnavailable
+	}
+	if !validNamespace(msg.Namespace) {
+		return 0, ErrInvalidNamespace
+	}
+	ttl := time.Duration(msg.Ttl) * time.Second
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 || ttl > d.maxTTL {
+		return 0, ErrInvalidTTL
+	}
+	// peers could only register for themselves
+	record, err := peerrecord.OpenBytes(msg.SignedRecord)
+	if err != nil || record.PeerID != senderPID {
+		return 0, ErrInvalidSignedRecord
+	}
+	if err = d.registry.add(msg.Namespace, senderPID, msg.SignedRecord, ttl); err != nil {
+		return 0, err
+	}
+	d.logger.Debugf("[RendezvousDiscovery] peer registered. (namespace: %s, pid: %s, ttl: %s)",
+		msg.Namespace, senderPID, ttl)
+	return ttl, nil
+}
+
+func (d *RendezvousDiscovery) handleDiscover(msg *pb.RendezvousMsg) ([]*pb.Registration, []byte, error) {
+	if d.registry == nil {
+		return nil, nil, ErrUnavailable
+	}
+	if !validNamespace(msg.Namespace) {
+		return nil, nil, ErrInvalidNamespace
+	}
+	limit := int(msg.Limit)
+	if limit <= 0 || limit > DefaultDiscoverLimit {
+		limit = DefaultDiscoverLimit
+	}
+	regs, cookie, err := d.registry.discover(msg.Namespace, msg.Cookie, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	res := make([]*pb.Registration, 0, len(regs))
+	for _, reg := range regs {
+		res = append(res, &pb.Registration{
+			SignedRecord: reg.record,
+			Ttl:          int64(reg.expire.Sub(now) / time.Second),
+		})
+	}
+	return res, cookie, nil
+}
+
+func (d *RendezvousDiscovery) sendMsg(receiver peer.ID, msg *pb.RendezvousMsg) {
+	msgBytes, err := proto.Marshal(msg)
+	if err != nil {
+		d.logger.Errorf("[RendezvousDiscovery] marshal rendezvous msg failed, %s", err.Error())
+		return
+	}
+	if err = d.host.SendMsg(ProtocolID, receiver, msgBytes); err != nil {
+		d.logger.Debugf("[RendezvousDiscovery] send rendezvous msg failed, %s (remote pid: %s)",
+			err.Error(), receiver)
+	}
+}
+
+// request send a request to the rendezvous point given and wait for the response.
+// If the rendezvous point is not connected, it will be dialed.
+func (d *RendezvousDiscovery) request(ctx context.Context, point peer.ID, msg *pb.RendezvousMsg) (
+	*pb.RendezvousMsg, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.requestTimeout)
+	defer cancel()
+	if !d.host.ConnMgr().IsConnected(point) {
+		if _, err := d.host.DialPeer(ctx, point); err != nil {
+			return nil, err
+		}
+	}
+	if !d.host.IsPeerSupportProtocol(point, ProtocolID) {
+		return nil, ErrUnavailable
+	}
+	msg.Seq = atomic.AddUint64(&d.seq, 1)
+	msgBytes, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	w := &rendezvousWaiter{pid: point, responseC: make(chan *pb.RendezvousMsg, 1)}
+	d.waiters.Store(msg.Seq, w)
+	defer d.waiters.Delete(msg.Seq)
+	if err = d.host.SendMsg(ProtocolID, point, msgBytes); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-w.responseC:
+		if res.Status != pb.RendezvousMsg_OK {
+			return nil, errorOfStatus(res.Status)
+		}
+		return res, nil
+	}
+}
+
+// Register register myself with a signed peer record under the namespace given to all rendezvous points.
+// Return nil if any rendezvous point accepted.
+func (d *RendezvousDiscovery) Register(ctx context.Context, ns string, ttl time.Duration) error {
+	signed, err := peerrecord.Seal(d.host.PrivateKey(), d.host.AnnounceAddrs())
+	if err != nil {
+		return err
+	}
+	record, err := proto.Marshal(signed)
+	if err != nil {
+		return err
+	}
+	err = ErrNoRendezvousPoint
+	registered := false
+	for _, point := range d.points {
+		if point == d.host.ID() {
+			continue
+		}
+		_, e := d.request(ctx, point, &pb.RendezvousMsg{
+			Type:         pb.RendezvousMsg_Register,
+			Namespace:    ns,
+			Ttl:          int64(ttl / time.Second),
+			SignedRecord: record,
+		})
+		if e != nil {
+			d.logger.Debugf("[RendezvousDiscovery] register failed, %s (namespace: %s, point: %s)", e.Error(), ns, point)
+			err = e
+			continue
+		}
+		registered = true
+	}
+	if registered {
+		return nil
+	}
+	return err
+}
+
+// Announce register myself under the namespace of service name given to all rendezvous points,
+// then refresh the registrations every half of ttl until Unregister called.
+func (d *RendezvousDiscovery) Announce(ctx context.Context, serviceName string, opts ...discovery.Option) error {
+	ns := strings.TrimSpace(serviceName)
+	if !validNamespace(ns) {
+		return ErrInvalidNamespace
+	}
+	os := &options{Options: discovery.Options{Opts: make(map[interface{}]interface{})}}
+	if err := os.applyDiscoveryOptions(opts...); err != nil {
+		return err
+	}
+	ttl := os.ttl
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if err := d.Register(ctx, ns, ttl); err != nil {
+		return err
+	}
+	refreshCtx, cancel := context.WithCancel(d.ctx)
+	d.announcedMu.Lock()
+	if oldCancel, ok := d.announced[ns]; ok {
+		oldCancel()
+	}
+	d.announced[ns] = cancel
+	d.announcedMu.Unlock()
+	go d.refreshTask(refreshCtx, ns, ttl)
+	return nil
+}
+
+func (d *RendezvousDiscovery) refreshTask(ctx context.Context, ns string, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Register(ctx, ns, ttl); err != nil {
+				d.logger.Warnf("[RendezvousDiscovery] refresh registration failed, %s (namespace: %s)",
+					err.Error(), ns)
+			}
+		}
+	}
+}
+
+// Unregister stop refreshing the registration of namespace, then tell the rendezvous points connected to remove it.
+func (d *RendezvousDiscovery) Unregister(serviceName string) {
+	ns := strings.TrimSpace(serviceName)
+	d.announcedMu.Lock()
+	if cancel, ok := d.announced[ns]; ok {
+		cancel()
+		delete(d.announced, ns)
+	}
+	d.announcedMu.Unlock()
+	for _, point := range d.points {
+		if !d.host.ConnMgr().IsConnected(point) || !d.host.IsPeerSupportProtocol(point, ProtocolID) {
+			continue
+		}
+		d.sendMsg(point, &pb.RendezvousMsg{Type: pb.RendezvousMsg_Unregister, Namespace: ns})
+	}
+}
+
+// Discover find at most limit peers registered under the namespace given after the cookie from the rendezvous
+// point given, and return the peer records verified with the cookie for the next page.
+func (d *RendezvousDiscovery) Discover(ctx context.Context, point peer.ID, ns string, limit int, cookie []byte) (
+	[]*peerrecord.PeerRecord, []byte, error) {
+	res, err := d.request(ctx, point, &pb.RendezvousMsg{
+		Type:      pb.RendezvousMsg_Discover,
+		Namespace: ns,
+		Limit:     uint32(limit),
+		Cookie:    cookie,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	records := make([]*peerrecord.PeerRecord, 0, len(res.Registrations))
+	for _, reg := range res.Registrations {
+		record, e := peerrecord.OpenBytes(reg.SignedRecord)
+		if e != nil {
+			d.logger.Warnf("[RendezvousDiscovery] invalid peer record, %s (point: %s)", e.Error(), point)
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, res.Cookie, nil
+}
+
+func (d *RendezvousDiscovery) findPeersTask(ctx context.Context, ns string, c chan ma.Multiaddr,
+	opts ...discovery.Option) {
+	defer d.findingMap.Delete(ns)
+	os := &options{Options: discovery.Options{Opts: make(map[interface{}]interface{})}}
+	if err := os.applyDiscoveryOptions(opts...); err != nil {
+		d.logger.Errorf("[RendezvousDiscovery] apply options failed, %s", err.Error())
+		return
+	}
+	limit := os.limit
+	if limit <= 0 {
+		limit = DefaultDiscoverLimit
+	}
+	cookies := make(map[peer.ID][]byte)
+	seqs := make(map[peer.ID]uint64)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.ctx.Done():
+			return
+		case <-timer.C:
+		}
+		for _, point := range d.points {
+			if point == d.host.ID() {
+				continue
+			}
+			// read all pages
+			for {
+				records, cookie, err := d.Discover(ctx, point, ns, limit, cookies[point])
+				if err != nil {
+					d.logger.Debugf("[RendezvousDiscovery] discover failed, %s (namespace: %s, point: %s)",
+						err.Error(), ns, point)
+					if err == ErrInvalidCookie {
+						delete(cookies, point)
+					}
+					break
+				}
+				cookies[point] = cookie
+				for _, record := range records {
+					if !d.pushRecord(ctx, c, seqs, record) {
+						return
+					}
+				}
+				if len(records) < limit {
+					break
+				}
+			}
+		}
+		timer.Reset(d.pollInterval)
+	}
+}
+
+// pushRecord record the addresses of peer found, then push the first one to finding chan if the record is newer
+// than the one pushed before. Return false if ctx done.
+func (d *RendezvousDiscovery) pushRecord(ctx context.Context, c chan ma.Multiaddr, seqs map[peer.ID]uint64,
+	record *peerrecord.PeerRecord) bool {
+	pid := record.PeerID
+	if pid == d.host.ID() || len(record.Addrs) == 0 || record.Seq <= seqs[pid] {
+		return true
+	}
+	seqs[pid] = record.Seq
+	d.host.PeerStore().AddAddrWithTTL(pid, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, record.Addrs...)
+	select {
+	case <-ctx.Done():
+		return false
+	case c <- util.CreateMultiAddrWithPidAndNetAddr(pid, record.Addrs[0]):
+		return true
+	}
+}
+
+// FindPeers run a loop task discovering the peers registered under the namespace of service name given from
+// rendezvous points, and addresses of peers found will be push to result chan.
+// If you want to quit finding task, the ctx should be canceled.
+func (d *RendezvousDiscovery) FindPeers(ctx context.Context, serviceName string, opts ...discovery.Option) (
+	<-chan ma.Multiaddr, error) {
+	ns := strings.TrimSpace(serviceName)
+	if !validNamespace(ns) {
+		return nil, ErrInvalidNamespace
+	}
+	if len(d.points) == 0 {
+		return nil, ErrNoRendezvousPoint
+	}
+	findingC := make(chan ma.Multiaddr)
+	_, ok := d.findingMap.LoadOrStore(ns, findingC)
+	if ok {
+		return nil, ErrFinding
+	}
+
+	go d.findPeersTask(ctx, ns, findingC, opts...)
+
+	return findingC, nil
+}
+
+func validNamespace(ns string) bool {
+	return ns != "" && len(ns) <= maxNamespaceLength
+}
+
+var statusErrors = map[pb.RendezvousMsg_Status]error{
+	pb.RendezvousMsg_InvalidNamespace:    ErrInvalidNamespace,
+	pb.RendezvousMsg_InvalidSignedRecord: ErrInvalidSignedRecord,
+	pb.RendezvousMsg_InvalidTTL:          ErrInvalidTTL,
+	pb.RendezvousMsg_InvalidCookie:       ErrInvalidCookie,
+	pb.RendezvousMsg_Unavailable:         ErrUnavailable,
+}
+
+func statusOfError(err error) pb.RendezvousMsg_Status {
+	for status, e := range statusErrors {
+		if e == err {
+			return status
+		}
+	}
+	return pb.RendezvousMsg_Unavailable
+}
+
+func errorOfStatus(status pb.RendezvousMsg_Status) error {
+	if err, ok := statusErrors[status]; ok {
+		return err
+	}
+	return ErrUnavailable
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package rendezvous_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/rendezvous"
+	"chainmaker.org/chainmaker/net-liquid/host/hosttest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRendezvousDiscovery(t *testing.T) {
+	// hosts[0] is the rendezvous point, hosts[1] and hosts[2] meet there
+	hosts := make([]host.Host, 3)
+	rds := make([]*rendezvous.RendezvousDiscovery, 3)
+	for i := range hosts {
+		hosts[i] = hosttest.NewHost(t)
+	}
+	point := hosttest.Addr(hosts[0])
+	for i := range hosts {
+		rd, err := rendezvous.NewRendezvousDiscovery(hosts[i],
+			rendezvous.WithServer(i == 0),
+			rendezvous.WithRendezvousPoints(point),
+		)
+		require.Nil(t, err)
+		rds[i] = rd
+		t.Cleanup(func() {
+			_ = rd.Close()
+		})
+	}
+
+	records, _, err := rds[2].Discover(context.Background(), hosts[0].ID(), "chain1", 10, nil)
+	require.Nil(t, err)
+	require.Len(t, records, 0)
+
+	require.Nil(t, rds[1].Announce(context.Background(), "chain1"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	findingC, err := rds[2].FindPeers(ctx, "chain1")
+	require.Nil(t, err)
+	select {
+	case addr := <-findingC:
+		netAddr, pid := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+		require.Equal(t, hosts[1].ID(), pid)
+		require.True(t, netAddr.Equal(hosts[1].LocalAddresses()[0]))
+	case <-time.After(5 * time.Second):
+		t.Fatal("peer registered not found")
+	}
+
+	// hosts[1] is not a rendezvous point
+	_, _, err = rds[2].Discover(context.Background(), hosts[1].ID(), "chain1", 10, nil)
+	require.Equal(t, rendezvous.ErrUnavailable, err)
+
+	// unregistered
+	rds[1].Unregister("chain1")
+	require.Eventually(t, func() bool {
+		records, _, e := rds[2].Discover(context.Background(), hosts[0].ID(), "chain1", 10, nil)
+		return e == nil && len(records) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
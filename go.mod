@@ -7,7 +7,7 @@ require (
 	chainmaker.org/chainmaker/net-common v1.0.1
 	chainmaker.org/chainmaker/protocol/v2 v2.1.0
 	github.com/cznic/mathutil v0.0.0-20181122101859-297441e03548
-	github.com/fsnotify/fsnotify v1.5.1 // indirect
+	github.com/fsnotify/fsnotify v1.5.1
 	github.com/gogo/protobuf v1.3.2
 	github.com/google/go-cmp v0.5.6 // indirect
 	github.com/kr/pretty v0.2.0 // indirect
@@ -20,9 +20,9 @@ require (
 	github.com/xiaotianfork/q-tls-common v0.1.3
 	github.com/xiaotianfork/quic-go v0.21.24
 	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5 // indirect
-	golang.org/x/net v0.0.0-20210503060351-7fd8e65b6420 // indirect
+	golang.org/x/net v0.0.0-20210503060351-7fd8e65b6420
 	golang.org/x/sys v0.0.0-20210823070655-63515b42dcdf
 	golang.org/x/tools v0.1.5 // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b // indirect
+	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
@@ -0,0 +1,58 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package host
+
+import (
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostAnnounceAddrs(t *testing.T) {
+	host3 := newTestHost(t, 2, nil)
+	host4 := newTestHost(t, 3, nil)
+	public := ma.StringCast("/ip4/1.2.3.4/tcp/18083")
+	host3.cfg.AppendAnnounceAddresses = []ma.Multiaddr{public}
+	var err error
+	host3.noAnnounceFilters, err = simple.NewAddrFilters("127.0.0.0/8")
+	require.Nil(t, err)
+	require.Nil(t, host3.Start())
+	require.Nil(t, host4.Start())
+	defer func() {
+		_ = host3.Stop()
+		_ = host4.Stop()
+	}()
+
+	// loopback addresses are filtered
+	require.Equal(t, []ma.Multiaddr{public}, host3.AnnounceAddrs())
+	require.False(t, host3.CanAnnounceAddr(host4.LocalAddresses()[0]))
+	require.True(t, host3.CanAnnounceAddr(public))
+
+	// only the addresses announced will be learned by others through identify
+	_, err = host3.Dial(testHostAddr(host4))
+	require.Nil(t, err)
+	require.Eventually(t, func() bool {
+		for _, info := range host4.PeerStore().AddrInfos(pidList[2]) {
+			if info.Source == store.AddrSourceIdentify {
+				return info.Addr.Equal(public)
+			}
+		}
+		return false
+	}, 5*time.Second, 50*time.Millisecond)
+	for _, info := range host4.PeerStore().AddrInfos(pidList[2]) {
+		require.False(t, info.Source == store.AddrSourceIdentify && !info.Addr.Equal(public))
+	}
+
+	// explicit announce addresses replace the listen addresses
+	addr3 := host3.LocalAddresses()[0]
+	host3.cfg.AnnounceAddresses = []ma.Multiaddr{addr3}
+	require.Equal(t, []ma.Multiaddr{addr3, public}, host3.AnnounceAddrs())
+}
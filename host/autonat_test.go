@@ -0,0 +1,66 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package host
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostAutoNAT(t *testing.T) {
+	hosts := make([]*BasicHost, 0, 3)
+	for _, idx := range []int{2, 0, 3} {
+		h := newTestHost(t, idx, nil)
+		require.Nil(t, h.Start())
+		hosts = append(hosts, h)
+	}
+	defer func() {
+		for i := range hosts {
+			_ = hosts[i].Stop()
+		}
+	}()
+	host3 := hosts[0]
+	listenAddr := host3.LocalAddresses()[0]
+	// nothing listens on the firewalled address
+	firewalled := freeTCPAddr(t)
+	host3.cfg.AppendAnnounceAddresses = []ma.Multiaddr{firewalled}
+	require.Equal(t, simple.ReachabilityUnknown, host3.Reachability())
+
+	// no peer to ask
+	require.Equal(t, simple.ErrNoAutoNATPeer, host3.autoNATService.Probe(context.Background()))
+
+	for _, h := range hosts[1:] {
+		_, err := host3.Dial(testHostAddr(h))
+		require.Nil(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return host3.IsPeerSupportProtocol(pidList[0], simple.AutoNATProtocolID) &&
+			host3.IsPeerSupportProtocol(pidList[3], simple.AutoNATProtocolID)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// the listen address is public, the firewalled one is private
+	require.Nil(t, host3.autoNATService.Probe(context.Background()))
+	require.Equal(t, simple.ReachabilityPublic, host3.Reachability())
+	require.Equal(t, simple.ReachabilityPublic, host3.autoNATService.AddrReachability(listenAddr))
+	require.Equal(t, simple.ReachabilityPrivate, host3.autoNATService.AddrReachability(firewalled))
+	require.Equal(t, []ma.Multiaddr{listenAddr}, host3.AnnounceAddrs())
+	// peers are still connected after dialing back
+	require.True(t, host3.ConnMgr().IsConnected(pidList[0]))
+	require.True(t, host3.ConnMgr().IsConnected(pidList[3]))
+
+	// private if only the firewalled address announced
+	host3.cfg.AnnounceAddresses = []ma.Multiaddr{firewalled}
+	host3.cfg.AppendAnnounceAddresses = nil
+	require.Nil(t, host3.autoNATService.Probe(context.Background()))
+	require.Equal(t, simple.ReachabilityPrivate, host3.Reachability())
+	require.Empty(t, host3.AnnounceAddrs())
+}
@@ -0,0 +1,313 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package host
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/network"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/host/quic"
+	"chainmaker.org/chainmaker/net-liquid/host/tcp"
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// DefaultDialTimeout is the default timeout of dialing to each address.
+	DefaultDialTimeout = 15 * time.Second
+	// DefaultDialStagger is the delay between the starts of dialing to two addresses of a peer.
+	DefaultDialStagger = 300 * time.Millisecond
+)
+
+// AddrDialError is the error of dialing to an address.
+type AddrDialError struct {
+	Addr ma.Multiaddr
+	Err  error
+}
+
+// Error return the string of error.
+func (e *AddrDialError) Error() string {
+	return e.Addr.String() + ": " + e.Err.Error()
+}
+
+// DialError is the aggregated error returned by DialPeer, describing each failure of addresses dialed.
+// errors.Is(err, ErrAllDialFailed) will be true for it.
+type DialError struct {
+	Peer   peer.ID
+	Errors []*AddrDialError
+}
+
+// Error return the string of error.
+func (e *DialError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(ErrAllDialFailed.Error())
+	sb.WriteString(" (remote pid: ")
+	sb.WriteString(e.Peer.ToString())
+	sb.WriteString(")")
+	for i := range e.Errors {
+		sb.WriteString("\n\t")
+		sb.WriteString(e.Errors[i].Error())
+	}
+	return sb.String()
+}
+
+// Unwrap return ErrAllDialFailed.
+func (e *DialError) Unwrap() error {
+	return ErrAllDialFailed
+}
+
+// noRelayDialKey is the key of context value, which prevents dialing to circuit addresses,
+// e.g. when dialing to a relay.
+type noRelayDialKey struct{}
+
+type dialResult struct {
+	addr ma.Multiaddr
+	conn network.Conn
+	err  error
+}
+
+// dialTimeout return the timeout of dialing to each address.
+func (bh *BasicHost) dialTimeout() time.Duration {
+	if bh.cfg.DialTimeout > 0 {
+		return bh.cfg.DialTimeout
+	}
+	return DefaultDialTimeout
+}
+
+// dialPriority return the priority of dialing to the peer.
+// The priority carried by ctx (see simple.WithDialPriority) will be used if exists,
+// otherwise direct peers and high-level peers (consensus & seeds) dial with simple.DialPriorityHigh.
+func (bh *BasicHost) dialPriority(ctx context.Context, pid peer.ID) simple.DialPriority {
+	if priority, ok := simple.DialPriorityFromContext(ctx); ok {
+		return priority
+	}
+	if bh.isDirectPeer(pid) {
+		return simple.DialPriorityHigh
+	}
+	if lcm, ok := bh.connMgr.(*simple.LevelConnManager); ok && lcm.IsHighLevel(pid) {
+		return simple.DialPriorityHigh
+	}
+	return simple.DialPriorityNormal
+}
+
+// DialPeer try to establish a connection with peer whose id is the given.
+// Dialing is managed by the DialManager of the host, concurrent dialing to the same peer will be coalesced
+// into one attempt, and simple.ErrDialBackoff will be returned if the peer is in backoff after failures.
+// Direct peers are exempt from the backoff, for ConnSupervisor keeps dialing to them with its own backoff.
+// The addresses of peer stored in PeerStore will be dialed concurrently with staggered starts (happy eyeballs),
+// the better ranked the earlier started. A failure of dialing will start the next address immediately.
+// The first connection established will be returned, and the connections established later will be closed.
+// If all failed, a *DialError will be returned.
+func (bh *BasicHost) DialPeer(ctx context.Context, pid peer.ID) (network.Conn, error) {
+	if conn := bh.connMgr.GetPeerConn(pid); conn != nil {
+		return conn, nil
+	}
+	if len(bh.peerDialAddrs(pid)) == 0 {
+		return nil, ErrPeerAddrNotFoundInPeerStore
+	}
+	if bh.isDirectPeer(pid) {
+		ctx = simple.WithoutDialBackoff(ctx)
+	}
+	return bh.dialMgr.Dial(ctx, pid, bh.dialPriority(ctx, pid), func(ctx context.Context) (network.Conn, error) {
+		return bh.dialPeer(ctx, pid)
+	})
+}
+
+func (bh *BasicHost) dialPeer(ctx context.Context, pid peer.ID) (network.Conn, error) {
+	addrs := bh.peerDialAddrs(pid)
+	if ctx.Value(noRelayDialKey{}) != nil {
+		direct := make([]ma.Multiaddr, 0, len(addrs))
+		for i := range addrs {
+			if !simple.IsCircuitAddr(addrs[i]) {
+				direct = append(direct, addrs[i])
+			}
+		}
+		addrs = direct
+	}
+	if len(addrs) == 0 {
+		return nil, ErrPeerAddrNotFoundInPeerStore
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	resC := make(chan *dialResult, len(addrs))
+	next, pending := 0, 0
+	startNext := func() {
+		addr := addrs[next]
+		next++
+		pending++
+		go func() {
+			if simple.IsCircuitAddr(addr) {
+				bh.logger.Debugf("[Host][DialPeer] try to connect to peer through relay(remote pid: %s, addr: %s)",
+					pid, addr.String())
+				conn, err := bh.dialRelayed(ctx, addr, pid)
+				resC <- &dialResult{addr: addr, conn: conn, err: err}
+				return
+			}
+			if err := bh.dialMgr.AcquireFD(ctx); err != nil {
+				resC <- &dialResult{addr: addr, err: err}
+				return
+			}
+			defer bh.dialMgr.ReleaseFD()
+			dialCtx, dialCancel := context.WithTimeout(ctx, bh.dialTimeout())
+			defer dialCancel()
+			bh.logger.Debugf("[Host][DialPeer] try to connect to peer(remote pid: %s, addr: %s)", pid, addr.String())
+			netAddr, _ := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+			conn, err := bh.dialNetAddr(dialCtx, pid, netAddr)
+			resC <- &dialResult{addr: addr, conn: conn, err: err}
+		}()
+	}
+
+	startNext()
+	timer := time.NewTimer(DefaultDialStagger)
+	defer timer.Stop()
+	dialErr := &DialError{Peer: pid}
+	for pending > 0 {
+		select {
+		case <-ctx.Done():
+			cancel()
+			go bh.drainDialResults(pid, resC, pending)
+			return nil, ctx.Err()
+		case <-timer.C:
+			if next < len(addrs) {
+				startNext()
+				timer.Reset(DefaultDialStagger)
+			}
+		case res := <-resC:
+			pending--
+			bh.peerStore.RecordDialResult(pid, res.addr, res.err == nil)
+			if res.err == nil {
+				cancel()
+				go bh.drainDialResults(pid, resC, pending)
+				return res.conn, nil
+			}
+			bh.logger.Debugf("[Host][DialPeer] connect to peer failed, %s (remote pid: %s, addr: %s)",
+				res.err.Error(), pid, res.addr.String())
+			dialErr.Errors = append(dialErr.Errors, &AddrDialError{Addr: res.addr, Err: res.err})
+			if next < len(addrs) {
+				startNext()
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(DefaultDialStagger)
+			}
+		}
+	}
+	cancel()
+	bh.logger.Warnf("[Host][DialPeer] all dial failed(remote pid: %s)", pid)
+	return nil, dialErr
+}
+
+// dialNetAddr dial to the net address of peer given.
+// A DNS address will be resolved every time before dialing, and the addresses resolved will be dialed in turn
+// until success.
+func (bh *BasicHost) dialNetAddr(ctx context.Context, pid peer.ID, netAddr ma.Multiaddr) (network.Conn, error) {
+	netAddrs, err := bh.resolver.Resolve(ctx, netAddr)
+	if err != nil {
+		return nil, err
+	}
+	for i := range netAddrs {
+		remoteAddr := netAddrs[i]
+		if pid != "" {
+			remoteAddr = util.CreateMultiAddrWithPidAndNetAddr(pid, remoteAddr)
+		}
+		var conn network.Conn
+		conn, err = bh.nw.Dial(ctx, remoteAddr)
+		if err == nil {
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// dialBack dial to the address of peer directly to check whether it is reachable for AutoNAT service.
+// The connection rejected by the conn handler (e.g. the connections to the peer reach the limit) means that
+// the address is reachable too.
+func (bh *BasicHost) dialBack(ctx context.Context, pid peer.ID, addr ma.Multiaddr) error {
+	if err := bh.dialMgr.AcquireFD(ctx); err != nil {
+		return err
+	}
+	defer bh.dialMgr.ReleaseFD()
+	ctx, cancel := context.WithTimeout(ctx, bh.dialTimeout())
+	defer cancel()
+	conn, err := bh.dialNetAddr(ctx, pid, addr)
+	if err == tcp.ErrConnRejectedByConnHandler || err == quic.ErrConnRejectedByConnHandler {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// dialRelayed dial to the target through the relay in the circuit address given.
+// The relay will be dialed first if not connected.
+func (bh *BasicHost) dialRelayed(ctx context.Context, circuitAddr ma.Multiaddr, target peer.ID) (
+	network.Conn, error) {
+	relayAddr, _, err := simple.SplitCircuitAddr(circuitAddr)
+	if err != nil {
+		return nil, err
+	}
+	relayNetAddr, relay := util.GetNetAddrAndPidFromNormalMultiAddr(relayAddr)
+	if relay == target || relay == bh.ID() {
+		return nil, simple.ErrInvalidCircuitAddr
+	}
+	ctx, cancel := context.WithTimeout(ctx, bh.dialTimeout())
+	defer cancel()
+	relayConn := bh.connMgr.GetPeerConn(relay)
+	if relayConn == nil {
+		if relayNetAddr != nil {
+			relayConn, err = bh.dialAddr(ctx, relay, relayNetAddr, relayAddr)
+		} else {
+			relayConn, err = bh.dialPeer(context.WithValue(ctx, noRelayDialKey{}, true), relay)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bh.relayService.Connect(ctx, relayConn, target)
+}
+
+// peerDialAddrs return the addresses of peer stored in PeerStore that could be dialed, ranked.
+// The circuit addresses are ranked after the direct ones.
+func (bh *BasicHost) peerDialAddrs(pid peer.ID) []ma.Multiaddr {
+	addrs := make([]ma.Multiaddr, 0)
+	circuitAddrs := make([]ma.Multiaddr, 0)
+	for _, addr := range bh.peerStore.GetAddrs(pid) {
+		if simple.IsCircuitAddr(addr) {
+			if _, target, err := simple.SplitCircuitAddr(addr); err == nil && (target == "" || target == pid) {
+				circuitAddrs = append(circuitAddrs, addr)
+			}
+			continue
+		}
+		if _, addrPID := util.GetNetAddrAndPidFromNormalMultiAddr(addr); addrPID != "" && addrPID != pid {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return append(addrs, circuitAddrs...)
+}
+
+// drainDialResults wait for the dialing not finished, then close the connections established.
+func (bh *BasicHost) drainDialResults(pid peer.ID, resC chan *dialResult, pending int) {
+	for i := 0; i < pending; i++ {
+		res := <-resC
+		bh.peerStore.RecordDialResult(pid, res.addr, res.err == nil)
+		if res.err == nil {
+			_ = res.conn.Close()
+		}
+	}
+}
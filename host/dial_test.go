@@ -0,0 +1,163 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package host
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialPriorityDirectPeers(t *testing.T) {
+	bh := newTestHost(t, 0, nil)
+	direct := util.CreateMultiAddrWithPidAndNetAddr(pidList[1], freeTCPAddr(t))
+
+	// direct peers changed while dialing, run with -race
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			bh.AddDirectPeer(direct)
+			bh.RemoveDirectPeer(pidList[1])
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = bh.dialPriority(context.Background(), pidList[1])
+		}
+	}()
+	wg.Wait()
+
+	bh.AddDirectPeer(direct)
+	require.Equal(t, simple.DialPriorityHigh, bh.dialPriority(context.Background(), pidList[1]))
+	bh.RemoveDirectPeer(pidList[1])
+	require.Equal(t, simple.DialPriorityNormal, bh.dialPriority(context.Background(), pidList[1]))
+}
+
+func TestDialDirectPeerWithoutBackoff(t *testing.T) {
+	bh := newTestHost(t, 0, nil)
+	refused := freeTCPAddr(t)
+
+	// direct peers are exempt from the backoff, for ConnSupervisor keeps them with its own backoff
+	bh.AddDirectPeer(util.CreateMultiAddrWithPidAndNetAddr(pidList[1], refused))
+	for i := 0; i < 2; i++ {
+		_, err := bh.DialPeer(context.Background(), pidList[1])
+		require.True(t, errors.Is(err, ErrAllDialFailed))
+	}
+	require.False(t, bh.dialMgr.InBackoff(pidList[1]))
+
+	// others will be in backoff after failure
+	bh.PeerStore().AddAddr(pidList[2], refused)
+	_, err := bh.DialPeer(context.Background(), pidList[2])
+	require.True(t, errors.Is(err, ErrAllDialFailed))
+	_, err = bh.DialPeer(context.Background(), pidList[2])
+	require.Equal(t, simple.ErrDialBackoff, err)
+}
+
+func TestHostNotifyPeerConnAfterStop(t *testing.T) {
+	bh := newTestHost(t, 0, nil)
+	require.Nil(t, bh.Start())
+	require.Nil(t, bh.Stop())
+
+	// a losing dial of DialPeer may establish its connection after host stopped,
+	// the conn handler should not block on notifying for the loop has exited.
+	doneC := make(chan struct{})
+	go func() {
+		bh.notifyPeerConn(nil)
+		close(doneC)
+	}()
+	select {
+	case <-doneC:
+	case <-time.After(time.Second):
+		t.Fatal("notifying peer conn blocked after host stopped")
+	}
+}
+
+func TestHostDialPeer(t *testing.T) {
+	host3 := newTestHost(t, 2, nil)
+	host4 := newTestHost(t, 3, nil)
+	require.Nil(t, host3.Start())
+	require.Nil(t, host4.Start())
+	defer func() {
+		_ = host3.Stop()
+		_ = host4.Stop()
+	}()
+	pid4, addr4 := host4.ID(), host4.LocalAddresses()[0]
+
+	// no address stored
+	_, err := host3.DialPeer(context.Background(), pid4)
+	require.Equal(t, ErrPeerAddrNotFoundInPeerStore, err)
+
+	// all addresses refused
+	host3.PeerStore().AddAddr(pid4, freeTCPAddr(t), freeTCPAddr(t))
+	_, err = host3.DialPeer(context.Background(), pid4)
+	require.True(t, errors.Is(err, ErrAllDialFailed))
+	dialErr, ok := err.(*DialError)
+	require.True(t, ok)
+	require.Len(t, dialErr.Errors, 2)
+
+	// the peer is in backoff after failure
+	_, err = host3.DialPeer(context.Background(), pid4)
+	require.Equal(t, simple.ErrDialBackoff, err)
+	host3.dialMgr.ClearBackoff(pid4)
+
+	// the address reachable wins
+	host3.PeerStore().AddAddr(pid4, addr4)
+	conn, err := host3.DialPeer(context.Background(), pid4)
+	require.Nil(t, err)
+	require.Equal(t, pid4, conn.RemotePeerID())
+	require.Equal(t, addr4, host3.PeerStore().GetFirstAddr(pid4))
+
+	// the connection established will be returned if connected
+	require.Eventually(t, func() bool {
+		return host3.ConnMgr().IsConnected(pid4)
+	}, 5*time.Second, 50*time.Millisecond)
+	conn2, err := host3.DialPeer(context.Background(), pid4)
+	require.Nil(t, err)
+	require.Equal(t, conn, conn2)
+}
+
+type localResolver struct{}
+
+func (localResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	if host == "node4.test" {
+		return []net.IPAddr{{IP: net.ParseIP("127.0.0.1")}}, nil
+	}
+	return nil, errors.New("no such host")
+}
+
+func (localResolver) LookupTXT(context.Context, string) ([]string, error) {
+	return nil, errors.New("no such host")
+}
+
+func TestHostDialDNSAddr(t *testing.T) {
+	host3 := newTestHost(t, 2, nil)
+	host4 := newTestHost(t, 3, nil)
+	host3.resolver = simple.NewMultiaddrResolver(localResolver{})
+	require.Nil(t, host3.Start())
+	require.Nil(t, host4.Start())
+	defer func() {
+		_ = host3.Stop()
+		_ = host4.Stop()
+	}()
+
+	port, err := host4.LocalAddresses()[0].ValueForProtocol(ma.P_TCP)
+	require.Nil(t, err)
+	conn, err := host3.Dial(ma.StringCast("/dns4/node4.test/tcp/" + port + "/p2p/" + pidList[3].ToString()))
+	require.Nil(t, err)
+	require.Equal(t, pidList[3], conn.RemotePeerID())
+}
@@ -0,0 +1,89 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package host
+
+import (
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/mgr"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostDirectPeerStatus(t *testing.T) {
+	// host4 listens on an address known before started
+	addr4 := freeTCPAddr(t)
+	host3 := newTestHost(t, 2, nil)
+	host4 := newTestHost(t, 3, func(cfg *HostConfig) {
+		cfg.ListenAddresses = []ma.Multiaddr{addr4}
+	})
+	require.Nil(t, host3.Start())
+	defer func() {
+		_ = host3.Stop()
+	}()
+
+	// a direct peer with two addresses, one of them refused
+	host3.AddDirectPeer(util.CreateMultiAddrWithPidAndNetAddr(pidList[3], freeTCPAddr(t)))
+	host3.AddDirectPeer(util.CreateMultiAddrWithPidAndNetAddr(pidList[3], addr4))
+	status := host3.DirectPeerStatus()
+	require.Len(t, status, 1)
+	require.Equal(t, pidList[3], status[0].PeerID)
+	require.Len(t, status[0].Addrs, 2)
+
+	// dialing failed before host4 started
+	require.Eventually(t, func() bool {
+		s := host3.DirectPeerStatus()[0]
+		return s.Attempts > 0 && s.LastError != nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// connected after host4 started
+	require.Nil(t, host4.Start())
+	require.Eventually(t, func() bool {
+		s := host3.DirectPeerStatus()[0]
+		return s.State == mgr.DirectPeerStateConnected && s.Attempts == 0
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// redial immediately after host4 stopped
+	require.Nil(t, host4.Stop())
+	require.Eventually(t, func() bool {
+		s := host3.DirectPeerStatus()[0]
+		return s.State != mgr.DirectPeerStateConnected && s.LastError != nil
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestHostRemoveDirectPeer(t *testing.T) {
+	host3 := newTestHost(t, 2, nil)
+	host4 := newTestHost(t, 3, nil)
+	require.Nil(t, host3.Start())
+	require.Nil(t, host4.Start())
+	defer func() {
+		_ = host3.Stop()
+		_ = host4.Stop()
+	}()
+	addr4 := host4.LocalAddresses()[0]
+	host3.AddDirectPeer(util.CreateMultiAddrWithPidAndNetAddr(pidList[3], addr4))
+	require.Eventually(t, func() bool {
+		return host3.ConnMgr().IsConnected(pidList[3])
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// the addresses configured expire after a while even if the peer is connected
+	host3.RemoveDirectPeer(pidList[3])
+	infos := host3.PeerStore().AddrInfos(pidList[3])
+	found := false
+	for _, info := range infos {
+		if info.Addr.Equal(addr4) {
+			found = true
+			require.False(t, info.Expires.IsZero())
+			require.InDelta(t, store.RecentlyConnectedAddrTTL, time.Until(info.Expires), float64(time.Second))
+		}
+	}
+	require.True(t, found)
+	require.Empty(t, host3.DirectPeerStatus())
+}
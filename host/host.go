@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"chainmaker.org/chainmaker/common/v2/crypto"
 	cmTls "chainmaker.org/chainmaker/common/v2/crypto/tls"
@@ -30,6 +31,13 @@ import (
 	ma "github.com/multiformats/go-multiaddr"
 )
 
+const (
+	// DefaultRedialRecentPeerCount is the default max count of peers recently seen that will be redialed at startup.
+	DefaultRedialRecentPeerCount = 10
+	// DefaultAddrGCInterval is the default interval of removing the expired addresses from PeerStore.
+	DefaultAddrGCInterval = time.Minute
+)
+
 var (
 	// ErrProtocolIDNotSupportedByPeer will be returned if protocol not supported by remote peer
 	// when calling SendMsg method.
@@ -71,12 +79,89 @@ type HostConfig struct {
 	MaxConnCountEachPeerAllowed int
 	// ConnEliminationStrategy is the strategy for connection manager eliminating connections.
 	ConnEliminationStrategy int
+	// ConnMgrLowWater is the count of peers that connection manager will trim down to.
+	ConnMgrLowWater int
+	// ConnMgrHighWater is the count of peers beyond which connection manager will start trimming.
+	// If it is not greater than 0, connections will be eliminated with ConnEliminationStrategy when adding.
+	ConnMgrHighWater int
+	// ConnMgrGracePeriod is the duration that a new peer will not be trimmed.
+	ConnMgrGracePeriod time.Duration
+	// PingInterval is the interval of the background prober pinging all peers connected.
+	// If it is 0, simple.DefaultPingInterval will be used. If it is negative, the prober will not run.
+	PingInterval time.Duration
+	// AutoNATInterval is the interval of probing the reachability by asking peers to dial back.
+	// If it is 0, simple.DefaultAutoNATInterval will be used. If it is negative, the prober will not run.
+	AutoNATInterval time.Duration
+	// EnableRelayService decides whether the host acts as a relay for others, limited by RelayLimits.
+	EnableRelayService bool
+	// RelayLimits is the resource limits of the host acting as a relay.
+	// The fields not set will be set to the defaults of simple.RelayLimits.
+	RelayLimits simple.RelayLimits
+	// StaticRelays is the list of addresses of relays that the host keeps reservations with,
+	// e.g. "/ip4/1.2.3.4/tcp/8080/p2p/QmRelay", so that others could connect to it through them.
+	StaticRelays []ma.Multiaddr
+	// EnableOverlayRouting decides whether the msgs to the peers not connected could be forwarded
+	// through the peers connected, see BasicHost.SendMsgRouted.
+	EnableOverlayRouting bool
+	// OverlayMaxHops is the max count of hops that a msg could be forwarded with overlay routing.
+	// If it is not greater than 0, simple.DefaultOverlayMaxHops will be used.
+	OverlayMaxHops int
+	// OverlayRouteInterval is the interval of advertising routes to neighbours with overlay routing.
+	// If it is not greater than 0, simple.DefaultOverlayRouteInterval will be used.
+	OverlayRouteInterval time.Duration
+	// AgentVersion is the agent version string sent to others by identify service.
+	// If it is empty, simple.DefaultAgentVersion will be used.
+	AgentVersion string
+	// PeerStorePath is the path of the local file that PeerStore persisted to.
+	// If it is empty, an in-memory PeerStore will be used.
+	PeerStorePath string
+	// PeerStoreRetention is the duration that a peer record persisted will be kept since last seen.
+	// If it is not greater than 0, simple.DefaultPeerStoreRetention will be used.
+	PeerStoreRetention time.Duration
+	// RedialRecentPeerCount is the max count of peers recently seen that will be redialed at startup.
+	// It works only if PeerStorePath set. If it is 0, DefaultRedialRecentPeerCount will be used.
+	// If it is negative, no peer will be redialed.
+	RedialRecentPeerCount int
+	// DialTimeout is the timeout of dialing to each address.
+	// If it is not greater than 0, DefaultDialTimeout will be used.
+	DialTimeout time.Duration
+	// MaxConcurrentDials is the max count of peers dialing at the same time.
+	// If it is not greater than 0, simple.DefaultMaxConcurrentDials will be used.
+	MaxConcurrentDials int
+	// MaxDialFDs is the max count of file descriptors used by dialing at the same time.
+	// If it is not greater than 0, simple.DefaultMaxDialFDs will be used.
+	MaxDialFDs int
+	// Resolver is the DNS resolver used to resolve /dns, /dns4 and /dns6 addresses when dialing.
+	// If it is nil, net.DefaultResolver will be used.
+	Resolver simple.Resolver
 	// ListenAddresses is the local addresses for listeners listening.
 	ListenAddresses []ma.Multiaddr
+	// AnnounceAddresses is the list of addresses advertised to others instead of the listen addresses,
+	// e.g. the public address mapped by NAT. If it is empty, the listen addresses will be advertised.
+	AnnounceAddresses []ma.Multiaddr
+	// AppendAnnounceAddresses is the list of addresses advertised to others in addition.
+	AppendAnnounceAddresses []ma.Multiaddr
+	// NoAnnounceCIDRs is the list of CIDRs, the addresses in them will never be advertised to others,
+	// e.g. "127.0.0.0/8","172.17.0.0/16".
+	// The addresses configured in AnnounceAddresses and AppendAnnounceAddresses will not be filtered.
+	NoAnnounceCIDRs []string
 	// DirectPeers stores the peer.ID and its remote address of peers need keeping connected.
 	// ConnSupervisor will check the connection stat of these peers.
 	// If anyone disconnected to us, supervisor will try to dial to it automatically.
 	DirectPeers map[peer.ID]ma.Multiaddr
+	// DirectPeerAddrs stores the additional addresses of direct peers, besides the one in DirectPeers.
+	// ConnSupervisor will dial to all addresses of a direct peer.
+	DirectPeerAddrs map[peer.ID][]ma.Multiaddr
+	// SupervisorTryTimes is the max count of dialing failed before ConnSupervisor giving up a direct peer.
+	// If it is 0, simple.DefaultTryTimes will be used. If it is negative, ConnSupervisor will never give up.
+	SupervisorTryTimes int
+	// SupervisorBackoffBase is the interval after the first failure of dialing to a direct peer,
+	// it will be doubled after each failure until SupervisorBackoffMax.
+	// If it is not greater than 0, simple.DefaultSupervisorBackoffBase will be used.
+	SupervisorBackoffBase time.Duration
+	// SupervisorBackoffMax is the max interval between two dialing to a direct peer.
+	// If it is not greater than 0, simple.DefaultSupervisorBackoffMax will be used.
+	SupervisorBackoffMax time.Duration
 	// BlackNetAddr is the list of net addresses that will be appended into blacklist.
 	// e.g. "127.0.0.1","127.0.0.1:8080","[::1]","[::1]:8080"
 	BlackNetAddr []string
@@ -98,6 +183,14 @@ func (c *HostConfig) AddDirectPeer(addr string) error {
 	if c.DirectPeers == nil {
 		c.DirectPeers = make(map[peer.ID]ma.Multiaddr)
 	}
+	if exist, ok := c.DirectPeers[pid]; ok && !exist.Equal(mA) {
+		// more than one address of the same direct peer
+		if c.DirectPeerAddrs == nil {
+			c.DirectPeerAddrs = make(map[peer.ID][]ma.Multiaddr)
+		}
+		c.DirectPeerAddrs[pid] = append(c.DirectPeerAddrs[pid], mA)
+		return nil
+	}
 	c.DirectPeers[pid] = mA
 	return nil
 }
@@ -148,11 +241,13 @@ func (c *HostConfig) NewHost(networkType NetworkType, ctx context.Context, logge
 	}
 	h.nw = nw
 	// set up PeerStore
-	h.peerStore = simple.NewSimplePeerStore(h.ID())
-	// set up ConnSupervisor
-	h.supervisor = simple.NewConnSupervisor(h, h.logger)
-	for id, addr := range c.DirectPeers {
-		h.supervisor.SetPeerAddr(id, addr)
+	if c.PeerStorePath != "" {
+		h.peerStore, err = simple.NewPersistentPeerStore(h.ID(), c.PeerStorePath, c.PeerStoreRetention, h.logger)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		h.peerStore = simple.NewSimplePeerStore(h.ID())
 	}
 
 	h.notifiee = sync.Map{}
@@ -162,6 +257,33 @@ func (c *HostConfig) NewHost(networkType NetworkType, ctx context.Context, logge
 	h.connMgr.(*simple.LevelConnManager).SetMaxPeerCountAllowed(h.cfg.MaxPeerCountAllowed)
 	h.connMgr.(*simple.LevelConnManager).SetMaxConnCountEachPeerAllowed(h.cfg.MaxConnCountEachPeerAllowed)
 	h.connMgr.(*simple.LevelConnManager).SetStrategy(simple.EliminationStrategyFromInt(h.cfg.ConnEliminationStrategy))
+	h.connMgr.(*simple.LevelConnManager).SetWatermarks(h.cfg.ConnMgrLowWater, h.cfg.ConnMgrHighWater)
+	if h.cfg.ConnMgrGracePeriod > 0 {
+		h.connMgr.(*simple.LevelConnManager).SetGracePeriod(h.cfg.ConnMgrGracePeriod)
+	}
+	h.peerScorer = h.connMgr.(*simple.LevelConnManager).Scorer()
+	// set up DialManager
+	h.dialMgr = simple.NewDialManager(h.cfg.MaxConcurrentDials, h.cfg.MaxDialFDs, h.logger)
+	h.resolver = simple.NewMultiaddrResolver(h.cfg.Resolver)
+	// set up filters of addresses advertised
+	h.noAnnounceFilters, err = simple.NewAddrFilters(h.cfg.NoAnnounceCIDRs...)
+	if err != nil {
+		return nil, err
+	}
+	// set up ConnSupervisor
+	h.supervisor = simple.NewConnSupervisor(h, h.logger,
+		simple.WithSupervisorTryTimes(h.cfg.SupervisorTryTimes),
+		simple.WithSupervisorBackoff(h.cfg.SupervisorBackoffBase, h.cfg.SupervisorBackoffMax))
+	for id, addr := range c.DirectPeers {
+		h.supervisor.SetPeerAddr(id, addr)
+		h.addConfigAddr(id, addr)
+	}
+	for id, addrs := range c.DirectPeerAddrs {
+		h.supervisor.AddPeerAddr(id, addrs...)
+		for i := range addrs {
+			h.addConfigAddr(id, addrs[i])
+		}
+	}
 	// set up SendStreamPoolMgr
 	h.peerSendStreamPoolMgr = simple.NewSendStreamPoolManager(h.connMgr, h.logger)
 	// set up ProtocolMgr
@@ -173,6 +295,54 @@ func (c *HostConfig) NewHost(networkType NetworkType, ctx context.Context, logge
 	if err = h.RegisterMsgPayloadHandler(h.protocolExchanger.ProtocolID(), h.protocolExchanger.Handle()); err != nil {
 		return nil, err
 	}
+	// set up PingService
+	pingInterval := h.cfg.PingInterval
+	if pingInterval == 0 {
+		pingInterval = simple.DefaultPingInterval
+	}
+	h.pingService = simple.NewPingService(h, pingInterval, h.logger)
+	h.pingService.SetSender(h.sendMsg)
+	h.pingService.OnLatency(func(pid peer.ID, conn network.Conn, rtt time.Duration) {
+		h.peerScorer.RecordLatency(pid, h.peerStore.LatencyEWMA(pid))
+		if conn != nil {
+			h.peerSendStreamPoolMgr.RecordConnLatency(pid, conn, rtt)
+		}
+	})
+	if err = h.RegisterMsgPayloadHandler(h.pingService.ProtocolID(), h.pingService.Handle()); err != nil {
+		return nil, err
+	}
+	// set up IdentifyService
+	h.identifyService = simple.NewIdentifyService(h, h.cfg.AgentVersion, h.logger)
+	if err = h.RegisterMsgPayloadHandler(h.identifyService.ProtocolID(), h.identifyService.Handle()); err != nil {
+		return nil, err
+	}
+	// set up AutoNATService
+	autoNATInterval := h.cfg.AutoNATInterval
+	if autoNATInterval == 0 {
+		autoNATInterval = simple.DefaultAutoNATInterval
+	}
+	h.autoNATService = simple.NewAutoNATService(h, h.announceCandidates, h.dialBack, autoNATInterval, h.logger)
+	if err = h.RegisterMsgPayloadHandler(h.autoNATService.ProtocolID(), h.autoNATService.Handle()); err != nil {
+		return nil, err
+	}
+	// set up RelayService
+	h.relayService = simple.NewRelayService(h, h.cfg.EnableRelayService, h.cfg.RelayLimits, h.logger)
+	for _, addr := range h.cfg.StaticRelays {
+		_, relay := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+		if relay == "" {
+			return nil, errors.New("peer id of static relay expected")
+		}
+		h.addConfigAddr(relay, addr)
+		h.relayService.AddRelay(relay)
+	}
+	// set up OverlayRouter
+	if h.cfg.EnableOverlayRouting {
+		h.overlayRouter = simple.NewOverlayRouter(h, h.deliverRoutedMsg, h.cfg.OverlayMaxHops,
+			h.cfg.OverlayRouteInterval, h.logger)
+		if err = h.RegisterMsgPayloadHandler(h.overlayRouter.ProtocolID(), h.overlayRouter.Handle()); err != nil {
+			return nil, err
+		}
+	}
 	// set up ReceiveStreamMgr
 	h.peerReceiveStreamMgr = simple.NewReceiveStreamManager(h.cfg.PeerReceiveStreamMaxCount)
 	// set up Blacklist
@@ -206,10 +376,21 @@ type BasicHost struct {
 	peerStore store.PeerStore
 	notifiee  sync.Map // map[host.Notifiee]struct{}
 
+	directPeersMu sync.RWMutex // guards cfg.DirectPeers and cfg.DirectPeerAddrs after host created
+
 	connMgr               mgr.ConnMgr
+	peerScorer            *simple.PeerScorer
 	supervisor            mgr.ConnSupervisor
+	dialMgr               *simple.DialManager
+	resolver              *simple.MultiaddrResolver
+	noAnnounceFilters     *simple.AddrFilters
 	protocolMgr           mgr.ProtocolManager
 	protocolExchanger     mgr.ProtocolExchanger
+	pingService           *simple.PingService
+	identifyService       *simple.IdentifyService
+	autoNATService        *simple.AutoNATService
+	relayService          *simple.RelayService
+	overlayRouter         *simple.OverlayRouter
 	peerSendStreamPoolMgr mgr.SendStreamPoolManager
 	peerReceiveStreamMgr  mgr.ReceiveStreamManager
 
@@ -233,11 +414,45 @@ func (bh *BasicHost) Start() error {
 		if err != nil {
 			return
 		}
+		// start connection manager trimming loop
+		err = bh.connMgr.(*simple.LevelConnManager).Start()
+		if err != nil {
+			return
+		}
 		//start connection supervisor
 		err = bh.supervisor.Start()
 		if err != nil {
 			return
 		}
+		// start ping prober
+		err = bh.pingService.Start()
+		if err != nil {
+			return
+		}
+		// start identify pushing
+		err = bh.identifyService.Start()
+		if err != nil {
+			return
+		}
+		// start reachability prober
+		err = bh.autoNATService.Start()
+		if err != nil {
+			return
+		}
+		// start reservation refresher of relays
+		err = bh.relayService.Start()
+		if err != nil {
+			return
+		}
+		// start routes advertiser
+		if bh.overlayRouter != nil {
+			err = bh.overlayRouter.Start()
+			if err != nil {
+				return
+			}
+		}
+		// redial peers recently seen
+		go bh.redialRecentPeers()
 		bh.logger.Infof("[Host] host started.")
 	})
 	return err
@@ -250,6 +465,23 @@ func (bh *BasicHost) Stop() error {
 		bh.once = sync.Once{}
 	}()
 	close(bh.closedChan)
+	if bh.overlayRouter != nil {
+		if err := bh.overlayRouter.Stop(); err != nil {
+			return err
+		}
+	}
+	if err := bh.relayService.Stop(); err != nil {
+		return err
+	}
+	if err := bh.autoNATService.Stop(); err != nil {
+		return err
+	}
+	if err := bh.identifyService.Stop(); err != nil {
+		return err
+	}
+	if err := bh.pingService.Stop(); err != nil {
+		return err
+	}
 	if err := bh.supervisor.Stop(); err != nil {
 		return err
 	}
@@ -259,6 +491,12 @@ func (bh *BasicHost) Stop() error {
 	if err := bh.nw.Close(); err != nil {
 		return err
 	}
+	if ps, ok := bh.peerStore.(*simple.PersistentPeerStore); ok {
+		// compact the log file and close it
+		if err := ps.Close(); err != nil {
+			return err
+		}
+	}
 	bh.logger.Infof("[Host] host stopped.")
 	return nil
 }
@@ -352,6 +590,15 @@ Loop:
 	}
 }
 
+// notifyPeerConn push the conn to the notifying loop, give up if the host closed,
+// otherwise the conn handler called while stopping will block the network closing.
+func (bh *BasicHost) notifyPeerConn(conn network.Conn) {
+	select {
+	case <-bh.closedChan:
+	case bh.notifyPeerConnChan <- conn:
+	}
+}
+
 func (bh *BasicHost) pushProtocolSignalLoop() {
 Loop:
 	for {
@@ -371,37 +618,58 @@ func (bh *BasicHost) sendPushProtocolSignal() {
 	}
 }
 
+func (bh *BasicHost) addrGCLoop() {
+	ticker := time.NewTicker(DefaultAddrGCInterval)
+	defer ticker.Stop()
+Loop:
+	for {
+		select {
+		case <-bh.closedChan:
+			break Loop
+		case <-ticker.C:
+			bh.peerStore.GCAddrs()
+		}
+	}
+}
+
 func (bh *BasicHost) runLoop() {
 	go bh.loop()
 	go bh.pushProtocolSignalLoop()
+	go bh.addrGCLoop()
 }
 
 // SendMsg will send a msg with the protocol which id is the given protocolID to
 // the receiver whose peer.ID is the given receiverPID.
 func (bh *BasicHost) SendMsg(protocolID protocol.ID, receiverPID peer.ID, msgPayload []byte) error {
+	_, err := bh.sendMsg(protocolID, receiverPID, msgPayload)
+	return err
+}
+
+// sendMsg send a msg like SendMsg, and return the connection the msg sent with.
+func (bh *BasicHost) sendMsg(protocolID protocol.ID, receiverPID peer.ID, msgPayload []byte) (network.Conn, error) {
 	// whether protocol supported
 	if !bh.protocolMgr.IsPeerSupported(receiverPID, protocolID) {
-		return ErrProtocolIDNotSupportedByPeer
+		return nil, ErrProtocolIDNotSupportedByPeer
 	}
 	// whether receiver connected to us
 	if !bh.connMgr.IsConnected(receiverPID) {
-		return ErrPeerNotConnected
+		return nil, ErrPeerNotConnected
 	}
 	// get send stream pool of receiver
 	streamPool := bh.peerSendStreamPoolMgr.GetPeerBestConnSendStreamPool(receiverPID)
 	if streamPool == nil {
-		return ErrStreamPoolNotFound
+		return nil, ErrStreamPoolNotFound
 	}
 	// borrow a send stream
 	stream, err := streamPool.(mgr.SendStreamPool).BorrowStream()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// create net message package
 	pkg := protocol.NewPackage(protocolID, msgPayload)
 	pkgData, err := pkg.ToBytes(bh.cfg.MsgCompress)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// write data length to stream
 	pkgDataLen := len(pkgData)
@@ -417,27 +685,55 @@ func (bh *BasicHost) SendMsg(protocolID protocol.ID, receiverPID peer.ID, msgPay
 		// err found
 		// whether network has shutdown
 		if bh.nw.Closed() {
-			return nil
+			return nil, nil
 		}
 		// whether connection created the stream has closed
 		if bh.CheckClosedConnWithErr(stream.Conn(), err) {
-			return ErrConnClosed
+			return nil, ErrConnClosed
 		}
 		// drop stream
 		streamPool.(mgr.SendStreamPool).DropStream(stream)
-		return err
+		return nil, err
 	}
 	// whether write data completely
 	if n < pkgDataLen+8 {
 		streamPool.(mgr.SendStreamPool).DropStream(stream)
-		return ErrSendMsgIncompletely
+		return nil, ErrSendMsgIncompletely
 	}
+	bh.peerScorer.RecordTraffic(receiverPID, n)
 	// send success, return the stream
 	err = streamPool.(mgr.SendStreamPool).ReturnStream(stream)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return stream.Conn(), nil
+}
+
+// SendMsgRouted send a msg to the peer directly if connected to us,
+// otherwise forward it through the peers connected with overlay routing, signed by us.
+// If overlay routing is not enabled, ErrPeerNotConnected will be returned for the peer not connected.
+func (bh *BasicHost) SendMsgRouted(protocolID protocol.ID, receiverPID peer.ID, msgPayload []byte) error {
+	if bh.connMgr.IsConnected(receiverPID) || bh.overlayRouter == nil {
+		return bh.SendMsg(protocolID, receiverPID, msgPayload)
+	}
+	return bh.overlayRouter.Send(protocolID, receiverPID, msgPayload)
+}
+
+// deliverRoutedMsg call the msg payload handler of protocol with the msg forwarded to us.
+// The msg from a peer in blacklist will be dropped, for it may be forwarded by other peers.
+func (bh *BasicHost) deliverRoutedMsg(srcPID peer.ID, protocolID protocol.ID, msgPayload []byte) {
+	if bh.blacklist.IsBlackPeer(srcPID) {
+		bh.logger.Infof("[Host] src peer in blacklist, drop the msg routed. (src pid:%s, protocol id:%s)",
+			srcPID, protocolID)
+		return
+	}
+	payloadHandler := bh.protocolMgr.GetHandler(protocolID)
+	if payloadHandler == nil {
+		bh.logger.Warnf("[Host] msg payload handler not found(protocol id:%s), "+
+			"drop the msg routed(src pid:%s)", protocolID, srcPID)
+		return
+	}
+	payloadHandler(srcPID, msgPayload)
 }
 
 func (bh *BasicHost) receiveStreamHandler(stream network.ReceiveStream) {
@@ -459,9 +755,11 @@ Loop:
 			err = e
 			break Loop
 		}
+		bh.peerScorer.RecordTraffic(rPID, len(dataBytes)+8)
 		pkg := &protocol.Package{}
 		e = pkg.FromBytes(dataBytes)
 		if e != nil {
+			bh.peerScorer.RecordMisbehaviour(rPID, 1)
 			err = e
 			break Loop
 		}
@@ -534,6 +832,21 @@ LOOP:
 	}
 }
 
+// acceptBidirectionalStreamLoop accept the bidirectional streams opened by others after protocols exchanged.
+// They are used by relay service only.
+func (bh *BasicHost) acceptBidirectionalStreamLoop(conn network.Conn) {
+	for {
+		stream, err := conn.AcceptBidirectionalStream()
+		if err != nil {
+			if !conn.IsClosed() && util.IsNetErrorTemporary(err) {
+				continue
+			}
+			return
+		}
+		go bh.relayService.HandleStream(stream)
+	}
+}
+
 func (bh *BasicHost) handleNewConn(conn network.Conn) (bool, error) {
 	rPID := conn.RemotePeerID()
 	if bh.blacklist.IsBlack(conn) {
@@ -630,18 +943,32 @@ func (bh *BasicHost) handleNewConn(conn network.Conn) (bool, error) {
 		bh.protocolMgr.SetPeerSupportedProtocols(rPID, rProtocols)
 	}
 
+	// add peer addr
+	addrSource := store.AddrSourceUnknown
+	if conn.Direction() == network.Inbound {
+		addrSource = store.AddrSourceInbound
+	}
+	bh.peerStore.AddAddrWithTTL(rPID, addrSource, store.ConnectedAddrTTL, conn.RemoteAddr())
+	bh.dialMgr.ClearBackoff(rPID)
+
 	// start accept receive stream loop
 	go bh.acceptReceiveStreamLoop(conn)
-
-	// add peer addr
-	bh.peerStore.AddAddr(rPID, conn.RemoteAddr())
+	// start accept bidirectional stream loop
+	go bh.acceptBidirectionalStreamLoop(conn)
 
 	bh.logger.Infof("[Host] new connection established(remote pid: %s, addr: %s, direction:%d)",
 		rPID, conn.RemoteAddr().String(), conn.Direction())
 	if exchangeProtocol {
 		bh.logger.Infof("[Host] peer connected(remote pid: %s, addr: %s)",
 			rPID, conn.RemoteAddr().String())
-		bh.notifyPeerConnChan <- conn
+		bh.notifyPeerConn(conn)
+		bh.markPeerSeen(rPID)
+		// send identify information to remote peer
+		go func() {
+			if e := bh.identifyService.Identify(conn); e != nil {
+				bh.logger.Debugf("[Host] send identify failed, %s (remote pid: %s)", e.Error(), rPID)
+			}
+		}()
 	}
 
 	return true, nil
@@ -661,13 +988,23 @@ func (bh *BasicHost) handleClosingConn(conn network.Conn) {
 		bh.logger.Infof("[Host] peer disconnected(remote pid: %s, addr: %s)",
 			rPID, conn.RemoteAddr().String())
 		// notify disconnected
-		bh.notifyPeerConnChan <- conn
+		bh.notifyPeerConn(conn)
 		// clean protocols records of remote peer
 		bh.protocolMgr.CleanPeerSupportedProtocols(rPID)
+		// clean metrics and identify records of remote peer
+		bh.peerStore.RemovePeerMetrics(rPID)
+		bh.peerStore.RemovePeerIdentify(rPID)
+		// the address observed by remote peer expired
+		bh.identifyService.PeerDisconnected(rPID)
+		bh.markPeerSeen(rPID)
+		// addresses of remote peer will expire after a while
+		bh.peerStore.SetAddrTTL(rPID, store.RecentlyConnectedAddrTTL)
 	}
 
-	// remove remote address of this connection
-	bh.peerStore.RemoveAddr(rPID, conn.RemoteAddr())
+	if conn.Direction() == network.Inbound {
+		// remove remote address of this inbound connection, for its remote port is ephemeral
+		bh.peerStore.RemoveAddr(rPID, conn.RemoteAddr())
+	}
 	// clean all send streams of this connection
 	err := bh.peerSendStreamPoolMgr.RemovePeerConnAndCloseSendStreamPool(rPID, conn)
 	if err != nil {
@@ -720,49 +1057,59 @@ func (bh *BasicHost) ID() peer.ID {
 }
 
 // Dial try to establish a connection with peer whose address is the given.
+// If the address contains a peer.ID, dialing will be managed by the DialManager of the host,
+// concurrent dialing to the same peer will be coalesced, and ErrDialBackoff may be returned.
+// A circuit address like "/ip4/1.2.3.4/tcp/8080/p2p/QmRelay/p2p-circuit/p2p/QmTarget" will be dialed
+// through the relay.
 func (bh *BasicHost) Dial(remoteAddr ma.Multiaddr) (network.Conn, error) {
+	if simple.IsCircuitAddr(remoteAddr) {
+		// dial through relay
+		_, target, err := simple.SplitCircuitAddr(remoteAddr)
+		if err != nil {
+			return nil, err
+		}
+		if target == "" {
+			return nil, simple.ErrInvalidCircuitAddr
+		}
+		return bh.dialMgr.Dial(bh.ctx, target, bh.dialPriority(bh.ctx, target),
+			func(ctx context.Context) (network.Conn, error) {
+				return bh.dialRelayed(ctx, remoteAddr, target)
+			})
+	}
 	// resolve remote net address and remote peer.ID
 	rAddr, remotePID := util.GetNetAddrAndPidFromNormalMultiAddr(remoteAddr)
 	if rAddr == nil && remotePID == "" {
 		return nil, errors.New("wrong addr")
 	}
 	if rAddr == nil {
-		// if remote net address is nil, try to query any from PeerStore.
-		remoteAddresses := bh.peerStore.GetAddrs(remotePID)
-		if len(remoteAddresses) == 0 {
-			// no address queried, return err
-			return nil, ErrPeerAddrNotFoundInPeerStore
-		}
-		// try to dial to each of addresses found
-		for i := range remoteAddresses {
-			addr := remoteAddresses[i]
-			tmpAddr, tmpPID := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
-			if tmpPID == "" {
-				remoteAddr = util.CreateMultiAddrWithPidAndNetAddr(remotePID, tmpAddr)
-			} else {
-				if tmpPID != remotePID {
-					continue
-				}
-				remoteAddr = addr
-			}
-			bh.logger.Infof("[Host][Dial] try to connect to peer(remote pid: %s, addr: %s)",
-				remotePID, remoteAddr.String())
-			conn, err := bh.nw.Dial(context.Background(), remoteAddr)
-			if err != nil {
-				bh.logger.Warnf("[Host][Dial] connect to peer failed, %s (remote pid: %s, addr: %s)",
-					err.Error(), remotePID, addr.String())
-				continue
-			}
-			// if dial success, return
-			return conn, nil
-		}
-		bh.logger.Errorf("[Host][Dial] all dial failed(remote pid: %s)", remotePID)
-		return nil, ErrAllDialFailed
+		// if remote net address is nil, try to dial to any address stored in PeerStore.
+		return bh.DialPeer(bh.ctx, remotePID)
 	}
+	if remotePID == "" {
+		return bh.dialAddr(bh.ctx, remotePID, rAddr, remoteAddr)
+	}
+	return bh.dialMgr.Dial(bh.ctx, remotePID, bh.dialPriority(bh.ctx, remotePID),
+		func(ctx context.Context) (network.Conn, error) {
+			return bh.dialAddr(ctx, remotePID, rAddr, remoteAddr)
+		})
+}
+
+// dialAddr dial to the address given with timeout.
+func (bh *BasicHost) dialAddr(ctx context.Context, remotePID peer.ID, rAddr, remoteAddr ma.Multiaddr) (
+	network.Conn, error) {
 	// dial to remote
 	bh.logger.Infof("[Host][Dial] try to connect to peer(remote pid: %s, addr: %s)",
 		remotePID, rAddr.String())
-	conn, err := bh.nw.Dial(context.Background(), remoteAddr)
+	if err := bh.dialMgr.AcquireFD(ctx); err != nil {
+		return nil, err
+	}
+	defer bh.dialMgr.ReleaseFD()
+	ctx, cancel := context.WithTimeout(ctx, bh.dialTimeout())
+	defer cancel()
+	conn, err := bh.dialNetAddr(ctx, remotePID, rAddr)
+	if remotePID != "" {
+		bh.peerStore.RecordDialResult(remotePID, rAddr, err == nil)
+	}
 	if err != nil {
 		bh.logger.Warnf("[Host][Dial] connect to peer failed, %s (remote pid: %s, addr: %s)",
 			err.Error(), remotePID, rAddr.String())
@@ -799,16 +1146,77 @@ func (bh *BasicHost) Notify(notifiee host.Notifiee) {
 // AddDirectPeer append a directed peer.
 func (bh *BasicHost) AddDirectPeer(mA ma.Multiaddr) {
 	_, peerId := util.GetNetAddrAndPidFromNormalMultiAddr(mA)
+	bh.directPeersMu.Lock()
+	defer bh.directPeersMu.Unlock()
 	if bh.cfg.DirectPeers == nil {
 		bh.cfg.DirectPeers = make(map[peer.ID]ma.Multiaddr)
 	}
-	bh.cfg.DirectPeers[peerId] = mA
-	bh.supervisor.SetPeerAddr(peerId, mA)
+	if _, ok := bh.cfg.DirectPeers[peerId]; !ok {
+		bh.cfg.DirectPeers[peerId] = mA
+	}
+	// a direct peer may have more than one address, all of them will be dialed
+	bh.supervisor.AddPeerAddr(peerId, mA)
+	bh.addConfigAddr(peerId, mA)
+}
+
+// DirectPeerStatus return the connection status of all direct peers maintained by ConnSupervisor.
+func (bh *BasicHost) DirectPeerStatus() []mgr.DirectPeerStatus {
+	return bh.supervisor.Status()
+}
+
+// addConfigAddr record the net address of peer configured by user into PeerStore.
+func (bh *BasicHost) addConfigAddr(pid peer.ID, mA ma.Multiaddr) {
+	netAddr, _ := util.GetNetAddrAndPidFromNormalMultiAddr(mA)
+	if netAddr == nil {
+		return
+	}
+	bh.peerStore.AddAddrWithTTL(pid, store.AddrSourceConfig, store.PermanentAddrTTL, netAddr)
+}
+
+// RemoveDirectPeer remove a directed peer, the addresses configured of it will expire after a while,
+// and the other addresses of it will expire after a while if not connected, or after disconnected.
+func (bh *BasicHost) RemoveDirectPeer(pid peer.ID) {
+	bh.directPeersMu.Lock()
+	defer bh.directPeersMu.Unlock()
+	delete(bh.cfg.DirectPeers, pid)
+	delete(bh.cfg.DirectPeerAddrs, pid)
+	bh.supervisor.RemovePeerAddr(pid)
+	bh.downgradeConfigAddrs(pid)
+	if !bh.connMgr.IsConnected(pid) {
+		bh.peerStore.SetAddrTTL(pid, store.RecentlyConnectedAddrTTL)
+	}
+}
+
+// downgradeConfigAddrs replace the permanent addresses configured of peer with the ones expire after
+// RecentlyConnectedAddrTTL, even if the peer is connected, for SetAddrTTL never changes them.
+func (bh *BasicHost) downgradeConfigAddrs(pid peer.ID) {
+	configAddrs := make([]ma.Multiaddr, 0)
+	for _, info := range bh.peerStore.AddrInfos(pid) {
+		if info.Source == store.AddrSourceConfig && info.Expires.IsZero() {
+			configAddrs = append(configAddrs, info.Addr)
+		}
+	}
+	if len(configAddrs) == 0 {
+		return
+	}
+	bh.peerStore.RemoveAddr(pid, configAddrs...)
+	bh.peerStore.AddAddrWithTTL(pid, store.AddrSourceConfig, store.RecentlyConnectedAddrTTL, configAddrs...)
+}
+
+// isDirectPeer return whether the peer is a directed peer.
+func (bh *BasicHost) isDirectPeer(pid peer.ID) bool {
+	bh.directPeersMu.RLock()
+	defer bh.directPeersMu.RUnlock()
+	_, ok := bh.cfg.DirectPeers[pid]
+	return ok
 }
 
 // ClearDirectPeers remove all directed peers.
 func (bh *BasicHost) ClearDirectPeers() {
+	bh.directPeersMu.Lock()
+	defer bh.directPeersMu.Unlock()
 	bh.cfg.DirectPeers = make(map[peer.ID]ma.Multiaddr)
+	bh.cfg.DirectPeerAddrs = make(map[peer.ID][]ma.Multiaddr)
 	bh.supervisor.RemoveAllPeer()
 }
 
@@ -817,6 +1225,111 @@ func (bh *BasicHost) LocalAddresses() []ma.Multiaddr {
 	return bh.nw.ListenAddresses()
 }
 
+// AnnounceAddrs return the list of net addresses advertised to others.
+// If HostConfig.AnnounceAddresses is not empty, it will be used instead of the listen addresses,
+// otherwise the listen addresses not blocked by HostConfig.NoAnnounceCIDRs will be used.
+// HostConfig.AppendAnnounceAddresses will always be appended.
+// The addresses confirmed unreachable by AutoNAT probing will be excluded.
+// If the host is not confirmed public, the circuit addresses through the relays reserved with will be appended.
+func (bh *BasicHost) AnnounceAddrs() []ma.Multiaddr {
+	candidates := bh.announceCandidates()
+	addrs := make([]ma.Multiaddr, 0, len(candidates))
+	for i := range candidates {
+		if bh.autoNATService.AddrReachability(candidates[i]) == simple.ReachabilityPrivate {
+			continue
+		}
+		addrs = append(addrs, candidates[i])
+	}
+	if bh.autoNATService.Reachability() != simple.ReachabilityPublic {
+		addrs = append(addrs, bh.relayService.RelayAddrs()...)
+	}
+	return addrs
+}
+
+// announceCandidates return the list of net addresses that could be advertised before reachability checking.
+func (bh *BasicHost) announceCandidates() []ma.Multiaddr {
+	var addrs []ma.Multiaddr
+	if len(bh.cfg.AnnounceAddresses) > 0 {
+		addrs = make([]ma.Multiaddr, len(bh.cfg.AnnounceAddresses))
+		copy(addrs, bh.cfg.AnnounceAddresses)
+	} else {
+		addrs = bh.noAnnounceFilters.Filter(bh.LocalAddresses())
+	}
+	for _, addr := range bh.cfg.AppendAnnounceAddresses {
+		exist := false
+		for i := range addrs {
+			if addrs[i].Equal(addr) {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// Reachability return the reachability of the host detected by AutoNAT probing.
+func (bh *BasicHost) Reachability() simple.Reachability {
+	return bh.autoNATService.Reachability()
+}
+
+// CanAnnounceAddr return whether the address could be advertised to others,
+// the addresses blocked by HostConfig.NoAnnounceCIDRs could not.
+func (bh *BasicHost) CanAnnounceAddr(addr ma.Multiaddr) bool {
+	return !bh.noAnnounceFilters.Blocked(addr)
+}
+
+// Ping send a ping to the peer connected and return the round-trip time.
+func (bh *BasicHost) Ping(ctx context.Context, pid peer.ID) (time.Duration, error) {
+	return bh.pingService.Ping(ctx, pid)
+}
+
+// ObservedAddrs return the list of addresses that other peers observed us at.
+func (bh *BasicHost) ObservedAddrs() []ma.Multiaddr {
+	return bh.identifyService.ObservedAddrs()
+}
+
+// markPeerSeen update the last seen time of peer if PeerStore is persistent.
+func (bh *BasicHost) markPeerSeen(pid peer.ID) {
+	if ps, ok := bh.peerStore.(*simple.PersistentPeerStore); ok {
+		ps.MarkSeen(pid)
+	}
+}
+
+// redialRecentPeers dial to the peers recently seen that persisted in PeerStore.
+func (bh *BasicHost) redialRecentPeers() {
+	ps, ok := bh.peerStore.(*simple.PersistentPeerStore)
+	if !ok {
+		return
+	}
+	count := bh.cfg.RedialRecentPeerCount
+	if count < 0 {
+		return
+	}
+	if count == 0 {
+		count = DefaultRedialRecentPeerCount
+	}
+	pids := ps.RecentPeers(count)
+	var wg sync.WaitGroup
+	for i := range pids {
+		pid := pids[i]
+		if pid == bh.ID() || bh.connMgr.IsConnected(pid) {
+			continue
+		}
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			bh.logger.Debugf("[Host] redial peer recently seen(remote pid: %s)", pid)
+			if _, err := bh.Dial(ma.StringCast("/p2p/" + pid.ToString())); err != nil {
+				bh.logger.Debugf("[Host] redial peer recently seen failed, %s (remote pid: %s)", err.Error(), pid)
+			}
+		}(pid)
+	}
+	wg.Wait()
+}
+
 // notifyPeerHandlers called when peer connected or disconnected
 func (bh *BasicHost) notifyPeerHandlers(pid peer.ID, isConnected bool) {
 	// call all notifee
@@ -104,7 +104,7 @@ func TestHostQUIC(t *testing.T) {
 	// register notifee
 	connectC := make(chan struct{}, 2)
 	disconnectC := make(chan struct{})
-	protocolSupportC := make(chan struct{})
+	protocolSupportC := make(chan protocol.ID)
 	protocolUnsupportedC := make(chan struct{})
 	notifeeBundle := &host.NotifieeBundle{
 		PeerConnectedFunc: func(id peer.ID) {
@@ -114,7 +114,7 @@ func TestHostQUIC(t *testing.T) {
 			disconnectC <- struct{}{}
 		},
 		PeerProtocolSupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
-			protocolSupportC <- struct{}{}
+			protocolSupportC <- protocolID
 		},
 		PeerProtocolUnsupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
 			protocolUnsupportedC <- struct{}{}
@@ -151,13 +151,16 @@ func TestHostQUIC(t *testing.T) {
 	})
 	require.Nil(t, err)
 
+	// both hosts push all the protocols supported, including the ones built in host
+	expected, total := expectedProtocolsSupported()
 	timer = time.NewTimer(5 * time.Second)
-	for i := 0; i < 4; i++ {
+	for i := 0; i < total; i++ {
 		select {
 		case <-timer.C:
 			t.Fatal("push protocol supported timeout")
-		case <-protocolSupportC:
-
+		case protocolID := <-protocolSupportC:
+			require.True(t, expected[protocolID] > 0, "unexpected protocol supported: %s", protocolID)
+			expected[protocolID]--
 		}
 	}
 
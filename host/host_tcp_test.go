@@ -8,6 +8,7 @@ package host
 
 import (
 	"context"
+	"net"
 	"strconv"
 	"testing"
 	"time"
@@ -19,7 +20,9 @@ import (
 	"chainmaker.org/chainmaker/net-liquid/core/host"
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
 	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
 	"chainmaker.org/chainmaker/net-liquid/logger"
+	"chainmaker.org/chainmaker/net-liquid/simple"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/require"
 )
@@ -35,6 +38,16 @@ var (
 )
 
 func CreateHostTCP(idx int, seeds map[peer.ID]ma.Multiaddr) (host.Host, error) {
+	h, err := createHostTCPWithConfig(idx, seeds, nil)
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// createHostTCPWithConfig create a host like CreateHostTCP, with the config modified by setCfg before creating.
+func createHostTCPWithConfig(idx int, seeds map[peer.ID]ma.Multiaddr, setCfg func(cfg *HostConfig)) (
+	*BasicHost, error) {
 	certPool := cmx509.NewCertPool()
 	for i := range certPEMs {
 		certPool.AppendCertsFromPEM(certPEMs[i])
@@ -81,10 +94,40 @@ func CreateHostTCP(idx int, seeds map[peer.ID]ma.Multiaddr) (host.Host, error) {
 		Insecurity:                false,
 		PrivateKey:                sk,
 	}
+	if setCfg != nil {
+		setCfg(hostCfg)
+	}
 
 	return hostCfg.NewHost(TcpNetwork, context.Background(), logger.NewLogPrinter("HOST"+strconv.Itoa(idx)))
 }
 
+// newTestHost create a host like CreateHostTCP listening on an ephemeral port of loopback,
+// with the config modified by setCfg before creating. The host will not be started.
+func newTestHost(t *testing.T, idx int, setCfg func(cfg *HostConfig)) *BasicHost {
+	h, err := createHostTCPWithConfig(idx, nil, func(cfg *HostConfig) {
+		cfg.ListenAddresses = []ma.Multiaddr{ma.StringCast("/ip4/127.0.0.1/tcp/0")}
+		if setCfg != nil {
+			setCfg(cfg)
+		}
+	})
+	require.Nil(t, err)
+	return h
+}
+
+// freeTCPAddr return a loopback address with a tcp port that nothing listens on.
+func freeTCPAddr(t *testing.T) ma.Multiaddr {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	require.Nil(t, l.Close())
+	return ma.StringCast("/ip4/127.0.0.1/tcp/" + strconv.Itoa(port))
+}
+
+// testHostAddr return the address with the peer id of the host started.
+func testHostAddr(h host.Host) ma.Multiaddr {
+	return util.CreateMultiAddrWithPidAndNetAddr(h.ID(), h.LocalAddresses()[0])
+}
+
 func TestHostTCP(t *testing.T) {
 	// create host1
 	host1, err := CreateHostTCP(0, map[peer.ID]ma.Multiaddr{pidList[1]: ma.Join(addr2TargetTcp, ma.StringCast("/p2p/"+pidList[1].ToString()))})
@@ -97,7 +140,7 @@ func TestHostTCP(t *testing.T) {
 	// register notifee
 	connectC := make(chan struct{}, 2)
 	disconnectC := make(chan struct{})
-	protocolSupportC := make(chan struct{})
+	protocolSupportC := make(chan protocol.ID)
 	protocolUnsupportedC := make(chan struct{})
 	notifeeBundle := &host.NotifieeBundle{
 		PeerConnectedFunc: func(id peer.ID) {
@@ -107,7 +150,7 @@ func TestHostTCP(t *testing.T) {
 			disconnectC <- struct{}{}
 		},
 		PeerProtocolSupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
-			protocolSupportC <- struct{}{}
+			protocolSupportC <- protocolID
 		},
 		PeerProtocolUnsupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
 			protocolUnsupportedC <- struct{}{}
@@ -144,13 +187,16 @@ func TestHostTCP(t *testing.T) {
 	})
 	require.Nil(t, err)
 
+	// both hosts push all the protocols supported, including the ones built in host
+	expected, total := expectedProtocolsSupported()
 	timer = time.NewTimer(5 * time.Second)
-	for i := 0; i < 4; i++ {
+	for i := 0; i < total; i++ {
 		select {
 		case <-timer.C:
 			t.Fatal("push protocol supported timeout")
-		case <-protocolSupportC:
-
+		case protocolID := <-protocolSupportC:
+			require.True(t, expected[protocolID] > 0, "unexpected protocol supported: %s", protocolID)
+			expected[protocolID]--
 		}
 	}
 
@@ -176,6 +222,24 @@ func TestHostTCP(t *testing.T) {
 
 	}
 
+	// host1 ping host2
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rtt, err := host1.Ping(ctx, pidList[1])
+	require.Nil(t, err)
+	require.True(t, rtt > 0)
+	require.Equal(t, rtt, host1.PeerStore().LatencyEWMA(pidList[1]))
+
+	// identify information of host2 should be stored in host1
+	require.Eventually(t, func() bool {
+		return host1.PeerStore().AgentVersion(pidList[1]) == simple.DefaultAgentVersion
+	}, 5*time.Second, 50*time.Millisecond)
+	require.NotNil(t, host1.PeerStore().PubKey(pidList[1]))
+	require.Contains(t, host1.PeerStore().GetAddrs(pidList[1]), host2.LocalAddresses()[0])
+	require.Eventually(t, func() bool {
+		return len(host2.(*BasicHost).ObservedAddrs()) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
 	bl := host1.IsPeerSupportProtocol(host2.ID(), testProtocolID)
 	require.True(t, bl)
 
@@ -20,6 +20,7 @@ import (
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
 	"chainmaker.org/chainmaker/net-liquid/core/protocol"
 	"chainmaker.org/chainmaker/net-liquid/logger"
+	"chainmaker.org/chainmaker/net-liquid/simple"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/require"
 )
@@ -108,6 +109,25 @@ func CreateHost(idx int, seeds map[peer.ID]ma.Multiaddr) (host.Host, error) {
 	return hostCfg.NewHost(TcpNetwork, context.Background(), logger.NewLogPrinter("HOST"+strconv.Itoa(idx)))
 }
 
+// expectedProtocolsSupported return the count of the PeerProtocolSupported notifications expected of each protocol
+// when two hosts both supporting testProtocolID connected, and the total count of them.
+// Each host is notified once of the protocols supported by the other,
+// including the ones built in host: protocol exchanger, identify, ping and AutoNAT.
+func expectedProtocolsSupported() (map[protocol.ID]int, int) {
+	expected := map[protocol.ID]int{
+		simple.ProtocolExchangerProtocolID: 2,
+		simple.IdentifyProtocolID:          2,
+		simple.PingProtocolID:              2,
+		simple.AutoNATProtocolID:           2,
+		testProtocolID:                     2,
+	}
+	total := 0
+	for _, count := range expected {
+		total += count
+	}
+	return expected, total
+}
+
 func TestHost(t *testing.T) {
 	// create host1
 	host1, err := CreateHost(0, map[peer.ID]ma.Multiaddr{pidList[1]: ma.Join(addr2Target, ma.StringCast("/p2p/"+pidList[1].ToString()))})
@@ -120,7 +140,7 @@ func TestHost(t *testing.T) {
 	// register notifee
 	connectC := make(chan struct{}, 2)
 	disconnectC := make(chan struct{})
-	protocolSupportC := make(chan struct{})
+	protocolSupportC := make(chan protocol.ID)
 	protocolUnsupportedC := make(chan struct{})
 	notifeeBundle := &host.NotifieeBundle{
 		PeerConnectedFunc: func(id peer.ID) {
@@ -130,7 +150,7 @@ func TestHost(t *testing.T) {
 			disconnectC <- struct{}{}
 		},
 		PeerProtocolSupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
-			protocolSupportC <- struct{}{}
+			protocolSupportC <- protocolID
 		},
 		PeerProtocolUnsupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
 			protocolUnsupportedC <- struct{}{}
@@ -167,13 +187,16 @@ func TestHost(t *testing.T) {
 	})
 	require.Nil(t, err)
 
+	// both hosts push all the protocols supported, including the ones built in host
+	expected, total := expectedProtocolsSupported()
 	timer = time.NewTimer(5 * time.Second)
-	for i := 0; i < 4; i++ {
+	for i := 0; i < total; i++ {
 		select {
 		case <-timer.C:
 			t.Fatal("push protocol supported timeout")
-		case <-protocolSupportC:
-
+		case protocolID := <-protocolSupportC:
+			require.True(t, expected[protocolID] > 0, "unexpected protocol supported: %s", protocolID)
+			expected[protocolID]--
 		}
 	}
 
@@ -0,0 +1,53 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package hosttest
+
+import (
+	"context"
+	"testing"
+
+	"chainmaker.org/chainmaker/common/v2/crypto"
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	lHost "chainmaker.org/chainmaker/net-liquid/host"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	"chainmaker.org/chainmaker/net-liquid/tlssupport"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+// NewHost create and start a host with a random key listening on an ephemeral port of loopback.
+// The host will be stopped when the test finished.
+// This function only for testing.
+func NewHost(t *testing.T) host.Host {
+	sk, err := asym.GenerateKeyPair(crypto.ECC_NISTP256)
+	require.Nil(t, err)
+	tlsCfg, loadPidFunc, err := tlssupport.MakeTlsConfigAndLoadPeerIdFuncWithPrivateKey(sk)
+	require.Nil(t, err)
+	hostCfg := &lHost.HostConfig{
+		TlsCfg:                    tlsCfg,
+		LoadPidFunc:               loadPidFunc,
+		SendStreamPoolInitSize:    10,
+		SendStreamPoolCap:         50,
+		PeerReceiveStreamMaxCount: 100,
+		ListenAddresses:           []ma.Multiaddr{ma.StringCast("/ip4/127.0.0.1/tcp/0")},
+		PrivateKey:                sk,
+	}
+	h, err := hostCfg.NewHost(lHost.TcpNetwork, context.Background(), logger.NilLogger)
+	require.Nil(t, err)
+	require.Nil(t, h.Start())
+	t.Cleanup(func() {
+		_ = h.Stop()
+	})
+	return h
+}
+
+// Addr return the address with the net address listened on and the peer id of the host started.
+func Addr(h host.Host) ma.Multiaddr {
+	return util.CreateMultiAddrWithPidAndNetAddr(h.ID(), h.LocalAddresses()[0])
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package host
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	"chainmaker.org/chainmaker/net-liquid/simple/pb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostOverlayRouting(t *testing.T) {
+	// host2 -- host0 -- host3, host2 and host3 are not connected directly
+	hosts := make([]*BasicHost, 0, 3)
+	for _, idx := range []int{2, 0, 3} {
+		h := newTestHost(t, idx, func(cfg *HostConfig) {
+			cfg.EnableOverlayRouting = true
+		})
+		require.Nil(t, h.Start())
+		hosts = append(hosts, h)
+	}
+	defer func() {
+		for i := range hosts {
+			_ = hosts[i].Stop()
+		}
+	}()
+	src, mid, dst := hosts[0], hosts[1], hosts[2]
+
+	var testProtocol protocol.ID = "/overlay-test/v0.0.1"
+	receivedC := make(chan string, 2)
+	require.Nil(t, dst.RegisterMsgPayloadHandler(testProtocol, func(senderPID peer.ID, msgPayload []byte) {
+		receivedC <- senderPID.ToString() + ":" + string(msgPayload)
+	}))
+	for _, h := range []*BasicHost{src, dst} {
+		_, err := h.Dial(testHostAddr(mid))
+		require.Nil(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return mid.IsPeerSupportProtocol(pidList[2], simple.OverlayProtocolID) &&
+			mid.IsPeerSupportProtocol(pidList[3], simple.OverlayProtocolID)
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// no route learned yet
+	require.Equal(t, simple.ErrNoOverlayRoute, src.SendMsgRouted(testProtocol, pidList[3], []byte("hello")))
+
+	// learn the routes advertised by the neighbour, then send msg through it
+	mid.overlayRouter.AdvertiseRoutes()
+	require.Eventually(t, func() bool {
+		nextHop, hops, ok := src.overlayRouter.Route(pidList[3])
+		return ok && nextHop == pidList[0] && hops == 2
+	}, 5*time.Second, 50*time.Millisecond)
+	require.False(t, src.ConnMgr().IsConnected(pidList[3]))
+	require.Nil(t, src.SendMsgRouted(testProtocol, pidList[3], []byte("hello")))
+	select {
+	case received := <-receivedC:
+		require.Equal(t, pidList[2].ToString()+":hello", received)
+	case <-time.After(5 * time.Second):
+		t.Fatal("msg routed not received")
+	}
+
+	// the msg forged by the forwarder is dropped by the destination
+	midPubKey, err := mid.PrivateKey().PublicKey().Bytes()
+	require.Nil(t, err)
+	forged, err := proto.Marshal(&pb.OverlayMsg{
+		MsgType: pb.OverlayMsg_DATA,
+		Data: &pb.OverlayData{
+			Src:        pidList[2].ToString(),
+			Dst:        pidList[3].ToString(),
+			ProtocolId: string(testProtocol),
+			Payload:    []byte("forged"),
+			MsgId:      []byte("forged-msg-id"),
+			Timestamp:  time.Now().UnixNano(),
+			PubKey:     midPubKey,
+			Signature:  []byte("forged-signature"),
+		},
+	})
+	require.Nil(t, err)
+	require.Nil(t, mid.SendMsg(simple.OverlayProtocolID, pidList[3], forged))
+	select {
+	case received := <-receivedC:
+		t.Fatalf("forged msg received: %s", received)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	// the genuine msg with the same id as the forged one is still delivered
+	genuine := &pb.OverlayData{
+		Src:        pidList[2].ToString(),
+		Dst:        pidList[3].ToString(),
+		ProtocolId: string(testProtocol),
+		Payload:    []byte("genuine"),
+		MsgId:      []byte("forged-msg-id"),
+		Timestamp:  time.Now().UnixNano(),
+	}
+	content, err := proto.Marshal(genuine)
+	require.Nil(t, err)
+	digest := sha256.Sum256(content)
+	genuine.Signature, err = src.PrivateKey().Sign(digest[:])
+	require.Nil(t, err)
+	genuine.PubKey, err = src.PrivateKey().PublicKey().Bytes()
+	require.Nil(t, err)
+	genuineMsg, err := proto.Marshal(&pb.OverlayMsg{MsgType: pb.OverlayMsg_DATA, Data: genuine})
+	require.Nil(t, err)
+	require.Nil(t, mid.SendMsg(simple.OverlayProtocolID, pidList[3], genuineMsg))
+	select {
+	case received := <-receivedC:
+		require.Equal(t, pidList[2].ToString()+":genuine", received)
+	case <-time.After(5 * time.Second):
+		t.Fatal("genuine msg not received")
+	}
+
+	// the msg from the peer in blacklist is dropped
+	dst.Blacklist().AddPeer(pidList[2])
+	require.Nil(t, src.SendMsgRouted(testProtocol, pidList[3], []byte("black")))
+	select {
+	case received := <-receivedC:
+		t.Fatalf("msg from peer in blacklist received: %s", received)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
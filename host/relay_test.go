@@ -0,0 +1,92 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package host
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/simple"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostRelay(t *testing.T) {
+	// host0 acts as a relay, host2 keeps a reservation with it, host3 connects to host2 through it
+	relay := newTestHost(t, 0, func(cfg *HostConfig) {
+		cfg.EnableRelayService = true
+		cfg.RelayLimits = simple.RelayLimits{MaxCircuitsPerPeer: 1}
+	})
+	require.Nil(t, relay.Start())
+	relayAddr := testHostAddr(relay)
+	target := newTestHost(t, 2, func(cfg *HostConfig) {
+		cfg.StaticRelays = []ma.Multiaddr{relayAddr}
+	})
+	dialer := newTestHost(t, 3, nil)
+	hosts := []*BasicHost{relay, target, dialer}
+	for _, h := range hosts[1:] {
+		require.Nil(t, h.Start())
+	}
+	defer func() {
+		for i := range hosts {
+			_ = hosts[i].Stop()
+		}
+	}()
+
+	// the reservation is kept by the refresher, and the circuit address is advertised
+	circuitAddr := simple.CreateCircuitAddr(relay.LocalAddresses()[0], pidList[0])
+	require.Eventually(t, func() bool {
+		return len(target.relayService.RelayAddrs()) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Contains(t, target.AnnounceAddrs(), circuitAddr)
+
+	// the dialer has no reservation
+	_, err := target.Dial(ma.Join(circuitAddr, util.CreateMultiAddrWithPid(pidList[3])))
+	require.Equal(t, simple.ErrRelayNoReservation, err)
+
+	// connect through the relay, then send msg with the relayed connection
+	conn, err := dialer.Dial(ma.Join(circuitAddr, util.CreateMultiAddrWithPid(pidList[2])))
+	require.Nil(t, err)
+	require.Equal(t, pidList[2], conn.RemotePeerID())
+	require.True(t, simple.IsCircuitAddr(conn.RemoteAddr()))
+	require.True(t, dialer.ConnMgr().IsConnected(pidList[2]))
+	require.Eventually(t, func() bool {
+		return target.ConnMgr().IsConnected(pidList[3])
+	}, 5*time.Second, 50*time.Millisecond)
+
+	var testProtocol protocol.ID = "/relay-test/v0.0.1"
+	receivedC := make(chan []byte, 1)
+	require.Nil(t, target.RegisterMsgPayloadHandler(testProtocol, func(senderPID peer.ID, msgPayload []byte) {
+		if senderPID == pidList[3] {
+			receivedC <- msgPayload
+		}
+	}))
+	require.Eventually(t, func() bool {
+		return dialer.IsPeerSupportProtocol(pidList[2], testProtocol)
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Nil(t, dialer.SendMsg(testProtocol, pidList[2], []byte("hello")))
+	select {
+	case payload := <-receivedC:
+		require.Equal(t, []byte("hello"), payload)
+	case <-time.After(5 * time.Second):
+		t.Fatal("msg not received through relay")
+	}
+
+	// only one circuit allowed for each peer
+	_, err = dialer.relayService.Connect(context.Background(), dialer.ConnMgr().GetPeerConn(pidList[0]), pidList[2])
+	require.Equal(t, simple.ErrRelayResourceLimitExceeded, err)
+
+	// the relayed connection closed with the circuit
+	require.Nil(t, conn.Close())
+	require.Eventually(t, func() bool {
+		return !target.ConnMgr().IsConnected(pidList[3])
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -282,6 +282,21 @@ func (c *conn) handshakeAndAttachYamux(conn net.Conn) error {
 
 // newConn create a new conn instance.
 func newConn(ctx context.Context, nw *tcpNetwork, c net.Conn, dir network.Direction) (*conn, error) {
+	laddr, err := manet.FromNetAddr(c.LocalAddr())
+	if err != nil {
+		return nil, err
+	}
+	raddr, err := manet.FromNetAddr(c.RemoteAddr())
+	if err != nil {
+		return nil, err
+	}
+	return upgradeConn(ctx, nw, c, dir, laddr, raddr)
+}
+
+// upgradeConn create a new conn instance with the multi-addresses given,
+// it is used for the net.Conn whose addresses could not be parsed to multi-addresses, e.g. a relayed circuit.
+func upgradeConn(ctx context.Context, nw *tcpNetwork, c net.Conn, dir network.Direction,
+	laddr, raddr ma.Multiaddr) (*conn, error) {
 	res := &conn{
 		BasicStat:  *network.NewStat(dir, time.Now(), nil),
 		ctx:        ctx,
@@ -290,25 +305,15 @@ func newConn(ctx context.Context, nw *tcpNetwork, c net.Conn, dir network.Direct
 		sess:       nil,
 		sessForUni: nil,
 		sessForBi:  nil,
-		laddr:      nil,
-		raddr:      nil,
+		laddr:      laddr,
+		raddr:      raddr,
 		lPID:       nw.LocalPeerID(),
 		rPID:       "",
 		closeC:     make(chan struct{}),
 		closeOnce:  sync.Once{},
 	}
-	var err error
-	res.laddr, err = manet.FromNetAddr(c.LocalAddr())
-	if err != nil {
-		return nil, err
-	}
-
-	res.raddr, err = manet.FromNetAddr(c.RemoteAddr())
-	if err != nil {
-		return nil, err
-	}
 
-	err = res.handshakeAndAttachYamux(c)
+	err := res.handshakeAndAttachYamux(c)
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +71,7 @@ var (
 type Option func(n *tcpNetwork) error
 
 var _ network.Network = (*tcpNetwork)(nil)
+var _ network.Upgrader = (*tcpNetwork)(nil)
 
 // tcpNetwork is an implementation of network.Network interface.
 // It uses TCP as transport layer.
@@ -223,7 +224,8 @@ func (t *tcpNetwork) dial(ctx context.Context, remoteAddr ma.Multiaddr) (*conn,
 			continue
 		}
 		// create a new conn with net.Conn
-		tc, err = newConn(ctx, t, c, network.Outbound)
+		// the connection should live longer than the dialing context
+		tc, err = newConn(t.ctx, t, c, network.Outbound)
 		if err != nil {
 			errs = append(errs, err)
 			continue
@@ -241,7 +243,7 @@ func (t *tcpNetwork) dial(ctx context.Context, remoteAddr ma.Multiaddr) (*conn,
 			return nil, errs
 		}
 		// create a new conn with net.Conn
-		tc, err = newConn(ctx, t, c, network.Outbound)
+		tc, err = newConn(t.ctx, t, c, network.Outbound)
 		if err != nil {
 			errs = append(errs, err)
 			return nil, errs
@@ -296,6 +298,43 @@ func (t *tcpNetwork) Dial(ctx context.Context, remoteAddr ma.Multiaddr) (network
 	return tc, nil
 }
 
+// Upgrade the net.Conn given (e.g. a circuit relayed by another peer) to a connection
+// secured with TLS (or pid exchanging if insecurity) and multiplexed with yamux, then call the conn handler.
+// The net.Conn will be closed if the handshaking not finished before ctx done.
+func (t *tcpNetwork) Upgrade(ctx context.Context, c net.Conn, dir network.Direction, laddr, raddr ma.Multiaddr,
+	rPID peer.ID) (network.Conn, error) {
+	doneC := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = c.Close()
+		case <-doneC:
+		}
+	}()
+	tc, err := upgradeConn(t.ctx, t, c, dir, laddr, raddr)
+	close(doneC)
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		_ = tc.Close()
+		return nil, ctx.Err()
+	}
+	if rPID != "" && tc.rPID != rPID {
+		_ = tc.Close()
+		t.logger.Debugf("[Network][Upgrade] pid mismatch, expected: %s, got: %s, close the connection.",
+			rPID, tc.rPID)
+		return nil, ErrPidMismatch
+	}
+	// call conn handler
+	accept := t.callConnHandler(tc)
+	if !accept {
+		return nil, ErrConnRejectedByConnHandler
+	}
+	return tc, nil
+}
+
 // Close the network.
 func (t *tcpNetwork) Close() error {
 	close(t.closeChan)
@@ -45,4 +45,23 @@ type pubSubConfig struct {
 type extensionsConfig struct {
 	EnablePkt          bool
 	EnablePriorityCtrl bool
+	// ConsensusPeerScoreWeight is the weight of consensus peers when scoring peers for connection elimination.
+	ConsensusPeerScoreWeight float64
+	// EnableDHT enables the kademlia DHT, which locates the consensus peers whose addresses are unknown.
+	EnableDHT bool
+	// EnableMdns enables the mDNS discovery, which finds the peers of the same chains in the local network.
+	EnableMdns bool
+	// MdnsInterfaces is the names of network interfaces that mDNS working on, all interfaces used if empty.
+	MdnsInterfaces []string
+	// EnablePeerExchange enables the peer exchange protocol, which heals the topology when neighbours churn.
+	EnablePeerExchange bool
+	// BootstrapFile is the path of the file listing the seeds, which will be reloaded when changed.
+	// One seed per line, or a YAML document if the extension is ".yaml" or ".yml".
+	BootstrapFile string
+	// DiscoveryAllowAllAddrs disables the scope-aware filtering of addresses returned by the discovery service,
+	// so that the loopback and private addresses of peers will be returned to everyone.
+	DiscoveryAllowAllAddrs bool
+	// DiscoveryPublicCIDRs is the list of CIDRs whose addresses will be returned to everyone by the discovery
+	// service, e.g. the private ranges routed between sites by VPN.
+	DiscoveryPublicCIDRs []string
 }
@@ -9,7 +9,6 @@ package liquidnet
 import (
 	"chainmaker.org/chainmaker/common/v2/crypto/asym"
 	"chainmaker.org/chainmaker/common/v2/crypto/tls"
-	"chainmaker.org/chainmaker/common/v2/helper"
 	cmTlsS "chainmaker.org/chainmaker/net-common/cmtlssupport"
 	"chainmaker.org/chainmaker/net-common/common"
 	"chainmaker.org/chainmaker/net-common/common/priorityblocker"
@@ -19,11 +18,19 @@ import (
 	"chainmaker.org/chainmaker/net-liquid/core/handler"
 	"chainmaker.org/chainmaker/net-liquid/core/host"
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/routing"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
 	"chainmaker.org/chainmaker/net-liquid/core/types"
 	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/discovery/bootstrap"
+	"chainmaker.org/chainmaker/net-liquid/discovery/mdns"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerexchange"
+	"chainmaker.org/chainmaker/net-liquid/discovery/peerrecord"
 	"chainmaker.org/chainmaker/net-liquid/discovery/protocoldiscovery"
 	lHost "chainmaker.org/chainmaker/net-liquid/host"
 	"chainmaker.org/chainmaker/net-liquid/pubsub"
+	"chainmaker.org/chainmaker/net-liquid/routing/kaddht"
+	"chainmaker.org/chainmaker/net-liquid/simple"
 	"chainmaker.org/chainmaker/net-liquid/tlssupport"
 	api "chainmaker.org/chainmaker/protocol/v2"
 	ma "github.com/multiformats/go-multiaddr"
@@ -75,6 +82,30 @@ const (
 	DefaultListenAddress = "/ip4/0.0.0.0/tcp/0"
 	// DefaultPubSubMaxMessageSize is the default value for pubSubConfig.MaxPubMessageSize.
 	DefaultPubSubMaxMessageSize = 50 * (2 << 20)
+	// DefaultConsensusPeerScoreWeight is the default value for extensionsConfig.ConsensusPeerScoreWeight.
+	DefaultConsensusPeerScoreWeight = 5.0
+)
+
+// consensusScoreInput is the name of the peer score input for consensus peers.
+const consensusScoreInput = "consensus"
+
+// seedResolveTimeout is the timeout of resolving a /dnsaddr seed.
+const seedResolveTimeout = 10 * time.Second
+
+// seedRefreshInterval is the interval of re-resolving the /dnsaddr seeds.
+const seedRefreshInterval = 10 * time.Minute
+
+// peerLocateTimeout is the timeout of locating a consensus peer with DHT.
+const peerLocateTimeout = 30 * time.Second
+
+// maxDiscoveryDials is the max count of the peers discovered dialing at the same time.
+const maxDiscoveryDials = 8
+
+// The keys of the metadata of peers stored in the MetadataBook of PeerStore.
+const (
+	metadataKeyTlsCert  = "liquidnet/tls-cert"
+	metadataKeyPubKey   = "liquidnet/pub-key"
+	metadataKeyChainIds = "liquidnet/chain-ids"
 )
 
 func InitLogger(globalNetLogger api.Logger, pubSubLogCreator func(chainId string) api.Logger) {
@@ -104,14 +135,27 @@ type LiquidNet struct {
 	tlsChainTrustRoots *cmTlsS.ChainTrustRoots
 	tlsCertValidator   *cmTlsS.CertValidator
 
-	peerIdChainIdsRecorder *common.PeerIdChainIdsRecorder
-	certIdPeerIdMapper     *common.CertIdPeerIdMapper
-	peerIdTlsCertStore     *common.PeerIdTlsCertStore
-	peerIdPubKeyStore      *common.PeerIdPubKeyStore
+	certIdPeerIdMapper *common.CertIdPeerIdMapper
+	// seeds stores the addresses resolved of the seeds added, map seed -> addresses,
+	// the /dnsaddr seeds in it will be re-resolved every seedRefreshInterval after started.
+	seeds             map[string][]ma.Multiaddr
+	seedRefreshCancel context.CancelFunc
+	// localIdentity is the tls cert or the public key (in pub key mode) of local peer,
+	// which will be stored into PeerStore as metadata when host created.
+	localIdentity []byte
 
 	subscribeTopic *types.StringSet
+	consensusPeers *types.PeerIdSet
 
 	discoveryService discovery.Discovery
+	// discoveryCancels stores the cancel functions of finding tasks of chains, map[string]context.CancelFunc
+	discoveryCancels sync.Map
+	// discoveryDialSem bounds the count of the goroutines dialing to the peers discovered
+	discoveryDialSem chan struct{}
+	dht              *kaddht.KadDHT
+	mdnsDiscovery    *mdns.MdnsDiscovery
+	peerExchange     *peerexchange.PeerExchange
+	bootstrap        *bootstrap.Bootstrap
 
 	extensionsCfg      *extensionsConfig
 	pktAdapter         *pktAdapter
@@ -150,15 +194,18 @@ func NewLiquidNet() (*LiquidNet, error) {
 			CustomChainTrustRootCertsBytes: make(map[string][][]byte),
 		},
 		memberStatusValidator: common.NewMemberStatusValidator(),
+		seeds:                 make(map[string][]ma.Multiaddr),
 		subscribeTopic:        &types.StringSet{},
-		extensionsCfg:         &extensionsConfig{EnablePkt: false},
-		pktAdapter:            nil,
-		priorityController:    nil,
+		consensusPeers:        &types.PeerIdSet{},
+		extensionsCfg: &extensionsConfig{
+			EnablePkt:                false,
+			ConsensusPeerScoreWeight: DefaultConsensusPeerScoreWeight,
+		},
+		pktAdapter:         nil,
+		priorityController: nil,
 	}
-	liquidNet.peerIdChainIdsRecorder = common.NewPeerIdChainIdsRecorder(log)
 	liquidNet.certIdPeerIdMapper = common.NewCertIdPeerIdMapper(log)
-	liquidNet.peerIdTlsCertStore = common.NewPeerIdTlsCertStore(log)
-	liquidNet.peerIdPubKeyStore = common.NewPeerIdPubKeyStore(log)
+	liquidNet.discoveryDialSem = make(chan struct{}, maxDiscoveryDials)
 	return liquidNet, nil
 }
 
@@ -182,6 +229,17 @@ func (l *LiquidNet) ExtensionsConfig() *extensionsConfig {
 	return l.extensionsCfg
 }
 
+// ContentRouting return the content routing based on DHT, with which the providers of any content could be found,
+// e.g. the peers holding a block. Nil will be returned if DHT is not enabled or the net is not started.
+func (l *LiquidNet) ContentRouting() routing.ContentRouting {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.dht == nil {
+		return nil
+	}
+	return l.dht
+}
+
 // GetNodeUid get self peer id
 func (l *LiquidNet) GetNodeUid() string {
 	return l.host.ID().ToString()
@@ -219,6 +277,22 @@ func (l *LiquidNet) InitPubSub(chainId string, maxMessageSize int) error {
 	return err
 }
 
+// StopPubSub will stop the PubSub instance of chain with given chainId,
+// then tell others that we no longer serve the chain.
+func (l *LiquidNet) StopPubSub(chainId string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	v, ok := l.psMap.Load(chainId)
+	if !ok {
+		return ErrorPubSubNotExist
+	}
+	l.psMap.Delete(chainId)
+	if l.startUp {
+		l.detachDiscovery(chainId)
+	}
+	return v.(broadcast.PubSub).Stop()
+}
+
 // BroadcastWithChainId publish the message to topic, if not subscribe the topic, will return error
 func (l *LiquidNet) BroadcastWithChainId(chainId string, topic string, data []byte) error {
 	// whether pub-sub service exist
@@ -240,7 +314,7 @@ func (l *LiquidNet) createSubMsgHandler(chainId string, handler api.PubSubMsgHan
 				return
 			}
 			// whether belong to the chain
-			if !l.peerIdChainIdsRecorder.IsPeerBelongToChain(publisherInner.ToString(), chainId) {
+			if !l.isPeerBelongToChain(publisherInner, chainId) {
 				log.Debug("[LiquidNet] get sub msg from peer not belong to chain (publisher:", publisherInner.ToString(), ", chain:", chainId, ")")
 				return
 			}
@@ -313,8 +387,12 @@ func (l *LiquidNet) SendMsg(chainId string, targetPeer string, msgFlag string, d
 
 	targetPeerId := peer.ID(targetPeer)
 
+	// forward msg through other peers if no direct connection and overlay routing enabled
+	routed := l.hostCfg.EnableOverlayRouting && !l.host.ConnMgr().IsConnected(targetPeerId)
 	// whether peer belong to chain
-	if !l.hostCfg.Insecurity && !l.peerIdChainIdsRecorder.IsPeerBelongToChain(targetPeer, chainId) {
+	// the peers not connected are unknown to recorder, only consensus peers are trusted for routed msgs
+	if !l.hostCfg.Insecurity && !l.isPeerBelongToChain(targetPeerId, chainId) &&
+		!(routed && l.consensusPeers.Exist(targetPeerId)) {
 		return ErrorNotBelongToChain
 	}
 	// create protocol id
@@ -332,7 +410,12 @@ func (l *LiquidNet) SendMsg(chainId string, targetPeer string, msgFlag string, d
 	}
 
 	// send msg
-	err := l.host.SendMsg(netProtocolId, targetPeerId, data)
+	var err error
+	if routed {
+		err = l.host.SendMsgRouted(netProtocolId, targetPeerId, data)
+	} else {
+		err = l.host.SendMsg(netProtocolId, targetPeerId, data)
+	}
 	if err != nil {
 		log.Errorf("[LiquidNet] [SendMsg] send message failed, %s (chain: %s, targetPeer: %s, msg_flag: %s)",
 			err.Error(), chainId, targetPeer, msgFlag)
@@ -341,8 +424,17 @@ func (l *LiquidNet) SendMsg(chainId string, targetPeer string, msgFlag string, d
 	return nil
 }
 
-func (l *LiquidNet) createMsgPayloadHandler(handler api.DirectMsgHandler) handler.MsgPayloadHandler {
+func (l *LiquidNet) createMsgPayloadHandler(chainId string, handler api.DirectMsgHandler) handler.MsgPayloadHandler {
 	return func(senderPID peer.ID, msgPayload []byte) {
+		// the sender of a msg routed is not connected to us, so it has not been verified on connecting,
+		// only the peers known belong to chain and the consensus peers are trusted, same as sending.
+		if !l.hostCfg.Insecurity && !l.host.ConnMgr().IsConnected(senderPID) &&
+			!l.isPeerBelongToChain(senderPID, chainId) &&
+			!l.consensusPeers.Exist(senderPID) {
+			log.Warnf("[LiquidNet] [DirectMsgHandle] %s, drop the msg routed. (chain: %s, sender: %s)",
+				ErrorNotBelongToChain.Error(), chainId, senderPID)
+			return
+		}
 		go func(senderPIDInner peer.ID, msgPayloadInner []byte) {
 			// call handler
 			err := handler(string(senderPIDInner), msgPayloadInner)
@@ -362,7 +454,7 @@ func (l *LiquidNet) DirectMsgHandle(chainId string, msgFlag string, handler api.
 	// create net protocol id
 	netProtocolId := CreateProtocolIdWithChainIdAndMsgFlag(chainId, msgFlag)
 	// create msg payload handler
-	h := l.createMsgPayloadHandler(handler)
+	h := l.createMsgPayloadHandler(chainId, handler)
 	// register handler
 	err := l.host.RegisterMsgPayloadHandler(netProtocolId, h)
 	if err != nil {
@@ -388,20 +480,15 @@ func (l *LiquidNet) CancelDirectMsgHandle(chainId string, msgFlag string) error
 }
 
 // AddSeed add a seed node addr.
+// A /dnsaddr/<domain> seed will be resolved to the list of seeds in the TXT records of _dnsaddr.<domain>.
 func (l *LiquidNet) AddSeed(seed string) error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
-	if l.startUp {
-		dp, err := ma.NewMultiaddr(seed)
-		if err != nil {
-			return err
-		}
-		l.host.AddDirectPeer(dp)
-	}
-	if err := l.hostCfg.AddDirectPeer(seed); err != nil {
+	seedAddrs, err := l.resolveSeed(l.context, seed)
+	if err != nil {
 		return err
 	}
-	return nil
+	return l.applySeed(seed, seedAddrs)
 }
 
 // RefreshSeeds refresh the seed node addr list.
@@ -409,24 +496,152 @@ func (l *LiquidNet) RefreshSeeds(seeds []string) error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 	l.hostCfg.DirectPeers = make(map[peer.ID]ma.Multiaddr)
+	l.hostCfg.DirectPeerAddrs = make(map[peer.ID][]ma.Multiaddr)
 	if l.startUp {
 		l.host.ClearDirectPeers()
 	}
+	l.seeds = make(map[string][]ma.Multiaddr)
 	for _, seed := range seeds {
-		if err := l.hostCfg.AddDirectPeer(seed); err != nil {
+		seedAddrs, err := l.resolveSeed(l.context, seed)
+		if err != nil {
+			return err
+		}
+		if err = l.applySeed(seed, seedAddrs); err != nil {
 			return err
 		}
+	}
+	return nil
+}
+
+// applySeed apply the addresses resolved from the seed as direct peers.
+// The direct peers resolved from the seed before but not listed any more will be removed,
+// unless they are listed by other seeds. It should be called when l.lock locked.
+func (l *LiquidNet) applySeed(seed string, seedAddrs []ma.Multiaddr) error {
+	before := seedPeerAddrs(l.seeds)
+	l.seeds[seed] = seedAddrs
+	after := seedPeerAddrs(l.seeds)
+	for pid, old := range before {
+		if addrs, ok := after[pid]; ok && sameAddrSet(old, addrs) {
+			continue
+		}
+		// removed, or the addresses changed and all of them will be added again
 		if l.startUp {
-			dp, err := ma.NewMultiaddr(seed)
-			if err != nil {
+			l.host.RemoveDirectPeer(pid)
+			continue
+		}
+		delete(l.hostCfg.DirectPeers, pid)
+		delete(l.hostCfg.DirectPeerAddrs, pid)
+	}
+	for pid, addrs := range after {
+		if old, ok := before[pid]; ok && sameAddrSet(old, addrs) {
+			continue
+		}
+		for _, dp := range addrs {
+			// the host shares the config, so only one of them should be updated
+			if l.startUp {
+				l.host.AddDirectPeer(dp)
+				continue
+			}
+			if err := l.hostCfg.AddDirectPeer(dp.String()); err != nil {
 				return err
 			}
-			l.host.AddDirectPeer(dp)
 		}
 	}
 	return nil
 }
 
+// seedRefreshLoop re-resolve the /dnsaddr seeds every seedRefreshInterval until the context done,
+// so that the seeds rotated in DNS records will be applied without restarting.
+func (l *LiquidNet) seedRefreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(seedRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.refreshDNSAddrSeeds(ctx)
+		}
+	}
+}
+
+// refreshDNSAddrSeeds re-resolve the /dnsaddr seeds and apply the results.
+// The addresses resolved before will be kept for the seeds failed to be resolved.
+func (l *LiquidNet) refreshDNSAddrSeeds(ctx context.Context) {
+	l.lock.Lock()
+	seeds := make([]string, 0, len(l.seeds))
+	for seed := range l.seeds {
+		if simple.IsDNSAddrList(ma.StringCast(seed)) {
+			seeds = append(seeds, seed)
+		}
+	}
+	l.lock.Unlock()
+	// resolve without locking, for it may take a while
+	for _, seed := range seeds {
+		seedAddrs, err := l.resolveSeed(ctx, seed)
+		if err != nil {
+			continue
+		}
+		l.lock.Lock()
+		if _, ok := l.seeds[seed]; ok && l.startUp {
+			if err = l.applySeed(seed, seedAddrs); err != nil {
+				log.Warnf("[LiquidNet] apply seed failed, %s (seed: %s)", err.Error(), seed)
+			}
+		}
+		l.lock.Unlock()
+	}
+}
+
+// seedPeerAddrs return the addresses of each peer listed by seeds.
+func seedPeerAddrs(seeds map[string][]ma.Multiaddr) map[peer.ID][]ma.Multiaddr {
+	res := make(map[peer.ID][]ma.Multiaddr)
+	for _, addrs := range seeds {
+		for _, addr := range addrs {
+			_, pid := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+			res[pid] = append(res[pid], addr)
+		}
+	}
+	return res
+}
+
+// sameAddrSet return whether the two lists contain the same addresses regardless of order.
+func sameAddrSet(a, b []ma.Multiaddr) bool {
+	set := make(map[string]struct{}, len(a))
+	for _, addr := range a {
+		set[addr.String()] = struct{}{}
+	}
+	if len(set) != len(b) {
+		return false
+	}
+	for _, addr := range b {
+		if _, ok := set[addr.String()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveSeed parse the seed addr, a /dnsaddr seed will be resolved to the list of seeds in TXT records.
+// The /dns, /dns4 and /dns6 seeds will be kept, they will be resolved by host on each dialing.
+func (l *LiquidNet) resolveSeed(ctx context.Context, seed string) ([]ma.Multiaddr, error) {
+	addr, err := ma.NewMultiaddr(seed)
+	if err != nil {
+		return nil, err
+	}
+	if !simple.IsDNSAddrList(addr) {
+		return []ma.Multiaddr{addr}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, seedResolveTimeout)
+	defer cancel()
+	addrs, err := simple.NewMultiaddrResolver(l.hostCfg.Resolver).ResolveDNSAddr(ctx, addr)
+	if err != nil {
+		log.Warnf("[LiquidNet] resolve seed failed, %s (seed: %s)", err.Error(), seed)
+		return nil, err
+	}
+	log.Infof("[LiquidNet] seed resolved. (seed: %s, count: %d)", seed, len(addrs))
+	return addrs, nil
+}
+
 // SetChainCustomTrustRoots set custom trust roots of chain.
 // In cert permission mode, if it is failed when verifying cert by access control of chains,
 // the cert will be verified by custom trust root pool again.
@@ -471,18 +686,16 @@ func (l *LiquidNet) ReVerifyPeers(chainId string) {
 	if l.hostCfg.Insecurity {
 		return
 	}
-	var peerIdTlsCertOrPubKeyMap map[string][]byte
-	if l.cryptoCfg.PubKeyMode {
-		peerIdTlsCertOrPubKeyMap = l.peerIdPubKeyStore.StoreCopy()
-	} else {
-		peerIdTlsCertOrPubKeyMap = l.peerIdTlsCertStore.StoreCopy()
+	peerIdTlsCertOrPubKeyMap := make(map[string][]byte)
+	for pid, val := range l.host.PeerStore().PeersWithKey(l.identityMetadataKey()) {
+		peerIdTlsCertOrPubKeyMap[pid.ToString()] = val.([]byte)
 	}
 	if len(peerIdTlsCertOrPubKeyMap) == 0 {
 		return
 	}
 
 	// re verify exist peers
-	existPeers := l.peerIdChainIdsRecorder.PeerIdsOfChain(chainId)
+	existPeers := l.peerIdsOfChain(chainId)
 	for _, existPeerId := range existPeers {
 		bytes, ok := peerIdTlsCertOrPubKeyMap[existPeerId]
 		if ok {
@@ -500,14 +713,14 @@ func (l *LiquidNet) ReVerifyPeers(chainId string) {
 			}
 			// if not passed, remove it from chain
 			if !passed {
-				l.peerIdChainIdsRecorder.RemovePeerChainId(existPeerId, chainId)
+				l.removePeerChainId(peer.ID(existPeerId), chainId)
 				log.Infof("[LiquidNet][ReVerifyPeers] remove peer from chain, (pid: %s, chain id: %s)",
 					existPeerId, chainId)
 				l.setChainPubSubBlackPeer(chainId, peer.ID(existPeerId))
 			}
 			delete(peerIdTlsCertOrPubKeyMap, existPeerId)
 		} else {
-			l.peerIdChainIdsRecorder.RemovePeerChainId(existPeerId, chainId)
+			l.removePeerChainId(peer.ID(existPeerId), chainId)
 			log.Infof("[LiquidNet][ReVerifyPeers] remove peer from chain, (pid: %s, chain id: %s)",
 				existPeerId, chainId)
 			l.setChainPubSubBlackPeer(chainId, peer.ID(existPeerId))
@@ -529,7 +742,7 @@ func (l *LiquidNet) ReVerifyPeers(chainId string) {
 		}
 		// if passed, add it to chain
 		if passed {
-			l.peerIdChainIdsRecorder.AddPeerChainId(pid, chainId)
+			l.addPeerChainId(peer.ID(pid), chainId)
 			log.Infof("[LiquidNet] [ReVerifyTrustRoots] add peer to chain, (pid: %s, chain id: %s)",
 				pid, chainId)
 			l.removeChainPubSubBlackPeer(chainId, peer.ID(pid))
@@ -537,7 +750,7 @@ func (l *LiquidNet) ReVerifyPeers(chainId string) {
 	}
 
 	// close all connections of peers not belong to any chain
-	for _, s := range l.peerIdChainIdsRecorder.PeerIdsOfNoChain() {
+	for _, s := range l.peerIdsOfNoChain() {
 		pid := peer.ID(s)
 		for c := l.host.ConnMgr().GetPeerConn(pid); c != nil; c = l.host.ConnMgr().GetPeerConn(pid) {
 			_ = c.Close()
@@ -625,13 +838,12 @@ func (l *LiquidNet) setUpTlsConfig(netType lHost.NetworkType) error {
 		if err != nil {
 			return err
 		}
-		pid, err := helper.CreateLibp2pPeerIdWithPrivateKey(privateKey)
+		// store pub key
+		pkPem, err := privateKey.PublicKey().String()
 		if err != nil {
 			return err
 		}
-		// store pub key
-		pkPem, err := privateKey.PublicKey().String()
-		l.peerIdPubKeyStore.SetPeerPubKey(pid, []byte(pkPem))
+		l.localIdentity = []byte(pkPem)
 		// create tls cert
 		if netType == lHost.QuicNetwork {
 			l.hostCfg.TlsCfg, err = cmTlsS.NewTlsConfigWithPubKeyMode4Quic(privateKey, l.tlsCertValidator)
@@ -645,21 +857,20 @@ func (l *LiquidNet) setUpTlsConfig(netType lHost.NetworkType) error {
 	} else {
 		var (
 			tlsCert *tls.Certificate
-			peerId  string
 			err     error
 		)
 
 		// create tls cert
 		if netType == lHost.QuicNetwork {
-			tlsCert, peerId, err = cmTlsS.GetCertAndPeerIdWithKeyPair4Quic(l.cryptoCfg.CertBytes, l.cryptoCfg.KeyBytes)
+			tlsCert, _, err = cmTlsS.GetCertAndPeerIdWithKeyPair4Quic(l.cryptoCfg.CertBytes, l.cryptoCfg.KeyBytes)
 		} else {
-			tlsCert, peerId, err = cmTlsS.GetCertAndPeerIdWithKeyPair(l.cryptoCfg.CertBytes, l.cryptoCfg.KeyBytes)
+			tlsCert, _, err = cmTlsS.GetCertAndPeerIdWithKeyPair(l.cryptoCfg.CertBytes, l.cryptoCfg.KeyBytes)
 		}
 		// store tls cert
 		if err != nil {
 			return err
 		}
-		l.peerIdTlsCertStore.SetPeerTlsCert(peerId, tlsCert.Certificate[0])
+		l.localIdentity = tlsCert.Certificate[0]
 		// create tls config
 		l.hostCfg.TlsCfg, err = cmTlsS.NewTlsConfigWithCertMode(*tlsCert, l.tlsCertValidator)
 		if err != nil {
@@ -718,8 +929,14 @@ func (l *LiquidNet) Start() error {
 	}
 
 	l.host = newHost
+	l.registerMetadataKeys()
+	if err = l.host.PeerStore().Put(l.host.ID(), l.identityMetadataKey(), l.localIdentity); err != nil {
+		return err
+	}
 	// bind notifiee
 	l.bindNotifiee()
+	// weight consensus peers
+	l.setUpPeerScorer()
 
 	// pkt adapter
 	if l.extensionsCfg.EnablePkt {
@@ -747,16 +964,102 @@ func (l *LiquidNet) Start() error {
 	}
 
 	// set up discovery service
+	var addrFilter protocoldiscovery.AddrFilter = protocoldiscovery.AllowAllAddrs
+	if !l.extensionsCfg.DiscoveryAllowAllAddrs {
+		addrFilter, err = protocoldiscovery.NewScopedAddrFilter(l.extensionsCfg.DiscoveryPublicCIDRs...)
+		if err != nil {
+			log.Errorf("[LiquidNet] parse discovery public cidrs failed, %s", err.Error())
+			return err
+		}
+	}
+	// the signed peer records verified are shared by discovery service and peer exchange
+	peerRecords := peerrecord.NewStore(peerrecord.DefaultStoreCapacity, l.host.ConnMgr().IsConnected)
 	l.discoveryService, err = protocoldiscovery.NewProtocolBasedDiscovery(
 		l.host,
 		protocoldiscovery.WithLogger(log),
 		protocoldiscovery.WithMaxQuerySize(3),
+		protocoldiscovery.WithAddrFilter(addrFilter),
+		protocoldiscovery.WithRecordStore(peerRecords),
 	)
 	if err != nil {
 		log.Errorf("[LiquidNet] set up discovery service failed, %s", err.Error())
 		return err
 	}
 	log.Info("[LiquidNet] discovery service set up.")
+
+	// set up dht
+	if l.extensionsCfg.EnableDHT {
+		l.dht, err = kaddht.NewKadDHT(l.host, kaddht.WithLogger(log))
+		if err != nil {
+			log.Errorf("[LiquidNet] set up dht failed, %s", err.Error())
+			return err
+		}
+		if err = l.dht.Start(); err != nil {
+			log.Errorf("[LiquidNet] start dht failed, %s", err.Error())
+			return err
+		}
+		dht := l.dht
+		l.consensusPeers.Range(func(pid peer.ID) bool {
+			go l.locatePeer(dht, pid)
+			return true
+		})
+		log.Info("[LiquidNet] dht started.")
+	}
+
+	// set up mdns discovery, peers in the local network are optional, so never fail on it
+	if l.extensionsCfg.EnableMdns {
+		l.mdnsDiscovery, err = mdns.NewMdnsDiscovery(l.host,
+			mdns.WithLogger(log),
+			mdns.WithInterfaces(l.extensionsCfg.MdnsInterfaces...),
+		)
+		if err == nil {
+			err = l.mdnsDiscovery.Start()
+		}
+		if err != nil {
+			log.Warnf("[LiquidNet] start mdns discovery failed, %s", err.Error())
+			l.mdnsDiscovery = nil
+			err = nil
+		} else {
+			log.Info("[LiquidNet] mdns discovery started.")
+		}
+	}
+
+	// set up peer exchange
+	if l.extensionsCfg.EnablePeerExchange {
+		l.peerExchange, err = peerexchange.NewPeerExchange(l.host,
+			peerexchange.WithLogger(log),
+			peerexchange.WithRecordStore(peerRecords),
+		)
+		if err != nil {
+			return err
+		}
+		if err = l.peerExchange.Start(); err != nil {
+			log.Errorf("[LiquidNet] start peer exchange failed, %s", err.Error())
+			return err
+		}
+		log.Info("[LiquidNet] peer exchange started.")
+	}
+
+	// set up the seeds in bootstrap file
+	if l.extensionsCfg.BootstrapFile != "" {
+		l.bootstrap, err = bootstrap.NewBootstrap(l.host,
+			bootstrap.WithLogger(log),
+			bootstrap.WithFile(l.extensionsCfg.BootstrapFile),
+		)
+		if err != nil {
+			return err
+		}
+		if err = l.bootstrap.Start(); err != nil {
+			log.Errorf("[LiquidNet] start bootstrap failed, %s (path: %s)",
+				err.Error(), l.extensionsCfg.BootstrapFile)
+			return err
+		}
+		log.Info("[LiquidNet] bootstrap started.")
+	}
+	// re-resolve the /dnsaddr seeds periodically
+	var seedRefreshCtx context.Context
+	seedRefreshCtx, l.seedRefreshCancel = context.WithCancel(l.context)
+	go l.seedRefreshLoop(seedRefreshCtx)
 	l.startUp = true
 	return err
 }
@@ -765,7 +1068,7 @@ func (l *LiquidNet) resetChainPubSubBlackPeerWithPid(pidStr string) {
 	l.psMap.Range(func(key, value interface{}) bool {
 		chainId := key.(string)
 		ps := value.(broadcast.PubSub)
-		if l.peerIdChainIdsRecorder.IsPeerBelongToChain(pidStr, chainId) {
+		if l.isPeerBelongToChain(peer.ID(pidStr), chainId) {
 			ps.RemoveBlackPeer(peer.ID(pidStr))
 		} else {
 			ps.SetBlackPeer(peer.ID(pidStr))
@@ -775,15 +1078,16 @@ func (l *LiquidNet) resetChainPubSubBlackPeerWithPid(pidStr string) {
 }
 
 func (l *LiquidNet) queryAndStoreDerivedInfoInCertValidator(peerIdStr string) {
-	if l.hostCfg.Insecurity {
+	if l.hostCfg.Insecurity || l.host == nil {
 		return
 	}
 	derivedInfo := l.tlsCertValidator.QueryDerivedInfoWithPeerId(peerIdStr)
 	if derivedInfo != nil {
-		l.peerIdTlsCertStore.SetPeerTlsCert(derivedInfo.PeerId, derivedInfo.TlsCertBytes)
-		l.peerIdPubKeyStore.SetPeerPubKey(derivedInfo.PeerId, derivedInfo.PubKeyBytes)
+		pid := peer.ID(derivedInfo.PeerId)
+		l.putPeerMetadata(pid, metadataKeyTlsCert, derivedInfo.TlsCertBytes)
+		l.putPeerMetadata(pid, metadataKeyPubKey, derivedInfo.PubKeyBytes)
 		for i := range derivedInfo.ChainIds {
-			l.peerIdChainIdsRecorder.AddPeerChainId(derivedInfo.PeerId, derivedInfo.ChainIds[i])
+			l.addPeerChainId(pid, derivedInfo.ChainIds[i])
 		}
 		l.certIdPeerIdMapper.Add(derivedInfo.CertId, derivedInfo.PeerId)
 		l.resetChainPubSubBlackPeerWithPid(derivedInfo.PeerId)
@@ -792,6 +1096,52 @@ func (l *LiquidNet) queryAndStoreDerivedInfoInCertValidator(peerIdStr string) {
 	}
 }
 
+// AddConsensusPeer mark a peer as consensus peer.
+// Consensus peers will be scored higher when the connection manager eliminating connections.
+func (l *LiquidNet) AddConsensusPeer(peerId string) {
+	l.consensusPeers.Put(peer.ID(peerId))
+	// l.dht will be reset when stopping, so capture it before locating
+	l.lock.Lock()
+	dht := l.dht
+	l.lock.Unlock()
+	if dht != nil {
+		go l.locatePeer(dht, peer.ID(peerId))
+	}
+}
+
+// locatePeer find the addresses of peer with DHT if they are not in PeerStore, then dial to it.
+func (l *LiquidNet) locatePeer(dht *kaddht.KadDHT, pid peer.ID) {
+	if pid == l.host.ID() || l.host.ConnMgr().IsConnected(pid) {
+		return
+	}
+	_, err := dht.FindPeer(l.context, "", pid, peerLocateTimeout)
+	if err != nil {
+		log.Debugf("[LiquidNet] [DHT] locate peer failed, %s (pid: %s)", err.Error(), pid)
+		return
+	}
+	_, _ = l.host.DialPeer(l.context, pid)
+}
+
+// RemoveConsensusPeer unmark a consensus peer.
+func (l *LiquidNet) RemoveConsensusPeer(peerId string) {
+	l.consensusPeers.Remove(peer.ID(peerId))
+}
+
+// setUpPeerScorer append a score input of consensus peers to the peer scorer of connection manager.
+func (l *LiquidNet) setUpPeerScorer() {
+	cm, ok := l.host.ConnMgr().(*simple.LevelConnManager)
+	if !ok || l.extensionsCfg.ConsensusPeerScoreWeight <= 0 {
+		return
+	}
+	cm.Scorer().SetScoreInput(consensusScoreInput, l.extensionsCfg.ConsensusPeerScoreWeight,
+		func(pid peer.ID) float64 {
+			if l.consensusPeers.Exist(pid) {
+				return 1
+			}
+			return 0
+		})
+}
+
 // bindNotifiee create a notifiee bundle then register it to the host.
 func (l *LiquidNet) bindNotifiee() {
 	notifieeBundle := &host.NotifieeBundle{
@@ -807,13 +1157,132 @@ func (l *LiquidNet) bindNotifiee() {
 			defer l.lock.Unlock()
 			peerIdStr := peerId.ToString()
 			log.Debugf("[LiquidNet] peer disconnect. (pid: %s)", peerIdStr)
-			l.peerIdChainIdsRecorder.RemoveAllByPeerId(peerIdStr)
-			l.peerIdTlsCertStore.RemoveByPeerId(peerIdStr)
-			l.peerIdPubKeyStore.RemoveByPeerId(peerIdStr)
-			l.certIdPeerIdMapper.RemoveByPeerId(peerIdStr)
+			l.removePeerRecords(peerIdStr)
 		},
 	}
 	l.host.Notify(notifieeBundle)
+	// records of peer should also be removed when the peer forgotten by peer store
+	l.host.PeerStore().OnPeerForgotten(func(pid peer.ID) {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		log.Debugf("[LiquidNet] peer forgotten. (pid: %s)", pid)
+		l.removePeerRecords(pid.ToString())
+	})
+}
+
+// removePeerRecords remove the chain ids, tls cert, public key and cert id records of peer.
+func (l *LiquidNet) removePeerRecords(peerIdStr string) {
+	ps := l.host.PeerStore()
+	ps.Delete(peer.ID(peerIdStr), metadataKeyChainIds)
+	ps.Delete(peer.ID(peerIdStr), metadataKeyTlsCert)
+	ps.Delete(peer.ID(peerIdStr), metadataKeyPubKey)
+	l.certIdPeerIdMapper.RemoveByPeerId(peerIdStr)
+}
+
+// identityMetadataKey return the metadata key of the tls certs or the public keys (in pub key mode) of peers,
+// with which the chain membership of peers verified.
+func (l *LiquidNet) identityMetadataKey() string {
+	if l.cryptoCfg.PubKeyMode {
+		return metadataKeyPubKey
+	}
+	return metadataKeyTlsCert
+}
+
+// registerMetadataKeys register the keys of the metadata of peers used by LiquidNet to the PeerStore.
+func (l *LiquidNet) registerMetadataKeys() {
+	ps := l.host.PeerStore()
+	ps.RegisterMetadataKey(metadataKeyTlsCert, simple.BytesMetadataCodec{})
+	ps.RegisterMetadataKey(metadataKeyPubKey, simple.BytesMetadataCodec{})
+	ps.RegisterMetadataKey(metadataKeyChainIds, simple.StringsMetadataCodec{})
+}
+
+// putPeerMetadata store a metadata value of peer into the PeerStore.
+func (l *LiquidNet) putPeerMetadata(pid peer.ID, key string, val interface{}) {
+	if err := l.host.PeerStore().Put(pid, key, val); err != nil {
+		log.Warnf("[LiquidNet] store metadata of peer failed, %s (pid: %s, key: %s)", err.Error(), pid, key)
+	}
+}
+
+// peerBytesMetadata return the metadata value of []byte of peer. If not exists, return nil.
+func (l *LiquidNet) peerBytesMetadata(pid peer.ID, key string) []byte {
+	val, ok := l.host.PeerStore().Get(pid, key)
+	if !ok {
+		return nil
+	}
+	return val.([]byte)
+}
+
+// peerChainIds return the ids of the chains that the peer belongs to,
+// and whether the chain membership of the peer has been recorded.
+func (l *LiquidNet) peerChainIds(pid peer.ID) ([]string, bool) {
+	val, ok := l.host.PeerStore().Get(pid, metadataKeyChainIds)
+	if !ok {
+		return nil, false
+	}
+	return val.([]string), true
+}
+
+// isPeerBelongToChain return whether the peer belongs to the chain.
+func (l *LiquidNet) isPeerBelongToChain(pid peer.ID, chainId string) bool {
+	chainIds, _ := l.peerChainIds(pid)
+	for i := range chainIds {
+		if chainIds[i] == chainId {
+			return true
+		}
+	}
+	return false
+}
+
+// addPeerChainId record that the peer belongs to the chain.
+// This method should be called with l.lock held, for the chain ids of peer are read and written back.
+func (l *LiquidNet) addPeerChainId(pid peer.ID, chainId string) {
+	if l.isPeerBelongToChain(pid, chainId) {
+		return
+	}
+	chainIds, _ := l.peerChainIds(pid)
+	l.putPeerMetadata(pid, metadataKeyChainIds, append(chainIds, chainId))
+}
+
+// removePeerChainId record that the peer does not belong to the chain any more.
+// The peer will be kept as a peer of no chain if it belongs to no other chain.
+// This method should be called with l.lock held, for the chain ids of peer are read and written back.
+func (l *LiquidNet) removePeerChainId(pid peer.ID, chainId string) {
+	chainIds, ok := l.peerChainIds(pid)
+	if !ok {
+		return
+	}
+	res := make([]string, 0, len(chainIds))
+	for i := range chainIds {
+		if chainIds[i] != chainId {
+			res = append(res, chainIds[i])
+		}
+	}
+	l.putPeerMetadata(pid, metadataKeyChainIds, res)
+}
+
+// peerIdsOfChain return the ids of the peers belong to the chain.
+func (l *LiquidNet) peerIdsOfChain(chainId string) []string {
+	res := make([]string, 0)
+	for pid, val := range l.host.PeerStore().PeersWithKey(metadataKeyChainIds) {
+		for _, id := range val.([]string) {
+			if id == chainId {
+				res = append(res, pid.ToString())
+				break
+			}
+		}
+	}
+	return res
+}
+
+// peerIdsOfNoChain return the ids of the peers whose chain membership recorded but belong to no chain.
+func (l *LiquidNet) peerIdsOfNoChain() []string {
+	res := make([]string, 0)
+	for pid, val := range l.host.PeerStore().PeersWithKey(metadataKeyChainIds) {
+		if len(val.([]string)) == 0 {
+			res = append(res, pid.ToString())
+		}
+	}
+	return res
 }
 
 func (l *LiquidNet) listenFindingChanTask(c <-chan ma.Multiaddr) {
@@ -832,8 +1301,24 @@ func (l *LiquidNet) listenFindingChanTask(c <-chan ma.Multiaddr) {
 				l.host.ConnMgr().IsConnected(pid) {
 				continue
 			}
-			log.Infof("[LiquidNet] [Discovery] find new peer.(pid: %s, addr: %s)", pid, addr.String())
-			_, _ = l.host.Dial(ai)
+			if addr != nil {
+				log.Infof("[LiquidNet] [Discovery] find new peer.(pid: %s, addr: %s)", pid, addr.String())
+				l.host.PeerStore().AddAddrWithTTL(pid, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, addr)
+			}
+			// peers discovered dial with the lowest priority, and never block the finding chan.
+			// if too many dialing, skip it, the address stored will be dialed when the peer found again.
+			select {
+			case l.discoveryDialSem <- struct{}{}:
+			default:
+				log.Debugf("[LiquidNet] [Discovery] too many peers dialing, skip dialing. (pid: %s)", pid)
+				continue
+			}
+			go func(pid peer.ID) {
+				defer func() {
+					<-l.discoveryDialSem
+				}()
+				_, _ = l.host.DialPeer(simple.WithDialPriority(l.context, simple.DialPriorityLow), pid)
+			}(pid)
 		}
 	}
 }
@@ -846,23 +1331,71 @@ func (l *LiquidNet) attachDiscovery(chainId string) error {
 		return err
 	}
 	log.Infof("[LiquidNet] chain service announced. (chain-id: %s)", chainId)
-	// discovery finding
+	// discovery finding, until the chain detached
+	ctx, cancel := context.WithCancel(l.context)
 	var findingC <-chan ma.Multiaddr
-	findingC, err = l.discoveryService.FindPeers(l.context, chainId, protocoldiscovery.WithQuerySize(3))
+	findingC, err = l.discoveryService.FindPeers(ctx, chainId, protocoldiscovery.WithQuerySize(3))
 	if err != nil {
+		cancel()
 		return err
 	}
+	l.discoveryCancels.Store(chainId, cancel)
 	go l.listenFindingChanTask(findingC)
 	log.Infof("[LiquidNet] chain peers finding... (chain-id: %s)", chainId)
+	if l.mdnsDiscovery != nil {
+		l.attachMdnsDiscovery(ctx, chainId)
+	}
 	return nil
 }
 
+// detachDiscovery stop finding peers of chain, then unannounce the chain service.
+func (l *LiquidNet) detachDiscovery(chainId string) {
+	if cancel, ok := l.discoveryCancels.Load(chainId); ok {
+		cancel.(context.CancelFunc)()
+		l.discoveryCancels.Delete(chainId)
+	}
+	if unannouncer, ok := l.discoveryService.(discovery.Unannouncer); ok {
+		if err := unannouncer.Unannounce(l.context, chainId); err != nil {
+			log.Warnf("[LiquidNet] unannounce chain service failed, %s (chain-id: %s)", err.Error(), chainId)
+		}
+	}
+	if l.mdnsDiscovery != nil {
+		_ = l.mdnsDiscovery.Unannounce(l.context, chainId)
+	}
+	log.Infof("[LiquidNet] chain service unannounced. (chain-id: %s)", chainId)
+}
+
+// attachMdnsDiscovery announce and find the chain service in the local network.
+func (l *LiquidNet) attachMdnsDiscovery(ctx context.Context, chainId string) {
+	if err := l.mdnsDiscovery.Announce(ctx, chainId); err != nil {
+		log.Warnf("[LiquidNet] mdns announce failed, %s (chain-id: %s)", err.Error(), chainId)
+		return
+	}
+	findingC, err := l.mdnsDiscovery.FindPeers(ctx, chainId)
+	if err != nil {
+		log.Warnf("[LiquidNet] mdns find peers failed, %s (chain-id: %s)", err.Error(), chainId)
+		return
+	}
+	go l.listenFindingChanTask(findingC)
+	log.Infof("[LiquidNet] chain peers finding in local network... (chain-id: %s)", chainId)
+}
+
 // Stop the local net.
 func (l *LiquidNet) Stop() error {
 	l.lock.Lock()
 	defer l.lock.Unlock()
 	log.Infof("[LiquidNet] stopping...")
+	if l.startUp {
+		l.psMap.Range(func(key, _ interface{}) bool {
+			l.detachDiscovery(key.(string))
+			return true
+		})
+	}
 	l.startUp = false
+	if l.seedRefreshCancel != nil {
+		l.seedRefreshCancel()
+		l.seedRefreshCancel = nil
+	}
 
 	l.psMap.Range(func(key, value interface{}) bool {
 		_ = value.(broadcast.PubSub).Stop()
@@ -875,6 +1408,22 @@ func (l *LiquidNet) Stop() error {
 	if l.pktAdapter != nil {
 		l.pktAdapter.cancel()
 	}
+	if l.dht != nil {
+		_ = l.dht.Stop()
+		l.dht = nil
+	}
+	if l.mdnsDiscovery != nil {
+		_ = l.mdnsDiscovery.Close()
+		l.mdnsDiscovery = nil
+	}
+	if l.peerExchange != nil {
+		_ = l.peerExchange.Stop()
+		l.peerExchange = nil
+	}
+	if l.bootstrap != nil {
+		_ = l.bootstrap.Stop()
+		l.bootstrap = nil
+	}
 	err := l.host.Stop()
 	if err != nil {
 		log.Infof("[LiquidNet] [Stop] stop host error. err:%v", err)
@@ -888,27 +1437,32 @@ func (l *LiquidNet) Stop() error {
 // ChainNodesInfo return base node info list of chain which id is the given chainId.
 func (l *LiquidNet) ChainNodesInfo(chainId string) ([]*api.ChainNodeInfo, error) {
 	result := make([]*api.ChainNodeInfo, 0)
+	if l.host == nil {
+		return result, nil
+	}
 	if !l.hostCfg.Insecurity {
 		peerIds := make([]string, 0)
 		peerIds = append(peerIds, l.GetNodeUid())
-		peerIds = append(peerIds, l.peerIdChainIdsRecorder.PeerIdsOfChain(chainId)...)
+		peerIds = append(peerIds, l.peerIdsOfChain(chainId)...)
 		for _, peerId := range peerIds {
 			pid := peerId
 			addrs := make([]string, 0)
 			if pid == l.GetNodeUid() {
-				for _, addr := range l.host.LocalAddresses() {
+				for _, addr := range l.host.AnnounceAddrs() {
 					addrs = append(addrs, addr.String())
 				}
 			} else {
 				for _, addr := range l.host.PeerStore().GetAddrs(peer.ID(pid)) {
+					if !l.host.CanAnnounceAddr(addr) {
+						continue
+					}
 					addrs = append(addrs, addr.String())
 				}
 			}
-			cert := l.peerIdTlsCertStore.GetCertByPeerId(peerId)
 			result = append(result, &api.ChainNodeInfo{
 				NodeUid:     peerId,
 				NodeAddress: addrs,
-				NodeTlsCert: cert,
+				NodeTlsCert: l.peerBytesMetadata(peer.ID(peerId), metadataKeyTlsCert),
 			})
 		}
 	}
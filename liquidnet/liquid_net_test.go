@@ -0,0 +1,108 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package liquidnet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	"chainmaker.org/chainmaker/net-liquid/tlssupport"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiquidNetApplySeed(t *testing.T) {
+	var pid1, pid2 peer.ID = "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4",
+		"QmeyNRs2DwWjcHTpcVHoUSaDAAif4VQZ2wQDQAUNDP33gH"
+	addr1 := ma.StringCast("/ip4/10.0.0.1/tcp/11301/p2p/" + string(pid1))
+	addr2 := ma.StringCast("/ip4/10.0.0.2/tcp/11301/p2p/" + string(pid2))
+	addr3 := ma.StringCast("/ip4/10.0.0.3/tcp/11301/p2p/" + string(pid2))
+	l, err := NewLiquidNet()
+	require.Nil(t, err)
+
+	require.Nil(t, l.applySeed("/dnsaddr/seeds.example.com", []ma.Multiaddr{addr1, addr2}))
+	require.Nil(t, l.applySeed(addr1.String(), []ma.Multiaddr{addr1}))
+	require.Len(t, l.hostCfg.DirectPeers, 2)
+
+	// the seeds rotated in DNS records, the peer listed by other seeds kept
+	require.Nil(t, l.applySeed("/dnsaddr/seeds.example.com", []ma.Multiaddr{addr3}))
+	require.Len(t, l.hostCfg.DirectPeers, 2)
+	require.True(t, l.hostCfg.DirectPeers[pid1].Equal(addr1))
+	require.True(t, l.hostCfg.DirectPeers[pid2].Equal(addr3))
+	require.Empty(t, l.hostCfg.DirectPeerAddrs[pid2])
+
+	require.Nil(t, l.applySeed("/dnsaddr/seeds.example.com", nil))
+	require.Len(t, l.hostCfg.DirectPeers, 1)
+	require.NotNil(t, l.hostCfg.DirectPeers[pid1])
+}
+
+func TestLiquidNetPeerChainIds(t *testing.T) {
+	var pid1, pid2 peer.ID = "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4",
+		"QmeyNRs2DwWjcHTpcVHoUSaDAAif4VQZ2wQDQAUNDP33gH"
+	l, err := NewLiquidNet()
+	require.Nil(t, err)
+	l.host, _, _, err = tlssupport.CreateHostRandom(0, "127.0.0.1", nil, logger.NilLogger)
+	require.Nil(t, err)
+	l.registerMetadataKeys()
+
+	l.addPeerChainId(pid1, "chain1")
+	l.addPeerChainId(pid1, "chain2")
+	l.addPeerChainId(pid1, "chain1")
+	l.addPeerChainId(pid2, "chain2")
+	chainIds, ok := l.peerChainIds(pid1)
+	require.True(t, ok)
+	require.Equal(t, []string{"chain1", "chain2"}, chainIds)
+	require.True(t, l.isPeerBelongToChain(pid2, "chain2"))
+	require.False(t, l.isPeerBelongToChain(pid2, "chain1"))
+	require.ElementsMatch(t, []string{pid1.ToString(), pid2.ToString()}, l.peerIdsOfChain("chain2"))
+
+	// the peer removed from all chains is a peer of no chain
+	l.removePeerChainId(pid2, "chain2")
+	require.Equal(t, []string{pid1.ToString()}, l.peerIdsOfChain("chain2"))
+	require.Equal(t, []string{pid2.ToString()}, l.peerIdsOfNoChain())
+
+	// all records removed with the peer
+	l.removePeerRecords(pid1.ToString())
+	_, ok = l.peerChainIds(pid1)
+	require.False(t, ok)
+	require.Empty(t, l.peerIdsOfChain("chain1"))
+}
+
+func TestLiquidNetChainNodesInfoNotStarted(t *testing.T) {
+	l, err := NewLiquidNet()
+	require.Nil(t, err)
+	infos, err := l.ChainNodesInfo("chain1")
+	require.Nil(t, err)
+	require.Empty(t, infos)
+	l.queryAndStoreDerivedInfoInCertValidator("QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4")
+}
+
+func TestLiquidNetListenFindingChan(t *testing.T) {
+	var pid1, pid2 peer.ID = "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4",
+		"QmeyNRs2DwWjcHTpcVHoUSaDAAif4VQZ2wQDQAUNDP33gH"
+	InitLogger(logger.NilLogger, nil)
+	l, err := NewLiquidNet()
+	require.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l.context = ctx
+	l.host, _, _, err = tlssupport.CreateHostRandom(0, "127.0.0.1", nil, logger.NilLogger)
+	require.Nil(t, err)
+
+	// the address without net address will not be stored
+	findingC := make(chan ma.Multiaddr)
+	go l.listenFindingChanTask(findingC)
+	findingC <- ma.StringCast("/p2p/" + string(pid1))
+	findingC <- ma.StringCast("/ip4/127.0.0.1/tcp/1/p2p/" + string(pid2))
+	require.Eventually(t, func() bool {
+		return len(l.host.PeerStore().GetAddrs(pid2)) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Empty(t, l.host.PeerStore().GetAddrs(pid1))
+}
@@ -8,6 +8,7 @@ package pubsub
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -17,6 +18,9 @@ import (
 	api "chainmaker.org/chainmaker/protocol/v2"
 )
 
+// meshProtectTagPrefix is the prefix of tags used to protect the connections of FullMsg peers.
+const meshProtectTagPrefix = "pubsub-mesh:"
+
 // TypeOfPeering is the type of peering stat.
 type TypeOfPeering int
 
@@ -180,9 +184,56 @@ func rollFewPeerInPeerIdSet(rollSize int32, s *types.PeerIdSet) []peer.ID {
 	return res
 }
 
+// meshProtectTag return the tag used to protect the connections of FullMsg peers of this topic.
+func (m *topicPeeringMgr) meshProtectTag() string {
+	return meshProtectTagPrefix + m.ps.chainPubSub.chainId + "/" + m.topic
+}
+
+// protectMeshPeer protect the connections of FullMsg peer from being trimmed by connection manager.
+func (m *topicPeeringMgr) protectMeshPeer(pid peer.ID) {
+	if h := m.ps.chainPubSub.host; h != nil {
+		h.ConnMgr().Protect(pid, m.meshProtectTag())
+	}
+}
+
+// unprotectMeshPeer remove the protection placed by protectMeshPeer.
+func (m *topicPeeringMgr) unprotectMeshPeer(pid peer.ID) {
+	if h := m.ps.chainPubSub.host; h != nil {
+		h.ConnMgr().Unprotect(pid, m.meshProtectTag())
+	}
+}
+
+// lowLatencyPeersInPeerIdSet return few peers in peer id set, peers with lower EWMA latency first.
+// Peers whose latency unknown are placed after all peers whose latency known.
+func (m *topicPeeringMgr) lowLatencyPeersInPeerIdSet(size int32, s *types.PeerIdSet) []peer.ID {
+	h := m.ps.chainPubSub.host
+	if h == nil {
+		return rollFewPeerInPeerIdSet(size, s)
+	}
+	pids := make([]peer.ID, 0, s.Size())
+	latencies := make(map[peer.ID]time.Duration, s.Size())
+	s.Range(func(pid peer.ID) bool {
+		pids = append(pids, pid)
+		latencies[pid] = h.PeerStore().LatencyEWMA(pid)
+		return true
+	})
+	sort.SliceStable(pids, func(i, j int) bool {
+		li, lj := latencies[pids[i]], latencies[pids[j]]
+		if li == 0 || lj == 0 {
+			return lj == 0 && li != 0
+		}
+		return li < lj
+	})
+	if int32(len(pids)) > size {
+		pids = pids[:size]
+	}
+	return pids
+}
+
 // joinUp upgrade MetadataOnly peer to FullMsg peer
 func (m *topicPeeringMgr) joinUp(pid peer.ID) {
 	m.fullMsgPeer.Put(pid)
+	m.protectMeshPeer(pid)
 	m.metadataOnlyPeer.Remove(pid)
 	m.fanOutPeer.Remove(pid)
 }
@@ -216,6 +267,7 @@ func (m *topicPeeringMgr) cancelFanOut(pid peer.ID) {
 func (m *topicPeeringMgr) cutOff(pid peer.ID) {
 	m.metadataOnlyPeer.Put(pid)
 	m.fullMsgPeer.Remove(pid)
+	m.unprotectMeshPeer(pid)
 }
 
 // sendCutOffCtrl send a cut-off control message
@@ -253,7 +305,7 @@ func (m *topicPeeringMgr) fullMsgCheck(lock bool) {
 	case currentSize < m.degreeLow:
 		// join up some peers
 		joinUpSize := m.degreeDesired - currentSize
-		joinUpPeers := rollFewPeerInPeerIdSet(joinUpSize, m.metadataOnlyPeer)
+		joinUpPeers := m.lowLatencyPeersInPeerIdSet(joinUpSize, m.metadataOnlyPeer)
 		for i := range joinUpPeers {
 			pid := joinUpPeers[i]
 			if m.fullMsgPeer.Exist(pid) {
@@ -375,6 +427,7 @@ func (m *topicPeeringMgr) CleanPeer(pid peer.ID) {
 	m.fanOutPeer.Remove(pid)
 	m.fullMsgPeer.Remove(pid)
 	m.metadataOnlyPeer.Remove(pid)
+	m.unprotectMeshPeer(pid)
 	// check FullMsgPeering
 	m.fullMsgCheck(false)
 }
@@ -514,6 +567,16 @@ func (m *topicPeeringMgr) TargetFullMsgPeers() (*types.PeerIdSet, string) {
 	return typeSet, whichType
 }
 
+// UnprotectAllMeshPeers remove the protections placed on all FullMsg peers of this topic.
+func (m *topicPeeringMgr) UnprotectAllMeshPeers() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	m.fullMsgPeer.Range(func(pid peer.ID) bool {
+		m.unprotectMeshPeer(pid)
+		return true
+	})
+}
+
 // PublishAppMsg publish messages to the topic.
 func (m *topicPeeringMgr) PublishAppMsg(messages []*pb.ApplicationMsg) {
 	if len(messages) == 0 {
@@ -289,6 +289,10 @@ func (p *ChainPubSub) AttachHost(h host.Host) error {
 func (p *ChainPubSub) Stop() error {
 	p.msgBasket.Cancel()
 	p.gossip.stop()
+	p.topicPeeringMgrs.Range(func(_, v interface{}) bool {
+		v.(*topicPeeringMgr).UnprotectAllMeshPeers()
+		return true
+	})
 	return nil
 }
 
@@ -0,0 +1,82 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kaddht_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/host/hosttest"
+	"chainmaker.org/chainmaker/net-liquid/routing/kaddht"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentRouting(t *testing.T) {
+	// hosts[1] -- hosts[0] -- hosts[2], hosts[1] and hosts[2] are not connected directly
+	hosts := make([]host.Host, 3)
+	dhts := make([]*kaddht.KadDHT, 3)
+	for i := range hosts {
+		hosts[i] = hosttest.NewHost(t)
+		d, err := kaddht.NewKadDHT(hosts[i], kaddht.WithRefreshInterval(0), kaddht.WithProviderTTL(time.Minute))
+		require.Nil(t, err)
+		require.Nil(t, d.Start())
+		dhts[i] = d
+		t.Cleanup(func() {
+			_ = d.Stop()
+		})
+	}
+	center := hosttest.Addr(hosts[0])
+	for _, h := range hosts[1:] {
+		_, err := h.Dial(center)
+		require.Nil(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return dhts[0].RoutingTable().Contains(hosts[1].ID()) && dhts[0].RoutingTable().Contains(hosts[2].ID()) &&
+			dhts[1].RoutingTable().Contains(hosts[0].ID()) && dhts[2].RoutingTable().Contains(hosts[0].ID())
+	}, 5*time.Second, 50*time.Millisecond)
+
+	_, err := dhts[1].FindProviders(context.Background(), "")
+	require.Equal(t, kaddht.ErrEmptyKey, err)
+	require.Equal(t, kaddht.ErrEmptyKey, dhts[2].Provide(context.Background(), ""))
+
+	// hosts[2] holds a block, find it on hosts[1]
+	key := string([]byte{0x01, 0x02, 0xff, 0x00})
+	require.Nil(t, dhts[2].Provide(context.Background(), key))
+	findProviders := func() []ma.Multiaddr {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		c, e := dhts[1].FindProviders(ctx, key)
+		require.Nil(t, e)
+		found := make([]ma.Multiaddr, 0)
+		for addr := range c {
+			found = append(found, addr)
+		}
+		return found
+	}
+	require.Eventually(t, func() bool {
+		return len(findProviders()) == 1
+	}, 5*time.Second, 100*time.Millisecond)
+	_, pid := util.GetNetAddrAndPidFromNormalMultiAddr(findProviders()[0])
+	require.Equal(t, hosts[2].ID(), pid)
+	_, err = hosts[1].DialPeer(context.Background(), pid)
+	require.Nil(t, err)
+
+	// nothing provided for other keys
+	require.Empty(t, func() []ma.Multiaddr {
+		c, e := dhts[1].FindProviders(context.Background(), "snapshot-1")
+		require.Nil(t, e)
+		found := make([]ma.Multiaddr, 0)
+		for addr := range c {
+			found = append(found, addr)
+		}
+		return found
+	}())
+}
@@ -0,0 +1,760 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kaddht
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/routing"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	"chainmaker.org/chainmaker/net-liquid/routing/kaddht/pb"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// ProtocolID is the protocol.ID for kademlia DHT.
+	ProtocolID protocol.ID = "/kad-dht/v0.0.1"
+	// DefaultBucketSize is the default max count of peers in each k-bucket, also the count of the closest peers
+	// returned by each query.
+	DefaultBucketSize = 20
+	// DefaultAlpha is the default count of concurrent queries in each lookup.
+	DefaultAlpha = 3
+	// DefaultQueryTimeout is the default timeout of each query sent to a peer.
+	DefaultQueryTimeout = 10 * time.Second
+	// DefaultRefreshInterval is the default interval of refreshing the buckets.
+	DefaultRefreshInterval = 10 * time.Minute
+	// DefaultProviderTTL is the default ttl of provider records, the records provided will be
+	// republished every half of it.
+	DefaultProviderTTL = time.Hour
+
+	// maxRefreshCommonPrefixLen is the max common prefix length of the buckets refreshed.
+	maxRefreshCommonPrefixLen = 15
+)
+
+var (
+	// ErrNoDHTPeer will be returned if there is no peer in the routing table.
+	ErrNoDHTPeer = errors.New("no peer in dht routing table")
+	// ErrDHTUnsupported will be returned if the peer queried does not support dht protocol.
+	ErrDHTUnsupported = errors.New("peer does not support dht protocol")
+	// ErrPeerNotFound will be returned if no address of the peer found.
+	ErrPeerNotFound = errors.New("peer not found")
+	// ErrEmptyKey will be returned if the key provided or found is empty.
+	ErrEmptyKey = errors.New("empty key")
+)
+
+// Option is a function to apply properties for KadDHT.
+type Option func(*KadDHT) error
+
+func (d *KadDHT) applyOptions(opts ...Option) error {
+	for _, opt := range opts {
+		if err := opt(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithLogger set a logger.
+func WithLogger(logger api.Logger) Option {
+	return func(d *KadDHT) error {
+		d.logger = logger
+		return nil
+	}
+}
+
+// WithBucketSize set the max count of peers in each k-bucket.
+func WithBucketSize(k int) Option {
+	return func(d *KadDHT) error {
+		d.bucketSize = k
+		return nil
+	}
+}
+
+// WithAlpha set the count of concurrent queries in each lookup.
+func WithAlpha(alpha int) Option {
+	return func(d *KadDHT) error {
+		d.alpha = alpha
+		return nil
+	}
+}
+
+// WithQueryTimeout set a time.Duration as timeout of each query sent to a peer.
+func WithQueryTimeout(timeout time.Duration) Option {
+	return func(d *KadDHT) error {
+		d.queryTimeout = timeout
+		return nil
+	}
+}
+
+// WithRefreshInterval set a time.Duration as interval of refreshing the buckets.
+// If it is not greater than 0, the buckets will not be refreshed in background.
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(d *KadDHT) error {
+		d.refreshInterval = interval
+		return nil
+	}
+}
+
+// WithProviderTTL set a time.Duration as ttl of provider records, which will be sent with the records provided
+// by myself, and be used for the records received without ttl. It is limited by MaxProviderTTL.
+func WithProviderTTL(ttl time.Duration) Option {
+	return func(d *KadDHT) error {
+		d.providerTTL = ttl
+		return nil
+	}
+}
+
+type dhtWaiter struct {
+	pid       peer.ID
+	responseC chan *pb.DHTMsg
+}
+
+var _ routing.Routing = (*KadDHT)(nil)
+var _ routing.ContentRouting = (*KadDHT)(nil)
+
+// KadDHT is a kademlia-style DHT built on host.Host, providing peer routing, protocol routing and content routing.
+// Peers are identified by the sha256 digest of their ids in the key space and are grouped into k-buckets by the
+// XOR distance to the local peer. The routing table is populated with the peers supporting DHT protocol once
+// connected, no matter whether they are seeds, peers discovered or peers dialing in.
+// Peers are located with iterative FIND_NODE queries, and the providers of a protocol, a service or any content
+// are found with the provider records stored on the peers closest to the key of it.
+type KadDHT struct {
+	host     host.Host
+	localKey Key
+	table    *RoutingTable
+
+	seq       uint64
+	waiters   sync.Map // map[uint64]*dhtWaiter
+	providers *providerStore
+	provided  sync.Map // map[string]struct{}, stores keys provided by myself
+
+	bucketSize      int
+	alpha           int
+	queryTimeout    time.Duration
+	refreshInterval time.Duration
+	providerTTL     time.Duration
+
+	closeC chan struct{}
+	once   sync.Once
+
+	logger api.Logger
+}
+
+// NewKadDHT create a new KadDHT instance.
+func NewKadDHT(h host.Host, opts ...Option) (*KadDHT, error) {
+	d := &KadDHT{
+		host:            h,
+		localKey:        KeyForPeer(h.ID()),
+		bucketSize:      DefaultBucketSize,
+		alpha:           DefaultAlpha,
+		queryTimeout:    DefaultQueryTimeout,
+		refreshInterval: DefaultRefreshInterval,
+		providerTTL:     DefaultProviderTTL,
+		logger:          logger.NilLogger,
+	}
+	if err := d.applyOptions(opts...); err != nil {
+		return nil, err
+	}
+	if d.bucketSize <= 0 {
+		d.bucketSize = DefaultBucketSize
+	}
+	if d.alpha <= 0 {
+		d.alpha = DefaultAlpha
+	}
+	if d.providerTTL <= 0 {
+		d.providerTTL = DefaultProviderTTL
+	}
+	if d.providerTTL > MaxProviderTTL {
+		d.providerTTL = MaxProviderTTL
+	}
+	d.table = NewRoutingTable(h.ID(), d.bucketSize, func(pid peer.ID) bool {
+		// the least recently seen peer will be replaced only if it is not connected
+		return !h.ConnMgr().IsConnected(pid)
+	})
+	d.providers = newProviderStore(d.providerTTL)
+	h.Notify(&host.NotifieeBundle{
+		PeerProtocolSupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
+			if protocolID == ProtocolID {
+				d.table.Update(pid)
+			}
+		},
+		PeerProtocolUnsupportedFunc: func(protocolID protocol.ID, pid peer.ID) {
+			if protocolID == ProtocolID {
+				d.table.Remove(pid)
+			}
+		},
+	})
+	return d, nil
+}
+
+// RoutingTable return the routing table of DHT.
+func (d *KadDHT) RoutingTable() *RoutingTable {
+	return d.table
+}
+
+func (d *KadDHT) handleMsg(senderPID peer.ID, msgPayload []byte) {
+	msg := &pb.DHTMsg{}
+	err := proto.Unmarshal(msgPayload, msg)
+	if err != nil {
+		d.logger.Errorf("[KadDHT] unmarshal dht msg failed, %s (sender id: %s)", err.Error(), senderPID)
+		return
+	}
+	switch msg.Type {
+	case pb.DHTMsg_FindNodeRes, pb.DHTMsg_GetProvidersRes:
+		v, ok := d.waiters.Load(msg.Seq)
+		if !ok {
+			return
+		}
+		w, _ := v.(*dhtWaiter)
+		if w.pid != senderPID {
+			d.logger.Warnf("[KadDHT] response sender mismatch, (sender id: %s, expected: %s)", senderPID, w.pid)
+			return
+		}
+		select {
+		case w.responseC <- msg:
+		default:
+		}
+		return
+	}
+	if len(msg.Key) != sha256.Size {
+		d.logger.Warnf("[KadDHT] invalid key length %d (sender id: %s)", len(msg.Key), senderPID)
+		return
+	}
+	// the sender supports dht protocol obviously
+	d.table.Update(senderPID)
+	switch msg.Type {
+	case pb.DHTMsg_FindNodeReq:
+		d.sendResponse(senderPID, &pb.DHTMsg{
+			Type:        pb.DHTMsg_FindNodeRes,
+			Seq:         msg.Seq,
+			CloserPeers: d.closerPeerInfos(msg.Key, senderPID),
+		})
+	case pb.DHTMsg_GetProvidersReq:
+		records := d.providers.get(msg.Key)
+		providers := make([]*pb.PeerInfo, 0, len(records))
+		for _, r := range records {
+			providers = append(providers, &pb.PeerInfo{Pid: r.pid.ToString(), Addrs: addrsToStrings(r.addrs)})
+		}
+		d.sendResponse(senderPID, &pb.DHTMsg{
+			Type:        pb.DHTMsg_GetProvidersRes,
+			Seq:         msg.Seq,
+			CloserPeers: d.closerPeerInfos(msg.Key, senderPID),
+			Providers:   providers,
+		})
+	case pb.DHTMsg_AddProvider:
+		for _, info := range msg.Providers {
+			// peers could only provide for themselves
+			if peer.ID(info.Pid) != senderPID {
+				continue
+			}
+			d.providers.add(msg.Key, senderPID, stringsToAddrs(info.Addrs), providerTTL(msg.Ttl))
+		}
+	default:
+		d.logger.Warnf("[KadDHT] unknown dht msg type %s (sender id: %s)", msg.Type.String(), senderPID)
+	}
+}
+
+func (d *KadDHT) sendResponse(receiver peer.ID, msg *pb.DHTMsg) {
+	msgBytes, err := proto.Marshal(msg)
+	if err != nil {
+		d.logger.Errorf("[KadDHT] marshal dht msg failed, %s", err.Error())
+		return
+	}
+	if err = d.host.SendMsg(ProtocolID, receiver, msgBytes); err != nil {
+		d.logger.Debugf("[KadDHT] send response msg failed, %s (remote pid: %s)", err.Error(), receiver)
+	}
+}
+
+// closerPeerInfos return the infos of peers in the routing table closest to the key, except the requester.
+func (d *KadDHT) closerPeerInfos(key Key, requester peer.ID) []*pb.PeerInfo {
+	pids := d.table.NearestPeers(key, d.bucketSize+1)
+	infos := make([]*pb.PeerInfo, 0, len(pids))
+	for _, pid := range pids {
+		if pid == requester || len(infos) >= d.bucketSize {
+			continue
+		}
+		addrs := d.dialableAddrs(pid)
+		if len(addrs) == 0 && !bytes.Equal(KeyForPeer(pid), key) {
+			// useless for requester unless it is the peer looked up
+			continue
+		}
+		infos = append(infos, &pb.PeerInfo{Pid: pid.ToString(), Addrs: addrsToStrings(addrs)})
+	}
+	return infos
+}
+
+// dialableAddrs return the net addresses of peer stored that could be told to others, except the remote
+// addresses of inbound connections whose remote port is ephemeral.
+func (d *KadDHT) dialableAddrs(pid peer.ID) []ma.Multiaddr {
+	infos := d.host.PeerStore().AddrInfos(pid)
+	addrs := make([]ma.Multiaddr, 0, len(infos))
+	for i := range infos {
+		if infos[i].Source != store.AddrSourceInbound && d.host.CanAnnounceAddr(infos[i].Addr) {
+			addrs = append(addrs, infos[i].Addr)
+		}
+	}
+	return addrs
+}
+
+// storePeerInfo record the addresses of the peer info received, then return the peer id.
+// Empty peer id will be returned if it is myself.
+func (d *KadDHT) storePeerInfo(info *pb.PeerInfo) peer.ID {
+	pid := peer.ID(info.Pid)
+	if pid == "" || pid == d.host.ID() {
+		return ""
+	}
+	addrs := stringsToAddrs(info.Addrs)
+	if len(addrs) > maxProviderAddrs {
+		addrs = addrs[:maxProviderAddrs]
+	}
+	if len(addrs) > 0 {
+		d.host.PeerStore().AddAddrWithTTL(pid, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, addrs...)
+	}
+	return pid
+}
+
+// query send a request to the peer given and wait for the response.
+// If the peer is not connected, it will be dialed with the addresses stored in PeerStore.
+func (d *KadDHT) query(ctx context.Context, pid peer.ID, reqType pb.DHTMsg_Type, key Key) (*pb.DHTMsg, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.queryTimeout)
+	defer cancel()
+	if !d.host.ConnMgr().IsConnected(pid) {
+		if _, err := d.host.DialPeer(ctx, pid); err != nil {
+			return nil, err
+		}
+	}
+	if !d.host.IsPeerSupportProtocol(pid, ProtocolID) {
+		return nil, ErrDHTUnsupported
+	}
+	seq := atomic.AddUint64(&d.seq, 1)
+	msgBytes, err := proto.Marshal(&pb.DHTMsg{Type: reqType, Seq: seq, Key: key})
+	if err != nil {
+		return nil, err
+	}
+	w := &dhtWaiter{pid: pid, responseC: make(chan *pb.DHTMsg, 1)}
+	d.waiters.Store(seq, w)
+	defer d.waiters.Delete(seq)
+	if err = d.host.SendMsg(ProtocolID, pid, msgBytes); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-w.responseC:
+		return res, nil
+	}
+}
+
+const (
+	lookupPending = iota
+	lookupQuerying
+	lookupSucceeded
+	lookupFailed
+)
+
+type lookupResult struct {
+	pid peer.ID
+	res *pb.DHTMsg
+	err error
+}
+
+// lookup run an iterative query for the key given. In each round, at most alpha peers closest to the key not
+// queried yet are queried, and the closer peers responded are merged into the candidates, until all the
+// closest bucket-size candidates have been queried. If handleRes returns true, the lookup stops immediately.
+// The peers closest to the key that responded will be returned.
+func (d *KadDHT) lookup(ctx context.Context, key Key, reqType pb.DHTMsg_Type,
+	handleRes func(from peer.ID, res *pb.DHTMsg) bool) ([]peer.ID, error) {
+	seeds := d.table.NearestPeers(key, d.bucketSize)
+	if len(seeds) == 0 {
+		return nil, ErrNoDHTPeer
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	states := make(map[peer.ID]int)
+	candidates := make([]*tableEntry, 0, len(seeds))
+	addCandidate := func(pid peer.ID) {
+		if _, ok := states[pid]; ok {
+			return
+		}
+		states[pid] = lookupPending
+		candidates = append(candidates, &tableEntry{pid: pid, key: KeyForPeer(pid)})
+	}
+	for _, pid := range seeds {
+		addCandidate(pid)
+	}
+
+	// the buffer is large enough for all queries running, so that they never block after lookup returned
+	resultC := make(chan *lookupResult, d.alpha)
+	inflight := 0
+LOOP:
+	for {
+		sort.Slice(candidates, func(i, j int) bool {
+			return closerTo(key, candidates[i].key, candidates[j].key)
+		})
+		examined := 0
+		for _, c := range candidates {
+			if inflight >= d.alpha || examined >= d.bucketSize {
+				break
+			}
+			if states[c.pid] == lookupFailed {
+				continue
+			}
+			examined++
+			if states[c.pid] != lookupPending {
+				continue
+			}
+			states[c.pid] = lookupQuerying
+			inflight++
+			go func(pid peer.ID) {
+				res, err := d.query(ctx, pid, reqType, key)
+				resultC <- &lookupResult{pid: pid, res: res, err: err}
+			}(c.pid)
+		}
+		if inflight == 0 {
+			break
+		}
+		var r *lookupResult
+		select {
+		case <-ctx.Done():
+			break LOOP
+		case r = <-resultC:
+		}
+		inflight--
+		if r.err != nil {
+			d.logger.Debugf("[KadDHT] query failed, %s (remote pid: %s)", r.err.Error(), r.pid)
+			states[r.pid] = lookupFailed
+			if !d.host.ConnMgr().IsConnected(r.pid) {
+				d.table.Remove(r.pid)
+			}
+			continue
+		}
+		states[r.pid] = lookupSucceeded
+		d.table.Update(r.pid)
+		closerPeers := r.res.CloserPeers
+		if len(closerPeers) > d.bucketSize {
+			closerPeers = closerPeers[:d.bucketSize]
+		}
+		for _, info := range closerPeers {
+			if pid := d.storePeerInfo(info); pid != "" {
+				addCandidate(pid)
+			}
+		}
+		if handleRes != nil && handleRes(r.pid, r.res) {
+			break
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return closerTo(key, candidates[i].key, candidates[j].key)
+	})
+	closest := make([]peer.ID, 0, d.bucketSize)
+	for _, c := range candidates {
+		if len(closest) >= d.bucketSize {
+			break
+		}
+		if states[c.pid] == lookupSucceeded {
+			closest = append(closest, c.pid)
+		}
+	}
+	return closest, nil
+}
+
+// FindPeer locate the peer whose id is the given in DHT, then the addresses found will be stored in PeerStore.
+// If the peer is connected or any address of it is stored already, return immediately.
+// The service is ignored, peers are located in the whole DHT. If timeout is greater than 0, the lookup
+// will be canceled after it.
+func (d *KadDHT) FindPeer(ctx context.Context, _ string, targetPeerId peer.ID,
+	timeout time.Duration) (peer.ID, error) {
+	if d.found(targetPeerId) {
+		return targetPeerId, nil
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	_, err := d.lookup(ctx, KeyForPeer(targetPeerId), pb.DHTMsg_FindNodeReq, func(_ peer.ID, _ *pb.DHTMsg) bool {
+		return d.found(targetPeerId)
+	})
+	if err != nil {
+		return "", err
+	}
+	if !d.found(targetPeerId) {
+		return "", ErrPeerNotFound
+	}
+	d.logger.Debugf("[KadDHT] peer found. (pid: %s)", targetPeerId)
+	return targetPeerId, nil
+}
+
+func (d *KadDHT) found(pid peer.ID) bool {
+	return pid == d.host.ID() || d.host.ConnMgr().IsConnected(pid) || d.host.PeerStore().GetFirstAddr(pid) != nil
+}
+
+// Provide tell the peers closest to the key given that I am a provider of it, where the key is
+// a protocol id, a service name or any content key, e.g. the hash of a block.
+// The provider records will expire after the provider ttl, and be republished every half of it
+// until StopProviding called or DHT stopped.
+func (d *KadDHT) Provide(ctx context.Context, key string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	d.provided.Store(key, struct{}{})
+	return d.provide(ctx, key)
+}
+
+// StopProviding stop republishing the provider records of the key given,
+// the records published will expire after the provider ttl.
+func (d *KadDHT) StopProviding(key string) {
+	d.provided.Delete(key)
+	d.providers.remove(KeyForString(key), d.host.ID())
+}
+
+func (d *KadDHT) provide(ctx context.Context, name string) error {
+	key := KeyForString(name)
+	selfAddrs := d.host.AnnounceAddrs()
+	d.providers.add(key, d.host.ID(), selfAddrs, d.providerTTL)
+	closest, err := d.lookup(ctx, key, pb.DHTMsg_FindNodeReq, nil)
+	if err != nil {
+		return err
+	}
+	msgBytes, err := proto.Marshal(&pb.DHTMsg{
+		Type: pb.DHTMsg_AddProvider,
+		Key:  key,
+		Providers: []*pb.PeerInfo{
+			{Pid: d.host.ID().ToString(), Addrs: addrsToStrings(selfAddrs)},
+		},
+		Ttl: int64(d.providerTTL / time.Second),
+	})
+	if err != nil {
+		return err
+	}
+	for _, pid := range closest {
+		if e := d.host.SendMsg(ProtocolID, pid, msgBytes); e != nil {
+			d.logger.Debugf("[KadDHT] send add provider msg failed, %s (remote pid: %s)", e.Error(), pid)
+		}
+	}
+	return nil
+}
+
+// findProviders find the providers of the key given, each of them will be passed to handle once.
+// The records stored locally will be handled first. If handle returns true, the finding stops.
+func (d *KadDHT) findProviders(ctx context.Context, key Key, handle func(pid peer.ID) bool) error {
+	seen := make(map[peer.ID]struct{})
+	handleOnce := func(pid peer.ID) bool {
+		if _, ok := seen[pid]; ok {
+			return false
+		}
+		seen[pid] = struct{}{}
+		return handle(pid)
+	}
+	for _, r := range d.providers.get(key) {
+		if r.pid == d.host.ID() {
+			continue
+		}
+		if len(r.addrs) > 0 {
+			d.host.PeerStore().AddAddrWithTTL(r.pid, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, r.addrs...)
+		}
+		if handleOnce(r.pid) {
+			return nil
+		}
+	}
+	_, err := d.lookup(ctx, key, pb.DHTMsg_GetProvidersReq, func(_ peer.ID, res *pb.DHTMsg) bool {
+		for _, info := range res.Providers {
+			pid := d.storePeerInfo(info)
+			if pid == "" {
+				continue
+			}
+			if handleOnce(pid) {
+				return true
+			}
+		}
+		return false
+	})
+	return err
+}
+
+// FindProviders find the providers of the key given in DHT except myself, and push the addresses of them to
+// the chan returned, which will be closed when the lookup finished or ctx done.
+// The providers whose addresses unknown will be ignored.
+func (d *KadDHT) FindProviders(ctx context.Context, key string) (<-chan ma.Multiaddr, error) {
+	if key == "" {
+		return nil, ErrEmptyKey
+	}
+	c := make(chan ma.Multiaddr)
+	go func() {
+		defer close(c)
+		err := d.findProviders(ctx, KeyForString(key), func(pid peer.ID) bool {
+			addr := d.host.PeerStore().GetFirstAddr(pid)
+			if addr == nil {
+				return false
+			}
+			select {
+			case <-ctx.Done():
+				return true
+			case c <- util.CreateMultiAddrWithPidAndNetAddr(pid, addr):
+				return false
+			}
+		})
+		if err != nil {
+			d.logger.Debugf("[KadDHT] find providers failed, %s (key: %s)", err.Error(), key)
+		}
+	}()
+	return c, nil
+}
+
+// FindPeerSupportProtocolsAsync find the peers providing all the protocols given in DHT, and push the addresses
+// of them to the chan returned, which will be closed when finding finished. If limit is greater than 0, at most
+// limit addresses will be pushed.
+func (d *KadDHT) FindPeerSupportProtocolsAsync(ctx context.Context, limit int,
+	protocolIDs ...protocol.ID) <-chan ma.Multiaddr {
+	c := make(chan ma.Multiaddr)
+	go func() {
+		defer close(c)
+		if len(protocolIDs) == 0 {
+			return
+		}
+		counts := make(map[peer.ID]int)
+		pushed := 0
+		for _, protocolID := range protocolIDs {
+			err := d.findProviders(ctx, KeyForString(string(protocolID)), func(pid peer.ID) bool {
+				counts[pid]++
+				if counts[pid] < len(protocolIDs) {
+					return false
+				}
+				addr := d.host.PeerStore().GetFirstAddr(pid)
+				if addr == nil {
+					return false
+				}
+				select {
+				case <-ctx.Done():
+					return true
+				case c <- util.CreateMultiAddrWithPidAndNetAddr(pid, addr):
+					pushed++
+				}
+				return limit > 0 && pushed >= limit
+			})
+			if err != nil {
+				d.logger.Debugf("[KadDHT] find providers failed, %s (protocol: %s)", err.Error(), protocolID)
+				return
+			}
+			if ctx.Err() != nil || (limit > 0 && pushed >= limit) {
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// Refresh look up the local key to find the closest neighbours, then look up a random key in each bucket
+// to populate the routing table.
+func (d *KadDHT) Refresh(ctx context.Context) error {
+	if _, err := d.lookup(ctx, d.localKey, pb.DHTMsg_FindNodeReq, nil); err != nil {
+		return err
+	}
+	maxCpl := d.table.MaxCommonPrefixLen()
+	if maxCpl > maxRefreshCommonPrefixLen {
+		maxCpl = maxRefreshCommonPrefixLen
+	}
+	for cpl := 0; cpl <= maxCpl; cpl++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		_, _ = d.lookup(ctx, randomKeyWithPrefixLen(d.localKey, cpl), pb.DHTMsg_FindNodeReq, nil)
+	}
+	return nil
+}
+
+// Start register DHT protocol to host, then start the refresh loop.
+func (d *KadDHT) Start() error {
+	if err := d.host.RegisterMsgPayloadHandler(ProtocolID, d.handleMsg); err != nil {
+		return err
+	}
+	// peers connected already
+	for _, pid := range d.host.PeerStore().AllSupportProtocolPeers(ProtocolID) {
+		d.table.Update(pid)
+	}
+	d.once.Do(func() {
+		d.closeC = make(chan struct{})
+		go d.refreshLoop(d.closeC)
+	})
+	return nil
+}
+
+// Stop the refresh loop, then unregister DHT protocol.
+func (d *KadDHT) Stop() error {
+	if d.closeC != nil {
+		close(d.closeC)
+		d.closeC = nil
+		d.once = sync.Once{}
+	}
+	return d.host.UnregisterMsgPayloadHandler(ProtocolID)
+}
+
+func (d *KadDHT) refreshLoop(closeC chan struct{}) {
+	var refreshC <-chan time.Time
+	if d.refreshInterval > 0 {
+		refreshTicker := time.NewTicker(d.refreshInterval)
+		defer refreshTicker.Stop()
+		refreshC = refreshTicker.C
+	}
+	republishTicker := time.NewTicker(d.providerTTL / 2)
+	defer republishTicker.Stop()
+	for {
+		select {
+		case <-closeC:
+			return
+		case <-refreshC:
+			if err := d.Refresh(d.host.Context()); err != nil {
+				d.logger.Debugf("[KadDHT] refresh failed, %s", err.Error())
+			}
+		case <-republishTicker.C:
+			d.providers.gc()
+			d.provided.Range(func(key, _ interface{}) bool {
+				name, _ := key.(string)
+				if err := d.provide(d.host.Context(), name); err != nil {
+					d.logger.Debugf("[KadDHT] republish provider record failed, %s (name: %s)", err.Error(), name)
+				}
+				return true
+			})
+		}
+	}
+}
+
+func addrsToStrings(addrs []ma.Multiaddr) []string {
+	res := make([]string, 0, len(addrs))
+	for i := range addrs {
+		res = append(res, addrs[i].String())
+	}
+	return res
+}
+
+func stringsToAddrs(strs []string) []ma.Multiaddr {
+	res := make([]ma.Multiaddr, 0, len(strs))
+	for i := range strs {
+		addr, err := ma.NewMultiaddr(strs[i])
+		if err != nil {
+			continue
+		}
+		res = append(res, addr)
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kaddht_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/host/hosttest"
+	"chainmaker.org/chainmaker/net-liquid/routing/kaddht"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKadDHT(t *testing.T) {
+	// hosts[1] -- hosts[0] -- hosts[2], hosts[1] and hosts[2] are not connected directly
+	hosts := make([]host.Host, 3)
+	dhts := make([]*kaddht.KadDHT, 3)
+	for i := range hosts {
+		hosts[i] = hosttest.NewHost(t)
+		d, err := kaddht.NewKadDHT(hosts[i], kaddht.WithRefreshInterval(0))
+		require.Nil(t, err)
+		require.Nil(t, d.Start())
+		dhts[i] = d
+		t.Cleanup(func() {
+			_ = d.Stop()
+		})
+	}
+	src, dst := hosts[1], hosts[2]
+
+	// no peer in routing table yet
+	_, err := dhts[1].FindPeer(context.Background(), "", dst.ID(), time.Second)
+	require.Equal(t, kaddht.ErrNoDHTPeer, err)
+
+	center := hosttest.Addr(hosts[0])
+	for _, h := range []host.Host{src, dst} {
+		_, err = h.Dial(center)
+		require.Nil(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return dhts[0].RoutingTable().Contains(src.ID()) && dhts[0].RoutingTable().Contains(dst.ID()) &&
+			dhts[1].RoutingTable().Contains(hosts[0].ID()) && dhts[2].RoutingTable().Contains(hosts[0].ID())
+	}, 5*time.Second, 50*time.Millisecond)
+
+	// locate hosts[2] through hosts[0]
+	require.Nil(t, src.PeerStore().GetFirstAddr(dst.ID()))
+	pid, err := dhts[1].FindPeer(context.Background(), "", dst.ID(), 5*time.Second)
+	require.Nil(t, err)
+	require.Equal(t, dst.ID(), pid)
+	require.NotNil(t, src.PeerStore().GetFirstAddr(dst.ID()))
+	_, err = src.DialPeer(context.Background(), dst.ID())
+	require.Nil(t, err)
+
+	// provide a protocol on hosts[2], then find it on hosts[1]
+	var testProtocol protocol.ID = "/dht-test/v0.0.1"
+	require.Nil(t, dhts[2].Provide(context.Background(), string(testProtocol)))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	found := make([]ma.Multiaddr, 0)
+	for addr := range dhts[1].FindPeerSupportProtocolsAsync(ctx, 1, testProtocol) {
+		found = append(found, addr)
+	}
+	require.Len(t, found, 1)
+	_, foundPid := util.GetNetAddrAndPidFromNormalMultiAddr(found[0])
+	require.Equal(t, dst.ID(), foundPid)
+
+	// peer unknown
+	_, err = dhts[1].FindPeer(context.Background(), "", peer.ID("unknown"), 5*time.Second)
+	require.Equal(t, kaddht.ErrPeerNotFound, err)
+}
@@ -0,0 +1,818 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: dht_msg.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type DHTMsg_Type int32
+
+const (
+	DHTMsg_FindNodeReq     DHTMsg_Type = 0
+	DHTMsg_FindNodeRes     DHTMsg_Type = 1
+	DHTMsg_AddProvider     DHTMsg_Type = 2
+	DHTMsg_GetProvidersReq DHTMsg_Type = 3
+	DHTMsg_GetProvidersRes DHTMsg_Type = 4
+)
+
+var DHTMsg_Type_name = map[int32]string{
+	0: "FindNodeReq",
+	1: "FindNodeRes",
+	2: "AddProvider",
+	3: "GetProvidersReq",
+	4: "GetProvidersRes",
+}
+
+var DHTMsg_Type_value = map[string]int32{
+	"FindNodeReq":     0,
+	"FindNodeRes":     1,
+	"AddProvider":     2,
+	"GetProvidersReq": 3,
+	"GetProvidersRes": 4,
+}
+
+func (x DHTMsg_Type) String() string {
+	return proto.EnumName(DHTMsg_Type_name, int32(x))
+}
+
+func (DHTMsg_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_42b7db5948b307e1, []int{0, 0}
+}
+
+type DHTMsg struct {
+	Type        DHTMsg_Type `protobuf:"varint,1,opt,name=type,proto3,enum=kaddht.DHTMsg_Type" json:"type,omitempty"`
+	Seq         uint64      `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Key         []byte      `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	CloserPeers []*PeerInfo `protobuf:"bytes,4,rep,name=closerPeers,proto3" json:"closerPeers,omitempty"`
+	Providers   []*PeerInfo `protobuf:"bytes,5,rep,name=providers,proto3" json:"providers,omitempty"`
+	Ttl         int64       `protobuf:"varint,6,opt,name=ttl,proto3" json:"ttl,omitempty"`
+}
+
+func (m *DHTMsg) Reset()         { *m = DHTMsg{} }
+func (m *DHTMsg) String() string { return proto.CompactTextString(m) }
+func (*DHTMsg) ProtoMessage()    {}
+func (*DHTMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_42b7db5948b307e1, []int{0}
+}
+func (m *DHTMsg) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DHTMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DHTMsg.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DHTMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DHTMsg.Merge(m, src)
+}
+func (m *DHTMsg) XXX_Size() int {
+	return m.Size()
+}
+func (m *DHTMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_DHTMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DHTMsg proto.InternalMessageInfo
+
+func (m *DHTMsg) GetType() DHTMsg_Type {
+	if m != nil {
+		return m.Type
+	}
+	return DHTMsg_FindNodeReq
+}
+
+func (m *DHTMsg) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *DHTMsg) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *DHTMsg) GetCloserPeers() []*PeerInfo {
+	if m != nil {
+		return m.CloserPeers
+	}
+	return nil
+}
+
+func (m *DHTMsg) GetProviders() []*PeerInfo {
+	if m != nil {
+		return m.Providers
+	}
+	return nil
+}
+
+func (m *DHTMsg) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+type PeerInfo struct {
+	Pid   string   `protobuf:"bytes,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Addrs []string `protobuf:"bytes,2,rep,name=addrs,proto3" json:"addrs,omitempty"`
+}
+
+func (m *PeerInfo) Reset()         { *m = PeerInfo{} }
+func (m *PeerInfo) String() string { return proto.CompactTextString(m) }
+func (*PeerInfo) ProtoMessage()    {}
+func (*PeerInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_42b7db5948b307e1, []int{1}
+}
+func (m *PeerInfo) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PeerInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PeerInfo.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PeerInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerInfo.Merge(m, src)
+}
+func (m *PeerInfo) XXX_Size() int {
+	return m.Size()
+}
+func (m *PeerInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerInfo proto.InternalMessageInfo
+
+func (m *PeerInfo) GetPid() string {
+	if m != nil {
+		return m.Pid
+	}
+	return ""
+}
+
+func (m *PeerInfo) GetAddrs() []string {
+	if m != nil {
+		return m.Addrs
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("kaddht.DHTMsg_Type", DHTMsg_Type_name, DHTMsg_Type_value)
+	proto.RegisterType((*DHTMsg)(nil), "kaddht.DHTMsg")
+	proto.RegisterType((*PeerInfo)(nil), "kaddht.PeerInfo")
+}
+
+func init() { proto.RegisterFile("dht_msg.proto", fileDescriptor_42b7db5948b307e1) }
+
+var fileDescriptor_42b7db5948b307e1 = []byte{
+	// 343 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0x3f, 0x4e, 0xc3, 0x30,
+	0x18, 0xc5, 0xe3, 0x24, 0x8d, 0xa8, 0x0b, 0x34, 0x72, 0x19, 0x32, 0x45, 0x51, 0x17, 0xb2, 0x10,
+	0x4b, 0xe1, 0x02, 0x80, 0x10, 0x7f, 0x06, 0x50, 0x65, 0x75, 0xea, 0x82, 0xd2, 0xda, 0xa4, 0x56,
+	0xd3, 0x38, 0xb5, 0x5d, 0xa4, 0xde, 0x82, 0xa3, 0x70, 0x0c, 0xc6, 0x8e, 0x8c, 0xa8, 0xbd, 0x08,
+	0x72, 0x43, 0x54, 0x10, 0x62, 0x7b, 0xdf, 0x2f, 0xef, 0x7d, 0x79, 0xfa, 0x0c, 0x8f, 0xe8, 0x54,
+	0x3f, 0xcd, 0x55, 0x9e, 0x54, 0x52, 0x68, 0x81, 0xbc, 0x59, 0x46, 0xe9, 0x54, 0xf7, 0xdf, 0x6c,
+	0xe8, 0x5d, 0xdf, 0x0d, 0x1f, 0x54, 0x8e, 0x4e, 0xa1, 0xab, 0x57, 0x15, 0x0b, 0x40, 0x04, 0xe2,
+	0xe3, 0xb4, 0x97, 0xd4, 0x8e, 0xa4, 0xfe, 0x9a, 0x0c, 0x57, 0x15, 0x23, 0x3b, 0x03, 0xf2, 0xa1,
+	0xa3, 0xd8, 0x22, 0xb0, 0x23, 0x10, 0xbb, 0xc4, 0x48, 0x43, 0x66, 0x6c, 0x15, 0x38, 0x11, 0x88,
+	0x0f, 0x89, 0x91, 0x28, 0x85, 0x9d, 0x49, 0x21, 0x14, 0x93, 0x03, 0xc6, 0xa4, 0x0a, 0xdc, 0xc8,
+	0x89, 0x3b, 0xa9, 0xdf, 0xec, 0x34, 0xf0, 0xbe, 0x7c, 0x16, 0xe4, 0xa7, 0x09, 0x25, 0xb0, 0x5d,
+	0x49, 0xf1, 0xc2, 0xa9, 0x49, 0xb4, 0xfe, 0x49, 0xec, 0x2d, 0xe6, 0xaf, 0x5a, 0x17, 0x81, 0x17,
+	0x81, 0xd8, 0x21, 0x46, 0xf6, 0x27, 0xd0, 0x35, 0x3d, 0x51, 0x17, 0x76, 0x6e, 0x78, 0x49, 0x1f,
+	0x05, 0x65, 0x84, 0x2d, 0x7c, 0xeb, 0x37, 0x50, 0x3e, 0x30, 0xe0, 0x92, 0xd2, 0xc1, 0xf7, 0x2e,
+	0xdf, 0x46, 0x3d, 0xd8, 0xbd, 0x65, 0xba, 0x01, 0xca, 0xc4, 0x9c, 0xbf, 0x50, 0xf9, 0x6e, 0x3f,
+	0x85, 0x07, 0x4d, 0x1b, 0x53, 0xa1, 0xe2, 0x74, 0x77, 0xb2, 0x36, 0x31, 0x12, 0x9d, 0xc0, 0x56,
+	0x46, 0xa9, 0x54, 0x81, 0x1d, 0x39, 0x71, 0x9b, 0xd4, 0xc3, 0xd5, 0xe8, 0x7d, 0x13, 0x82, 0xf5,
+	0x26, 0x04, 0x9f, 0x9b, 0x10, 0xbc, 0x6e, 0x43, 0x6b, 0xbd, 0x0d, 0xad, 0x8f, 0x6d, 0x68, 0x8d,
+	0x2e, 0x26, 0xd3, 0x8c, 0x97, 0xf3, 0x6c, 0xc6, 0x64, 0x22, 0x64, 0x8e, 0xf7, 0xe3, 0x59, 0x2e,
+	0xf0, 0x5c, 0xd0, 0x65, 0xc1, 0x70, 0xc9, 0x34, 0x2e, 0xf8, 0x62, 0xc9, 0x29, 0x96, 0x62, 0xa9,
+	0x79, 0x99, 0xe3, 0xfa, 0x2e, 0xb8, 0x1a, 0x8f, 0xbd, 0xdd, 0x8b, 0x9e, 0x7f, 0x0d, 0x00, 0x66,
+	0x2b, 0x20, 0x7f, 0xe2, 0x01, 0x00, 0x00,
+}
+
+func (m *DHTMsg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DHTMsg) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DHTMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Ttl != 0 {
+		i = encodeVarintDhtMsg(dAtA, i, uint64(m.Ttl))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.Providers) > 0 {
+		for iNdEx := len(m.Providers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Providers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintDhtMsg(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x2a
+		}
+	}
+	if len(m.CloserPeers) > 0 {
+		for iNdEx := len(m.CloserPeers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.CloserPeers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintDhtMsg(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintDhtMsg(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Seq != 0 {
+		i = encodeVarintDhtMsg(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Type != 0 {
+		i = encodeVarintDhtMsg(dAtA, i, uint64(m.Type))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *PeerInfo) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PeerInfo) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PeerInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Addrs) > 0 {
+		for iNdEx := len(m.Addrs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Addrs[iNdEx])
+			copy(dAtA[i:], m.Addrs[iNdEx])
+			i = encodeVarintDhtMsg(dAtA, i, uint64(len(m.Addrs[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Pid) > 0 {
+		i -= len(m.Pid)
+		copy(dAtA[i:], m.Pid)
+		i = encodeVarintDhtMsg(dAtA, i, uint64(len(m.Pid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintDhtMsg(dAtA []byte, offset int, v uint64) int {
+	offset -= sovDhtMsg(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *DHTMsg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Type != 0 {
+		n += 1 + sovDhtMsg(uint64(m.Type))
+	}
+	if m.Seq != 0 {
+		n += 1 + sovDhtMsg(uint64(m.Seq))
+	}
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovDhtMsg(uint64(l))
+	}
+	if len(m.CloserPeers) > 0 {
+		for _, e := range m.CloserPeers {
+			l = e.Size()
+			n += 1 + l + sovDhtMsg(uint64(l))
+		}
+	}
+	if len(m.Providers) > 0 {
+		for _, e := range m.Providers {
+			l = e.Size()
+			n += 1 + l + sovDhtMsg(uint64(l))
+		}
+	}
+	if m.Ttl != 0 {
+		n += 1 + sovDhtMsg(uint64(m.Ttl))
+	}
+	return n
+}
+
+func (m *PeerInfo) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Pid)
+	if l > 0 {
+		n += 1 + l + sovDhtMsg(uint64(l))
+	}
+	if len(m.Addrs) > 0 {
+		for _, s := range m.Addrs {
+			l = len(s)
+			n += 1 + l + sovDhtMsg(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovDhtMsg(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozDhtMsg(x uint64) (n int) {
+	return sovDhtMsg(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *DHTMsg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDhtMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DHTMsg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DHTMsg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Type", wireType)
+			}
+			m.Type = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Type |= DHTMsg_Type(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CloserPeers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.CloserPeers = append(m.CloserPeers, &PeerInfo{})
+			if err := m.CloserPeers[len(m.CloserPeers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Providers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Providers = append(m.Providers, &PeerInfo{})
+			if err := m.Providers[len(m.Providers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ttl", wireType)
+			}
+			m.Ttl = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Ttl |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDhtMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PeerInfo) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDhtMsg
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PeerInfo: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PeerInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Pid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addrs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addrs = append(m.Addrs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDhtMsg(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDhtMsg
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipDhtMsg(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowDhtMsg
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowDhtMsg
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthDhtMsg
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupDhtMsg
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthDhtMsg
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthDhtMsg        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowDhtMsg          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupDhtMsg = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,155 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kaddht
+
+import (
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// maxProvidersPerKey is the max count of provider records stored for each key.
+	maxProvidersPerKey = 64
+	// maxProviderKeys is the max count of keys stored, the records of new keys will be dropped when reached.
+	maxProviderKeys = 8192
+	// maxKeysPerProvider is the max count of keys that a peer could provide,
+	// so that a peer could not fill the store by itself.
+	maxKeysPerProvider = 256
+	// maxProviderAddrs is the max count of addresses stored for each provider record.
+	maxProviderAddrs = 8
+	// MaxProviderTTL is the max ttl of provider records accepted.
+	MaxProviderTTL = 24 * time.Hour
+)
+
+type providerRecord struct {
+	pid    peer.ID
+	addrs  []ma.Multiaddr
+	expire time.Time
+}
+
+// providerStore stores the provider records received from others, each of them expires after its ttl,
+// or the default ttl if not given.
+type providerStore struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	records map[string]map[peer.ID]*providerRecord
+	keys    map[peer.ID]int // the count of keys provided by each peer
+}
+
+func newProviderStore(ttl time.Duration) *providerStore {
+	return &providerStore{
+		ttl:     ttl,
+		records: make(map[string]map[peer.ID]*providerRecord),
+		keys:    make(map[peer.ID]int),
+	}
+}
+
+// add a provider record of the key given with ttl, or renew it if exists.
+// If ttl is not greater than 0, the default one will be used.
+func (ps *providerStore) add(key Key, pid peer.ID, addrs []ma.Multiaddr, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = ps.ttl
+	}
+	if ttl > MaxProviderTTL {
+		ttl = MaxProviderTTL
+	}
+	if len(addrs) > maxProviderAddrs {
+		addrs = addrs[:maxProviderAddrs]
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	m, ok := ps.records[string(key)]
+	if r, exist := m[pid]; exist {
+		r.addrs, r.expire = addrs, time.Now().Add(ttl)
+		return
+	}
+	if len(m) >= maxProvidersPerKey || ps.keys[pid] >= maxKeysPerProvider {
+		return
+	}
+	if !ok {
+		if len(ps.records) >= maxProviderKeys {
+			return
+		}
+		m = make(map[peer.ID]*providerRecord)
+		ps.records[string(key)] = m
+	}
+	m[pid] = &providerRecord{pid: pid, addrs: addrs, expire: time.Now().Add(ttl)}
+	ps.keys[pid]++
+}
+
+// deleteLocked delete the provider record of peer for the key given, should be called when ps.mu locked.
+func (ps *providerStore) deleteLocked(key string, pid peer.ID) {
+	m, ok := ps.records[key]
+	if !ok {
+		return
+	}
+	if _, exist := m[pid]; !exist {
+		return
+	}
+	delete(m, pid)
+	if len(m) == 0 {
+		delete(ps.records, key)
+	}
+	ps.keys[pid]--
+	if ps.keys[pid] <= 0 {
+		delete(ps.keys, pid)
+	}
+}
+
+// get the provider records of the key given that have not expired.
+func (ps *providerStore) get(key Key) []*providerRecord {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	m := ps.records[string(key)]
+	now := time.Now()
+	res := make([]*providerRecord, 0, len(m))
+	for pid, r := range m {
+		if now.After(r.expire) {
+			ps.deleteLocked(string(key), pid)
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// providerTTL return the ttl of provider record given in seconds as time.Duration, limited by MaxProviderTTL.
+// 0 will be returned if not given, e.g. the record sent by the older version.
+func providerTTL(seconds int64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	if seconds > int64(MaxProviderTTL/time.Second) {
+		return MaxProviderTTL
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// remove the provider record of peer for the key given.
+func (ps *providerStore) remove(key Key, pid peer.ID) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.deleteLocked(string(key), pid)
+}
+
+// gc remove all the provider records expired.
+func (ps *providerStore) gc() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	now := time.Now()
+	for key, m := range ps.records {
+		for pid, r := range m {
+			if now.After(r.expire) {
+				ps.deleteLocked(key, pid)
+			}
+		}
+	}
+}
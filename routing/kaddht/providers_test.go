@@ -0,0 +1,67 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kaddht
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderStore(t *testing.T) {
+	ps := newProviderStore(time.Hour)
+	key := KeyForString("block-1")
+
+	// the ttl given is used, or the default one if not given
+	ps.add(key, peer.ID("p1"), nil, 50*time.Millisecond)
+	ps.add(key, peer.ID("p2"), nil, 0)
+	require.Len(t, ps.get(key), 2)
+	time.Sleep(100 * time.Millisecond)
+	records := ps.get(key)
+	require.Len(t, records, 1)
+	require.Equal(t, peer.ID("p2"), records[0].pid)
+
+	ps.remove(key, peer.ID("p2"))
+	require.Len(t, ps.get(key), 0)
+	require.Len(t, ps.records, 0)
+}
+
+func TestProviderTTL(t *testing.T) {
+	require.Equal(t, time.Duration(0), providerTTL(0))
+	require.Equal(t, time.Duration(0), providerTTL(-1))
+	require.Equal(t, time.Minute, providerTTL(60))
+	require.Equal(t, MaxProviderTTL, providerTTL(1<<62))
+}
+
+func TestProviderStoreLimits(t *testing.T) {
+	ps := newProviderStore(time.Hour)
+
+	// a peer could not provide too many keys
+	for i := 0; i < maxKeysPerProvider+10; i++ {
+		ps.add(KeyForString("key-"+strconv.Itoa(i)), peer.ID("p1"), nil, 0)
+	}
+	require.Len(t, ps.records, maxKeysPerProvider)
+	require.Equal(t, maxKeysPerProvider, ps.keys[peer.ID("p1")])
+	// renewing the records stored is allowed
+	ps.add(KeyForString("key-0"), peer.ID("p1"), nil, 0)
+	require.Len(t, ps.get(KeyForString("key-0")), 1)
+	ps.remove(KeyForString("key-0"), peer.ID("p1"))
+	ps.add(KeyForString("key-new"), peer.ID("p1"), nil, 0)
+	require.Len(t, ps.get(KeyForString("key-new")), 1)
+
+	// the total count of keys is limited
+	for i := 0; len(ps.records) < maxProviderKeys; i++ {
+		pid := peer.ID("provider-" + strconv.Itoa(i/maxKeysPerProvider))
+		ps.add(KeyForString("more-"+strconv.Itoa(i)), pid, nil, 0)
+	}
+	ps.add(KeyForString("overflow"), peer.ID("p2"), nil, 0)
+	require.Len(t, ps.get(KeyForString("overflow")), 0)
+	require.Len(t, ps.records, maxProviderKeys)
+}
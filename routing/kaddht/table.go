@@ -0,0 +1,217 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kaddht
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"sort"
+	"sync"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+)
+
+// KeyBits is the count of bits of keys in the kademlia key space.
+const KeyBits = sha256.Size * 8
+
+// Key is a key in the kademlia key space.
+type Key []byte
+
+// KeyForPeer return the key of the peer id given, which is the sha256 digest of it.
+func KeyForPeer(pid peer.ID) Key {
+	return KeyForString(string(pid))
+}
+
+// KeyForString return the key of the string given, e.g. a protocol id or a service name.
+func KeyForString(s string) Key {
+	digest := sha256.Sum256([]byte(s))
+	return digest[:]
+}
+
+// Distance return the XOR distance between two keys.
+func Distance(a, b Key) Key {
+	d := make(Key, len(a))
+	for i := range a {
+		d[i] = a[i] ^ b[i]
+	}
+	return d
+}
+
+// CommonPrefixLen return the count of the leading bits shared by two keys.
+func CommonPrefixLen(a, b Key) int {
+	for i := range a {
+		x := a[i] ^ b[i]
+		if x == 0 {
+			continue
+		}
+		n := 0
+		for x&0x80 == 0 {
+			x <<= 1
+			n++
+		}
+		return i*8 + n
+	}
+	return len(a) * 8
+}
+
+// closerTo return whether key a is closer to target than key b.
+func closerTo(target, a, b Key) bool {
+	return bytes.Compare(Distance(a, target), Distance(b, target)) < 0
+}
+
+// randomKeyWithPrefixLen return a random key sharing exactly cpl leading bits with the key given.
+func randomKeyWithPrefixLen(k Key, cpl int) Key {
+	r := make(Key, len(k))
+	_, _ = rand.Read(r)
+	byteIdx, bitIdx := cpl/8, uint(cpl%8)
+	copy(r[:byteIdx], k[:byteIdx])
+	// keep the leading bits of the byte, flip the bit at cpl and keep the others random
+	mask := byte(0xff) << (8 - bitIdx)
+	flip := byte(0x80) >> bitIdx
+	r[byteIdx] = (k[byteIdx] & mask) | (^k[byteIdx] & flip) | (r[byteIdx] &^ (mask | flip))
+	return r
+}
+
+type tableEntry struct {
+	pid peer.ID
+	key Key
+}
+
+// RoutingTable is the routing table of kademlia, peers are grouped into k-buckets by the common prefix length
+// of their keys and the local key. Each bucket holds at most k peers ordered from least recently seen to most
+// recently seen.
+type RoutingTable struct {
+	local     Key
+	k         int
+	evictable func(pid peer.ID) bool
+
+	mu      sync.RWMutex
+	buckets [][]*tableEntry
+}
+
+// NewRoutingTable create a new *RoutingTable for the local peer id given.
+// When a bucket is full, the least recently seen peer in it will be replaced by the new one
+// only if evictable returns true for it, otherwise the new one will be dropped.
+func NewRoutingTable(local peer.ID, k int, evictable func(pid peer.ID) bool) *RoutingTable {
+	return &RoutingTable{
+		local:     KeyForPeer(local),
+		k:         k,
+		evictable: evictable,
+		buckets:   make([][]*tableEntry, KeyBits),
+	}
+}
+
+func (rt *RoutingTable) bucketIdx(key Key) int {
+	cpl := CommonPrefixLen(rt.local, key)
+	if cpl >= KeyBits {
+		cpl = KeyBits - 1
+	}
+	return cpl
+}
+
+// Update add the peer given to the routing table, or mark it as most recently seen if it exists.
+// Return whether the peer is in the table after updating.
+func (rt *RoutingTable) Update(pid peer.ID) bool {
+	key := KeyForPeer(pid)
+	if bytes.Equal(key, rt.local) {
+		return false
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	idx := rt.bucketIdx(key)
+	bucket := rt.buckets[idx]
+	for i := range bucket {
+		if bucket[i].pid == pid {
+			e := bucket[i]
+			bucket = append(bucket[:i], bucket[i+1:]...)
+			rt.buckets[idx] = append(bucket, e)
+			return true
+		}
+	}
+	e := &tableEntry{pid: pid, key: key}
+	if len(bucket) < rt.k {
+		rt.buckets[idx] = append(bucket, e)
+		return true
+	}
+	if rt.evictable == nil || !rt.evictable(bucket[0].pid) {
+		return false
+	}
+	rt.buckets[idx] = append(bucket[1:], e)
+	return true
+}
+
+// Remove the peer given from the routing table.
+func (rt *RoutingTable) Remove(pid peer.ID) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	idx := rt.bucketIdx(KeyForPeer(pid))
+	bucket := rt.buckets[idx]
+	for i := range bucket {
+		if bucket[i].pid == pid {
+			rt.buckets[idx] = append(bucket[:i], bucket[i+1:]...)
+			return
+		}
+	}
+}
+
+// Contains return whether the peer given is in the routing table.
+func (rt *RoutingTable) Contains(pid peer.ID) bool {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	for _, e := range rt.buckets[rt.bucketIdx(KeyForPeer(pid))] {
+		if e.pid == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// Size return the count of peers in the routing table.
+func (rt *RoutingTable) Size() int {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	size := 0
+	for i := range rt.buckets {
+		size += len(rt.buckets[i])
+	}
+	return size
+}
+
+// MaxCommonPrefixLen return the max index of the buckets not empty, or -1 if the table is empty.
+func (rt *RoutingTable) MaxCommonPrefixLen() int {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	for i := len(rt.buckets) - 1; i >= 0; i-- {
+		if len(rt.buckets[i]) > 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// NearestPeers return at most count peers in the routing table that are closest to the key given,
+// ordered by distance ascending.
+func (rt *RoutingTable) NearestPeers(key Key, count int) []peer.ID {
+	rt.mu.RLock()
+	entries := make([]*tableEntry, 0, rt.k)
+	for i := range rt.buckets {
+		entries = append(entries, rt.buckets[i]...)
+	}
+	rt.mu.RUnlock()
+	sort.Slice(entries, func(i, j int) bool {
+		return closerTo(key, entries[i].key, entries[j].key)
+	})
+	if len(entries) > count {
+		entries = entries[:count]
+	}
+	res := make([]peer.ID, len(entries))
+	for i := range entries {
+		res[i] = entries[i].pid
+	}
+	return res
+}
@@ -0,0 +1,71 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package kaddht
+
+import (
+	"strconv"
+	"testing"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommonPrefixLen(t *testing.T) {
+	a := Key{0x00, 0xff}
+	require.Equal(t, 16, CommonPrefixLen(a, a))
+	require.Equal(t, 0, CommonPrefixLen(a, Key{0x80, 0xff}))
+	require.Equal(t, 7, CommonPrefixLen(a, Key{0x01, 0xff}))
+	require.Equal(t, 9, CommonPrefixLen(a, Key{0x00, 0xbf}))
+
+	local := KeyForPeer("local")
+	for cpl := 0; cpl < 20; cpl++ {
+		require.Equal(t, cpl, CommonPrefixLen(local, randomKeyWithPrefixLen(local, cpl)))
+	}
+}
+
+func TestRoutingTable(t *testing.T) {
+	local := peer.ID("local")
+	evictable := make(map[peer.ID]bool)
+	rt := NewRoutingTable(local, 2, func(pid peer.ID) bool {
+		return evictable[pid]
+	})
+	require.False(t, rt.Update(local))
+
+	// fill the bucket whose common prefix length is 0
+	localKey := KeyForPeer(local)
+	bucket0 := make([]peer.ID, 0, 3)
+	for i := 0; len(bucket0) < 3; i++ {
+		pid := peer.ID("peer" + strconv.Itoa(i))
+		if CommonPrefixLen(localKey, KeyForPeer(pid)) == 0 {
+			bucket0 = append(bucket0, pid)
+		}
+	}
+	require.True(t, rt.Update(bucket0[0]))
+	require.True(t, rt.Update(bucket0[1]))
+	// bucket full and the least recently seen one could not be evicted
+	require.False(t, rt.Update(bucket0[2]))
+	require.Equal(t, 2, rt.Size())
+	// mark peer0 as the most recently seen, then peer1 will be evicted
+	require.True(t, rt.Update(bucket0[0]))
+	evictable[bucket0[1]] = true
+	require.True(t, rt.Update(bucket0[2]))
+	require.False(t, rt.Contains(bucket0[1]))
+	require.True(t, rt.Contains(bucket0[0]))
+	require.True(t, rt.Contains(bucket0[2]))
+
+	// nearest peers ordered by distance
+	target := KeyForPeer(bucket0[2])
+	nearest := rt.NearestPeers(target, 10)
+	require.Len(t, nearest, 2)
+	require.Equal(t, bucket0[2], nearest[0])
+	require.Len(t, rt.NearestPeers(target, 1), 1)
+
+	rt.Remove(bucket0[2])
+	require.False(t, rt.Contains(bucket0[2]))
+	require.Equal(t, 1, rt.Size())
+	require.Equal(t, 0, rt.MaxCommonPrefixLen())
+}
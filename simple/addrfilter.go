@@ -0,0 +1,63 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"net"
+
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+// AddrFilters filters the addresses whose IP is in any of the CIDRs given.
+// It is used to exclude addresses such as loopback and docker bridge addresses from being advertised.
+// A nil *AddrFilters blocks nothing.
+type AddrFilters struct {
+	nets []*net.IPNet
+}
+
+// NewAddrFilters create a new *AddrFilters instance with CIDRs, e.g. "127.0.0.0/8", "172.17.0.0/16".
+func NewAddrFilters(cidrs ...string) (*AddrFilters, error) {
+	f := &AddrFilters{nets: make([]*net.IPNet, 0, len(cidrs))}
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		f.nets = append(f.nets, ipNet)
+	}
+	return f, nil
+}
+
+// Blocked return whether the IP of the address is in any CIDR of filters.
+// The addresses without IP (e.g. /dns4/...) will never be blocked.
+func (f *AddrFilters) Blocked(addr ma.Multiaddr) bool {
+	if f == nil || len(f.nets) == 0 || addr == nil {
+		return false
+	}
+	ip, err := manet.ToIP(addr)
+	if err != nil {
+		return false
+	}
+	for i := range f.nets {
+		if f.nets[i].Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter return the addresses that not blocked.
+func (f *AddrFilters) Filter(addrs []ma.Multiaddr) []ma.Multiaddr {
+	res := make([]ma.Multiaddr, 0, len(addrs))
+	for i := range addrs {
+		if !f.Blocked(addrs[i]) {
+			res = append(res, addrs[i])
+		}
+	}
+	return res
+}
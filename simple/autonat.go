@@ -0,0 +1,420 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/handler"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/simple/pb"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr/net"
+)
+
+const (
+	// AutoNATProtocolID is the protocol.ID for AutoNAT service.
+	AutoNATProtocolID protocol.ID = "/autonat/v0.0.1"
+	// DefaultAutoNATInterval is the default interval of probing the reachability.
+	DefaultAutoNATInterval = 5 * time.Minute
+	// DefaultAutoNATPeers is the default max count of peers asked to dial back in each probing.
+	DefaultAutoNATPeers = 3
+	// DefaultAutoNATConfirmations is the default count of peers failed to dial back
+	// before an address confirmed unreachable.
+	DefaultAutoNATConfirmations = 2
+	// DefaultAutoNATTimeout is the default timeout of waiting for the response of dialing back.
+	DefaultAutoNATTimeout = 30 * time.Second
+
+	// maxDialBackAddrs is the max count of addresses dialed back for each request.
+	maxDialBackAddrs = 16
+	// maxConcurrentDialBack is the max count of requests dialing back at the same time.
+	maxConcurrentDialBack = 4
+)
+
+var (
+	// ErrNoAutoNATPeer will be returned if no peer connected supports AutoNAT protocol.
+	ErrNoAutoNATPeer = errors.New("no peer supports autonat")
+	// ErrDialBackRefused will be reported if the address dialing back requested is refused.
+	ErrDialBackRefused = errors.New("dial back refused")
+)
+
+// Reachability is the reachability of a host or an address of it.
+type Reachability int
+
+const (
+	// ReachabilityUnknown means the reachability has not been confirmed yet.
+	ReachabilityUnknown Reachability = iota
+	// ReachabilityPublic means the host or the address could be dialed by others.
+	ReachabilityPublic
+	// ReachabilityPrivate means the host or the address could not be dialed by others.
+	ReachabilityPrivate
+)
+
+// String return the name of reachability.
+func (r Reachability) String() string {
+	switch r {
+	case ReachabilityPublic:
+		return "public"
+	case ReachabilityPrivate:
+		return "private"
+	default:
+		return "unknown"
+	}
+}
+
+// DialBackFunc is a function that dial to the address of peer given directly
+// to check whether it is reachable. The connection established should be closed.
+type DialBackFunc func(ctx context.Context, pid peer.ID, addr ma.Multiaddr) error
+
+type autoNATWaiter struct {
+	pid       peer.ID
+	responseC chan []*pb.DialBackResult
+}
+
+// AutoNATService provides a dial-back protocol detecting the reachability of the host.
+// The host asks a few peers connected to dial back the addresses it would advertise and report the results,
+// an address dialed back successfully by any peer is public, and an address failed to be dialed back by
+// enough peers is confirmed private. If any address is public, the host is public, and if all addresses are
+// private, the host is private.
+// On the other side, it dials back the addresses requested by others, only the addresses whose IP is the same
+// as the remote IP of the connection will be dialed, the others will be refused.
+type AutoNATService struct {
+	host          host.Host
+	addrsFunc     func() []ma.Multiaddr
+	dialBack      DialBackFunc
+	seq           uint64
+	waiters       sync.Map // map[uint64]*autoNATWaiter
+	interval      time.Duration
+	timeout       time.Duration
+	peers         int
+	confirmations int
+	dialBackLimit chan struct{}
+
+	mu           sync.RWMutex
+	addrStates   map[string]Reachability
+	reachability Reachability
+
+	closeC chan struct{}
+	once   sync.Once
+
+	logger api.Logger
+}
+
+// NewAutoNATService create a new *AutoNATService instance.
+// addrsFunc returns the addresses whose reachability will be probed, dialBack dials back for others.
+// If interval is not greater than 0, the background prober will not run.
+func NewAutoNATService(h host.Host, addrsFunc func() []ma.Multiaddr, dialBack DialBackFunc,
+	interval time.Duration, logger api.Logger) *AutoNATService {
+	return &AutoNATService{
+		host:          h,
+		addrsFunc:     addrsFunc,
+		dialBack:      dialBack,
+		interval:      interval,
+		timeout:       DefaultAutoNATTimeout,
+		peers:         DefaultAutoNATPeers,
+		confirmations: DefaultAutoNATConfirmations,
+		dialBackLimit: make(chan struct{}, maxConcurrentDialBack),
+		addrStates:    make(map[string]Reachability),
+		logger:        logger,
+	}
+}
+
+// ProtocolID is the protocol.ID of AutoNAT service.
+// The protocol id will be registered in host.RegisterMsgPayloadHandler method.
+func (s *AutoNATService) ProtocolID() protocol.ID {
+	return AutoNATProtocolID
+}
+
+// Handle is the msg payload handler of AutoNAT service.
+// It will be registered in host.Host.RegisterMsgPayloadHandler method.
+func (s *AutoNATService) Handle() handler.MsgPayloadHandler {
+	return func(senderPID peer.ID, msgPayload []byte) {
+		msg := &pb.AutoNATMsg{}
+		err := proto.Unmarshal(msgPayload, msg)
+		if err != nil {
+			s.logger.Errorf("[AutoNATService] handler msg payload failed, %s (sender id: %s)",
+				err.Error(), senderPID)
+			return
+		}
+		switch msg.MsgType {
+		case pb.AutoNATMsg_DIAL_BACK:
+			go s.handleDialBack(senderPID, msg)
+		case pb.AutoNATMsg_DIAL_BACK_RESPONSE:
+			v, ok := s.waiters.Load(msg.Seq)
+			if !ok {
+				return
+			}
+			w, _ := v.(*autoNATWaiter)
+			if w.pid != senderPID {
+				s.logger.Warnf("[AutoNATService] response sender mismatch, (sender id: %s, expected: %s)",
+					senderPID, w.pid)
+				return
+			}
+			select {
+			case w.responseC <- msg.Results:
+			default:
+			}
+		default:
+			return
+		}
+	}
+}
+
+// handleDialBack dial back the addresses requested by sender, then send the results to it.
+func (s *AutoNATService) handleDialBack(senderPID peer.ID, msg *pb.AutoNATMsg) {
+	conn := s.host.ConnMgr().GetPeerConn(senderPID)
+	if conn == nil {
+		return
+	}
+	observedIP, err := manet.ToIP(conn.RemoteAddr())
+	if err != nil {
+		return
+	}
+	select {
+	case s.dialBackLimit <- struct{}{}:
+		defer func() { <-s.dialBackLimit }()
+	default:
+		s.logger.Debugf("[AutoNATService] too many dialing back, ignore request (sender id: %s)", senderPID)
+		return
+	}
+	addrs := msg.Addrs
+	if len(addrs) > maxDialBackAddrs {
+		addrs = addrs[:maxDialBackAddrs]
+	}
+	results := make([]*pb.DialBackResult, 0, len(addrs))
+	for _, addrStr := range addrs {
+		res := &pb.DialBackResult{Addr: addrStr, Status: pb.DialBackResult_REFUSED}
+		results = append(results, res)
+		addr, e := ma.NewMultiaddr(addrStr)
+		if e != nil {
+			res.Error = e.Error()
+			continue
+		}
+		// dial back the addresses with the same IP as the connection only,
+		// to avoid being used to attack others.
+		ip, e := manet.ToIP(addr)
+		if e != nil || !ip.Equal(observedIP) {
+			res.Error = ErrDialBackRefused.Error()
+			continue
+		}
+		ctx, cancel := context.WithTimeout(s.host.Context(), s.timeout)
+		e = s.dialBack(ctx, senderPID, addr)
+		cancel()
+		if e != nil {
+			res.Status = pb.DialBackResult_FAILED
+			res.Error = e.Error()
+			continue
+		}
+		res.Status = pb.DialBackResult_OK
+	}
+	bytes, err := proto.Marshal(&pb.AutoNATMsg{
+		MsgType: pb.AutoNATMsg_DIAL_BACK_RESPONSE,
+		Seq:     msg.Seq,
+		Results: results,
+	})
+	if err != nil {
+		s.logger.Errorf("[AutoNATService] marshal response msg failed, %s", err.Error())
+		return
+	}
+	if err = s.host.SendMsg(AutoNATProtocolID, senderPID, bytes); err != nil {
+		s.logger.Debugf("[AutoNATService] send response msg failed, %s (remote pid: %s)", err.Error(), senderPID)
+	}
+}
+
+// RequestDialBack ask the peer connected to dial back the addresses given, and return the results.
+func (s *AutoNATService) RequestDialBack(ctx context.Context, pid peer.ID, addrs []ma.Multiaddr) (
+	[]*pb.DialBackResult, error) {
+	addrStrs := make([]string, 0, len(addrs))
+	for i := range addrs {
+		addrStrs = append(addrStrs, addrs[i].String())
+	}
+	seq := atomic.AddUint64(&s.seq, 1)
+	bytes, err := proto.Marshal(&pb.AutoNATMsg{
+		MsgType: pb.AutoNATMsg_DIAL_BACK,
+		Seq:     seq,
+		Addrs:   addrStrs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	w := &autoNATWaiter{pid: pid, responseC: make(chan []*pb.DialBackResult, 1)}
+	s.waiters.Store(seq, w)
+	defer s.waiters.Delete(seq)
+	if err = s.host.SendMsg(AutoNATProtocolID, pid, bytes); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case results := <-w.responseC:
+		return results, nil
+	}
+}
+
+// Probe ask a few peers connected to dial back the addresses of the host, then update the reachability.
+func (s *AutoNATService) Probe(ctx context.Context) error {
+	addrs := s.addrsFunc()
+	if len(addrs) == 0 {
+		return nil
+	}
+	pids := make([]peer.ID, 0)
+	for _, pid := range s.host.ConnMgr().AllPeer() {
+		if s.host.IsPeerSupportProtocol(pid, AutoNATProtocolID) {
+			pids = append(pids, pid)
+		}
+	}
+	if len(pids) == 0 {
+		return ErrNoAutoNATPeer
+	}
+	rand.Shuffle(len(pids), func(i, j int) {
+		pids[i], pids[j] = pids[j], pids[i]
+	})
+	if len(pids) > s.peers {
+		pids = pids[:s.peers]
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successes, failures := make(map[string]int), make(map[string]int)
+	for i := range pids {
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			reqCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+			results, err := s.RequestDialBack(reqCtx, pid, addrs)
+			if err != nil {
+				s.logger.Debugf("[AutoNATService] request dial back failed, %s (remote pid: %s)", err.Error(), pid)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, res := range results {
+				switch res.Status {
+				case pb.DialBackResult_OK:
+					successes[res.Addr]++
+				case pb.DialBackResult_FAILED:
+					failures[res.Addr]++
+				}
+			}
+		}(pids[i])
+	}
+	wg.Wait()
+	s.update(addrs, successes, failures)
+	return nil
+}
+
+func (s *AutoNATService) update(addrs []ma.Multiaddr, successes, failures map[string]int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	addrStates := make(map[string]Reachability, len(addrs))
+	public, private := 0, 0
+	for i := range addrs {
+		key := addrs[i].String()
+		state := ReachabilityUnknown
+		switch {
+		case successes[key] > 0:
+			state = ReachabilityPublic
+		case failures[key] >= s.confirmations:
+			state = ReachabilityPrivate
+		default:
+			// keep the state confirmed last time if no enough peers answered
+			if last, ok := s.addrStates[key]; ok {
+				state = last
+			}
+		}
+		addrStates[key] = state
+		switch state {
+		case ReachabilityPublic:
+			public++
+		case ReachabilityPrivate:
+			private++
+		}
+	}
+	s.addrStates = addrStates
+	reachability := ReachabilityUnknown
+	if public > 0 {
+		reachability = ReachabilityPublic
+	} else if private == len(addrs) {
+		reachability = ReachabilityPrivate
+	}
+	if reachability != s.reachability {
+		s.logger.Infof("[AutoNATService] reachability changed. (from: %s, to: %s)",
+			s.reachability.String(), reachability.String())
+		s.reachability = reachability
+	}
+}
+
+// Reachability return the reachability of the host.
+func (s *AutoNATService) Reachability() Reachability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.reachability
+}
+
+// AddrReachability return the reachability of the address of the host.
+func (s *AutoNATService) AddrReachability(addr ma.Multiaddr) Reachability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.addrStates[addr.String()]
+}
+
+// Start the background prober.
+func (s *AutoNATService) Start() error {
+	if s.interval <= 0 {
+		return nil
+	}
+	s.once.Do(func() {
+		s.closeC = make(chan struct{})
+		go s.probeLoop(s.closeC)
+	})
+	return nil
+}
+
+// Stop the background prober.
+func (s *AutoNATService) Stop() error {
+	if s.closeC == nil {
+		return nil
+	}
+	close(s.closeC)
+	s.closeC = nil
+	s.once = sync.Once{}
+	return nil
+}
+
+func (s *AutoNATService) probeLoop(closeC chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closeC:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithCancel(s.host.Context())
+			go func() {
+				select {
+				case <-closeC:
+				case <-ctx.Done():
+				}
+				cancel()
+			}()
+			if err := s.Probe(ctx); err != nil {
+				s.logger.Debugf("[AutoNATService] probe failed, %s", err.Error())
+			}
+			cancel()
+		}
+	}
+}
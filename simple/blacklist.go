@@ -109,3 +109,8 @@ func (s *simpleBlacklist) IsBlack(conn network.Conn) bool {
 	}
 	return false
 }
+
+// IsBlackPeer check whether the peer id exist in blacklist.
+func (s *simpleBlacklist) IsBlackPeer(pid peer.ID) bool {
+	return s.peerIds.Exist(pid)
+}
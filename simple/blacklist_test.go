@@ -171,3 +171,12 @@ func (m mockConn) CreateBidirectionalStream() (network.Stream, error) {
 func (m mockConn) AcceptBidirectionalStream() (network.Stream, error) {
 	panic("implement me")
 }
+
+func TestSimpleBlacklistIsBlackPeer(t *testing.T) {
+	l := NewBlackList()
+	require.False(t, l.IsBlackPeer("0"))
+	l.AddPeer("0")
+	require.True(t, l.IsBlackPeer("0"))
+	l.RemovePeer("0")
+	require.False(t, l.IsBlackPeer("0"))
+}
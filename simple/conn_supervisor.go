@@ -7,31 +7,83 @@ SPDX-License-Identifier: Apache-2.0
 package simple
 
 import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"chainmaker.org/chainmaker/net-common/utils"
 	"chainmaker.org/chainmaker/net-liquid/core/host"
 	"chainmaker.org/chainmaker/net-liquid/core/mgr"
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
 	api "chainmaker.org/chainmaker/protocol/v2"
 	ma "github.com/multiformats/go-multiaddr"
 )
 
-const DefaultTryTimes = 50
+const (
+	// DefaultTryTimes is the default max count of dialing failed before giving up.
+	DefaultTryTimes = 50
+	// DefaultSupervisorBackoffBase is the default interval after the first failure of dialing to a direct peer.
+	// The interval will be doubled after each failure until DefaultSupervisorBackoffMax.
+	DefaultSupervisorBackoffBase = time.Second
+	// DefaultSupervisorBackoffMax is the default max interval between two dialing to a direct peer.
+	DefaultSupervisorBackoffMax = 2 * time.Minute
+	// supervisorBackoffJitter is the ratio of random jitter applied to each backoff interval.
+	supervisorBackoffJitter = 0.2
+	// supervisorCheckInterval is the interval of checking the connection state of direct peers.
+	supervisorCheckInterval = 5 * time.Second
+)
+
+// directPeerProtectTag is the tag used to protect the connections of direct peers.
+const directPeerProtectTag = "direct-peer"
+
+var (
+	// ErrSupervisorPidMismatch will be recorded if the peer id of connection dialed mismatch the direct peer.
+	ErrSupervisorPidMismatch = errors.New("pid mismatch")
+)
+
+// ConnSupervisorOption is a function to set option value for connSupervisor.
+type ConnSupervisorOption func(cs *connSupervisor)
+
+// WithSupervisorTryTimes set the max count of dialing failed before giving up.
+// If tryTimes is 0, DefaultTryTimes will be used. If tryTimes is negative, supervisor will never give up.
+func WithSupervisorTryTimes(tryTimes int) ConnSupervisorOption {
+	return func(cs *connSupervisor) {
+		if tryTimes != 0 {
+			cs.tryTimes = tryTimes
+		}
+	}
+}
+
+// WithSupervisorBackoff set the base and the max interval of exponential backoff between two dialing.
+// If any of them is not greater than 0, the default value will be used.
+func WithSupervisorBackoff(base, max time.Duration) ConnSupervisorOption {
+	return func(cs *connSupervisor) {
+		if base > 0 {
+			cs.backoffBase = base
+		}
+		if max > 0 {
+			cs.backoffMax = max
+		}
+	}
+}
 
 var _ mgr.ConnSupervisor = (*connSupervisor)(nil)
 
 // connSupervisor is an implementation of mgr.ConnSupervisor interface.
 // connSupervisor maintains the connection state of the necessary peers.
-// If a necessary peer is not connected to us, supervisor will try to dial to it.
+// If a necessary peer is not connected to us, supervisor will try to dial to it
+// with exponential backoff and jitter, until connected or the max try times reached.
 type connSupervisor struct {
 	sync.RWMutex
 	once sync.Once
 	host host.Host
 
-	directPeer map[peer.ID]ma.Multiaddr
+	directPeer map[peer.ID]*directPeerEntry
 
 	checkTimer *time.Timer
 	signalChan chan struct{}
@@ -40,35 +92,126 @@ type connSupervisor struct {
 	logger api.Logger
 
 	tryTimes     int
-	actuators    map[peer.ID]*tryToDialActuator
+	backoffBase  time.Duration
+	backoffMax   time.Duration
 	allConnected int32
 
 	hostNotifiee *host.NotifieeBundle
 }
 
+// directPeerEntry is the record of a direct peer. All fields are guarded by the lock of connSupervisor.
+type directPeerEntry struct {
+	pid         peer.ID
+	addrs       []ma.Multiaddr
+	state       mgr.DirectPeerState
+	attempts    int
+	lastErr     error
+	lastAttempt time.Time
+	nextAttempt time.Time
+	running     bool
+	wakeC       chan struct{}
+	removedC    chan struct{}
+}
+
 // NewConnSupervisor create a new *connSupervisor instance.
-func NewConnSupervisor(h host.Host, logger api.Logger) mgr.ConnSupervisor {
+func NewConnSupervisor(h host.Host, logger api.Logger, opts ...ConnSupervisorOption) mgr.ConnSupervisor {
 	cs := &connSupervisor{
 		once:         sync.Once{},
 		host:         h,
-		directPeer:   make(map[peer.ID]ma.Multiaddr),
+		directPeer:   make(map[peer.ID]*directPeerEntry),
 		signalChan:   make(chan struct{}, 1),
 		closeChan:    nil,
 		logger:       logger,
 		tryTimes:     DefaultTryTimes,
-		actuators:    make(map[peer.ID]*tryToDialActuator),
+		backoffBase:  DefaultSupervisorBackoffBase,
+		backoffMax:   DefaultSupervisorBackoffMax,
 		allConnected: 0,
 		hostNotifiee: &host.NotifieeBundle{},
 	}
+	for _, opt := range opts {
+		opt(cs)
+	}
 	cs.hostNotifiee.PeerDisconnectedFunc = cs.NoticeDisconnected
 	return cs
 }
 
 // SetPeerAddr will set a peer as a necessary peer and store the peer's address.
+// The addresses of the peer stored before will be replaced.
 func (c *connSupervisor) SetPeerAddr(pid peer.ID, addr ma.Multiaddr) {
 	c.Lock()
-	defer c.Unlock()
-	c.directPeer[pid] = addr
+	e := c.loadOrCreateEntry(pid)
+	e.addrs = []ma.Multiaddr{addr}
+	c.resetEntry(e)
+	c.Unlock()
+	c.storeAddrs(pid, addr)
+	c.signal()
+}
+
+// AddPeerAddr will set a peer as a necessary peer and append the addresses to the peer's addresses.
+func (c *connSupervisor) AddPeerAddr(pid peer.ID, addrs ...ma.Multiaddr) {
+	c.Lock()
+	e := c.loadOrCreateEntry(pid)
+	for _, addr := range addrs {
+		exist := false
+		for i := range e.addrs {
+			if e.addrs[i].Equal(addr) {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			e.addrs = append(e.addrs, addr)
+		}
+	}
+	c.resetEntry(e)
+	c.Unlock()
+	c.storeAddrs(pid, addrs...)
+	c.signal()
+}
+
+// loadOrCreateEntry should be called when c locked.
+func (c *connSupervisor) loadOrCreateEntry(pid peer.ID) *directPeerEntry {
+	e, ok := c.directPeer[pid]
+	if !ok {
+		e = &directPeerEntry{
+			pid:      pid,
+			addrs:    make([]ma.Multiaddr, 0, 1),
+			wakeC:    make(chan struct{}, 1),
+			removedC: make(chan struct{}),
+		}
+		c.directPeer[pid] = e
+		c.host.ConnMgr().Protect(pid, directPeerProtectTag)
+	}
+	return e
+}
+
+// resetEntry let the peer given up be dialed again, and wake up the dialing in backoff.
+// It should be called when c locked.
+func (c *connSupervisor) resetEntry(e *directPeerEntry) {
+	if e.state == mgr.DirectPeerStateGaveUp {
+		e.state = mgr.DirectPeerStateDisconnected
+		e.attempts = 0
+	}
+	if e.running {
+		select {
+		case e.wakeC <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// storeAddrs record the net addresses of direct peer into PeerStore, so that all of them will be dialed.
+func (c *connSupervisor) storeAddrs(pid peer.ID, addrs ...ma.Multiaddr) {
+	for _, addr := range addrs {
+		netAddr, _ := util.GetNetAddrAndPidFromNormalMultiAddr(addr)
+		if netAddr == nil {
+			continue
+		}
+		c.host.PeerStore().AddAddrWithTTL(pid, store.AddrSourceConfig, store.PermanentAddrTTL, netAddr)
+	}
+}
+
+func (c *connSupervisor) signal() {
 	select {
 	case c.signalChan <- struct{}{}:
 	default:
@@ -79,20 +222,60 @@ func (c *connSupervisor) SetPeerAddr(pid peer.ID, addr ma.Multiaddr) {
 func (c *connSupervisor) RemoveAllPeer() {
 	c.Lock()
 	defer c.Unlock()
-	c.directPeer = make(map[peer.ID]ma.Multiaddr)
+	for pid, e := range c.directPeer {
+		c.host.ConnMgr().Unprotect(pid, directPeerProtectTag)
+		close(e.removedC)
+	}
+	c.directPeer = make(map[peer.ID]*directPeerEntry)
 }
 
 // RemovePeerAddr will unset a necessary peer.
 func (c *connSupervisor) RemovePeerAddr(pid peer.ID) {
 	c.Lock()
 	defer c.Unlock()
+	e, ok := c.directPeer[pid]
+	if !ok {
+		return
+	}
 	delete(c.directPeer, pid)
+	close(e.removedC)
+	c.host.ConnMgr().Unprotect(pid, directPeerProtectTag)
+}
+
+// Status return the status of all necessary peers, sorted by peer id.
+func (c *connSupervisor) Status() []mgr.DirectPeerStatus {
+	c.RLock()
+	defer c.RUnlock()
+	res := make([]mgr.DirectPeerStatus, 0, len(c.directPeer))
+	for pid, e := range c.directPeer {
+		state := e.state
+		if c.host.ConnMgr().IsConnected(pid) {
+			state = mgr.DirectPeerStateConnected
+		} else if state == mgr.DirectPeerStateConnected {
+			state = mgr.DirectPeerStateDisconnected
+		}
+		addrs := make([]ma.Multiaddr, len(e.addrs))
+		copy(addrs, e.addrs)
+		res = append(res, mgr.DirectPeerStatus{
+			PeerID:      pid,
+			Addrs:       addrs,
+			State:       state,
+			Attempts:    e.attempts,
+			LastError:   e.lastErr,
+			LastAttempt: e.lastAttempt,
+			NextAttempt: e.nextAttempt,
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].PeerID < res[j].PeerID
+	})
+	return res
 }
 
 func (c *connSupervisor) Start() error {
 	c.once.Do(func() {
 		c.closeChan = make(chan struct{})
-		go c.loop()
+		go c.loop(c.closeChan)
 		c.signalChan <- struct{}{}
 		c.host.Notify(c.hostNotifiee)
 	})
@@ -106,64 +289,59 @@ func (c *connSupervisor) Stop() error {
 }
 
 // NoticeDisconnected is a function called back when any peer disconnected.
-// When called, supervisor will start to check connections.
+// If the peer is a necessary peer, supervisor will redial to it immediately.
 func (c *connSupervisor) NoticeDisconnected(pid peer.ID) {
-	c.RLock()
-	if _, ok := c.directPeer[pid]; !ok {
-		c.RUnlock()
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.directPeer[pid]
+	if !ok || c.closeChan == nil {
 		return
 	}
-	c.RUnlock()
 	select {
 	case <-c.closeChan:
-	case c.signalChan <- struct{}{}:
+		return
 	default:
 	}
+	atomic.StoreInt32(&c.allConnected, 0)
+	e.state = mgr.DirectPeerStateDisconnected
+	e.attempts = 0
+	c.startDialing(e)
 }
 
-func (c *connSupervisor) loop() {
-	c.checkTimer = time.NewTimer(5 * time.Second)
+func (c *connSupervisor) loop(closeChan chan struct{}) {
+	c.checkTimer = time.NewTimer(supervisorCheckInterval)
 	for {
 		select {
-		case <-c.closeChan:
+		case <-closeChan:
 			return
 		case <-c.signalChan:
 			c.checkConn()
 		case <-c.checkTimer.C:
-			select {
-			case c.signalChan <- struct{}{}:
-			default:
-
-			}
+			c.signal()
 		}
 	}
 }
 
 func (c *connSupervisor) checkConn() {
-	c.RLock()
-	defer c.RUnlock()
+	// the next check should be scheduled on every path, otherwise the periodic checking stops
+	defer c.resetCheckTimer()
+	c.Lock()
+	defer c.Unlock()
 	allSize := len(c.directPeer)
 	if allSize == 0 {
 		return
 	}
 	curSize := 0
-	necessaryPeers := c.directPeer
-	for pid, addr := range necessaryPeers {
+	for pid, e := range c.directPeer {
 		if pid == c.host.ID() || c.host.ConnMgr().IsConnected(pid) {
 			curSize++
-			_, ok := c.actuators[pid]
-			if ok {
-				delete(c.actuators, pid)
+			if !e.running {
+				e.state = mgr.DirectPeerStateConnected
 			}
 			continue
 		}
-		c.allConnected = 0
-		ac, ok := c.actuators[pid]
-		if !ok || ac.hasFinished() {
-			c.actuators[pid] = newTryToDialActuator(pid, addr, c, c.tryTimes)
-			ac = c.actuators[pid]
-		}
-		go ac.run()
+		atomic.StoreInt32(&c.allConnected, 0)
+		c.startDialing(e)
 	}
 	select {
 	case <-c.closeChan:
@@ -176,119 +354,137 @@ func (c *connSupervisor) checkConn() {
 				allSize, curSize)
 			c.logger.Infof("[ConnSupervisor] all necessary peers connected.")
 			atomic.StoreInt32(&c.allConnected, 1)
-			select {
-			case c.signalChan <- struct{}{}:
-			default:
-
-			}
 		}
 	} else {
 		c.logger.Debugf("[ConnSupervisor][CheckConn] necessary peers count:%d, connected:%d)", allSize, curSize)
-		c.checkTimer.Reset(5 * time.Second)
 	}
 }
 
-type tryToDialActuator struct {
-	peerId     peer.ID
-	peerAddr   ma.Multiaddr
-	fibonacci  []int64
-	idx        int
-	giveUp     bool
-	finishFlag int32
-	statC      chan struct{}
-
-	cs *connSupervisor
-}
-
-func newTryToDialActuator(pid peer.ID, addr ma.Multiaddr, cs *connSupervisor, tryTimes int) *tryToDialActuator {
-	return &tryToDialActuator{
-		peerId:     pid,
-		peerAddr:   addr,
-		fibonacci:  utils.FibonacciArray(tryTimes),
-		idx:        0,
-		giveUp:     false,
-		finishFlag: 0,
-		statC:      make(chan struct{}, 1),
-		cs:         cs,
+// resetCheckTimer schedule the next checking after supervisorCheckInterval.
+// It should be called in the loop goroutine only, so that the timer fired could be drained safely.
+func (c *connSupervisor) resetCheckTimer() {
+	if !c.checkTimer.Stop() {
+		select {
+		case <-c.checkTimer.C:
+		default:
+		}
 	}
+	c.checkTimer.Reset(supervisorCheckInterval)
 }
 
-func (a *tryToDialActuator) hasFinished() bool {
-	return atomic.LoadInt32(&a.finishFlag) == 1
-}
-
-func (a *tryToDialActuator) finished() {
-	atomic.StoreInt32(&a.finishFlag, 1)
-}
-
-func (a *tryToDialActuator) run() {
-	select {
-	case a.statC <- struct{}{}:
-		defer func() {
-			<-a.statC
-		}()
-	default:
+// startDialing start the dialing loop of the peer if it is not running and not given up,
+// otherwise wake up the dialing in backoff. It should be called when c locked.
+func (c *connSupervisor) startDialing(e *directPeerEntry) {
+	if e.running {
+		select {
+		case e.wakeC <- struct{}{}:
+		default:
+		}
 		return
 	}
-	if a.giveUp || a.hasFinished() {
+	if e.state == mgr.DirectPeerStateGaveUp {
 		return
 	}
-Loop:
-	for {
-		select {
-		case <-a.cs.closeChan:
-			break Loop
-		default:
+	e.running = true
+	go c.dialLoop(e, c.closeChan)
+}
 
-		}
-		if a.cs.host.ConnMgr().IsConnected(a.peerId) {
-			a.finished()
-			break Loop
-		}
-		a.cs.logger.Infof("[ConnSupervisor] try to dial to peer(pid: %s,addr:%s)", a.peerId, a.peerAddr.String())
-		conn, err := a.cs.host.Dial(a.peerAddr)
+// backoff return the interval before the next dialing after failures, with random jitter.
+func (c *connSupervisor) backoff(failures int) time.Duration {
+	d := c.backoffBase
+	for i := 1; i < failures && d < c.backoffMax; i++ {
+		d = d * 2
+	}
+	if d > c.backoffMax {
+		d = c.backoffMax
+	}
+	jitter := (rand.Float64()*2 - 1) * supervisorBackoffJitter
+	return time.Duration(float64(d) * (1 + jitter))
+}
+
+// dialLoop keep dialing to the peer until connected, removed, supervisor stopped or max try times reached.
+func (c *connSupervisor) dialLoop(e *directPeerEntry, closeChan chan struct{}) {
+	defer func() {
+		c.Lock()
+		e.running = false
+		c.Unlock()
+	}()
+	ctx, cancel := context.WithCancel(WithDialPriority(c.host.Context(), DialPriorityHigh))
+	defer cancel()
+	go func() {
 		select {
-		case <-a.cs.closeChan:
-			break Loop
-		default:
+		case <-closeChan:
+		case <-e.removedC:
+		case <-ctx.Done():
 		}
-		if a.cs.host.ConnMgr().IsConnected(a.peerId) {
-			a.finished()
-			break Loop
+		cancel()
+	}()
+	for {
+		if ctx.Err() != nil {
+			return
 		}
-		if err != nil {
-			a.cs.logger.Warnf("[ConnSupervisor] try to dial to peer failed(peer: %s, times: %d),%s",
-				a.peerId, a.idx+1, err.Error())
-			a.idx = a.idx + 1
-			if a.idx >= len(a.fibonacci) {
-				a.cs.logger.Warnf("[ConnSupervisor] can not dial to peer, give it up. (peer:%s)", a.peerId)
-				a.giveUp = true
-				break
-			}
-			timeout := time.Duration(a.fibonacci[a.idx]) * time.Second
-			time.Sleep(timeout)
-			continue Loop
+		if c.host.ConnMgr().IsConnected(e.pid) {
+			c.markConnected(e)
+			return
 		}
-		if conn == nil {
-			a.cs.logger.Errorf("[ConnSupervisor] try to dial to peer(pid: %s) failed, nil conn", a.peerId)
-			a.idx = a.idx + 1
-			if a.idx >= len(a.fibonacci) {
-				a.cs.logger.Warnf("[ConnSupervisor] can not dial to peer, give it up. (peer:%s)", a.peerId)
-				a.giveUp = true
-				break Loop
-			}
-			timeout := time.Duration(a.fibonacci[a.idx]) * time.Second
-			time.Sleep(timeout)
-			continue Loop
+		c.Lock()
+		e.state = mgr.DirectPeerStateDialing
+		e.lastAttempt = time.Now()
+		c.Unlock()
+		c.logger.Infof("[ConnSupervisor] try to dial to peer(pid: %s)", e.pid)
+		conn, err := c.host.DialPeer(ctx, e.pid)
+		if ctx.Err() != nil {
+			return
 		}
-		if a.peerId != conn.RemotePeerID() {
-			a.cs.logger.Errorf("[ConnSupervisor] try to dial to peer(pid: %s) failed, pid mismatch(got: %s), "+
-				"close the connection.", a.peerId, conn.RemotePeerID())
+		if err == nil && conn != nil && conn.RemotePeerID() != e.pid {
+			c.logger.Errorf("[ConnSupervisor] try to dial to peer(pid: %s) failed, pid mismatch(got: %s), "+
+				"close the connection.", e.pid, conn.RemotePeerID())
 			_ = conn.Close()
-			break Loop
+			err = ErrSupervisorPidMismatch
+		}
+		if err == nil || c.host.ConnMgr().IsConnected(e.pid) {
+			c.logger.Debugf("[ConnSupervisor] dial to peer(pid: %s) success", e.pid)
+			c.markConnected(e)
+			return
+		}
+
+		c.Lock()
+		if err != ErrDialBackoff {
+			// the peer in backoff of DialManager is not counted as a failure of dialing
+			e.attempts++
+		}
+		e.lastErr = err
+		if c.tryTimes > 0 && e.attempts >= c.tryTimes {
+			e.state = mgr.DirectPeerStateGaveUp
+			c.Unlock()
+			c.logger.Warnf("[ConnSupervisor] can not dial to peer, give it up. (peer:%s)", e.pid)
+			return
+		}
+		timeout := c.backoff(e.attempts)
+		e.state = mgr.DirectPeerStateBackoff
+		e.nextAttempt = time.Now().Add(timeout)
+		attempts := e.attempts
+		c.Unlock()
+		c.logger.Warnf("[ConnSupervisor] try to dial to peer failed(peer: %s, times: %d, next: %s),%s",
+			e.pid, attempts, timeout.String(), err.Error())
+
+		timer := time.NewTimer(timeout)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-e.wakeC:
+			timer.Stop()
+		case <-timer.C:
 		}
-		a.cs.logger.Debugf("[ConnSupervisor] dial to peer(pid: %s) success", a.peerId)
-		a.finished()
-		break Loop
 	}
 }
+
+func (c *connSupervisor) markConnected(e *directPeerEntry) {
+	c.Lock()
+	defer c.Unlock()
+	e.state = mgr.DirectPeerStateConnected
+	e.attempts = 0
+	e.lastErr = nil
+	e.nextAttempt = time.Time{}
+}
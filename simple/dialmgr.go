@@ -0,0 +1,323 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/network"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	api "chainmaker.org/chainmaker/protocol/v2"
+)
+
+// DialPriority is the priority of dialing in the queue of DialManager.
+type DialPriority int
+
+const (
+	// DialPriorityLow is the priority for dialing to the peers discovered.
+	DialPriorityLow DialPriority = iota
+	// DialPriorityNormal is the default priority.
+	DialPriorityNormal
+	// DialPriorityHigh is the priority for dialing to the direct peers and the consensus peers.
+	DialPriorityHigh
+)
+
+const (
+	// DefaultMaxConcurrentDials is the default max count of peers dialing at the same time.
+	DefaultMaxConcurrentDials = 16
+	// DefaultMaxDialFDs is the default max count of file descriptors used by dialing at the same time.
+	DefaultMaxDialFDs = 64
+	// DefaultDialBackoffBase is the default backoff duration after the first failure of dialing to a peer.
+	// The duration will be doubled after each failure until DefaultDialBackoffMax.
+	DefaultDialBackoffBase = 5 * time.Second
+	// DefaultDialBackoffMax is the default max backoff duration of dialing to a peer.
+	DefaultDialBackoffMax = 5 * time.Minute
+)
+
+var (
+	// ErrDialBackoff will be returned if dialing to a peer which is in backoff after failures.
+	ErrDialBackoff = errors.New("dial backoff")
+)
+
+type dialPriorityKey struct{}
+
+// WithDialPriority return a new context carrying the dial priority given.
+func WithDialPriority(ctx context.Context, priority DialPriority) context.Context {
+	return context.WithValue(ctx, dialPriorityKey{}, priority)
+}
+
+// DialPriorityFromContext return the dial priority carried by the context, and whether it exists.
+func DialPriorityFromContext(ctx context.Context) (DialPriority, bool) {
+	priority, ok := ctx.Value(dialPriorityKey{}).(DialPriority)
+	return priority, ok
+}
+
+type noDialBackoffKey struct{}
+
+// WithoutDialBackoff return a new context which makes the dialing ignore the backoff of peer,
+// and the failure of which will not put the peer into backoff,
+// e.g. for the peers maintained by ConnSupervisor with its own backoff.
+func WithoutDialBackoff(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noDialBackoffKey{}, true)
+}
+
+func noDialBackoff(ctx context.Context) bool {
+	v, _ := ctx.Value(noDialBackoffKey{}).(bool)
+	return v
+}
+
+// DialFunc is a function that dial to a peer.
+type DialFunc func(ctx context.Context) (network.Conn, error)
+
+type dialJob struct {
+	pid      peer.ID
+	priority DialPriority
+	seq      uint64
+	dial     DialFunc
+	index    int  // index in queue, -1 means not queued
+	exempt   bool // whether exempt from backoff
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+
+	done chan struct{}
+	conn network.Conn
+	err  error
+}
+
+// dialQueue is a priority queue of dial jobs implemented heap.Interface.
+// The job with higher priority pops first, and the jobs with the same priority pop in FIFO.
+type dialQueue []*dialJob
+
+func (q dialQueue) Len() int { return len(q) }
+
+func (q dialQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q dialQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *dialQueue) Push(x interface{}) {
+	job, _ := x.(*dialJob)
+	job.index = len(*q)
+	*q = append(*q, job)
+}
+
+func (q *dialQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	job.index = -1
+	*q = old[:n-1]
+	return job
+}
+
+type dialBackoff struct {
+	failures int
+	until    time.Time
+}
+
+// DialManager manages all outbound dialing of a host.
+// Concurrent dialing to the same peer will be coalesced into one attempt.
+// The count of peers dialing at the same time and the file descriptors used by dialing are capped,
+// the dialing waiting in queue will be started with priority.
+// A peer will be in backoff after dialing failures, and dialing to it will return ErrDialBackoff until timeout,
+// unless the dialing is exempt from backoff with WithoutDialBackoff.
+type DialManager struct {
+	mu      sync.Mutex
+	active  map[peer.ID]*dialJob
+	queue   dialQueue
+	seq     uint64
+	running int
+
+	maxConcurrent int
+	fdLimiter     chan struct{}
+
+	backoffs    map[peer.ID]*dialBackoff
+	backoffBase time.Duration
+	backoffMax  time.Duration
+
+	logger api.Logger
+}
+
+// NewDialManager create a new *DialManager instance.
+// If maxConcurrent is not greater than 0, DefaultMaxConcurrentDials will be used.
+// If maxFDs is not greater than 0, DefaultMaxDialFDs will be used.
+func NewDialManager(maxConcurrent, maxFDs int, logger api.Logger) *DialManager {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentDials
+	}
+	if maxFDs <= 0 {
+		maxFDs = DefaultMaxDialFDs
+	}
+	return &DialManager{
+		active:        make(map[peer.ID]*dialJob),
+		queue:         make(dialQueue, 0),
+		maxConcurrent: maxConcurrent,
+		fdLimiter:     make(chan struct{}, maxFDs),
+		backoffs:      make(map[peer.ID]*dialBackoff),
+		backoffBase:   DefaultDialBackoffBase,
+		backoffMax:    DefaultDialBackoffMax,
+		logger:        logger,
+	}
+}
+
+// SetBackoff set the base and the max duration of backoff.
+func (m *DialManager) SetBackoff(base, max time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.backoffBase = base
+	m.backoffMax = max
+}
+
+// Dial run the dial function given for the peer with the priority given, and wait for the result.
+// If another dialing to the same peer is running or queued, the dial function given will be ignored
+// and the result of that dialing will be returned.
+func (m *DialManager) Dial(ctx context.Context, pid peer.ID, priority DialPriority, dial DialFunc) (network.Conn, error) {
+	exempt := noDialBackoff(ctx)
+	m.mu.Lock()
+	if b, ok := m.backoffs[pid]; ok && !exempt && time.Now().Before(b.until) {
+		m.mu.Unlock()
+		return nil, ErrDialBackoff
+	}
+	job, ok := m.active[pid]
+	if !ok {
+		m.seq++
+		jobCtx, cancel := context.WithCancel(context.Background())
+		job = &dialJob{
+			pid:      pid,
+			priority: priority,
+			seq:      m.seq,
+			dial:     dial,
+			ctx:      jobCtx,
+			cancel:   cancel,
+			done:     make(chan struct{}),
+		}
+		m.active[pid] = job
+		heap.Push(&m.queue, job)
+	} else if job.index >= 0 && priority > job.priority {
+		// upgrade the priority of the job queued
+		job.priority = priority
+		heap.Fix(&m.queue, job.index)
+	}
+	if exempt {
+		job.exempt = true
+	}
+	job.waiters++
+	m.schedule()
+	m.mu.Unlock()
+
+	select {
+	case <-job.done:
+		return job.conn, job.err
+	case <-ctx.Done():
+		m.mu.Lock()
+		job.waiters--
+		if job.waiters == 0 {
+			// nobody cares about the result any more
+			job.cancel()
+			if job.index >= 0 {
+				heap.Remove(&m.queue, job.index)
+				delete(m.active, pid)
+				job.err = ctx.Err()
+				close(job.done)
+			}
+		}
+		m.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// schedule start the jobs in queue until reaching the cap. It should be called when m.mu locked.
+func (m *DialManager) schedule() {
+	for m.running < m.maxConcurrent && m.queue.Len() > 0 {
+		job, _ := heap.Pop(&m.queue).(*dialJob)
+		m.running++
+		go m.run(job)
+	}
+}
+
+func (m *DialManager) run(job *dialJob) {
+	conn, err := job.dial(job.ctx)
+	job.cancel()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running--
+	delete(m.active, job.pid)
+	switch {
+	case err == nil:
+		delete(m.backoffs, job.pid)
+	case errors.Is(err, context.Canceled):
+		// canceled by waiters, not a failure of peer
+	case job.exempt:
+		m.logger.Debugf("[DialManager] dial failed, exempt from backoff (remote pid: %s)", job.pid)
+	default:
+		b, ok := m.backoffs[job.pid]
+		if !ok {
+			b = &dialBackoff{}
+			m.backoffs[job.pid] = b
+		}
+		b.failures++
+		d := m.backoffBase
+		for i := 1; i < b.failures && d < m.backoffMax; i++ {
+			d = d * 2
+		}
+		if d > m.backoffMax {
+			d = m.backoffMax
+		}
+		b.until = time.Now().Add(d)
+		m.logger.Debugf("[DialManager] dial failed, backoff %s (remote pid: %s, failures: %d)",
+			d.String(), job.pid, b.failures)
+	}
+	job.conn, job.err = conn, err
+	close(job.done)
+	m.schedule()
+}
+
+// ClearBackoff remove the backoff of peer. It should be called when the peer connected.
+func (m *DialManager) ClearBackoff(pid peer.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.backoffs, pid)
+}
+
+// InBackoff return whether the peer is in backoff.
+func (m *DialManager) InBackoff(pid peer.ID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.backoffs[pid]
+	return ok && time.Now().Before(b.until)
+}
+
+// AcquireFD acquire a file descriptor for dialing, block until available or ctx done.
+// ReleaseFD should be called after dialing finished.
+func (m *DialManager) AcquireFD(ctx context.Context) error {
+	select {
+	case m.fdLimiter <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReleaseFD release a file descriptor acquired by AcquireFD.
+func (m *DialManager) ReleaseFD() {
+	<-m.fdLimiter
+}
@@ -0,0 +1,105 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/network"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialManager(t *testing.T) {
+	m := NewDialManager(1, 0, logger.NilLogger)
+	m.SetBackoff(50*time.Millisecond, time.Second)
+	ctx := context.Background()
+
+	// concurrent dialing to the same peer will be coalesced
+	var calls int32
+	release := make(chan struct{})
+	blockingDial := func(ctx context.Context) (network.Conn, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return nil, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.Dial(ctx, "pid1", DialPriorityNormal, blockingDial)
+			require.Nil(t, err)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	// queued dialing pops with priority
+	orderC := make(chan peer.ID, 2)
+	recordDial := func(pid peer.ID) DialFunc {
+		return func(ctx context.Context) (network.Conn, error) {
+			orderC <- pid
+			return nil, nil
+		}
+	}
+	go func() { _, _ = m.Dial(ctx, "low", DialPriorityLow, recordDial("low")) }()
+	time.Sleep(10 * time.Millisecond)
+	go func() { _, _ = m.Dial(ctx, "high", DialPriorityHigh, recordDial("high")) }()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.EqualValues(t, 1, atomic.LoadInt32(&calls))
+	require.Equal(t, peer.ID("high"), <-orderC)
+	require.Equal(t, peer.ID("low"), <-orderC)
+
+	// peer will be in backoff after failure
+	errDial := errors.New("dial failed")
+	failedDial := func(ctx context.Context) (network.Conn, error) {
+		return nil, errDial
+	}
+	_, err := m.Dial(ctx, "pid2", DialPriorityNormal, failedDial)
+	require.Equal(t, errDial, err)
+	require.True(t, m.InBackoff("pid2"))
+	_, err = m.Dial(ctx, "pid2", DialPriorityNormal, failedDial)
+	require.Equal(t, ErrDialBackoff, err)
+	time.Sleep(60 * time.Millisecond)
+	require.False(t, m.InBackoff("pid2"))
+	m.ClearBackoff("pid2")
+	_, err = m.Dial(ctx, "pid2", DialPriorityNormal, recordDial("pid2"))
+	require.Nil(t, err)
+	require.Equal(t, peer.ID("pid2"), <-orderC)
+
+	// dialing exempt from backoff ignores it and never puts peer into backoff
+	_, err = m.Dial(ctx, "pid2", DialPriorityNormal, failedDial)
+	require.Equal(t, errDial, err)
+	require.True(t, m.InBackoff("pid2"))
+	_, err = m.Dial(WithoutDialBackoff(ctx), "pid2", DialPriorityNormal, recordDial("pid2"))
+	require.Nil(t, err)
+	require.Equal(t, peer.ID("pid2"), <-orderC)
+	m.ClearBackoff("pid2")
+	_, err = m.Dial(WithoutDialBackoff(ctx), "pid2", DialPriorityNormal, failedDial)
+	require.Equal(t, errDial, err)
+	require.False(t, m.InBackoff("pid2"))
+
+	// waiter gone, the dialing queued will be canceled
+	release = make(chan struct{})
+	go func() { _, _ = m.Dial(ctx, "pid3", DialPriorityNormal, blockingDial) }()
+	time.Sleep(10 * time.Millisecond)
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = m.Dial(cctx, "pid4", DialPriorityNormal, recordDial("pid4"))
+	require.Equal(t, context.DeadlineExceeded, err)
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	require.Empty(t, orderC)
+}
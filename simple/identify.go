@@ -0,0 +1,363 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/handler"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/network"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/simple/pb"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// IdentifyProtocolID is the protocol.ID for identify service.
+	IdentifyProtocolID protocol.ID = "/identify/v0.0.1"
+	// DefaultAgentVersion is the default agent version string sent to others.
+	DefaultAgentVersion = "chainmaker-net-liquid"
+	// DefaultIdentifyPushCheckInterval is the default interval of checking whether addresses announced changed.
+	DefaultIdentifyPushCheckInterval = 30 * time.Second
+	// MaxObservedAddrs is the max count of different addresses observed recorded.
+	MaxObservedAddrs = 64
+)
+
+var (
+	// ErrIdentifyPubKeyMismatch will be returned if the public key received mismatch the sender peer id.
+	ErrIdentifyPubKeyMismatch = errors.New("public key mismatch the sender peer id")
+)
+
+// IdentifyService provides an identify protocol exchanging the listen addresses, the address observed,
+// the public key, the agent version and the protocols supported between hosts.
+// The information received will be stored in the store.PeerStore of the host.
+// The addresses announced by the host (see host.Host.AnnounceAddrs) will be sent as the listen addresses.
+// It also pushes updates to all peers connected when the addresses announced by the host changed.
+type IdentifyService struct {
+	host          host.Host
+	agentVersion  string
+	checkInterval time.Duration
+
+	observedMu    sync.RWMutex
+	observedAddrs map[string]map[peer.ID]struct{} // map[addr string]peers observed
+	observers     map[peer.ID]string              // map[peer]addr string observed by peer
+
+	addrsMu   sync.Mutex
+	lastAddrs []ma.Multiaddr
+
+	closeC chan struct{}
+	once   sync.Once
+
+	logger api.Logger
+}
+
+// NewIdentifyService create a new *IdentifyService instance.
+// If agentVersion is empty, DefaultAgentVersion will be used.
+func NewIdentifyService(h host.Host, agentVersion string, logger api.Logger) *IdentifyService {
+	if agentVersion == "" {
+		agentVersion = DefaultAgentVersion
+	}
+	return &IdentifyService{
+		host:          h,
+		agentVersion:  agentVersion,
+		checkInterval: DefaultIdentifyPushCheckInterval,
+		observedAddrs: make(map[string]map[peer.ID]struct{}),
+		observers:     make(map[peer.ID]string),
+		logger:        logger,
+	}
+}
+
+// ProtocolID is the protocol.ID of identify service.
+// The protocol id will be registered in host.RegisterMsgPayloadHandler method.
+func (s *IdentifyService) ProtocolID() protocol.ID {
+	return IdentifyProtocolID
+}
+
+// Handle is the msg payload handler of identify service.
+// It will be registered in host.Host.RegisterMsgPayloadHandler method.
+func (s *IdentifyService) Handle() handler.MsgPayloadHandler {
+	return func(senderPID peer.ID, msgPayload []byte) {
+		msg := &pb.IdentifyMsg{}
+		err := proto.Unmarshal(msgPayload, msg)
+		if err != nil {
+			s.logger.Errorf("[IdentifyService] handler msg payload failed, %s (sender id: %s)",
+				err.Error(), senderPID)
+			return
+		}
+		if err = s.consume(senderPID, msg); err != nil {
+			s.logger.Warnf("[IdentifyService] consume identify msg failed, %s (sender id: %s)",
+				err.Error(), senderPID)
+		}
+	}
+}
+
+// consume store the information in identify msg into the store.PeerStore of the host.
+func (s *IdentifyService) consume(senderPID peer.ID, msg *pb.IdentifyMsg) error {
+	ps := s.host.PeerStore()
+	if len(msg.PublicKey) > 0 {
+		pubKey, err := asym.PublicKeyFromDER(msg.PublicKey)
+		if err != nil {
+			return err
+		}
+		pid, err := util.ResolvePIDFromPubKey(pubKey)
+		if err != nil {
+			return err
+		}
+		if pid != senderPID {
+			return ErrIdentifyPubKeyMismatch
+		}
+		ps.SetPubKey(senderPID, pubKey)
+	}
+	ps.SetAgentVersion(senderPID, msg.AgentVersion)
+
+	addrs := make([]ma.Multiaddr, 0, len(msg.ListenAddrs))
+	for i := range msg.ListenAddrs {
+		addr, err := ma.NewMultiaddr(msg.ListenAddrs[i])
+		if err != nil {
+			s.logger.Debugf("[IdentifyService] invalid listen address, %s (sender id: %s)", err.Error(), senderPID)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	// listen addresses told by peer itself rank before the remote addresses of inbound connections,
+	// because the remote port of an inbound connection is always ephemeral.
+	ps.AddAddrWithTTL(senderPID, store.AddrSourceIdentify, store.ConnectedAddrTTL, addrs...)
+
+	if len(msg.Protocols) > 0 {
+		s.host.ProtocolMgr().SetPeerSupportedProtocols(senderPID, protocol.ParseStringsToIDs(msg.Protocols))
+	}
+
+	if msg.ObservedAddr != "" {
+		observed, err := ma.NewMultiaddr(msg.ObservedAddr)
+		if err != nil {
+			s.logger.Debugf("[IdentifyService] invalid observed address, %s (sender id: %s)", err.Error(), senderPID)
+			return nil
+		}
+		s.recordObservedAddr(senderPID, observed)
+	}
+	return nil
+}
+
+// recordObservedAddr record the address that the peer observed us at.
+// Each peer counts once, only the latest address observed by it will be kept.
+// If MaxObservedAddrs reached, the address observed by the fewest peers will be evicted for a new one.
+func (s *IdentifyService) recordObservedAddr(pid peer.ID, addr ma.Multiaddr) {
+	addrStr := addr.String()
+	s.observedMu.Lock()
+	defer s.observedMu.Unlock()
+	if old, ok := s.observers[pid]; ok {
+		if old == addrStr {
+			return
+		}
+		s.removeObserverLocked(pid, old)
+	}
+	observers, ok := s.observedAddrs[addrStr]
+	if !ok {
+		if len(s.observedAddrs) >= MaxObservedAddrs {
+			s.evictObservedAddrLocked()
+		}
+		observers = make(map[peer.ID]struct{})
+		s.observedAddrs[addrStr] = observers
+	}
+	observers[pid] = struct{}{}
+	s.observers[pid] = addrStr
+}
+
+// evictObservedAddrLocked remove the address observed by the fewest peers.
+func (s *IdentifyService) evictObservedAddrLocked() {
+	var (
+		evict string
+		count int
+	)
+	for addrStr, observers := range s.observedAddrs {
+		if evict == "" || len(observers) < count {
+			evict, count = addrStr, len(observers)
+		}
+	}
+	for pid := range s.observedAddrs[evict] {
+		delete(s.observers, pid)
+	}
+	delete(s.observedAddrs, evict)
+}
+
+func (s *IdentifyService) removeObserverLocked(pid peer.ID, addrStr string) {
+	delete(s.observers, pid)
+	observers := s.observedAddrs[addrStr]
+	delete(observers, pid)
+	if len(observers) == 0 {
+		delete(s.observedAddrs, addrStr)
+	}
+}
+
+// PeerDisconnected should be called when a peer disconnected,
+// the address observed by the peer will be expired.
+func (s *IdentifyService) PeerDisconnected(pid peer.ID) {
+	s.observedMu.Lock()
+	defer s.observedMu.Unlock()
+	if addrStr, ok := s.observers[pid]; ok {
+		s.removeObserverLocked(pid, addrStr)
+	}
+}
+
+// ObservedAddrs return the list of addresses that other peers observed us at.
+// The addresses observed by more peers will be in the front of the list.
+func (s *IdentifyService) ObservedAddrs() []ma.Multiaddr {
+	s.observedMu.RLock()
+	defer s.observedMu.RUnlock()
+	addrs := make([]string, 0, len(s.observedAddrs))
+	for addr := range s.observedAddrs {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		if len(s.observedAddrs[addrs[i]]) != len(s.observedAddrs[addrs[j]]) {
+			return len(s.observedAddrs[addrs[i]]) > len(s.observedAddrs[addrs[j]])
+		}
+		return addrs[i] < addrs[j]
+	})
+	res := make([]ma.Multiaddr, 0, len(addrs))
+	for i := range addrs {
+		res = append(res, ma.StringCast(addrs[i]))
+	}
+	return res
+}
+
+func (s *IdentifyService) createMsg(msgType pb.IdentifyMsg_IdentifyMsgType, observed ma.Multiaddr) ([]byte, error) {
+	pubKey, err := s.host.PrivateKey().PublicKey().Bytes()
+	if err != nil {
+		return nil, err
+	}
+	lAddrs := s.host.AnnounceAddrs()
+	listenAddrs := make([]string, 0, len(lAddrs))
+	for i := range lAddrs {
+		listenAddrs = append(listenAddrs, lAddrs[i].String())
+	}
+	msg := &pb.IdentifyMsg{
+		MsgType:      msgType,
+		ListenAddrs:  listenAddrs,
+		PublicKey:    pubKey,
+		AgentVersion: s.agentVersion,
+		Protocols:    protocol.ParseIDsToStrings(s.host.ProtocolMgr().GetSelfSupportedProtocols()),
+	}
+	if observed != nil {
+		msg.ObservedAddr = observed.String()
+	}
+	return proto.Marshal(msg)
+}
+
+// Identify send the identify information of us to the remote peer of the connection given.
+// The remote address of the connection will be sent as the address observed.
+func (s *IdentifyService) Identify(conn network.Conn) error {
+	bytes, err := s.createMsg(pb.IdentifyMsg_IDENTIFY, conn.RemoteAddr())
+	if err != nil {
+		return err
+	}
+	return s.host.SendMsg(IdentifyProtocolID, conn.RemotePeerID(), bytes)
+}
+
+// PushToAll push the identify information of us to all peers connected.
+func (s *IdentifyService) PushToAll() {
+	pids := s.host.ConnMgr().AllPeer()
+	var wg sync.WaitGroup
+	for i := range pids {
+		pid := pids[i]
+		if !s.host.IsPeerSupportProtocol(pid, IdentifyProtocolID) {
+			continue
+		}
+		conn := s.host.ConnMgr().GetPeerConn(pid)
+		if conn == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(pid peer.ID, conn network.Conn) {
+			defer wg.Done()
+			bytes, err := s.createMsg(pb.IdentifyMsg_PUSH, conn.RemoteAddr())
+			if err != nil {
+				s.logger.Errorf("[IdentifyService] create push msg failed, %s", err.Error())
+				return
+			}
+			if err = s.host.SendMsg(IdentifyProtocolID, pid, bytes); err != nil {
+				s.logger.Debugf("[IdentifyService] push identify failed, %s (remote pid: %s)", err.Error(), pid)
+			}
+		}(pid, conn)
+	}
+	wg.Wait()
+}
+
+// Start the background task pushing updates when the addresses announced changed.
+func (s *IdentifyService) Start() error {
+	s.once.Do(func() {
+		s.addrsMu.Lock()
+		s.lastAddrs = s.host.AnnounceAddrs()
+		s.addrsMu.Unlock()
+		s.closeC = make(chan struct{})
+		go s.pushLoop(s.closeC)
+	})
+	return nil
+}
+
+// Stop the background task.
+func (s *IdentifyService) Stop() error {
+	if s.closeC == nil {
+		return nil
+	}
+	close(s.closeC)
+	s.closeC = nil
+	s.once = sync.Once{}
+	return nil
+}
+
+func (s *IdentifyService) pushLoop(closeC chan struct{}) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closeC:
+			return
+		case <-ticker.C:
+			s.PushIfAddrsChanged()
+		}
+	}
+}
+
+// PushIfAddrsChanged push updates to all peers connected if the addresses announced changed since last checking.
+func (s *IdentifyService) PushIfAddrsChanged() {
+	addrs := s.host.AnnounceAddrs()
+	s.addrsMu.Lock()
+	changed := !sameAddrs(s.lastAddrs, addrs)
+	s.lastAddrs = addrs
+	s.addrsMu.Unlock()
+	if changed {
+		s.logger.Infof("[IdentifyService] addresses announced changed, push identify to all peers.")
+		s.PushToAll()
+	}
+}
+
+func sameAddrs(a, b []ma.Multiaddr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	m := make(map[string]struct{}, len(a))
+	for i := range a {
+		m[a[i].String()] = struct{}{}
+	}
+	for i := range b {
+		if _, ok := m[b[i].String()]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"strconv"
+	"testing"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentifyObservedAddrs(t *testing.T) {
+	s := NewIdentifyService(nil, "", logger.NilLogger)
+	addr1 := ma.StringCast("/ip4/1.2.3.4/tcp/8081")
+	addr2 := ma.StringCast("/ip4/1.2.3.4/tcp/8082")
+	pid1, pid2, pid3 := peer.ID("peer1"), peer.ID("peer2"), peer.ID("peer3")
+
+	// each peer counts once
+	s.recordObservedAddr(pid1, addr1)
+	s.recordObservedAddr(pid1, addr1)
+	s.recordObservedAddr(pid1, addr1)
+	s.recordObservedAddr(pid2, addr2)
+	s.recordObservedAddr(pid3, addr2)
+	require.Equal(t, []ma.Multiaddr{addr2, addr1}, s.ObservedAddrs())
+
+	// only the latest address observed by peer kept
+	s.recordObservedAddr(pid1, addr2)
+	require.Equal(t, []ma.Multiaddr{addr2}, s.ObservedAddrs())
+
+	// expired on disconnection
+	s.PeerDisconnected(pid1)
+	s.PeerDisconnected(pid2)
+	s.PeerDisconnected(pid3)
+	require.Empty(t, s.ObservedAddrs())
+	require.Empty(t, s.observers)
+
+	// bounded, the address observed by the fewest peers evicted
+	s.recordObservedAddr(pid1, addr1)
+	s.recordObservedAddr(pid2, addr1)
+	for i := 0; i < MaxObservedAddrs*2; i++ {
+		s.recordObservedAddr(peer.ID("p"+strconv.Itoa(i)), ma.StringCast("/ip4/5.6.7.8/tcp/"+strconv.Itoa(10000+i)))
+	}
+	addrs := s.ObservedAddrs()
+	require.Equal(t, MaxObservedAddrs, len(addrs))
+	require.Equal(t, addr1, addrs[0])
+	require.Equal(t, MaxObservedAddrs+1, len(s.observers))
+}
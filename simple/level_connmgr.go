@@ -9,6 +9,7 @@ package simple
 import (
 	"errors"
 	"math/rand"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -34,10 +35,12 @@ const (
 	FIFO EliminationStrategy = "FIFO"
 	// LIFO LAST_IN_FIRST_OUT elimination strategy
 	LIFO EliminationStrategy = "LIFO"
+	// Score LOWEST_SCORE_FIRST_OUT elimination strategy
+	Score EliminationStrategy = "SCORE"
 )
 
 // EliminationStrategyFromInt get EliminationStrategy with a int value.
-// 1.Random, 2.FIFO, 3.LIFO
+// 1.Random, 2.FIFO, 3.LIFO, 4.Score
 func EliminationStrategyFromInt(strategy int) EliminationStrategy {
 	switch strategy {
 	case 1:
@@ -46,6 +49,8 @@ func EliminationStrategyFromInt(strategy int) EliminationStrategy {
 		return FIFO
 	case 3:
 		return LIFO
+	case 4:
+		return Score
 	default:
 		return Unknown
 		//panic(errors.New("unknown elimination strategy"))
@@ -63,12 +68,23 @@ const DefaultMaxConnCountEachPeerAllowed = 1
 // DefaultEliminationStrategy is the default strategy for elimination.
 const DefaultEliminationStrategy = LIFO
 
+// DefaultGracePeriod is the default duration that a new peer will not be trimmed.
+const DefaultGracePeriod = 30 * time.Second
+
+// DefaultTrimInterval is the default interval of the background trimming loop.
+const DefaultTrimInterval = time.Minute
+
+// trimmingHookTimeout is the max duration waiting for the hooks called before trimming.
+const trimmingHookTimeout = 3 * time.Second
+
 type peerConnections struct {
-	pid  peer.ID
-	conn *types.ConnSet
+	pid     peer.ID
+	conn    *types.ConnSet
+	addedAt time.Time
 }
 
 var _ mgr.ConnMgr = (*LevelConnManager)(nil)
+var _ mgr.TrimNotifier = (*LevelConnManager)(nil)
 
 // LevelConnManager is a connection manager of peers.
 type LevelConnManager struct {
@@ -87,13 +103,29 @@ type LevelConnManager struct {
 	expandingC chan struct{}
 
 	eliminatePeers *types.PeerIdSet
+
+	scorer *PeerScorer
+
+	tagLock   sync.RWMutex
+	tags      map[peer.ID]map[string]int
+	protected map[peer.ID]map[string]struct{}
+
+	lowWater     int
+	highWater    int
+	gracePeriod  time.Duration
+	trimInterval time.Duration
+	trimSignal   chan struct{}
+	closeC       chan struct{}
+
+	hooksLock     sync.RWMutex
+	trimmingHooks []mgr.PeerTrimmingHook
 }
 
 // SetStrategy set the elimination strategy. If not set, default is LIFO.
 func (cm *LevelConnManager) SetStrategy(strategy EliminationStrategy) {
 	strategy = EliminationStrategy(strings.ToUpper(string(strategy)))
 	switch strategy {
-	case Random, FIFO, LIFO:
+	case Random, FIFO, LIFO, Score:
 		cm.strategy = strategy
 	default:
 		cm.logger.Warnf("[LevelConnManager] wrong strategy set(strategy:%s). use default(default:%s)",
@@ -122,6 +154,32 @@ func (cm *LevelConnManager) SetMaxConnCountEachPeerAllowed(max int) {
 	cm.maxConnCountEachPeerAllowed = max
 }
 
+// SetWatermarks set the low and high watermarks of peer count.
+// If high watermark is greater than 0, connections will not be eliminated when adding,
+// they will be trimmed to the low watermark in a background loop when the count of peers exceeds the high watermark.
+// Otherwise, watermarks are disabled.
+func (cm *LevelConnManager) SetWatermarks(low, high int) {
+	cm.cmLock.Lock()
+	defer cm.cmLock.Unlock()
+	if high > 0 && (low < 0 || low > high) {
+		cm.logger.Warnf("[LevelConnManager] wrong low watermark set(low:%d, high:%d). use high watermark.",
+			low, high)
+		low = high
+	}
+	cm.lowWater = low
+	cm.highWater = high
+}
+
+// SetGracePeriod set the duration that a new peer will not be trimmed. If not set, default is 30s.
+func (cm *LevelConnManager) SetGracePeriod(gracePeriod time.Duration) {
+	if gracePeriod < 0 {
+		cm.logger.Warnf("[LevelConnManager] wrong grace period set(grace period:%s). use default(default:%s)",
+			gracePeriod, DefaultGracePeriod)
+		gracePeriod = DefaultGracePeriod
+	}
+	cm.gracePeriod = gracePeriod
+}
+
 // NewLevelConnManager create a new LevelConnManager.
 func NewLevelConnManager(logger api.Logger, h host.Host) *LevelConnManager {
 	return &LevelConnManager{
@@ -135,12 +193,40 @@ func NewLevelConnManager(logger api.Logger, h host.Host) *LevelConnManager {
 		host:                        h,
 		expandingC:                  make(chan struct{}, 1),
 		eliminatePeers:              &types.PeerIdSet{},
+		scorer:                      NewPeerScorer(h),
+		tags:                        make(map[peer.ID]map[string]int),
+		protected:                   make(map[peer.ID]map[string]struct{}),
+		gracePeriod:                 DefaultGracePeriod,
+		trimInterval:                DefaultTrimInterval,
+		trimSignal:                  make(chan struct{}, 1),
+	}
+}
+
+// Start the background trimming loop.
+func (cm *LevelConnManager) Start() error {
+	cm.cmLock.Lock()
+	defer cm.cmLock.Unlock()
+	if cm.closeC != nil {
+		return nil
 	}
+	cm.closeC = make(chan struct{})
+	go cm.trimLoop(cm.closeC)
+	return nil
+}
+
+// Scorer return the *PeerScorer used by Score elimination strategy.
+// Score inputs could be set on it to change the weight of peers.
+func (cm *LevelConnManager) Scorer() *PeerScorer {
+	return cm.scorer
 }
 
 func (cm *LevelConnManager) Close() error {
 	cm.cmLock.Lock()
 	defer cm.cmLock.Unlock()
+	if cm.closeC != nil {
+		close(cm.closeC)
+		cm.closeC = nil
+	}
 	for idx := range cm.lowLevelConn {
 		cm.lowLevelConn[idx].conn.Range(func(c network.Conn) bool {
 			_ = c.Close()
@@ -157,26 +243,144 @@ func (cm *LevelConnManager) Close() error {
 	return nil
 }
 
-// IsHighLevel return true if the peer which is high-level (consensus & seeds) node. Otherwise, return false.
+// IsHighLevel return true if the peer which is high-level (consensus & seeds) node or protected.
+// Otherwise, return false.
 func (cm *LevelConnManager) IsHighLevel(peerId peer.ID) bool {
-	return cm.highLevelPeers.Exist(peerId)
+	return cm.highLevelPeers.Exist(peerId) || cm.IsProtected(peerId, "")
 }
 
 // AddAsHighLevelPeer add a peer id as high level peer.
 func (cm *LevelConnManager) AddAsHighLevelPeer(peerId peer.ID) {
 	cm.highLevelPeers.Put(peerId)
+	cm.relevel()
 }
 
 // RemoveHighLevelPeer remove a high level peer id.
 func (cm *LevelConnManager) RemoveHighLevelPeer(peerId peer.ID) {
 	cm.highLevelPeers.Remove(peerId)
+	cm.relevel()
 }
 
 // ClearHighLevelPeer clear all high level peer id records.
 func (cm *LevelConnManager) ClearHighLevelPeer() {
 	cm.highLevelPeersLock.Lock()
-	defer cm.highLevelPeersLock.Unlock()
 	cm.highLevelPeers = &types.PeerIdSet{}
+	cm.highLevelPeersLock.Unlock()
+	cm.relevel()
+}
+
+// relevel move the connections of peers whose level changed to the right level list.
+func (cm *LevelConnManager) relevel() {
+	cm.cmLock.Lock()
+	defer cm.cmLock.Unlock()
+	highLevelConn := make([]*peerConnections, 0, len(cm.highLevelConn))
+	lowLevelConn := make([]*peerConnections, 0, len(cm.lowLevelConn))
+	for _, pcs := range cm.highLevelConn {
+		if cm.IsHighLevel(pcs.pid) {
+			highLevelConn = append(highLevelConn, pcs)
+		} else {
+			lowLevelConn = append(lowLevelConn, pcs)
+		}
+	}
+	for _, pcs := range cm.lowLevelConn {
+		if cm.IsHighLevel(pcs.pid) {
+			highLevelConn = append(highLevelConn, pcs)
+		} else {
+			lowLevelConn = append(lowLevelConn, pcs)
+		}
+	}
+	cm.highLevelConn = highLevelConn
+	cm.lowLevelConn = lowLevelConn
+}
+
+// TagPeer tags a peer with a string, associating a weight with the tag.
+// Peers with higher total weight of tags will be trimmed later.
+func (cm *LevelConnManager) TagPeer(pid peer.ID, tag string, weight int) {
+	cm.tagLock.Lock()
+	defer cm.tagLock.Unlock()
+	tags, ok := cm.tags[pid]
+	if !ok {
+		tags = make(map[string]int)
+		cm.tags[pid] = tags
+	}
+	tags[tag] = weight
+}
+
+// UntagPeer removes the tagged value from the peer.
+func (cm *LevelConnManager) UntagPeer(pid peer.ID, tag string) {
+	cm.tagLock.Lock()
+	defer cm.tagLock.Unlock()
+	tags, ok := cm.tags[pid]
+	if !ok {
+		return
+	}
+	delete(tags, tag)
+	if len(tags) == 0 {
+		delete(cm.tags, pid)
+	}
+}
+
+// tagValue return the total weight of tags of the peer.
+func (cm *LevelConnManager) tagValue(pid peer.ID) int {
+	cm.tagLock.RLock()
+	defer cm.tagLock.RUnlock()
+	res := 0
+	for _, weight := range cm.tags[pid] {
+		res = res + weight
+	}
+	return res
+}
+
+// Protect protects a peer from having its connections trimmed.
+// A protected peer will be seen as a high-level peer.
+func (cm *LevelConnManager) Protect(pid peer.ID, tag string) {
+	cm.tagLock.Lock()
+	tags, ok := cm.protected[pid]
+	if !ok {
+		tags = make(map[string]struct{})
+		cm.protected[pid] = tags
+	}
+	tags[tag] = struct{}{}
+	cm.tagLock.Unlock()
+	if !ok {
+		cm.relevel()
+	}
+}
+
+// Unprotect removes a protection that may have been placed on a peer under the tag given.
+// The return value indicates whether the peer continues to be protected after this call.
+func (cm *LevelConnManager) Unprotect(pid peer.ID, tag string) bool {
+	cm.tagLock.Lock()
+	tags, ok := cm.protected[pid]
+	if !ok {
+		cm.tagLock.Unlock()
+		return false
+	}
+	delete(tags, tag)
+	if len(tags) > 0 {
+		cm.tagLock.Unlock()
+		return true
+	}
+	delete(cm.protected, pid)
+	cm.tagLock.Unlock()
+	cm.relevel()
+	return false
+}
+
+// IsProtected returns true if the peer is protected for the tag given.
+// If tag is empty, returns true if the peer is protected by any tag.
+func (cm *LevelConnManager) IsProtected(pid peer.ID, tag string) bool {
+	cm.tagLock.RLock()
+	defer cm.tagLock.RUnlock()
+	tags, ok := cm.protected[pid]
+	if !ok {
+		return false
+	}
+	if tag == "" {
+		return true
+	}
+	_, ok = tags[tag]
+	return ok
 }
 
 func (cm *LevelConnManager) getHighLevelConnections(pid peer.ID) (*types.ConnSet, int) {
@@ -197,7 +401,7 @@ func (cm *LevelConnManager) getLowLevelConnections(pid peer.ID) (*types.ConnSet,
 	return nil, -1
 }
 
-func (cm *LevelConnManager) eliminateConnections(isHighLevel bool) (peer.ID, error) {
+func (cm *LevelConnManager) eliminateConnections(newPid peer.ID, isHighLevel bool) (peer.ID, error) {
 	switch cm.strategy {
 	case Random:
 		return cm.eliminateConnectionsRandom(isHighLevel)
@@ -205,11 +409,13 @@ func (cm *LevelConnManager) eliminateConnections(isHighLevel bool) (peer.ID, err
 		return cm.eliminateConnectionsFIFO(isHighLevel)
 	case LIFO:
 		return cm.eliminateConnectionsLIFO(isHighLevel)
+	case Score:
+		return cm.eliminateConnectionsScore(newPid, isHighLevel)
 	default:
 		cm.logger.Warnf("[LevelConnManager] unknown elimination strategy[%s], use default[%s]",
 			cm.strategy, DefaultEliminationStrategy)
 		cm.strategy = DefaultEliminationStrategy
-		return cm.eliminateConnections(isHighLevel)
+		return cm.eliminateConnections(newPid, isHighLevel)
 	}
 }
 
@@ -368,6 +574,64 @@ func (cm *LevelConnManager) eliminateConnectionsLIFO(isHighLevel bool) (peer.ID,
 	return "", nil
 }
 
+// lowestScoreIdx return the index of the peer who has the lowest score in the list given.
+// The new peer will be skipped if any other peer could be chosen, so that it will have a chance to be scored.
+func (cm *LevelConnManager) lowestScoreIdx(pcs []*peerConnections, newPid peer.ID) int {
+	pids := make([]peer.ID, 0, len(pcs))
+	idxMap := make([]int, 0, len(pcs))
+	for i := range pcs {
+		if pcs[i].pid == newPid && len(pcs) > 1 {
+			continue
+		}
+		pids = append(pids, pcs[i].pid)
+		idxMap = append(idxMap, i)
+	}
+	return idxMap[cm.scorer.LowestScorePeer(pids)]
+}
+
+func (cm *LevelConnManager) closeLowLevelConnLowestScore(newPid peer.ID) (peer.ID, error) {
+	idx := cm.lowestScoreIdx(cm.lowLevelConn, newPid)
+	eliminatedPid := cm.lowLevelConn[idx].pid
+	cm.closeLowLevelConnWithIdx(idx)
+	cm.lowLevelConn = append(cm.lowLevelConn[:idx], cm.lowLevelConn[idx+1:]...)
+	return eliminatedPid, nil
+}
+
+func (cm *LevelConnManager) closeHighLevelConnLowestScore(newPid peer.ID) (peer.ID, error) {
+	idx := cm.lowestScoreIdx(cm.highLevelConn, newPid)
+	eliminatedPid := cm.highLevelConn[idx].pid
+	cm.closeHighLevelConnWithIdx(idx)
+	cm.highLevelConn = append(cm.highLevelConn[:idx], cm.highLevelConn[idx+1:]...)
+	return eliminatedPid, nil
+}
+
+func (cm *LevelConnManager) eliminateConnectionsScore(newPid peer.ID, isHighLevel bool) (peer.ID, error) {
+	hCount := len(cm.highLevelConn)
+	lCount := len(cm.lowLevelConn)
+	if hCount+lCount > cm.maxPeerCountAllowed {
+		if lCount > 0 {
+			eliminatedPid, err := cm.closeLowLevelConnLowestScore(newPid)
+			if err != nil {
+				return "", err
+			}
+			cm.logger.Infof("[LevelConnManager] eliminate connections(strategy:Score, is high-level:%v, "+
+				"eliminated pid:%s)", isHighLevel, eliminatedPid)
+			return eliminatedPid, nil
+		}
+		if !isHighLevel {
+			return "", eliminatedHighLevelConnBugError
+		}
+		eliminatedPid, err := cm.closeHighLevelConnLowestScore(newPid)
+		if err != nil {
+			return "", err
+		}
+		cm.logger.Infof("[LevelConnManager] eliminate connections(strategy:Score, is high-level:%v, "+
+			"eliminated pid:%s)", isHighLevel, eliminatedPid)
+		return eliminatedPid, nil
+	}
+	return "", nil
+}
+
 // AddPeerConn add a connection.
 func (cm *LevelConnManager) AddPeerConn(pid peer.ID, conn network.Conn) bool {
 	cm.cmLock.Lock()
@@ -386,10 +650,12 @@ func (cm *LevelConnManager) AddPeerConn(pid peer.ID, conn network.Conn) bool {
 		connSet = &types.ConnSet{}
 		connSet.Put(conn)
 		pcs := &peerConnections{
-			pid:  pid,
-			conn: connSet,
+			pid:     pid,
+			conn:    connSet,
+			addedAt: time.Now(),
 		}
 		cm.highLevelConn = append(cm.highLevelConn, pcs)
+		cm.scorer.PeerConnected(pid)
 	} else {
 		connSet, _ := cm.getLowLevelConnections(pid)
 		if connSet != nil {
@@ -402,16 +668,29 @@ func (cm *LevelConnManager) AddPeerConn(pid peer.ID, conn network.Conn) bool {
 		connSet = &types.ConnSet{}
 		connSet.Put(conn)
 		pcs := &peerConnections{
-			pid:  pid,
-			conn: connSet,
+			pid:     pid,
+			conn:    connSet,
+			addedAt: time.Now(),
 		}
 		cm.lowLevelConn = append(cm.lowLevelConn, pcs)
+		cm.scorer.PeerConnected(pid)
+	}
+	if cm.highWater > 0 {
+		// trim in background loop
+		if len(cm.highLevelConn)+len(cm.lowLevelConn) > cm.highWater {
+			select {
+			case cm.trimSignal <- struct{}{}:
+			default:
+			}
+		}
+		return true
 	}
-	ePid, err := cm.eliminateConnections(isHighLevel)
+	ePid, err := cm.eliminateConnections(pid, isHighLevel)
 	if err != nil {
 		cm.logger.Errorf("[LevelConnManager] eliminate connection failed, %s", err.Error())
 	} else if ePid != "" {
 		cm.eliminatePeers.Put(ePid)
+		cm.scorer.PeerDisconnected(ePid)
 		cm.logger.Infof("[LevelConnManager] eliminate connection ok(pid:%s)", ePid)
 	}
 	return true
@@ -450,6 +729,9 @@ func (cm *LevelConnManager) RemovePeerConn(pid peer.ID, conn network.Conn) bool
 	if cm.eliminatePeers.Remove(pid) {
 		res = true
 	}
+	if res {
+		cm.scorer.PeerDisconnected(pid)
+	}
 	return res
 }
 
@@ -531,7 +813,7 @@ func (cm *LevelConnManager) IsAllowed(pid peer.ID) bool {
 	if currentCount > 0 {
 		return currentCount < cm.maxConnCountEachPeerAllowed
 	}
-	if cm.strategy == LIFO {
+	if cm.strategy == LIFO && cm.highWater <= 0 {
 		if cm.IsHighLevel(pid) {
 			return len(cm.highLevelConn) < cm.maxPeerCountAllowed
 		}
@@ -583,8 +865,14 @@ func (cm *LevelConnManager) PeerCount() int {
 	return len(cm.highLevelConn) + len(cm.lowLevelConn)
 }
 
-// MaxPeerCountAllowed return max peer count allowed .
+// MaxPeerCountAllowed return max peer count allowed.
+// If watermarks enabled, return the high watermark.
 func (cm *LevelConnManager) MaxPeerCountAllowed() int {
+	cm.cmLock.RLock()
+	defer cm.cmLock.RUnlock()
+	if cm.highWater > 0 {
+		return cm.highWater
+	}
 	return cm.maxPeerCountAllowed
 }
 
@@ -603,3 +891,146 @@ func (cm *LevelConnManager) AllPeer() []peer.ID {
 	}
 	return res
 }
+
+func (cm *LevelConnManager) trimLoop(closeC chan struct{}) {
+	ticker := time.NewTicker(cm.trimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closeC:
+			return
+		case <-ticker.C:
+			cm.TrimOpenConns()
+		case <-cm.trimSignal:
+			cm.TrimOpenConns()
+		}
+	}
+}
+
+// OnPeerTrimming register a hook that will be called before the connections of a peer trimmed.
+func (cm *LevelConnManager) OnPeerTrimming(hook mgr.PeerTrimmingHook) {
+	cm.hooksLock.Lock()
+	defer cm.hooksLock.Unlock()
+	cm.trimmingHooks = append(cm.trimmingHooks, hook)
+}
+
+// TrimOpenConns closes the connections of as many peers as needed to make the peer count equal the low watermark,
+// if the peer count exceeds the high watermark.
+// Peers protected, high-level peers and peers in grace period will not be trimmed.
+// Peers with lower total weight of tags will be trimmed first, if equal, the lower score the earlier.
+// The hooks registered with OnPeerTrimming will be called before the connections closed.
+func (cm *LevelConnManager) TrimOpenConns() {
+	pids, count := cm.peersToTrim()
+	if len(pids) == 0 {
+		return
+	}
+	cm.callTrimmingHooks(pids)
+	trimCount := cm.trimPeers(pids)
+	cm.logger.Infof("[LevelConnManager] trim connections(peer count:%d, high watermark:%d, "+
+		"low watermark:%d, trimmed:%d)", count, cm.highWater, cm.lowWater, trimCount)
+}
+
+// peersToTrim return the peers should be trimmed and the count of peers connected.
+func (cm *LevelConnManager) peersToTrim() ([]peer.ID, int) {
+	cm.cmLock.RLock()
+	defer cm.cmLock.RUnlock()
+	count := len(cm.highLevelConn) + len(cm.lowLevelConn)
+	if cm.highWater <= 0 || count <= cm.highWater {
+		return nil, count
+	}
+	now := time.Now()
+	candidates := make([]*peerConnections, 0, len(cm.lowLevelConn))
+	for _, pcs := range cm.lowLevelConn {
+		if now.Sub(pcs.addedAt) < cm.gracePeriod || cm.IsHighLevel(pcs.pid) {
+			continue
+		}
+		candidates = append(candidates, pcs)
+	}
+	tagValues := make(map[peer.ID]int, len(candidates))
+	scores := make(map[peer.ID]float64, len(candidates))
+	for _, pcs := range candidates {
+		tagValues[pcs.pid] = cm.tagValue(pcs.pid)
+		scores[pcs.pid] = cm.scorer.Score(pcs.pid)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		vi, vj := tagValues[candidates[i].pid], tagValues[candidates[j].pid]
+		if vi != vj {
+			return vi < vj
+		}
+		return scores[candidates[i].pid] < scores[candidates[j].pid]
+	})
+	trimCount := count - cm.lowWater
+	if trimCount > len(candidates) {
+		trimCount = len(candidates)
+	}
+	pids := make([]peer.ID, 0, trimCount)
+	for _, pcs := range candidates[:trimCount] {
+		pids = append(pids, pcs.pid)
+	}
+	return pids, count
+}
+
+// callTrimmingHooks call all hooks for each peer given, and wait until all returned or timeout.
+func (cm *LevelConnManager) callTrimmingHooks(pids []peer.ID) {
+	cm.hooksLock.RLock()
+	hooks := make([]mgr.PeerTrimmingHook, len(cm.trimmingHooks))
+	copy(hooks, cm.trimmingHooks)
+	cm.hooksLock.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(pids) * len(hooks))
+	for _, pid := range pids {
+		for _, hook := range hooks {
+			go func(pid peer.ID, hook mgr.PeerTrimmingHook) {
+				defer wg.Done()
+				hook(pid)
+			}(pid, hook)
+		}
+	}
+	doneC := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneC)
+	}()
+	timer := time.NewTimer(trimmingHookTimeout)
+	defer timer.Stop()
+	select {
+	case <-doneC:
+	case <-timer.C:
+		cm.logger.Warnf("[LevelConnManager] wait for trimming hooks timeout")
+	}
+}
+
+// trimPeers close the connections of the low-level peers given, return the count of peers trimmed.
+func (cm *LevelConnManager) trimPeers(pids []peer.ID) int {
+	cm.cmLock.Lock()
+	defer cm.cmLock.Unlock()
+	toTrim := make(map[peer.ID]struct{}, len(pids))
+	for _, pid := range pids {
+		// peers become high-level while calling hooks will not be trimmed
+		if !cm.IsHighLevel(pid) {
+			toTrim[pid] = struct{}{}
+		}
+	}
+	lowLevelConn := make([]*peerConnections, 0, len(cm.lowLevelConn))
+	trimCount := 0
+	for _, pcs := range cm.lowLevelConn {
+		if _, ok := toTrim[pcs.pid]; !ok {
+			lowLevelConn = append(lowLevelConn, pcs)
+			continue
+		}
+		pcs.conn.Range(func(c network.Conn) bool {
+			go func(connToClose network.Conn) {
+				_ = connToClose.Close()
+			}(c)
+			return true
+		})
+		cm.eliminatePeers.Put(pcs.pid)
+		cm.scorer.PeerDisconnected(pcs.pid)
+		trimCount++
+	}
+	cm.lowLevelConn = lowLevelConn
+	return trimCount
+}
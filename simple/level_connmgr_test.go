@@ -0,0 +1,123 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelConnManagerScoreElimination(t *testing.T) {
+	cm := NewLevelConnManager(logger.NilLogger, nil)
+	cm.SetStrategy(EliminationStrategyFromInt(4))
+	cm.SetMaxPeerCountAllowed(2)
+	var pid1, pid2, pid3, pid4 peer.ID = "pid1", "pid2", "pid3", "pid4"
+	cm.AddAsHighLevelPeer(pid4)
+
+	require.True(t, cm.AddPeerConn(pid1, &connStub{}))
+	require.True(t, cm.AddPeerConn(pid2, &connStub{}))
+	cm.Scorer().RecordMisbehaviour(pid1, 5)
+
+	// the lowest-scoring peer will be eliminated, not the new one
+	require.True(t, cm.IsAllowed(pid3))
+	require.True(t, cm.AddPeerConn(pid3, &connStub{}))
+	require.False(t, cm.IsConnected(pid1))
+	require.True(t, cm.IsConnected(pid2))
+	require.True(t, cm.IsConnected(pid3))
+
+	// high-level peer will not be eliminated while low-level peers exist
+	cm.Scorer().RecordMisbehaviour(pid4, 100)
+	require.True(t, cm.AddPeerConn(pid4, &connStub{}))
+	require.True(t, cm.IsConnected(pid4))
+	require.Equal(t, 2, cm.PeerCount())
+}
+
+func TestLevelConnManagerProtect(t *testing.T) {
+	cm := NewLevelConnManager(logger.NilLogger, nil)
+	var pid1 peer.ID = "pid1"
+	require.True(t, cm.AddPeerConn(pid1, &connStub{}))
+	require.False(t, cm.IsHighLevel(pid1))
+
+	cm.Protect(pid1, "a")
+	cm.Protect(pid1, "b")
+	require.True(t, cm.IsProtected(pid1, ""))
+	require.True(t, cm.IsProtected(pid1, "a"))
+	require.False(t, cm.IsProtected(pid1, "c"))
+	require.True(t, cm.IsHighLevel(pid1))
+	_, idx := cm.getHighLevelConnections(pid1)
+	require.Equal(t, 0, idx)
+
+	require.True(t, cm.Unprotect(pid1, "a"))
+	require.False(t, cm.Unprotect(pid1, "b"))
+	require.False(t, cm.IsProtected(pid1, ""))
+	_, idx = cm.getLowLevelConnections(pid1)
+	require.Equal(t, 0, idx)
+}
+
+func TestLevelConnManagerTrimOpenConns(t *testing.T) {
+	cm := NewLevelConnManager(logger.NilLogger, nil)
+	cm.SetWatermarks(2, 4)
+	cm.SetGracePeriod(0)
+	var pid1, pid2, pid3, pid4, pid5 peer.ID = "pid1", "pid2", "pid3", "pid4", "pid5"
+	cm.Protect(pid1, "test")
+	cm.TagPeer(pid2, "test", 10)
+	cm.TagPeer(pid3, "test", 5)
+	for _, pid := range []peer.ID{pid1, pid2, pid3, pid4} {
+		require.True(t, cm.AddPeerConn(pid, &connStub{}))
+	}
+	// not trimmed when high watermark not exceeded
+	cm.TrimOpenConns()
+	require.Equal(t, 4, cm.PeerCount())
+	require.Equal(t, 4, cm.MaxPeerCountAllowed())
+
+	// no elimination when adding, trim to low watermark with the lowest tag value first
+	require.True(t, cm.AddPeerConn(pid5, &connStub{}))
+	require.Equal(t, 5, cm.PeerCount())
+	cm.UntagPeer(pid3, "test")
+	cm.TrimOpenConns()
+	require.Equal(t, 2, cm.PeerCount())
+	require.True(t, cm.IsConnected(pid1))
+	require.True(t, cm.IsConnected(pid2))
+
+	// peers in grace period will not be trimmed
+	cm.SetGracePeriod(time.Hour)
+	require.True(t, cm.AddPeerConn(pid3, &connStub{}))
+	require.True(t, cm.AddPeerConn(pid4, &connStub{}))
+	require.True(t, cm.AddPeerConn(pid5, &connStub{}))
+	cm.TrimOpenConns()
+	require.Equal(t, 5, cm.PeerCount())
+}
+
+func TestLevelConnManagerTrimmingHook(t *testing.T) {
+	cm := NewLevelConnManager(logger.NilLogger, nil)
+	cm.SetWatermarks(1, 2)
+	cm.SetGracePeriod(0)
+	var pid1, pid2, pid3 peer.ID = "pid1", "pid2", "pid3"
+	cm.TagPeer(pid1, "test", 10)
+	trimming := make(chan peer.ID, 3)
+	cm.OnPeerTrimming(func(pid peer.ID) {
+		// still connected when hooks called
+		require.True(t, cm.IsConnected(pid))
+		trimming <- pid
+	})
+	for _, pid := range []peer.ID{pid1, pid2, pid3} {
+		require.True(t, cm.AddPeerConn(pid, &connStub{}))
+	}
+	cm.TrimOpenConns()
+	require.Equal(t, 1, cm.PeerCount())
+	require.True(t, cm.IsConnected(pid1))
+	close(trimming)
+	trimmed := make([]peer.ID, 0, 2)
+	for pid := range trimming {
+		trimmed = append(trimmed, pid)
+	}
+	require.ElementsMatch(t, []peer.ID{pid2, pid3}, trimmed)
+}
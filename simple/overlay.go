@@ -0,0 +1,418 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/common/v2/crypto/asym"
+	"chainmaker.org/chainmaker/net-liquid/core/handler"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/types"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/simple/pb"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// OverlayProtocolID is the protocol.ID for overlay routing service.
+	OverlayProtocolID protocol.ID = "/overlay/v0.0.1"
+	// DefaultOverlayMaxHops is the default max count of hops that a msg could be forwarded.
+	DefaultOverlayMaxHops = 5
+	// DefaultOverlayRouteInterval is the default interval of advertising routes to neighbours.
+	DefaultOverlayRouteInterval = 30 * time.Second
+
+	// overlayRouteTTLFactor is the count of intervals that a route learned will be kept without refreshing.
+	overlayRouteTTLFactor = 3
+	// overlayMaxRoutes is the max count of routes advertised in a msg.
+	overlayMaxRoutes = 1024
+	// overlaySeenCacheSize is the size of the cache of msgs seen, used to drop duplicate msgs.
+	overlaySeenCacheSize = 8192
+	// overlayMsgMaxAge is the max age of a msg forwarded, the older ones will be dropped.
+	overlayMsgMaxAge = 2 * time.Minute
+	// overlayMsgIDLength is the length of a random msg id.
+	overlayMsgIDLength = 16
+)
+
+var (
+	// ErrNoOverlayRoute will be returned if no route to the peer found.
+	ErrNoOverlayRoute = errors.New("no overlay route to peer")
+	// ErrOverlaySignatureInvalid will be reported if the signature of a msg forwarded is invalid.
+	ErrOverlaySignatureInvalid = errors.New("invalid overlay msg signature")
+	// ErrOverlayPubKeyMismatch will be reported if the public key of a msg forwarded mismatch its source.
+	ErrOverlayPubKeyMismatch = errors.New("overlay msg public key mismatch source")
+	// ErrOverlayMsgExpired will be reported if a msg forwarded is too old.
+	ErrOverlayMsgExpired = errors.New("overlay msg expired")
+)
+
+// OverlayDeliverFunc is a function that delivers a msg forwarded to us to the msg payload handler of protocol.
+type OverlayDeliverFunc func(srcPID peer.ID, protocolID protocol.ID, msgPayload []byte)
+
+type overlayRoute struct {
+	nextHop peer.ID
+	hops    uint32
+	expire  time.Time
+}
+
+// OverlayRouter forwards msgs to the peers not connected to us through the peers connected.
+// Each host advertises the peers it could reach (the neighbours connected and the routes learned) with
+// the count of hops to its neighbours periodically, so that every host builds a distance-vector routing table
+// with the shortest next hop for each peer reachable in max hops. The routes learned from a neighbour are not
+// advertised back to it (split horizon).
+// A msg forwarded is signed by its source, so that forwarders could not forge the content, and it is verified
+// by its destination. Msgs beyond max hops, seen before or too old are dropped to prevent loops.
+type OverlayRouter struct {
+	host     host.Host
+	deliver  OverlayDeliverFunc
+	maxHops  uint32
+	interval time.Duration
+
+	mu     sync.RWMutex
+	routes map[peer.ID]*overlayRoute
+	seen   *types.FIFOCache
+
+	closeC chan struct{}
+	once   sync.Once
+
+	logger api.Logger
+}
+
+// NewOverlayRouter create a new *OverlayRouter instance.
+// The msgs forwarded to us will be delivered with deliver.
+// If maxHops is not greater than 0, DefaultOverlayMaxHops will be used.
+// If interval is not greater than 0, DefaultOverlayRouteInterval will be used.
+func NewOverlayRouter(h host.Host, deliver OverlayDeliverFunc, maxHops int, interval time.Duration,
+	logger api.Logger) *OverlayRouter {
+	if maxHops <= 0 {
+		maxHops = DefaultOverlayMaxHops
+	}
+	if interval <= 0 {
+		interval = DefaultOverlayRouteInterval
+	}
+	return &OverlayRouter{
+		host:     h,
+		deliver:  deliver,
+		maxHops:  uint32(maxHops),
+		interval: interval,
+		routes:   make(map[peer.ID]*overlayRoute),
+		seen:     types.NewFIFOCache(overlaySeenCacheSize, true),
+		logger:   logger,
+	}
+}
+
+// ProtocolID is the protocol.ID of overlay routing service.
+// The protocol id will be registered in host.RegisterMsgPayloadHandler method.
+func (r *OverlayRouter) ProtocolID() protocol.ID {
+	return OverlayProtocolID
+}
+
+// Handle is the msg payload handler of overlay routing service.
+// It will be registered in host.Host.RegisterMsgPayloadHandler method.
+func (r *OverlayRouter) Handle() handler.MsgPayloadHandler {
+	return func(senderPID peer.ID, msgPayload []byte) {
+		msg := &pb.OverlayMsg{}
+		err := proto.Unmarshal(msgPayload, msg)
+		if err != nil {
+			r.logger.Errorf("[OverlayRouter] handler msg payload failed, %s (sender id: %s)",
+				err.Error(), senderPID)
+			return
+		}
+		switch msg.MsgType {
+		case pb.OverlayMsg_ROUTES:
+			r.updateRoutes(senderPID, msg.Routes)
+		case pb.OverlayMsg_DATA:
+			if msg.Data == nil {
+				return
+			}
+			r.handleData(senderPID, msg.Data)
+		default:
+			return
+		}
+	}
+}
+
+// updateRoutes replace the routes through the neighbour with the ones advertised by it.
+func (r *OverlayRouter) updateRoutes(neighbour peer.ID, routes []*pb.OverlayRoute) {
+	self := r.host.ID()
+	now := time.Now()
+	expire := now.Add(r.interval * overlayRouteTTLFactor)
+	advertised := make(map[peer.ID]struct{}, len(routes))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, route := range routes {
+		dst := peer.ID(route.Pid)
+		if dst == self || dst == neighbour || route.Hops == 0 {
+			continue
+		}
+		hops := route.Hops + 1
+		if hops > r.maxHops {
+			continue
+		}
+		advertised[dst] = struct{}{}
+		exist, ok := r.routes[dst]
+		if !ok || exist.nextHop == neighbour || hops < exist.hops || now.After(exist.expire) {
+			r.routes[dst] = &overlayRoute{nextHop: neighbour, hops: hops, expire: expire}
+		}
+	}
+	// the routes through the neighbour not advertised any more are withdrawn
+	for dst, route := range r.routes {
+		if _, ok := advertised[dst]; !ok && route.nextHop == neighbour {
+			delete(r.routes, dst)
+		}
+	}
+}
+
+// neighbours return the peers connected that support overlay routing.
+func (r *OverlayRouter) neighbours() []peer.ID {
+	res := make([]peer.ID, 0)
+	for _, pid := range r.host.ConnMgr().AllPeer() {
+		if r.host.IsPeerSupportProtocol(pid, OverlayProtocolID) {
+			res = append(res, pid)
+		}
+	}
+	return res
+}
+
+// AdvertiseRoutes send the routes reachable to each neighbour.
+func (r *OverlayRouter) AdvertiseRoutes() {
+	neighbours := r.neighbours()
+	now := time.Now()
+	r.mu.RLock()
+	learned := make(map[peer.ID]*overlayRoute, len(r.routes))
+	for dst, route := range r.routes {
+		if now.Before(route.expire) && route.hops < r.maxHops {
+			learned[dst] = route
+		}
+	}
+	r.mu.RUnlock()
+	for _, to := range neighbours {
+		routes := make([]*pb.OverlayRoute, 0, len(neighbours)+len(learned))
+		for _, pid := range neighbours {
+			if pid != to {
+				routes = append(routes, &pb.OverlayRoute{Pid: pid.ToString(), Hops: 1})
+			}
+		}
+		for dst, route := range learned {
+			// split horizon
+			if dst == to || route.nextHop == to || r.host.ConnMgr().IsConnected(dst) {
+				continue
+			}
+			routes = append(routes, &pb.OverlayRoute{Pid: dst.ToString(), Hops: route.hops})
+		}
+		if len(routes) > overlayMaxRoutes {
+			routes = routes[:overlayMaxRoutes]
+		}
+		bytes, err := proto.Marshal(&pb.OverlayMsg{MsgType: pb.OverlayMsg_ROUTES, Routes: routes})
+		if err != nil {
+			r.logger.Errorf("[OverlayRouter] marshal routes msg failed, %s", err.Error())
+			return
+		}
+		if err = r.host.SendMsg(OverlayProtocolID, to, bytes); err != nil {
+			r.logger.Debugf("[OverlayRouter] send routes msg failed, %s (remote pid: %s)", err.Error(), to)
+		}
+	}
+}
+
+// Route return the next hop and the count of hops to the peer.
+// If the peer is a neighbour, the next hop is itself.
+func (r *OverlayRouter) Route(dst peer.ID) (peer.ID, uint32, bool) {
+	if r.host.ConnMgr().IsConnected(dst) && r.host.IsPeerSupportProtocol(dst, OverlayProtocolID) {
+		return dst, 1, true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	route, ok := r.routes[dst]
+	if !ok || time.Now().After(route.expire) || !r.host.ConnMgr().IsConnected(route.nextHop) {
+		return "", 0, false
+	}
+	return route.nextHop, route.hops, true
+}
+
+// Send a msg signed by us to the peer through the routes learned.
+func (r *OverlayRouter) Send(protocolID protocol.ID, dst peer.ID, msgPayload []byte) error {
+	nextHop, _, ok := r.Route(dst)
+	if !ok {
+		return ErrNoOverlayRoute
+	}
+	msgID := make([]byte, overlayMsgIDLength)
+	if _, err := rand.Read(msgID); err != nil {
+		return err
+	}
+	data := &pb.OverlayData{
+		Src:        r.host.ID().ToString(),
+		Dst:        dst.ToString(),
+		ProtocolId: string(protocolID),
+		Payload:    msgPayload,
+		MsgId:      msgID,
+		Timestamp:  time.Now().UnixNano(),
+	}
+	if err := r.sign(data); err != nil {
+		return err
+	}
+	r.seen.PutIfNotExist(seenKey(data), struct{}{})
+	return r.forward(nextHop, data)
+}
+
+func (r *OverlayRouter) forward(nextHop peer.ID, data *pb.OverlayData) error {
+	bytes, err := proto.Marshal(&pb.OverlayMsg{MsgType: pb.OverlayMsg_DATA, Data: data})
+	if err != nil {
+		return err
+	}
+	return r.host.SendMsg(OverlayProtocolID, nextHop, bytes)
+}
+
+// seenKey return the key of a msg in the cache of msgs seen.
+// The msg id is chosen by its source, so the msgs from different sources may have the same msg id.
+func seenKey(data *pb.OverlayData) string {
+	return data.Src + "/" + string(data.MsgId)
+}
+
+// handleData deliver the msg to us, or forward it to the next hop.
+func (r *OverlayRouter) handleData(senderPID peer.ID, data *pb.OverlayData) {
+	key := seenKey(data)
+	if r.seen.Exist(key) {
+		// seen before, drop it to prevent loops
+		return
+	}
+	age := time.Since(time.Unix(0, data.Timestamp))
+	if age > overlayMsgMaxAge || age < -overlayMsgMaxAge {
+		r.logger.Debugf("[OverlayRouter] %s, drop it. (src: %s, sender id: %s)",
+			ErrOverlayMsgExpired.Error(), data.Src, senderPID)
+		return
+	}
+	dst := peer.ID(data.Dst)
+	if dst == r.host.ID() {
+		if err := r.verify(data); err != nil {
+			r.logger.Warnf("[OverlayRouter] verify msg failed, %s, drop it. (src: %s, sender id: %s)",
+				err.Error(), data.Src, senderPID)
+			return
+		}
+		// marked as seen only if verified, otherwise a forged msg could suppress the genuine one
+		if !r.seen.PutIfNotExist(key, struct{}{}) {
+			return
+		}
+		r.deliver(peer.ID(data.Src), protocol.ID(data.ProtocolId), data.Payload)
+		return
+	}
+	if !r.seen.PutIfNotExist(key, struct{}{}) {
+		return
+	}
+	data.Hops++
+	if data.Hops >= r.maxHops {
+		r.logger.Debugf("[OverlayRouter] max hops reached, drop msg. (src: %s, dst: %s)", data.Src, dst)
+		return
+	}
+	nextHop, _, ok := r.Route(dst)
+	if !ok || nextHop == senderPID || nextHop == peer.ID(data.Src) {
+		r.logger.Debugf("[OverlayRouter] no route to forward msg, drop it. (src: %s, dst: %s)", data.Src, dst)
+		return
+	}
+	if err := r.forward(nextHop, data); err != nil {
+		r.logger.Debugf("[OverlayRouter] forward msg failed, %s (src: %s, dst: %s, next hop: %s)",
+			err.Error(), data.Src, dst, nextHop)
+	}
+}
+
+// signContent return the digest of the fields signed of a msg.
+func signContent(data *pb.OverlayData) ([]byte, error) {
+	bytes, err := proto.Marshal(&pb.OverlayData{
+		Src:        data.Src,
+		Dst:        data.Dst,
+		ProtocolId: data.ProtocolId,
+		Payload:    data.Payload,
+		MsgId:      data.MsgId,
+		Timestamp:  data.Timestamp,
+	})
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(bytes)
+	return digest[:], nil
+}
+
+func (r *OverlayRouter) sign(data *pb.OverlayData) error {
+	pubKey, err := r.host.PrivateKey().PublicKey().Bytes()
+	if err != nil {
+		return err
+	}
+	content, err := signContent(data)
+	if err != nil {
+		return err
+	}
+	data.Signature, err = r.host.PrivateKey().Sign(content)
+	if err != nil {
+		return err
+	}
+	data.PubKey = pubKey
+	return nil
+}
+
+func (r *OverlayRouter) verify(data *pb.OverlayData) error {
+	pubKey, err := asym.PublicKeyFromDER(data.PubKey)
+	if err != nil {
+		return err
+	}
+	pid, err := util.ResolvePIDFromPubKey(pubKey)
+	if err != nil {
+		return err
+	}
+	if pid != peer.ID(data.Src) {
+		return ErrOverlayPubKeyMismatch
+	}
+	content, err := signContent(data)
+	if err != nil {
+		return err
+	}
+	ok, err := pubKey.Verify(content, data.Signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrOverlaySignatureInvalid
+	}
+	return nil
+}
+
+// Start the background advertiser.
+func (r *OverlayRouter) Start() error {
+	r.once.Do(func() {
+		r.closeC = make(chan struct{})
+		go r.advertiseLoop(r.closeC)
+	})
+	return nil
+}
+
+// Stop the background advertiser.
+func (r *OverlayRouter) Stop() error {
+	if r.closeC == nil {
+		return nil
+	}
+	close(r.closeC)
+	r.closeC = nil
+	r.once = sync.Once{}
+	return nil
+}
+
+func (r *OverlayRouter) advertiseLoop(closeC chan struct{}) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closeC:
+			return
+		case <-ticker.C:
+			r.AdvertiseRoutes()
+		}
+	}
+}
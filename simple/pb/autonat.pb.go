@@ -0,0 +1,775 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: autonat.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type AutoNATMsg_AutoNATMsgType int32
+
+const (
+	AutoNATMsg_DIAL_BACK          AutoNATMsg_AutoNATMsgType = 0
+	AutoNATMsg_DIAL_BACK_RESPONSE AutoNATMsg_AutoNATMsgType = 1
+)
+
+var AutoNATMsg_AutoNATMsgType_name = map[int32]string{
+	0: "DIAL_BACK",
+	1: "DIAL_BACK_RESPONSE",
+}
+
+var AutoNATMsg_AutoNATMsgType_value = map[string]int32{
+	"DIAL_BACK":          0,
+	"DIAL_BACK_RESPONSE": 1,
+}
+
+func (x AutoNATMsg_AutoNATMsgType) String() string {
+	return proto.EnumName(AutoNATMsg_AutoNATMsgType_name, int32(x))
+}
+
+func (AutoNATMsg_AutoNATMsgType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_a04e278ef61ac07a, []int{0, 0}
+}
+
+type DialBackResult_DialBackStatus int32
+
+const (
+	DialBackResult_OK      DialBackResult_DialBackStatus = 0
+	DialBackResult_FAILED  DialBackResult_DialBackStatus = 1
+	DialBackResult_REFUSED DialBackResult_DialBackStatus = 2
+)
+
+var DialBackResult_DialBackStatus_name = map[int32]string{
+	0: "OK",
+	1: "FAILED",
+	2: "REFUSED",
+}
+
+var DialBackResult_DialBackStatus_value = map[string]int32{
+	"OK":      0,
+	"FAILED":  1,
+	"REFUSED": 2,
+}
+
+func (x DialBackResult_DialBackStatus) String() string {
+	return proto.EnumName(DialBackResult_DialBackStatus_name, int32(x))
+}
+
+func (DialBackResult_DialBackStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_a04e278ef61ac07a, []int{1, 0}
+}
+
+type AutoNATMsg struct {
+	MsgType AutoNATMsg_AutoNATMsgType `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3,enum=net.AutoNATMsg_AutoNATMsgType" json:"msg_type,omitempty"`
+	Seq     uint64                    `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Addrs   []string                  `protobuf:"bytes,3,rep,name=addrs,proto3" json:"addrs,omitempty"`
+	Results []*DialBackResult         `protobuf:"bytes,4,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (m *AutoNATMsg) Reset()         { *m = AutoNATMsg{} }
+func (m *AutoNATMsg) String() string { return proto.CompactTextString(m) }
+func (*AutoNATMsg) ProtoMessage()    {}
+func (*AutoNATMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a04e278ef61ac07a, []int{0}
+}
+func (m *AutoNATMsg) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AutoNATMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AutoNATMsg.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AutoNATMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AutoNATMsg.Merge(m, src)
+}
+func (m *AutoNATMsg) XXX_Size() int {
+	return m.Size()
+}
+func (m *AutoNATMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_AutoNATMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AutoNATMsg proto.InternalMessageInfo
+
+func (m *AutoNATMsg) GetMsgType() AutoNATMsg_AutoNATMsgType {
+	if m != nil {
+		return m.MsgType
+	}
+	return AutoNATMsg_DIAL_BACK
+}
+
+func (m *AutoNATMsg) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *AutoNATMsg) GetAddrs() []string {
+	if m != nil {
+		return m.Addrs
+	}
+	return nil
+}
+
+func (m *AutoNATMsg) GetResults() []*DialBackResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type DialBackResult struct {
+	Addr   string                        `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Status DialBackResult_DialBackStatus `protobuf:"varint,2,opt,name=status,proto3,enum=net.DialBackResult_DialBackStatus" json:"status,omitempty"`
+	Error  string                        `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (m *DialBackResult) Reset()         { *m = DialBackResult{} }
+func (m *DialBackResult) String() string { return proto.CompactTextString(m) }
+func (*DialBackResult) ProtoMessage()    {}
+func (*DialBackResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a04e278ef61ac07a, []int{1}
+}
+func (m *DialBackResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DialBackResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DialBackResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DialBackResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DialBackResult.Merge(m, src)
+}
+func (m *DialBackResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *DialBackResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_DialBackResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DialBackResult proto.InternalMessageInfo
+
+func (m *DialBackResult) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *DialBackResult) GetStatus() DialBackResult_DialBackStatus {
+	if m != nil {
+		return m.Status
+	}
+	return DialBackResult_OK
+}
+
+func (m *DialBackResult) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterEnum("net.AutoNATMsg_AutoNATMsgType", AutoNATMsg_AutoNATMsgType_name, AutoNATMsg_AutoNATMsgType_value)
+	proto.RegisterEnum("net.DialBackResult_DialBackStatus", DialBackResult_DialBackStatus_name, DialBackResult_DialBackStatus_value)
+	proto.RegisterType((*AutoNATMsg)(nil), "net.AutoNATMsg")
+	proto.RegisterType((*DialBackResult)(nil), "net.DialBackResult")
+}
+
+func init() { proto.RegisterFile("autonat.proto", fileDescriptor_a04e278ef61ac07a) }
+
+var fileDescriptor_a04e278ef61ac07a = []byte{
+	// 368 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x91, 0xbd, 0x8e, 0xd3, 0x40,
+	0x14, 0x85, 0x3d, 0x71, 0x70, 0xc8, 0x5d, 0xad, 0x65, 0x0d, 0x08, 0xb9, 0xb2, 0x2c, 0x57, 0x6e,
+	0xd6, 0x16, 0xa1, 0xe0, 0xa7, 0x73, 0xb0, 0x57, 0x5a, 0xb1, 0xec, 0xa2, 0xf1, 0xd2, 0xd0, 0x44,
+	0xb3, 0xeb, 0x91, 0xb1, 0x62, 0x7b, 0x9c, 0x99, 0x71, 0x91, 0xb7, 0xe0, 0x35, 0x78, 0x13, 0xca,
+	0x74, 0x50, 0xa2, 0xe4, 0x45, 0x90, 0x27, 0x90, 0x10, 0x69, 0xbb, 0xf3, 0x69, 0xce, 0xdc, 0x7b,
+	0x8e, 0x2e, 0x9c, 0xd3, 0x5e, 0xf1, 0x96, 0xaa, 0xa8, 0x13, 0x5c, 0x71, 0x6c, 0xb6, 0x4c, 0x05,
+	0x3f, 0x11, 0x40, 0xd2, 0x2b, 0x7e, 0x93, 0xdc, 0x7d, 0x94, 0x25, 0x7e, 0x0b, 0x4f, 0x1b, 0x59,
+	0x2e, 0xd4, 0xba, 0x63, 0x2e, 0xf2, 0x51, 0x68, 0xcf, 0xbc, 0xa8, 0x65, 0x2a, 0x3a, 0x5a, 0xfe,
+	0x93, 0x77, 0xeb, 0x8e, 0x91, 0x49, 0xb3, 0x17, 0xd8, 0x01, 0x53, 0xb2, 0x95, 0x3b, 0xf2, 0x51,
+	0x38, 0x26, 0x83, 0xc4, 0xcf, 0xe1, 0x09, 0x2d, 0x0a, 0x21, 0x5d, 0xd3, 0x37, 0xc3, 0x29, 0xd9,
+	0x03, 0xbe, 0x80, 0x89, 0x60, 0xb2, 0xaf, 0x95, 0x74, 0xc7, 0xbe, 0x19, 0x9e, 0xcd, 0x9e, 0xe9,
+	0x0d, 0x69, 0x45, 0xeb, 0x39, 0x7d, 0x58, 0x12, 0xfd, 0x46, 0xfe, 0x79, 0x82, 0xd7, 0x60, 0x9f,
+	0x6e, 0xc4, 0xe7, 0x30, 0x4d, 0xaf, 0x92, 0xeb, 0xc5, 0x3c, 0x79, 0xff, 0xc1, 0x31, 0xf0, 0x0b,
+	0xc0, 0x07, 0x5c, 0x90, 0x2c, 0xff, 0x74, 0x7b, 0x93, 0x67, 0x0e, 0x0a, 0xbe, 0x23, 0xb0, 0x4f,
+	0x87, 0x62, 0x0c, 0xe3, 0x21, 0x83, 0x6e, 0x36, 0x25, 0x5a, 0xe3, 0x77, 0x60, 0x49, 0x45, 0x55,
+	0x2f, 0x75, 0x72, 0x7b, 0x16, 0x3c, 0x92, 0xe6, 0x80, 0xb9, 0x76, 0x92, 0xbf, 0x3f, 0x86, 0x82,
+	0x4c, 0x08, 0x2e, 0x5c, 0x53, 0x0f, 0xdc, 0x43, 0xf0, 0x12, 0xec, 0x53, 0x3f, 0xb6, 0x60, 0x74,
+	0x3b, 0x44, 0x05, 0xb0, 0x2e, 0x93, 0xab, 0xeb, 0x2c, 0x75, 0x10, 0x3e, 0x83, 0x09, 0xc9, 0x2e,
+	0x3f, 0xe7, 0x59, 0xea, 0x8c, 0xe6, 0xe4, 0xc7, 0xd6, 0x43, 0x9b, 0xad, 0x87, 0x7e, 0x6f, 0x3d,
+	0xf4, 0x6d, 0xe7, 0x19, 0x9b, 0x9d, 0x67, 0xfc, 0xda, 0x79, 0xc6, 0x97, 0x37, 0x0f, 0x5f, 0x69,
+	0xd5, 0x36, 0x74, 0xc9, 0x44, 0xc4, 0x45, 0x19, 0x1f, 0xf1, 0xa2, 0xe4, 0x71, 0xc3, 0x8b, 0xbe,
+	0x66, 0x71, 0xcb, 0x54, 0x5c, 0x57, 0xab, 0xbe, 0x2a, 0x62, 0x59, 0x35, 0x5d, 0xcd, 0xe2, 0xee,
+	0xfe, 0xde, 0xd2, 0x57, 0x7e, 0xf5, 0x67, 0x00, 0x29, 0x05, 0xa3, 0xb4, 0xf6, 0x01, 0x00, 0x00,
+}
+
+func (m *AutoNATMsg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AutoNATMsg) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AutoNATMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for iNdEx := len(m.Results) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Results[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintAutonat(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.Addrs) > 0 {
+		for iNdEx := len(m.Addrs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Addrs[iNdEx])
+			copy(dAtA[i:], m.Addrs[iNdEx])
+			i = encodeVarintAutonat(dAtA, i, uint64(len(m.Addrs[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.Seq != 0 {
+		i = encodeVarintAutonat(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.MsgType != 0 {
+		i = encodeVarintAutonat(dAtA, i, uint64(m.MsgType))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DialBackResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DialBackResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DialBackResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Error) > 0 {
+		i -= len(m.Error)
+		copy(dAtA[i:], m.Error)
+		i = encodeVarintAutonat(dAtA, i, uint64(len(m.Error)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Status != 0 {
+		i = encodeVarintAutonat(dAtA, i, uint64(m.Status))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Addr) > 0 {
+		i -= len(m.Addr)
+		copy(dAtA[i:], m.Addr)
+		i = encodeVarintAutonat(dAtA, i, uint64(len(m.Addr)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintAutonat(dAtA []byte, offset int, v uint64) int {
+	offset -= sovAutonat(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *AutoNATMsg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MsgType != 0 {
+		n += 1 + sovAutonat(uint64(m.MsgType))
+	}
+	if m.Seq != 0 {
+		n += 1 + sovAutonat(uint64(m.Seq))
+	}
+	if len(m.Addrs) > 0 {
+		for _, s := range m.Addrs {
+			l = len(s)
+			n += 1 + l + sovAutonat(uint64(l))
+		}
+	}
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovAutonat(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *DialBackResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Addr)
+	if l > 0 {
+		n += 1 + l + sovAutonat(uint64(l))
+	}
+	if m.Status != 0 {
+		n += 1 + sovAutonat(uint64(m.Status))
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovAutonat(uint64(l))
+	}
+	return n
+}
+
+func sovAutonat(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozAutonat(x uint64) (n int) {
+	return sovAutonat(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *AutoNATMsg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAutonat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AutoNATMsg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AutoNATMsg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgType", wireType)
+			}
+			m.MsgType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MsgType |= AutoNATMsg_AutoNATMsgType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addrs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addrs = append(m.Addrs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Results = append(m.Results, &DialBackResult{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAutonat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DialBackResult) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAutonat
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DialBackResult: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DialBackResult: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Addr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Addr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			m.Status = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Status |= DialBackResult_DialBackStatus(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAutonat(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthAutonat
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipAutonat(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowAutonat
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowAutonat
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthAutonat
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupAutonat
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthAutonat
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthAutonat        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowAutonat          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupAutonat = fmt.Errorf("proto: unexpected end of group")
+)
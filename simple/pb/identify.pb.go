@@ -0,0 +1,600 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: identify.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type IdentifyMsg_IdentifyMsgType int32
+
+const (
+	IdentifyMsg_IDENTIFY IdentifyMsg_IdentifyMsgType = 0
+	IdentifyMsg_PUSH     IdentifyMsg_IdentifyMsgType = 1
+)
+
+var IdentifyMsg_IdentifyMsgType_name = map[int32]string{
+	0: "IDENTIFY",
+	1: "PUSH",
+}
+
+var IdentifyMsg_IdentifyMsgType_value = map[string]int32{
+	"IDENTIFY": 0,
+	"PUSH":     1,
+}
+
+func (x IdentifyMsg_IdentifyMsgType) String() string {
+	return proto.EnumName(IdentifyMsg_IdentifyMsgType_name, int32(x))
+}
+
+func (IdentifyMsg_IdentifyMsgType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_83f1e7e6b485409f, []int{0, 0}
+}
+
+type IdentifyMsg struct {
+	MsgType      IdentifyMsg_IdentifyMsgType `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3,enum=net.IdentifyMsg_IdentifyMsgType" json:"msg_type,omitempty"`
+	ListenAddrs  []string                    `protobuf:"bytes,2,rep,name=listen_addrs,json=listenAddrs,proto3" json:"listen_addrs,omitempty"`
+	ObservedAddr string                      `protobuf:"bytes,3,opt,name=observed_addr,json=observedAddr,proto3" json:"observed_addr,omitempty"`
+	PublicKey    []byte                      `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	AgentVersion string                      `protobuf:"bytes,5,opt,name=agent_version,json=agentVersion,proto3" json:"agent_version,omitempty"`
+	Protocols    []string                    `protobuf:"bytes,6,rep,name=protocols,proto3" json:"protocols,omitempty"`
+}
+
+func (m *IdentifyMsg) Reset()         { *m = IdentifyMsg{} }
+func (m *IdentifyMsg) String() string { return proto.CompactTextString(m) }
+func (*IdentifyMsg) ProtoMessage()    {}
+func (*IdentifyMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_83f1e7e6b485409f, []int{0}
+}
+func (m *IdentifyMsg) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *IdentifyMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_IdentifyMsg.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *IdentifyMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IdentifyMsg.Merge(m, src)
+}
+func (m *IdentifyMsg) XXX_Size() int {
+	return m.Size()
+}
+func (m *IdentifyMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_IdentifyMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IdentifyMsg proto.InternalMessageInfo
+
+func (m *IdentifyMsg) GetMsgType() IdentifyMsg_IdentifyMsgType {
+	if m != nil {
+		return m.MsgType
+	}
+	return IdentifyMsg_IDENTIFY
+}
+
+func (m *IdentifyMsg) GetListenAddrs() []string {
+	if m != nil {
+		return m.ListenAddrs
+	}
+	return nil
+}
+
+func (m *IdentifyMsg) GetObservedAddr() string {
+	if m != nil {
+		return m.ObservedAddr
+	}
+	return ""
+}
+
+func (m *IdentifyMsg) GetPublicKey() []byte {
+	if m != nil {
+		return m.PublicKey
+	}
+	return nil
+}
+
+func (m *IdentifyMsg) GetAgentVersion() string {
+	if m != nil {
+		return m.AgentVersion
+	}
+	return ""
+}
+
+func (m *IdentifyMsg) GetProtocols() []string {
+	if m != nil {
+		return m.Protocols
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("net.IdentifyMsg_IdentifyMsgType", IdentifyMsg_IdentifyMsgType_name, IdentifyMsg_IdentifyMsgType_value)
+	proto.RegisterType((*IdentifyMsg)(nil), "net.IdentifyMsg")
+}
+
+func init() { proto.RegisterFile("identify.proto", fileDescriptor_83f1e7e6b485409f) }
+
+var fileDescriptor_83f1e7e6b485409f = []byte{
+	// 319 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0xcf, 0xc1, 0x4a, 0xf3, 0x40,
+	0x10, 0x07, 0xf0, 0x6c, 0xdb, 0xaf, 0x5f, 0xb3, 0x8d, 0xb5, 0xec, 0x29, 0x07, 0x0d, 0xb1, 0x5e,
+	0xe2, 0xc1, 0x04, 0xf4, 0x22, 0x78, 0x52, 0x54, 0x2c, 0xa2, 0x48, 0xac, 0x82, 0x5e, 0x42, 0xd2,
+	0x8c, 0x71, 0x69, 0xb2, 0x1b, 0x77, 0xb7, 0x85, 0xbc, 0x85, 0x6f, 0xe0, 0xeb, 0x78, 0xec, 0xd1,
+	0xa3, 0xb4, 0x2f, 0x22, 0x49, 0x94, 0x16, 0x6f, 0x33, 0x3f, 0xfe, 0x0c, 0xf3, 0xc7, 0x3d, 0x1a,
+	0x03, 0x53, 0xf4, 0xb9, 0x70, 0x73, 0xc1, 0x15, 0x27, 0x4d, 0x06, 0x6a, 0xf0, 0xde, 0xc0, 0xdd,
+	0xe1, 0x8f, 0x5f, 0xcb, 0x84, 0x1c, 0xe3, 0x4e, 0x26, 0x93, 0x40, 0x15, 0x39, 0x98, 0xc8, 0x46,
+	0x4e, 0xef, 0xc0, 0x76, 0x19, 0x28, 0x77, 0x2d, 0xb3, 0x3e, 0x8f, 0x8a, 0x1c, 0xfc, 0xff, 0x59,
+	0x3d, 0x90, 0x1d, 0x6c, 0xa4, 0x54, 0x2a, 0x60, 0x41, 0x18, 0xc7, 0x42, 0x9a, 0x0d, 0xbb, 0xe9,
+	0xe8, 0x7e, 0xb7, 0xb6, 0x93, 0x92, 0xc8, 0x2e, 0xde, 0xe0, 0x91, 0x04, 0x31, 0x83, 0xb8, 0x0a,
+	0x99, 0x4d, 0x1b, 0x39, 0xba, 0x6f, 0xfc, 0x62, 0x99, 0x22, 0xdb, 0x18, 0xe7, 0xd3, 0x28, 0xa5,
+	0xe3, 0x60, 0x02, 0x85, 0xd9, 0xb2, 0x91, 0x63, 0xf8, 0x7a, 0x2d, 0x57, 0x50, 0x94, 0x37, 0xc2,
+	0x04, 0x98, 0x0a, 0x66, 0x20, 0x24, 0xe5, 0xcc, 0xfc, 0x57, 0xdf, 0xa8, 0xf0, 0xa1, 0x36, 0xb2,
+	0x85, 0xf5, 0xaa, 0xe6, 0x98, 0xa7, 0xd2, 0x6c, 0x57, 0x8f, 0xac, 0x60, 0xb0, 0x87, 0x37, 0xff,
+	0xb4, 0x20, 0x06, 0xee, 0x0c, 0xcf, 0xce, 0x6f, 0x46, 0xc3, 0x8b, 0xc7, 0xbe, 0x46, 0x3a, 0xb8,
+	0x75, 0x7b, 0x7f, 0x77, 0xd9, 0x47, 0xa7, 0xfe, 0xc7, 0xc2, 0x42, 0xf3, 0x85, 0x85, 0xbe, 0x16,
+	0x16, 0x7a, 0x5b, 0x5a, 0xda, 0x7c, 0x69, 0x69, 0x9f, 0x4b, 0x4b, 0x7b, 0x3a, 0x1a, 0xbf, 0x84,
+	0x94, 0x65, 0xe1, 0x04, 0x84, 0xcb, 0x45, 0xe2, 0xad, 0xd6, 0xfd, 0x84, 0x7b, 0x19, 0x8f, 0xa7,
+	0x29, 0x78, 0x0c, 0x94, 0x97, 0xd2, 0xd7, 0x29, 0x8d, 0x3d, 0x49, 0xb3, 0x3c, 0x05, 0x2f, 0x8f,
+	0xa2, 0x76, 0xf5, 0xc9, 0xe1, 0xf7, 0x00, 0x09, 0xb4, 0xd0, 0x48, 0x93, 0x01, 0x00, 0x00,
+}
+
+func (m *IdentifyMsg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *IdentifyMsg) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *IdentifyMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Protocols) > 0 {
+		for iNdEx := len(m.Protocols) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Protocols[iNdEx])
+			copy(dAtA[i:], m.Protocols[iNdEx])
+			i = encodeVarintIdentify(dAtA, i, uint64(len(m.Protocols[iNdEx])))
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if len(m.AgentVersion) > 0 {
+		i -= len(m.AgentVersion)
+		copy(dAtA[i:], m.AgentVersion)
+		i = encodeVarintIdentify(dAtA, i, uint64(len(m.AgentVersion)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.PublicKey) > 0 {
+		i -= len(m.PublicKey)
+		copy(dAtA[i:], m.PublicKey)
+		i = encodeVarintIdentify(dAtA, i, uint64(len(m.PublicKey)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.ObservedAddr) > 0 {
+		i -= len(m.ObservedAddr)
+		copy(dAtA[i:], m.ObservedAddr)
+		i = encodeVarintIdentify(dAtA, i, uint64(len(m.ObservedAddr)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.ListenAddrs) > 0 {
+		for iNdEx := len(m.ListenAddrs) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ListenAddrs[iNdEx])
+			copy(dAtA[i:], m.ListenAddrs[iNdEx])
+			i = encodeVarintIdentify(dAtA, i, uint64(len(m.ListenAddrs[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.MsgType != 0 {
+		i = encodeVarintIdentify(dAtA, i, uint64(m.MsgType))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintIdentify(dAtA []byte, offset int, v uint64) int {
+	offset -= sovIdentify(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *IdentifyMsg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MsgType != 0 {
+		n += 1 + sovIdentify(uint64(m.MsgType))
+	}
+	if len(m.ListenAddrs) > 0 {
+		for _, s := range m.ListenAddrs {
+			l = len(s)
+			n += 1 + l + sovIdentify(uint64(l))
+		}
+	}
+	l = len(m.ObservedAddr)
+	if l > 0 {
+		n += 1 + l + sovIdentify(uint64(l))
+	}
+	l = len(m.PublicKey)
+	if l > 0 {
+		n += 1 + l + sovIdentify(uint64(l))
+	}
+	l = len(m.AgentVersion)
+	if l > 0 {
+		n += 1 + l + sovIdentify(uint64(l))
+	}
+	if len(m.Protocols) > 0 {
+		for _, s := range m.Protocols {
+			l = len(s)
+			n += 1 + l + sovIdentify(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovIdentify(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozIdentify(x uint64) (n int) {
+	return sovIdentify(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *IdentifyMsg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIdentify
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: IdentifyMsg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: IdentifyMsg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgType", wireType)
+			}
+			m.MsgType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIdentify
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MsgType |= IdentifyMsg_IdentifyMsgType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ListenAddrs", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIdentify
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ListenAddrs = append(m.ListenAddrs, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ObservedAddr", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIdentify
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ObservedAddr = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PublicKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIdentify
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PublicKey = append(m.PublicKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.PublicKey == nil {
+				m.PublicKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AgentVersion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIdentify
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AgentVersion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocols", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIdentify
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Protocols = append(m.Protocols, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIdentify(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIdentify
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipIdentify(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowIdentify
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowIdentify
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowIdentify
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthIdentify
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupIdentify
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthIdentify
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthIdentify        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowIdentify          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupIdentify = fmt.Errorf("proto: unexpected end of group")
+)
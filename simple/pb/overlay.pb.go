@@ -0,0 +1,1222 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: overlay.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type OverlayMsg_OverlayMsgType int32
+
+const (
+	OverlayMsg_ROUTES OverlayMsg_OverlayMsgType = 0
+	OverlayMsg_DATA   OverlayMsg_OverlayMsgType = 1
+)
+
+var OverlayMsg_OverlayMsgType_name = map[int32]string{
+	0: "ROUTES",
+	1: "DATA",
+}
+
+var OverlayMsg_OverlayMsgType_value = map[string]int32{
+	"ROUTES": 0,
+	"DATA":   1,
+}
+
+func (x OverlayMsg_OverlayMsgType) String() string {
+	return proto.EnumName(OverlayMsg_OverlayMsgType_name, int32(x))
+}
+
+func (OverlayMsg_OverlayMsgType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_61fc82527fbe24ad, []int{0, 0}
+}
+
+type OverlayMsg struct {
+	MsgType OverlayMsg_OverlayMsgType `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3,enum=net.OverlayMsg_OverlayMsgType" json:"msg_type,omitempty"`
+	Routes  []*OverlayRoute           `protobuf:"bytes,2,rep,name=routes,proto3" json:"routes,omitempty"`
+	Data    *OverlayData              `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *OverlayMsg) Reset()         { *m = OverlayMsg{} }
+func (m *OverlayMsg) String() string { return proto.CompactTextString(m) }
+func (*OverlayMsg) ProtoMessage()    {}
+func (*OverlayMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_61fc82527fbe24ad, []int{0}
+}
+func (m *OverlayMsg) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OverlayMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OverlayMsg.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OverlayMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OverlayMsg.Merge(m, src)
+}
+func (m *OverlayMsg) XXX_Size() int {
+	return m.Size()
+}
+func (m *OverlayMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_OverlayMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OverlayMsg proto.InternalMessageInfo
+
+func (m *OverlayMsg) GetMsgType() OverlayMsg_OverlayMsgType {
+	if m != nil {
+		return m.MsgType
+	}
+	return OverlayMsg_ROUTES
+}
+
+func (m *OverlayMsg) GetRoutes() []*OverlayRoute {
+	if m != nil {
+		return m.Routes
+	}
+	return nil
+}
+
+func (m *OverlayMsg) GetData() *OverlayData {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type OverlayRoute struct {
+	Pid  string `protobuf:"bytes,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	Hops uint32 `protobuf:"varint,2,opt,name=hops,proto3" json:"hops,omitempty"`
+}
+
+func (m *OverlayRoute) Reset()         { *m = OverlayRoute{} }
+func (m *OverlayRoute) String() string { return proto.CompactTextString(m) }
+func (*OverlayRoute) ProtoMessage()    {}
+func (*OverlayRoute) Descriptor() ([]byte, []int) {
+	return fileDescriptor_61fc82527fbe24ad, []int{1}
+}
+func (m *OverlayRoute) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OverlayRoute) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OverlayRoute.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OverlayRoute) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OverlayRoute.Merge(m, src)
+}
+func (m *OverlayRoute) XXX_Size() int {
+	return m.Size()
+}
+func (m *OverlayRoute) XXX_DiscardUnknown() {
+	xxx_messageInfo_OverlayRoute.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OverlayRoute proto.InternalMessageInfo
+
+func (m *OverlayRoute) GetPid() string {
+	if m != nil {
+		return m.Pid
+	}
+	return ""
+}
+
+func (m *OverlayRoute) GetHops() uint32 {
+	if m != nil {
+		return m.Hops
+	}
+	return 0
+}
+
+type OverlayData struct {
+	Src        string `protobuf:"bytes,1,opt,name=src,proto3" json:"src,omitempty"`
+	Dst        string `protobuf:"bytes,2,opt,name=dst,proto3" json:"dst,omitempty"`
+	ProtocolId string `protobuf:"bytes,3,opt,name=protocol_id,json=protocolId,proto3" json:"protocol_id,omitempty"`
+	Payload    []byte `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	MsgId      []byte `protobuf:"bytes,5,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
+	Timestamp  int64  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	PubKey     []byte `protobuf:"bytes,7,opt,name=pub_key,json=pubKey,proto3" json:"pub_key,omitempty"`
+	Signature  []byte `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	Hops       uint32 `protobuf:"varint,9,opt,name=hops,proto3" json:"hops,omitempty"`
+}
+
+func (m *OverlayData) Reset()         { *m = OverlayData{} }
+func (m *OverlayData) String() string { return proto.CompactTextString(m) }
+func (*OverlayData) ProtoMessage()    {}
+func (*OverlayData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_61fc82527fbe24ad, []int{2}
+}
+func (m *OverlayData) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *OverlayData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_OverlayData.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *OverlayData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OverlayData.Merge(m, src)
+}
+func (m *OverlayData) XXX_Size() int {
+	return m.Size()
+}
+func (m *OverlayData) XXX_DiscardUnknown() {
+	xxx_messageInfo_OverlayData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OverlayData proto.InternalMessageInfo
+
+func (m *OverlayData) GetSrc() string {
+	if m != nil {
+		return m.Src
+	}
+	return ""
+}
+
+func (m *OverlayData) GetDst() string {
+	if m != nil {
+		return m.Dst
+	}
+	return ""
+}
+
+func (m *OverlayData) GetProtocolId() string {
+	if m != nil {
+		return m.ProtocolId
+	}
+	return ""
+}
+
+func (m *OverlayData) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *OverlayData) GetMsgId() []byte {
+	if m != nil {
+		return m.MsgId
+	}
+	return nil
+}
+
+func (m *OverlayData) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *OverlayData) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *OverlayData) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *OverlayData) GetHops() uint32 {
+	if m != nil {
+		return m.Hops
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("net.OverlayMsg_OverlayMsgType", OverlayMsg_OverlayMsgType_name, OverlayMsg_OverlayMsgType_value)
+	proto.RegisterType((*OverlayMsg)(nil), "net.OverlayMsg")
+	proto.RegisterType((*OverlayRoute)(nil), "net.OverlayRoute")
+	proto.RegisterType((*OverlayData)(nil), "net.OverlayData")
+}
+
+func init() { proto.RegisterFile("overlay.proto", fileDescriptor_61fc82527fbe24ad) }
+
+var fileDescriptor_61fc82527fbe24ad = []byte{
+	// 410 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x54, 0x90, 0x3f, 0x8f, 0xd3, 0x30,
+	0x18, 0xc6, 0x6b, 0xd2, 0x4b, 0x9b, 0xb7, 0x77, 0xa7, 0x62, 0x09, 0xe1, 0x01, 0x85, 0xa8, 0x42,
+	0x28, 0x0c, 0x34, 0x52, 0x61, 0x80, 0xf1, 0xd0, 0x31, 0x9c, 0x10, 0x3a, 0xc9, 0x94, 0x85, 0xa5,
+	0x72, 0x63, 0x2b, 0x67, 0x5d, 0x12, 0x9b, 0xd8, 0x41, 0xca, 0xb7, 0xe0, 0x23, 0x31, 0x32, 0xde,
+	0xc8, 0x88, 0xda, 0x95, 0x0f, 0x81, 0xec, 0x52, 0x92, 0xdb, 0x9e, 0xf7, 0x79, 0xde, 0x9f, 0xde,
+	0x3f, 0x70, 0xa6, 0xbe, 0x89, 0xa6, 0x64, 0xdd, 0x52, 0x37, 0xca, 0x2a, 0x1c, 0xd4, 0xc2, 0x2e,
+	0x7e, 0x20, 0x80, 0xeb, 0x83, 0xfd, 0xd1, 0x14, 0xf8, 0x2d, 0x4c, 0x2b, 0x53, 0x6c, 0x6c, 0xa7,
+	0x05, 0x41, 0x09, 0x4a, 0xcf, 0x57, 0xf1, 0xb2, 0x16, 0x76, 0xd9, 0xb7, 0x0c, 0xe4, 0xba, 0xd3,
+	0x82, 0x4e, 0xaa, 0x83, 0xc0, 0x2f, 0x20, 0x6c, 0x54, 0x6b, 0x85, 0x21, 0x0f, 0x92, 0x20, 0x9d,
+	0xad, 0x1e, 0x0e, 0x41, 0xea, 0x12, 0xfa, 0xaf, 0x01, 0x3f, 0x83, 0x31, 0x67, 0x96, 0x91, 0x20,
+	0x41, 0xe9, 0x6c, 0x35, 0x1f, 0x36, 0x5e, 0x32, 0xcb, 0xa8, 0x4f, 0x17, 0xcf, 0xe1, 0xfc, 0xfe,
+	0x2c, 0x0c, 0x10, 0xd2, 0xeb, 0xcf, 0xeb, 0xf7, 0x9f, 0xe6, 0x23, 0x3c, 0x85, 0xf1, 0xe5, 0xc5,
+	0xfa, 0x62, 0x8e, 0x16, 0xaf, 0xe1, 0x74, 0x38, 0x05, 0xcf, 0x21, 0xd0, 0x92, 0xfb, 0xf5, 0x23,
+	0xea, 0x24, 0xc6, 0x30, 0xbe, 0x51, 0xda, 0x2d, 0x86, 0xd2, 0x33, 0xea, 0xf5, 0xe2, 0x0f, 0x82,
+	0xd9, 0x60, 0xa6, 0xa3, 0x4c, 0x93, 0x1f, 0x29, 0xd3, 0xe4, 0xce, 0xe1, 0xc6, 0x7a, 0x28, 0xa2,
+	0x4e, 0xe2, 0xa7, 0x30, 0xf3, 0xaf, 0xcb, 0x55, 0xb9, 0x91, 0xdc, 0xaf, 0x1f, 0x51, 0x38, 0x5a,
+	0x57, 0x1c, 0x13, 0x98, 0x68, 0xd6, 0x95, 0x8a, 0x71, 0x32, 0x4e, 0x50, 0x7a, 0x4a, 0x8f, 0x25,
+	0x7e, 0x04, 0xa1, 0x7b, 0xac, 0xe4, 0xe4, 0xc4, 0x07, 0x27, 0x95, 0x29, 0xae, 0x38, 0x7e, 0x02,
+	0x91, 0x95, 0x95, 0x30, 0x96, 0x55, 0x9a, 0x84, 0x09, 0x4a, 0x03, 0xda, 0x1b, 0xf8, 0x31, 0x4c,
+	0x74, 0xbb, 0xdd, 0xdc, 0x8a, 0x8e, 0x4c, 0x3c, 0x15, 0xea, 0x76, 0xfb, 0x41, 0x74, 0x0e, 0x33,
+	0xb2, 0xa8, 0x99, 0x6d, 0x1b, 0x41, 0xa6, 0x3e, 0xea, 0x8d, 0xff, 0xe7, 0x46, 0xfd, 0xb9, 0xef,
+	0xe8, 0xcf, 0x5d, 0x8c, 0xee, 0x76, 0x31, 0xfa, 0xbd, 0x8b, 0xd1, 0xf7, 0x7d, 0x3c, 0xba, 0xdb,
+	0xc7, 0xa3, 0x5f, 0xfb, 0x78, 0xf4, 0xe5, 0x4d, 0x7e, 0xc3, 0x64, 0x5d, 0xb1, 0x5b, 0xd1, 0x2c,
+	0x55, 0x53, 0x64, 0x7d, 0xf9, 0xb2, 0x50, 0x59, 0xa5, 0x78, 0x5b, 0x8a, 0xac, 0x16, 0x36, 0x2b,
+	0xe5, 0xd7, 0x56, 0xf2, 0xcc, 0xc8, 0x4a, 0x97, 0x22, 0xd3, 0xdb, 0x6d, 0xe8, 0x2f, 0x7f, 0xf5,
+	0x77, 0x00, 0x78, 0x3a, 0xc0, 0x22, 0x58, 0x02, 0x00, 0x00,
+}
+
+func (m *OverlayMsg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OverlayMsg) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OverlayMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Data != nil {
+		{
+			size, err := m.Data.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintOverlay(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Routes) > 0 {
+		for iNdEx := len(m.Routes) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Routes[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintOverlay(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.MsgType != 0 {
+		i = encodeVarintOverlay(dAtA, i, uint64(m.MsgType))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OverlayRoute) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OverlayRoute) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OverlayRoute) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Hops != 0 {
+		i = encodeVarintOverlay(dAtA, i, uint64(m.Hops))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Pid) > 0 {
+		i -= len(m.Pid)
+		copy(dAtA[i:], m.Pid)
+		i = encodeVarintOverlay(dAtA, i, uint64(len(m.Pid)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *OverlayData) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *OverlayData) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *OverlayData) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Hops != 0 {
+		i = encodeVarintOverlay(dAtA, i, uint64(m.Hops))
+		i--
+		dAtA[i] = 0x48
+	}
+	if len(m.Signature) > 0 {
+		i -= len(m.Signature)
+		copy(dAtA[i:], m.Signature)
+		i = encodeVarintOverlay(dAtA, i, uint64(len(m.Signature)))
+		i--
+		dAtA[i] = 0x42
+	}
+	if len(m.PubKey) > 0 {
+		i -= len(m.PubKey)
+		copy(dAtA[i:], m.PubKey)
+		i = encodeVarintOverlay(dAtA, i, uint64(len(m.PubKey)))
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.Timestamp != 0 {
+		i = encodeVarintOverlay(dAtA, i, uint64(m.Timestamp))
+		i--
+		dAtA[i] = 0x30
+	}
+	if len(m.MsgId) > 0 {
+		i -= len(m.MsgId)
+		copy(dAtA[i:], m.MsgId)
+		i = encodeVarintOverlay(dAtA, i, uint64(len(m.MsgId)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Payload) > 0 {
+		i -= len(m.Payload)
+		copy(dAtA[i:], m.Payload)
+		i = encodeVarintOverlay(dAtA, i, uint64(len(m.Payload)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.ProtocolId) > 0 {
+		i -= len(m.ProtocolId)
+		copy(dAtA[i:], m.ProtocolId)
+		i = encodeVarintOverlay(dAtA, i, uint64(len(m.ProtocolId)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Dst) > 0 {
+		i -= len(m.Dst)
+		copy(dAtA[i:], m.Dst)
+		i = encodeVarintOverlay(dAtA, i, uint64(len(m.Dst)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Src) > 0 {
+		i -= len(m.Src)
+		copy(dAtA[i:], m.Src)
+		i = encodeVarintOverlay(dAtA, i, uint64(len(m.Src)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintOverlay(dAtA []byte, offset int, v uint64) int {
+	offset -= sovOverlay(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *OverlayMsg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MsgType != 0 {
+		n += 1 + sovOverlay(uint64(m.MsgType))
+	}
+	if len(m.Routes) > 0 {
+		for _, e := range m.Routes {
+			l = e.Size()
+			n += 1 + l + sovOverlay(uint64(l))
+		}
+	}
+	if m.Data != nil {
+		l = m.Data.Size()
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	return n
+}
+
+func (m *OverlayRoute) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Pid)
+	if l > 0 {
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	if m.Hops != 0 {
+		n += 1 + sovOverlay(uint64(m.Hops))
+	}
+	return n
+}
+
+func (m *OverlayData) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Src)
+	if l > 0 {
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	l = len(m.Dst)
+	if l > 0 {
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	l = len(m.ProtocolId)
+	if l > 0 {
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	l = len(m.Payload)
+	if l > 0 {
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	l = len(m.MsgId)
+	if l > 0 {
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	if m.Timestamp != 0 {
+		n += 1 + sovOverlay(uint64(m.Timestamp))
+	}
+	l = len(m.PubKey)
+	if l > 0 {
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovOverlay(uint64(l))
+	}
+	if m.Hops != 0 {
+		n += 1 + sovOverlay(uint64(m.Hops))
+	}
+	return n
+}
+
+func sovOverlay(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozOverlay(x uint64) (n int) {
+	return sovOverlay(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *OverlayMsg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOverlay
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OverlayMsg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OverlayMsg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgType", wireType)
+			}
+			m.MsgType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MsgType |= OverlayMsg_OverlayMsgType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Routes", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Routes = append(m.Routes, &OverlayRoute{})
+			if err := m.Routes[len(m.Routes)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Data == nil {
+				m.Data = &OverlayData{}
+			}
+			if err := m.Data.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOverlay(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OverlayRoute) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOverlay
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OverlayRoute: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OverlayRoute: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pid", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Pid = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hops", wireType)
+			}
+			m.Hops = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Hops |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOverlay(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *OverlayData) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowOverlay
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: OverlayData: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: OverlayData: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Src", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Src = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dst", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dst = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProtocolId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProtocolId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Payload", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Payload = append(m.Payload[:0], dAtA[iNdEx:postIndex]...)
+			if m.Payload == nil {
+				m.Payload = []byte{}
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgId", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MsgId = append(m.MsgId[:0], dAtA[iNdEx:postIndex]...)
+			if m.MsgId == nil {
+				m.MsgId = []byte{}
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Timestamp", wireType)
+			}
+			m.Timestamp = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Timestamp |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PubKey", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PubKey = append(m.PubKey[:0], dAtA[iNdEx:postIndex]...)
+			if m.PubKey == nil {
+				m.PubKey = []byte{}
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hops", wireType)
+			}
+			m.Hops = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Hops |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipOverlay(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthOverlay
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipOverlay(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowOverlay
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowOverlay
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthOverlay
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupOverlay
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthOverlay
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthOverlay        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowOverlay          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupOverlay = fmt.Errorf("proto: unexpected end of group")
+)
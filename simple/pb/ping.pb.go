@@ -0,0 +1,418 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: ping.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type PingMsg_PingMsgType int32
+
+const (
+	PingMsg_PING PingMsg_PingMsgType = 0
+	PingMsg_PONG PingMsg_PingMsgType = 1
+)
+
+var PingMsg_PingMsgType_name = map[int32]string{
+	0: "PING",
+	1: "PONG",
+}
+
+var PingMsg_PingMsgType_value = map[string]int32{
+	"PING": 0,
+	"PONG": 1,
+}
+
+func (x PingMsg_PingMsgType) String() string {
+	return proto.EnumName(PingMsg_PingMsgType_name, int32(x))
+}
+
+func (PingMsg_PingMsgType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_6d51d96c3ad891f5, []int{0, 0}
+}
+
+type PingMsg struct {
+	MsgType PingMsg_PingMsgType `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3,enum=net.PingMsg_PingMsgType" json:"msg_type,omitempty"`
+	Seq     uint64              `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Payload []byte              `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *PingMsg) Reset()         { *m = PingMsg{} }
+func (m *PingMsg) String() string { return proto.CompactTextString(m) }
+func (*PingMsg) ProtoMessage()    {}
+func (*PingMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6d51d96c3ad891f5, []int{0}
+}
+func (m *PingMsg) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *PingMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_PingMsg.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *PingMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PingMsg.Merge(m, src)
+}
+func (m *PingMsg) XXX_Size() int {
+	return m.Size()
+}
+func (m *PingMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_PingMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PingMsg proto.InternalMessageInfo
+
+func (m *PingMsg) GetMsgType() PingMsg_PingMsgType {
+	if m != nil {
+		return m.MsgType
+	}
+	return PingMsg_PING
+}
+
+func (m *PingMsg) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *PingMsg) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("net.PingMsg_PingMsgType", PingMsg_PingMsgType_name, PingMsg_PingMsgType_value)
+	proto.RegisterType((*PingMsg)(nil), "net.PingMsg")
+}
+
+func init() { proto.RegisterFile("ping.proto", fileDescriptor_6d51d96c3ad891f5) }
+
+var fileDescriptor_6d51d96c3ad891f5 = []byte{
+	// 228 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x2a, 0xc8, 0xcc, 0x4b,
+	0xd7, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0xce, 0x4b, 0x2d, 0x51, 0xea, 0x65, 0xe4, 0x62,
+	0x0f, 0xc8, 0xcc, 0x4b, 0xf7, 0x2d, 0x4e, 0x17, 0x32, 0xe6, 0xe2, 0xc8, 0x2d, 0x4e, 0x8f, 0x2f,
+	0xa9, 0x2c, 0x48, 0x95, 0x60, 0x54, 0x60, 0xd4, 0xe0, 0x33, 0x92, 0xd0, 0xcb, 0x4b, 0x2d, 0xd1,
+	0x83, 0xca, 0xc3, 0xe8, 0x90, 0xca, 0x82, 0xd4, 0x20, 0xf6, 0x5c, 0x08, 0x43, 0x48, 0x80, 0x8b,
+	0xb9, 0x38, 0xb5, 0x50, 0x82, 0x49, 0x81, 0x51, 0x83, 0x25, 0x08, 0xc4, 0x14, 0x92, 0xe0, 0x62,
+	0x2f, 0x48, 0xac, 0xcc, 0xc9, 0x4f, 0x4c, 0x91, 0x60, 0x56, 0x60, 0xd4, 0xe0, 0x09, 0x82, 0x71,
+	0x95, 0x14, 0xb9, 0xb8, 0x91, 0xcc, 0x10, 0xe2, 0xe0, 0x62, 0x09, 0xf0, 0xf4, 0x73, 0x17, 0x60,
+	0x00, 0xb3, 0xfc, 0xfd, 0xdc, 0x05, 0x18, 0x9d, 0x82, 0x4e, 0x3c, 0x92, 0x63, 0xbc, 0xf0, 0x48,
+	0x8e, 0xf1, 0xc1, 0x23, 0x39, 0xc6, 0x09, 0x8f, 0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63, 0xb8, 0xf1,
+	0x58, 0x8e, 0x21, 0xca, 0x22, 0x39, 0x23, 0x31, 0x33, 0x2f, 0x37, 0x31, 0x3b, 0xb5, 0x48, 0x2f,
+	0xbf, 0x28, 0x5d, 0x1f, 0xc1, 0xd5, 0x4d, 0xcf, 0xd7, 0xcf, 0xcd, 0x4f, 0x29, 0xcd, 0x49, 0xd5,
+	0xcf, 0x4b, 0x2d, 0xd1, 0xcf, 0xc9, 0x2c, 0x2c, 0xcd, 0x4c, 0xd1, 0x2f, 0xce, 0xcc, 0x2d, 0xc8,
+	0x49, 0xd5, 0x2f, 0x48, 0x4a, 0x62, 0x03, 0xfb, 0xd7, 0x18, 0x30, 0x00, 0x60, 0x00, 0x2c, 0xc8,
+	0xfd, 0x00, 0x00, 0x00,
+}
+
+func (m *PingMsg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PingMsg) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PingMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Payload) > 0 {
+		i -= len(m.Payload)
+		copy(dAtA[i:], m.Payload)
+		i = encodeVarintPing(dAtA, i, uint64(len(m.Payload)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Seq != 0 {
+		i = encodeVarintPing(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.MsgType != 0 {
+		i = encodeVarintPing(dAtA, i, uint64(m.MsgType))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintPing(dAtA []byte, offset int, v uint64) int {
+	offset -= sovPing(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *PingMsg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MsgType != 0 {
+		n += 1 + sovPing(uint64(m.MsgType))
+	}
+	if m.Seq != 0 {
+		n += 1 + sovPing(uint64(m.Seq))
+	}
+	l = len(m.Payload)
+	if l > 0 {
+		n += 1 + l + sovPing(uint64(l))
+	}
+	return n
+}
+
+func sovPing(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozPing(x uint64) (n int) {
+	return sovPing(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *PingMsg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowPing
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PingMsg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PingMsg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgType", wireType)
+			}
+			m.MsgType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPing
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MsgType |= PingMsg_PingMsgType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPing
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Payload", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowPing
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthPing
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthPing
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Payload = append(m.Payload[:0], dAtA[iNdEx:postIndex]...)
+			if m.Payload == nil {
+				m.Payload = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipPing(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthPing
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipPing(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowPing
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPing
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowPing
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthPing
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupPing
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthPing
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthPing        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowPing          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupPing = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,581 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: relay.proto
+
+package pb
+
+import (
+	fmt "fmt"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type RelayMsg_RelayMsgType int32
+
+const (
+	RelayMsg_RESERVE RelayMsg_RelayMsgType = 0
+	RelayMsg_CONNECT RelayMsg_RelayMsgType = 1
+	RelayMsg_STOP    RelayMsg_RelayMsgType = 2
+	RelayMsg_STATUS  RelayMsg_RelayMsgType = 3
+)
+
+var RelayMsg_RelayMsgType_name = map[int32]string{
+	0: "RESERVE",
+	1: "CONNECT",
+	2: "STOP",
+	3: "STATUS",
+}
+
+var RelayMsg_RelayMsgType_value = map[string]int32{
+	"RESERVE": 0,
+	"CONNECT": 1,
+	"STOP":    2,
+	"STATUS":  3,
+}
+
+func (x RelayMsg_RelayMsgType) String() string {
+	return proto.EnumName(RelayMsg_RelayMsgType_name, int32(x))
+}
+
+func (RelayMsg_RelayMsgType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_9f69a7d5a802d584, []int{0, 0}
+}
+
+type RelayMsg_RelayStatus int32
+
+const (
+	RelayMsg_OK                      RelayMsg_RelayStatus = 0
+	RelayMsg_RESERVATION_REFUSED     RelayMsg_RelayStatus = 1
+	RelayMsg_RESOURCE_LIMIT_EXCEEDED RelayMsg_RelayStatus = 2
+	RelayMsg_PERMISSION_DENIED       RelayMsg_RelayStatus = 3
+	RelayMsg_NO_RESERVATION          RelayMsg_RelayStatus = 4
+	RelayMsg_CONNECTION_FAILED       RelayMsg_RelayStatus = 5
+	RelayMsg_MALFORMED_MESSAGE       RelayMsg_RelayStatus = 6
+)
+
+var RelayMsg_RelayStatus_name = map[int32]string{
+	0: "OK",
+	1: "RESERVATION_REFUSED",
+	2: "RESOURCE_LIMIT_EXCEEDED",
+	3: "PERMISSION_DENIED",
+	4: "NO_RESERVATION",
+	5: "CONNECTION_FAILED",
+	6: "MALFORMED_MESSAGE",
+}
+
+var RelayMsg_RelayStatus_value = map[string]int32{
+	"OK":                      0,
+	"RESERVATION_REFUSED":     1,
+	"RESOURCE_LIMIT_EXCEEDED": 2,
+	"PERMISSION_DENIED":       3,
+	"NO_RESERVATION":          4,
+	"CONNECTION_FAILED":       5,
+	"MALFORMED_MESSAGE":       6,
+}
+
+func (x RelayMsg_RelayStatus) String() string {
+	return proto.EnumName(RelayMsg_RelayStatus_name, int32(x))
+}
+
+func (RelayMsg_RelayStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_9f69a7d5a802d584, []int{0, 1}
+}
+
+type RelayMsg struct {
+	MsgType       RelayMsg_RelayMsgType `protobuf:"varint,1,opt,name=msg_type,json=msgType,proto3,enum=net.RelayMsg_RelayMsgType" json:"msg_type,omitempty"`
+	PeerId        string                `protobuf:"bytes,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`
+	Status        RelayMsg_RelayStatus  `protobuf:"varint,3,opt,name=status,proto3,enum=net.RelayMsg_RelayStatus" json:"status,omitempty"`
+	Expire        int64                 `protobuf:"varint,4,opt,name=expire,proto3" json:"expire,omitempty"`
+	LimitDuration int64                 `protobuf:"varint,5,opt,name=limit_duration,json=limitDuration,proto3" json:"limit_duration,omitempty"`
+	LimitData     int64                 `protobuf:"varint,6,opt,name=limit_data,json=limitData,proto3" json:"limit_data,omitempty"`
+}
+
+func (m *RelayMsg) Reset()         { *m = RelayMsg{} }
+func (m *RelayMsg) String() string { return proto.CompactTextString(m) }
+func (*RelayMsg) ProtoMessage()    {}
+func (*RelayMsg) Descriptor() ([]byte, []int) {
+	return fileDescriptor_9f69a7d5a802d584, []int{0}
+}
+func (m *RelayMsg) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *RelayMsg) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_RelayMsg.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *RelayMsg) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RelayMsg.Merge(m, src)
+}
+func (m *RelayMsg) XXX_Size() int {
+	return m.Size()
+}
+func (m *RelayMsg) XXX_DiscardUnknown() {
+	xxx_messageInfo_RelayMsg.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RelayMsg proto.InternalMessageInfo
+
+func (m *RelayMsg) GetMsgType() RelayMsg_RelayMsgType {
+	if m != nil {
+		return m.MsgType
+	}
+	return RelayMsg_RESERVE
+}
+
+func (m *RelayMsg) GetPeerId() string {
+	if m != nil {
+		return m.PeerId
+	}
+	return ""
+}
+
+func (m *RelayMsg) GetStatus() RelayMsg_RelayStatus {
+	if m != nil {
+		return m.Status
+	}
+	return RelayMsg_OK
+}
+
+func (m *RelayMsg) GetExpire() int64 {
+	if m != nil {
+		return m.Expire
+	}
+	return 0
+}
+
+func (m *RelayMsg) GetLimitDuration() int64 {
+	if m != nil {
+		return m.LimitDuration
+	}
+	return 0
+}
+
+func (m *RelayMsg) GetLimitData() int64 {
+	if m != nil {
+		return m.LimitData
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("net.RelayMsg_RelayMsgType", RelayMsg_RelayMsgType_name, RelayMsg_RelayMsgType_value)
+	proto.RegisterEnum("net.RelayMsg_RelayStatus", RelayMsg_RelayStatus_name, RelayMsg_RelayStatus_value)
+	proto.RegisterType((*RelayMsg)(nil), "net.RelayMsg")
+}
+
+func init() { proto.RegisterFile("relay.proto", fileDescriptor_9f69a7d5a802d584) }
+
+var fileDescriptor_9f69a7d5a802d584 = []byte{
+	// 437 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xcf, 0xaa, 0xd3, 0x40,
+	0x14, 0xc6, 0x33, 0xcd, 0xbd, 0x69, 0xef, 0xa9, 0x96, 0x71, 0x44, 0x1b, 0x15, 0x43, 0x29, 0x08,
+	0xdd, 0xd8, 0xa0, 0x22, 0xb8, 0x12, 0x62, 0x33, 0x95, 0x60, 0x93, 0x5c, 0x66, 0x52, 0x11, 0x37,
+	0x21, 0xd7, 0x0c, 0x35, 0x98, 0x7f, 0x26, 0x53, 0xb0, 0x6f, 0xe1, 0x43, 0xf8, 0x30, 0x2e, 0x2f,
+	0xae, 0x5c, 0x4a, 0xfb, 0x22, 0x92, 0x34, 0x6a, 0x17, 0xee, 0xce, 0xf7, 0xcd, 0x6f, 0x7e, 0x9c,
+	0x81, 0x81, 0x61, 0x25, 0xd2, 0x68, 0x37, 0x2f, 0xab, 0x42, 0x16, 0x44, 0xcd, 0x85, 0x9c, 0xfe,
+	0x50, 0x61, 0xc0, 0x9a, 0xd2, 0xad, 0x37, 0xe4, 0x39, 0x0c, 0xb2, 0x7a, 0x13, 0xca, 0x5d, 0x29,
+	0x74, 0x34, 0x41, 0xb3, 0xd1, 0xd3, 0xfb, 0xf3, 0x5c, 0xc8, 0xf9, 0x1f, 0xe0, 0xef, 0x10, 0xec,
+	0x4a, 0xc1, 0xfa, 0xd9, 0x71, 0x20, 0x63, 0xe8, 0x97, 0x42, 0x54, 0x61, 0x12, 0xeb, 0xbd, 0x09,
+	0x9a, 0x5d, 0x30, 0xad, 0x89, 0x4e, 0x4c, 0x9e, 0x80, 0x56, 0xcb, 0x48, 0x6e, 0x6b, 0x5d, 0x6d,
+	0x6d, 0xf7, 0xfe, 0x63, 0xe3, 0x2d, 0xc0, 0x3a, 0x90, 0xdc, 0x05, 0x4d, 0x7c, 0x29, 0x93, 0x4a,
+	0xe8, 0x67, 0x13, 0x34, 0x53, 0x59, 0x97, 0xc8, 0x23, 0x18, 0xa5, 0x49, 0x96, 0xc8, 0x30, 0xde,
+	0x56, 0x91, 0x4c, 0x8a, 0x5c, 0x3f, 0x6f, 0xcf, 0x6f, 0xb6, 0xad, 0xdd, 0x95, 0xe4, 0x21, 0x40,
+	0x87, 0x45, 0x32, 0xd2, 0xb5, 0x16, 0xb9, 0x38, 0x22, 0x91, 0x8c, 0xa6, 0x2f, 0xe1, 0xc6, 0xe9,
+	0x13, 0xc8, 0x10, 0xfa, 0x8c, 0x72, 0xca, 0xde, 0x52, 0xac, 0x34, 0x61, 0xe1, 0x7b, 0x1e, 0x5d,
+	0x04, 0x18, 0x91, 0x01, 0x9c, 0xf1, 0xc0, 0xbf, 0xc4, 0x3d, 0x02, 0xa0, 0xf1, 0xc0, 0x0a, 0xd6,
+	0x1c, 0xab, 0xd3, 0x6f, 0x08, 0x86, 0x27, 0x5b, 0x13, 0x0d, 0x7a, 0xfe, 0x1b, 0xac, 0x90, 0x31,
+	0xdc, 0x3e, 0x7a, 0xac, 0xc0, 0xf1, 0xbd, 0x90, 0xd1, 0xe5, 0x9a, 0x53, 0x1b, 0x23, 0xf2, 0x00,
+	0xc6, 0x8c, 0x72, 0x7f, 0xcd, 0x16, 0x34, 0x5c, 0x39, 0xae, 0x13, 0x84, 0xf4, 0xdd, 0x82, 0x52,
+	0x9b, 0xda, 0xb8, 0x47, 0xee, 0xc0, 0xad, 0x4b, 0xca, 0x5c, 0x87, 0xf3, 0xe6, 0x92, 0x4d, 0x3d,
+	0x87, 0xda, 0x58, 0x25, 0x04, 0x46, 0x9e, 0x1f, 0x9e, 0xf8, 0xf0, 0x59, 0x83, 0x76, 0xbb, 0x35,
+	0xe8, 0xd2, 0x72, 0x56, 0xd4, 0xc6, 0xe7, 0x4d, 0xed, 0x5a, 0xab, 0xa5, 0xcf, 0x5c, 0x6a, 0x87,
+	0x2e, 0xe5, 0xdc, 0x7a, 0x4d, 0xb1, 0xf6, 0x8a, 0x7d, 0xdf, 0x1b, 0xe8, 0x7a, 0x6f, 0xa0, 0x5f,
+	0x7b, 0x03, 0x7d, 0x3d, 0x18, 0xca, 0xf5, 0xc1, 0x50, 0x7e, 0x1e, 0x0c, 0xe5, 0xfd, 0x8b, 0x0f,
+	0x1f, 0xa3, 0x24, 0xcf, 0xa2, 0x4f, 0xa2, 0x9a, 0x17, 0xd5, 0xc6, 0xfc, 0x17, 0x1f, 0x6f, 0x0a,
+	0x33, 0x2b, 0xe2, 0x6d, 0x2a, 0xcc, 0x5c, 0x48, 0x33, 0x4d, 0x3e, 0x6f, 0x93, 0xd8, 0xac, 0x93,
+	0xac, 0x4c, 0x85, 0x59, 0x5e, 0x5d, 0x69, 0xed, 0xa7, 0x79, 0xf6, 0x7b, 0x00, 0x94, 0x33, 0x85,
+	0x2b, 0x43, 0x02, 0x00, 0x00,
+}
+
+func (m *RelayMsg) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *RelayMsg) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *RelayMsg) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.LimitData != 0 {
+		i = encodeVarintRelay(dAtA, i, uint64(m.LimitData))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.LimitDuration != 0 {
+		i = encodeVarintRelay(dAtA, i, uint64(m.LimitDuration))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.Expire != 0 {
+		i = encodeVarintRelay(dAtA, i, uint64(m.Expire))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Status != 0 {
+		i = encodeVarintRelay(dAtA, i, uint64(m.Status))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.PeerId) > 0 {
+		i -= len(m.PeerId)
+		copy(dAtA[i:], m.PeerId)
+		i = encodeVarintRelay(dAtA, i, uint64(len(m.PeerId)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.MsgType != 0 {
+		i = encodeVarintRelay(dAtA, i, uint64(m.MsgType))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintRelay(dAtA []byte, offset int, v uint64) int {
+	offset -= sovRelay(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *RelayMsg) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.MsgType != 0 {
+		n += 1 + sovRelay(uint64(m.MsgType))
+	}
+	l = len(m.PeerId)
+	if l > 0 {
+		n += 1 + l + sovRelay(uint64(l))
+	}
+	if m.Status != 0 {
+		n += 1 + sovRelay(uint64(m.Status))
+	}
+	if m.Expire != 0 {
+		n += 1 + sovRelay(uint64(m.Expire))
+	}
+	if m.LimitDuration != 0 {
+		n += 1 + sovRelay(uint64(m.LimitDuration))
+	}
+	if m.LimitData != 0 {
+		n += 1 + sovRelay(uint64(m.LimitData))
+	}
+	return n
+}
+
+func sovRelay(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozRelay(x uint64) (n int) {
+	return sovRelay(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *RelayMsg) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowRelay
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: RelayMsg: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: RelayMsg: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgType", wireType)
+			}
+			m.MsgType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRelay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MsgType |= RelayMsg_RelayMsgType(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PeerId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRelay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRelay
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRelay
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PeerId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Status", wireType)
+			}
+			m.Status = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRelay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Status |= RelayMsg_RelayStatus(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Expire", wireType)
+			}
+			m.Expire = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRelay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Expire |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LimitDuration", wireType)
+			}
+			m.LimitDuration = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRelay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LimitDuration |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LimitData", wireType)
+			}
+			m.LimitData = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRelay
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LimitData |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipRelay(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthRelay
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipRelay(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowRelay
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowRelay
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowRelay
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthRelay
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupRelay
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthRelay
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthRelay        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowRelay          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupRelay = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,257 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+)
+
+const (
+	// ScoreInputLatency is the name of the score input computed with the latency of peer.
+	ScoreInputLatency = "latency"
+	// ScoreInputUptime is the name of the score input computed with how long the peer has been connected.
+	ScoreInputUptime = "uptime"
+	// ScoreInputTraffic is the name of the score input computed with the count of bytes exchanged with peer.
+	ScoreInputTraffic = "traffic"
+	// ScoreInputProtocols is the name of the score input computed with the overlap of protocols supported.
+	ScoreInputProtocols = "protocols"
+	// ScoreInputMisbehaviour is the name of the score input computed with the misbehaviour penalties of peer.
+	ScoreInputMisbehaviour = "misbehaviour"
+)
+
+const (
+	// DefaultLatencyScoreWeight is the default weight of ScoreInputLatency.
+	DefaultLatencyScoreWeight = 1.0
+	// DefaultUptimeScoreWeight is the default weight of ScoreInputUptime.
+	DefaultUptimeScoreWeight = 1.0
+	// DefaultTrafficScoreWeight is the default weight of ScoreInputTraffic.
+	DefaultTrafficScoreWeight = 1.0
+	// DefaultProtocolsScoreWeight is the default weight of ScoreInputProtocols.
+	DefaultProtocolsScoreWeight = 1.0
+	// DefaultMisbehaviourScoreWeight is the default weight of ScoreInputMisbehaviour.
+	DefaultMisbehaviourScoreWeight = 2.0
+
+	// latencyScoreBase is the latency that will be scored 0.5.
+	latencyScoreBase = 100 * time.Millisecond
+	// uptimeScoreBase is the uptime that will be scored 0.5.
+	uptimeScoreBase = 10 * time.Minute
+	// trafficScoreBase is the count of bytes exchanged that will be scored 0.5.
+	trafficScoreBase = 1 << 20
+)
+
+// PeerScoreInput is a pluggable source of peer score.
+// The value returned should be in [0, 1] and the bigger the better.
+// It will be called when the connection manager is locked,
+// so it must not call any method of mgr.ConnMgr.
+type PeerScoreInput func(pid peer.ID) float64
+
+type weightedScoreInput struct {
+	weight float64
+	input  PeerScoreInput
+}
+
+// peerScoreStat records the raw values of a peer for scoring.
+type peerScoreStat struct {
+	latency      int64 // nanoseconds, 0 means unknown
+	connectedAt  int64 // unix nanoseconds, 0 means not connected
+	traffic      uint64
+	misbehaviour uint64
+}
+
+// PeerScorer computes the score of peers with weighted PeerScoreInput list.
+// A peer with higher score is more useful to us.
+type PeerScorer struct {
+	mu     sync.RWMutex
+	inputs map[string]*weightedScoreInput
+
+	stats sync.Map // map[peer.ID]*peerScoreStat
+
+	host host.Host
+}
+
+// NewPeerScorer create a new *PeerScorer with default score inputs.
+// If h is nil, ScoreInputProtocols will not be set.
+func NewPeerScorer(h host.Host) *PeerScorer {
+	s := &PeerScorer{
+		inputs: make(map[string]*weightedScoreInput),
+		stats:  sync.Map{},
+		host:   h,
+	}
+	s.SetScoreInput(ScoreInputLatency, DefaultLatencyScoreWeight, s.latencyScore)
+	s.SetScoreInput(ScoreInputUptime, DefaultUptimeScoreWeight, s.uptimeScore)
+	s.SetScoreInput(ScoreInputTraffic, DefaultTrafficScoreWeight, s.trafficScore)
+	s.SetScoreInput(ScoreInputMisbehaviour, DefaultMisbehaviourScoreWeight, s.misbehaviourScore)
+	if h != nil {
+		s.SetScoreInput(ScoreInputProtocols, DefaultProtocolsScoreWeight, s.protocolsScore)
+	}
+	return s
+}
+
+// SetScoreInput set a score input with the name given. If the name exists, it will be replaced.
+func (s *PeerScorer) SetScoreInput(name string, weight float64, input PeerScoreInput) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputs[name] = &weightedScoreInput{weight: weight, input: input}
+}
+
+// RemoveScoreInput remove the score input with the name given.
+func (s *PeerScorer) RemoveScoreInput(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inputs, name)
+}
+
+// Score return the weighted sum of all score inputs of peer.
+func (s *PeerScorer) Score(pid peer.ID) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var score float64
+	for _, wi := range s.inputs {
+		score = score + wi.weight*wi.input(pid)
+	}
+	return score
+}
+
+// LowestScorePeer return the index of the peer who has the lowest score in the list given.
+// If more than one peer has the lowest score, the last connected one will be returned.
+// If the list is empty, return -1.
+func (s *PeerScorer) LowestScorePeer(pids []peer.ID) int {
+	if len(pids) == 0 {
+		return -1
+	}
+	res := 0
+	lowest := s.Score(pids[0])
+	for i := 1; i < len(pids); i++ {
+		if score := s.Score(pids[i]); score <= lowest {
+			res, lowest = i, score
+		}
+	}
+	return res
+}
+
+func (s *PeerScorer) loadOrCreateStat(pid peer.ID) *peerScoreStat {
+	v, ok := s.stats.Load(pid)
+	if !ok {
+		v, _ = s.stats.LoadOrStore(pid, &peerScoreStat{})
+	}
+	return v.(*peerScoreStat)
+}
+
+func (s *PeerScorer) loadStat(pid peer.ID) *peerScoreStat {
+	v, ok := s.stats.Load(pid)
+	if !ok {
+		return nil
+	}
+	return v.(*peerScoreStat)
+}
+
+// RecordLatency record the latest latency of peer.
+func (s *PeerScorer) RecordLatency(pid peer.ID, latency time.Duration) {
+	if latency <= 0 {
+		return
+	}
+	atomic.StoreInt64(&s.loadOrCreateStat(pid).latency, int64(latency))
+}
+
+// RecordTraffic append the count of bytes exchanged with peer.
+func (s *PeerScorer) RecordTraffic(pid peer.ID, bytes int) {
+	if bytes <= 0 {
+		return
+	}
+	atomic.AddUint64(&s.loadOrCreateStat(pid).traffic, uint64(bytes))
+}
+
+// RecordMisbehaviour append a misbehaviour penalty of peer.
+func (s *PeerScorer) RecordMisbehaviour(pid peer.ID, penalty uint64) {
+	atomic.AddUint64(&s.loadOrCreateStat(pid).misbehaviour, penalty)
+}
+
+// PeerConnected should be called when a peer connected.
+func (s *PeerScorer) PeerConnected(pid peer.ID) {
+	stat := s.loadOrCreateStat(pid)
+	atomic.CompareAndSwapInt64(&stat.connectedAt, 0, time.Now().UnixNano())
+}
+
+// PeerDisconnected should be called when a peer disconnected.
+// The stat of peer will be cleaned except misbehaviour penalties.
+func (s *PeerScorer) PeerDisconnected(pid peer.ID) {
+	stat := s.loadStat(pid)
+	if stat == nil {
+		return
+	}
+	if atomic.LoadUint64(&stat.misbehaviour) == 0 {
+		s.stats.Delete(pid)
+		return
+	}
+	atomic.StoreInt64(&stat.connectedAt, 0)
+	atomic.StoreInt64(&stat.latency, 0)
+	atomic.StoreUint64(&stat.traffic, 0)
+}
+
+// halfScore map a value in [0, +∞) to [0, 1), the value equal to base will be mapped to 0.5.
+func halfScore(v, base float64) float64 {
+	return v / (v + base)
+}
+
+func (s *PeerScorer) latencyScore(pid peer.ID) float64 {
+	stat := s.loadStat(pid)
+	if stat == nil {
+		return 0.5
+	}
+	latency := atomic.LoadInt64(&stat.latency)
+	if latency == 0 {
+		return 0.5
+	}
+	return 1 - halfScore(float64(latency), float64(latencyScoreBase))
+}
+
+func (s *PeerScorer) uptimeScore(pid peer.ID) float64 {
+	stat := s.loadStat(pid)
+	if stat == nil {
+		return 0
+	}
+	connectedAt := atomic.LoadInt64(&stat.connectedAt)
+	if connectedAt == 0 {
+		return 0
+	}
+	return halfScore(float64(time.Now().UnixNano()-connectedAt), float64(uptimeScoreBase))
+}
+
+func (s *PeerScorer) trafficScore(pid peer.ID) float64 {
+	stat := s.loadStat(pid)
+	if stat == nil {
+		return 0
+	}
+	return halfScore(float64(atomic.LoadUint64(&stat.traffic)), trafficScoreBase)
+}
+
+func (s *PeerScorer) misbehaviourScore(pid peer.ID) float64 {
+	stat := s.loadStat(pid)
+	if stat == nil {
+		return 1
+	}
+	return 1 - halfScore(float64(atomic.LoadUint64(&stat.misbehaviour)), 1)
+}
+
+func (s *PeerScorer) protocolsScore(pid peer.ID) float64 {
+	local := s.host.ProtocolMgr().GetSelfSupportedProtocols()
+	if len(local) == 0 {
+		return 0
+	}
+	contained := 0
+	for i := range local {
+		if s.host.ProtocolMgr().IsPeerSupported(pid, local[i]) {
+			contained++
+		}
+	}
+	return float64(contained) / float64(len(local))
+}
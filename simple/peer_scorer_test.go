@@ -0,0 +1,48 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerScorer(t *testing.T) {
+	s := NewPeerScorer(nil)
+	var pid1, pid2, pid3 peer.ID = "pid1", "pid2", "pid3"
+	s.PeerConnected(pid1)
+	s.PeerConnected(pid2)
+	s.PeerConnected(pid3)
+
+	s.RecordLatency(pid1, 10*time.Millisecond)
+	s.RecordLatency(pid2, time.Second)
+	require.True(t, s.Score(pid1) > s.Score(pid2))
+
+	s.RecordTraffic(pid2, 10<<20)
+	s.RecordMisbehaviour(pid3, 10)
+	require.Equal(t, 2, s.LowestScorePeer([]peer.ID{pid1, pid2, pid3}))
+
+	// misbehaviour penalties will be kept after disconnected
+	s.PeerDisconnected(pid3)
+	require.Equal(t, 1, s.LowestScorePeer([]peer.ID{pid1, pid3}))
+	s.PeerDisconnected(pid1)
+	require.Equal(t, s.Score("unknown"), s.Score(pid1))
+
+	// pluggable score input
+	s.SetScoreInput("test", 100, func(pid peer.ID) float64 {
+		if pid == pid3 {
+			return 1
+		}
+		return 0
+	})
+	require.Equal(t, 1, s.LowestScorePeer([]peer.ID{pid3, pid2}))
+	s.RemoveScoreInput("test")
+	require.Equal(t, 0, s.LowestScorePeer([]peer.ID{pid3, pid2}))
+}
@@ -7,8 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package simple
 
 import (
+	"encoding/json"
+	"errors"
+	"sort"
 	"sync"
+	"time"
 
+	"chainmaker.org/chainmaker/common/v2/crypto"
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
 	"chainmaker.org/chainmaker/net-liquid/core/protocol"
 	"chainmaker.org/chainmaker/net-liquid/core/store"
@@ -16,37 +21,147 @@ import (
 	ma "github.com/multiformats/go-multiaddr"
 )
 
+// addrEntry is a net address of peer with its details.
+type addrEntry struct {
+	addr        ma.Multiaddr
+	key         string
+	source      store.AddrSource
+	expires     time.Time // zero value means never expire
+	dialSuccess int
+	dialFailure int
+	lastSuccess time.Time
+	lastFailure time.Time
+}
+
+// expired return whether the address expired at the time given.
+func (e *addrEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && now.After(e.expires)
+}
+
+// dialState return 2 if the last dialing succeeded, 1 if never dialed, 0 if the last dialing failed.
+func (e *addrEntry) dialState() int {
+	switch {
+	case e.dialSuccess == 0 && e.dialFailure == 0:
+		return 1
+	case e.lastSuccess.After(e.lastFailure):
+		return 2
+	default:
+		return 0
+	}
+}
+
+func (e *addrEntry) info() *store.AddrInfo {
+	return &store.AddrInfo{
+		Addr:        e.addr,
+		Source:      e.source,
+		Expires:     e.expires,
+		DialSuccess: e.dialSuccess,
+		DialFailure: e.dialFailure,
+		LastSuccess: e.lastSuccess,
+		LastFailure: e.lastFailure,
+	}
+}
+
+// addrSourcePriority return the trust priority of the source, the bigger the more trusted.
+func addrSourcePriority(source store.AddrSource) int {
+	switch source {
+	case store.AddrSourceConfig:
+		return 4
+	case store.AddrSourceIdentify:
+		return 3
+	case store.AddrSourceDiscovery, store.AddrSourcePeerExchange:
+		return 2
+	case store.AddrSourceUnknown:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// addrExpires return the expiration time of ttl given since now.
+func addrExpires(now time.Time, ttl time.Duration) time.Time {
+	if ttl >= store.ConnectedAddrTTL {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
 type addrList struct {
 	mu sync.RWMutex
-	l  []string
+	l  []*addrEntry
 }
 
 func newAddrList() *addrList {
 	return &addrList{
 		mu: sync.RWMutex{},
-		l:  make([]string, 0),
+		l:  make([]*addrEntry, 0),
 	}
 }
 
-func (s *addrList) Append(addr ...ma.Multiaddr) {
+func (s *addrList) find(key string) *addrEntry {
+	for i := range s.l {
+		if s.l[i].key == key {
+			return s.l[i]
+		}
+	}
+	return nil
+}
+
+func (s *addrList) Save(source store.AddrSource, ttl time.Duration, addr ...ma.Multiaddr) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	for _, ma := range addr {
-		s.l = append(s.l, ma.String())
+	now := time.Now()
+	expires := addrExpires(now, ttl)
+	for _, a := range addr {
+		key := a.String()
+		e := s.find(key)
+		if e == nil || e.expired(now) {
+			if e == nil {
+				e = &addrEntry{addr: a, key: key}
+				s.l = append(s.l, e)
+			}
+			e.source, e.expires = source, expires
+			continue
+		}
+		if !e.expires.IsZero() && (expires.IsZero() || expires.After(e.expires)) {
+			e.expires = expires
+		}
+		if addrSourcePriority(source) > addrSourcePriority(e.source) {
+			e.source = source
+		}
 	}
 }
 
-func (s *addrList) Save(addr ...ma.Multiaddr) {
+func (s *addrList) Reset(addr ...ma.Multiaddr) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	tempM := make(map[string]struct{})
-	for i := range s.l {
-		tempM[s.l[i]] = struct{}{}
+	res := make([]*addrEntry, 0, len(addr))
+	for _, a := range addr {
+		key := a.String()
+		e := s.find(key)
+		if e == nil {
+			e = &addrEntry{addr: a, key: key, source: store.AddrSourceUnknown}
+		}
+		e.expires = time.Time{}
+		res = append(res, e)
+	}
+	s.l = res
+}
+
+func (s *addrList) SetTTL(ttl time.Duration, addr ...ma.Multiaddr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires := addrExpires(time.Now(), ttl)
+	m := make(map[string]struct{}, len(addr))
+	for i := range addr {
+		m[addr[i].String()] = struct{}{}
 	}
-	for _, ma := range addr {
-		addrStr := ma.String()
-		if _, ok := tempM[addrStr]; !ok {
-			s.l = append(s.l, ma.String())
+	for _, e := range s.l {
+		if e.source == store.AddrSourceConfig {
+			continue
+		}
+		if _, ok := m[e.key]; ok || len(addr) == 0 {
+			e.expires = expires
 		}
 	}
 }
@@ -57,34 +172,79 @@ func (s *addrList) Remove(addr ...ma.Multiaddr) {
 	if len(s.l) == 0 {
 		return
 	}
-	res := make([]string, 0)
+	res := make([]*addrEntry, 0)
 	m := make(map[string]struct{})
 	for i := range addr {
 		m[addr[i].String()] = struct{}{}
 	}
 	for i := range s.l {
 		tmp := s.l[i]
-		if _, ok := m[tmp]; !ok {
+		if _, ok := m[tmp.key]; !ok {
 			res = append(res, tmp)
 		}
 	}
 	s.l = res
 }
 
-func (s *addrList) List() []ma.Multiaddr {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	res := make([]ma.Multiaddr, len(s.l))
-	for i, _ := range s.l {
-		res[i] = ma.StringCast(s.l[i])
+func (s *addrList) RecordDialResult(addr ma.Multiaddr, success bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.find(addr.String())
+	if e == nil {
+		return
 	}
-	return res
+	if success {
+		e.dialSuccess++
+		e.lastSuccess = time.Now()
+	} else {
+		e.dialFailure++
+		e.lastFailure = time.Now()
+	}
+}
+
+// GC remove all addresses expired.
+func (s *addrList) GC() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	res := make([]*addrEntry, 0, len(s.l))
+	for i := range s.l {
+		if !s.l[i].expired(now) {
+			res = append(res, s.l[i])
+		}
+	}
+	s.l = res
 }
 
-func (s *addrList) Size() int {
+// Ranked return the details of all addresses not expired, ranked by likelihood of dialing success.
+// The address which the last dialing succeeded ranks first, then the one never dialed, then the one last failed.
+// With the same dialing state, the address from the more trusted source ranks first,
+// then the one with less failures, then the one added earlier.
+func (s *addrList) Ranked() []*store.AddrInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return len(s.l)
+	now := time.Now()
+	entries := make([]*addrEntry, 0, len(s.l))
+	for i := range s.l {
+		if !s.l[i].expired(now) {
+			entries = append(entries, s.l[i])
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.dialState() != b.dialState() {
+			return a.dialState() > b.dialState()
+		}
+		if addrSourcePriority(a.source) != addrSourcePriority(b.source) {
+			return addrSourcePriority(a.source) > addrSourcePriority(b.source)
+		}
+		return a.dialFailure < b.dialFailure
+	})
+	res := make([]*store.AddrInfo, len(entries))
+	for i := range entries {
+		res[i] = entries[i].info()
+	}
+	return res
 }
 
 var _ store.AddrBook = (*addrBook)(nil)
@@ -99,28 +259,44 @@ func newAddrBook() store.AddrBook {
 	return &addrBook{book: sync.Map{}}
 }
 
-// AddAddr append some net addresses of peer.
-func (s *addrBook) AddAddr(pid peer.ID, addr ...ma.Multiaddr) {
+func (s *addrBook) loadOrCreateList(pid peer.ID) *addrList {
 	list, ok := s.book.Load(pid)
 	if !ok {
-		l := newAddrList()
-		list, ok = s.book.LoadOrStore(pid, l)
-		if !ok {
-			list = l
-		}
+		list, _ = s.book.LoadOrStore(pid, newAddrList())
 	}
-	for i := range addr {
-		newAddr := addr[i]
-		list.(*addrList).Save(newAddr)
+	return list.(*addrList)
+}
+
+// AddAddr append some net addresses of peer.
+// The addresses added will be with store.AddrSourceUnknown and store.PermanentAddrTTL.
+func (s *addrBook) AddAddr(pid peer.ID, addr ...ma.Multiaddr) {
+	s.AddAddrWithTTL(pid, store.AddrSourceUnknown, store.PermanentAddrTTL, addr...)
+}
+
+// AddAddrWithTTL append some net addresses of peer learned from the source given, which expire after ttl.
+// If an address exists, its ttl will be extended if the new one is longer,
+// and its source will be replaced if the new one is more trusted.
+func (s *addrBook) AddAddrWithTTL(pid peer.ID, source store.AddrSource, ttl time.Duration, addr ...ma.Multiaddr) {
+	if ttl <= 0 {
+		return
 	}
+	s.loadOrCreateList(pid).Save(source, ttl, addr...)
 }
 
 // SetAddrs record some addresses of peer.
 // This function will clean all addresses that not in list.
+// The addresses in list will never expire, the details of the addresses existing will be kept.
 func (s *addrBook) SetAddrs(pid peer.ID, addrs []ma.Multiaddr) {
-	list := newAddrList()
-	list.Append(addrs...)
-	s.book.Store(pid, list)
+	s.loadOrCreateList(pid).Reset(addrs...)
+}
+
+// SetAddrTTL reset the ttl of some net addresses of peer. If no address given, all addresses of peer will be reset.
+// The ttl of addresses with store.AddrSourceConfig will not be changed.
+func (s *addrBook) SetAddrTTL(pid peer.ID, ttl time.Duration, addr ...ma.Multiaddr) {
+	list, ok := s.book.Load(pid)
+	if ok {
+		list.(*addrList).SetTTL(ttl, addr...)
+	}
 }
 
 // RemoveAddr remove some net addresses of peer.
@@ -131,29 +307,59 @@ func (s *addrBook) RemoveAddr(pid peer.ID, addr ...ma.Multiaddr) {
 	}
 }
 
-// GetFirstAddr return first net address of peer.
+// RecordDialResult record the result of dialing to a net address of peer.
+func (s *addrBook) RecordDialResult(pid peer.ID, addr ma.Multiaddr, success bool) {
+	list, ok := s.book.Load(pid)
+	if ok {
+		list.(*addrList).RecordDialResult(addr, success)
+	}
+}
+
+// GetFirstAddr return the best-ranked net address of peer.
 // If no address stored, return nil.
 func (s *addrBook) GetFirstAddr(pid peer.ID) ma.Multiaddr {
 	var res ma.Multiaddr
 	list, ok := s.book.Load(pid)
 	if ok {
-		tmp := list.(*addrList).List()
+		tmp := list.(*addrList).Ranked()
 		if len(tmp) > 0 {
-			res = tmp[0]
+			res = tmp[0].Addr
 		}
 	}
 	return res
 }
 
-// GetAddrs return all net address of peer.
+// GetAddrs return all net address of peer not expired, ranked by likelihood of dialing success.
 func (s *addrBook) GetAddrs(pid peer.ID) []ma.Multiaddr {
 	list, ok := s.book.Load(pid)
 	if ok {
-		return list.(*addrList).List()
+		infos := list.(*addrList).Ranked()
+		res := make([]ma.Multiaddr, len(infos))
+		for i := range infos {
+			res[i] = infos[i].Addr
+		}
+		return res
+	}
+	return nil
+}
+
+// AddrInfos return the details of all net address of peer not expired, ranked as GetAddrs.
+func (s *addrBook) AddrInfos(pid peer.ID) []*store.AddrInfo {
+	list, ok := s.book.Load(pid)
+	if ok {
+		return list.(*addrList).Ranked()
 	}
 	return nil
 }
 
+// GCAddrs remove all addresses expired.
+func (s *addrBook) GCAddrs() {
+	s.book.Range(func(_, value interface{}) bool {
+		value.(*addrList).GC()
+		return true
+	})
+}
+
 type protocolSet struct {
 	s types.Set
 }
@@ -312,16 +518,322 @@ func newProtocolBook(localPid peer.ID) store.ProtocolBook {
 	}
 }
 
+// LatencyEWMASmoothing is the smoothing factor of the EWMA latency.
+// The bigger the value, the more weight the latest latency measured.
+const LatencyEWMASmoothing = 0.1
+
+var _ store.MetricsBook = (*metricsBook)(nil)
+
+// metricsBook is a simple implementation of store.MetricsBook interface.
+type metricsBook struct {
+	mu      sync.RWMutex
+	latency map[peer.ID]time.Duration
+}
+
+// newMetricsBook create a new *metricsBook instance.
+func newMetricsBook() store.MetricsBook {
+	return &metricsBook{latency: make(map[peer.ID]time.Duration)}
+}
+
+// RecordLatency record a new latency measured of peer, the EWMA latency of peer will be updated.
+func (m *metricsBook) RecordLatency(pid peer.ID, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, ok := m.latency[pid]
+	if !ok {
+		m.latency[pid] = latency
+		return
+	}
+	m.latency[pid] = time.Duration(LatencyEWMASmoothing*float64(latency) + (1-LatencyEWMASmoothing)*float64(old))
+}
+
+// LatencyEWMA return the exponentially-weighted moving average latency of peer.
+// If no latency recorded, return 0.
+func (m *metricsBook) LatencyEWMA(pid peer.ID) time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.latency[pid]
+}
+
+// RemovePeerMetrics remove all metrics records of peer.
+func (m *metricsBook) RemovePeerMetrics(pid peer.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.latency, pid)
+}
+
+var _ store.IdentifyBook = (*identifyBook)(nil)
+
+// identifyBook is a simple implementation of store.IdentifyBook interface.
+type identifyBook struct {
+	mu           sync.RWMutex
+	pubKeys      map[peer.ID]crypto.PublicKey
+	agentVersion map[peer.ID]string
+}
+
+// newIdentifyBook create a new *identifyBook instance.
+func newIdentifyBook() store.IdentifyBook {
+	return &identifyBook{
+		pubKeys:      make(map[peer.ID]crypto.PublicKey),
+		agentVersion: make(map[peer.ID]string),
+	}
+}
+
+// SetPubKey record the public key of peer.
+func (i *identifyBook) SetPubKey(pid peer.ID, pubKey crypto.PublicKey) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.pubKeys[pid] = pubKey
+}
+
+// PubKey return the public key of peer. If no public key recorded, return nil.
+func (i *identifyBook) PubKey(pid peer.ID) crypto.PublicKey {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.pubKeys[pid]
+}
+
+// SetAgentVersion record the agent version string of peer.
+func (i *identifyBook) SetAgentVersion(pid peer.ID, agentVersion string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.agentVersion[pid] = agentVersion
+}
+
+// AgentVersion return the agent version string of peer. If nothing recorded, return "".
+func (i *identifyBook) AgentVersion(pid peer.ID) string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.agentVersion[pid]
+}
+
+// RemovePeerIdentify remove all identify records of peer.
+func (i *identifyBook) RemovePeerIdentify(pid peer.ID) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.pubKeys, pid)
+	delete(i.agentVersion, pid)
+}
+
+var (
+	// ErrMetadataKeyNotRegistered will be returned if the metadata key is not registered with a codec.
+	ErrMetadataKeyNotRegistered = errors.New("metadata key is not registered")
+	// ErrMetadataValueType will be returned if the metadata value is not of the type supported by the codec.
+	ErrMetadataValueType = errors.New("wrong type of metadata value")
+)
+
+var _ store.MetadataCodec = BytesMetadataCodec{}
+
+// BytesMetadataCodec is a store.MetadataCodec for the metadata values of []byte.
+type BytesMetadataCodec struct{}
+
+// Encode return a copy of the bytes given.
+func (BytesMetadataCodec) Encode(val interface{}) ([]byte, error) {
+	bytes, ok := val.([]byte)
+	if !ok {
+		return nil, ErrMetadataValueType
+	}
+	return append([]byte(nil), bytes...), nil
+}
+
+// Decode return a copy of the bytes given.
+func (BytesMetadataCodec) Decode(data []byte) (interface{}, error) {
+	return append([]byte(nil), data...), nil
+}
+
+var _ store.MetadataCodec = StringsMetadataCodec{}
+
+// StringsMetadataCodec is a store.MetadataCodec for the metadata values of []string.
+type StringsMetadataCodec struct{}
+
+// Encode return the json bytes of the strings given.
+func (StringsMetadataCodec) Encode(val interface{}) ([]byte, error) {
+	strs, ok := val.([]string)
+	if !ok {
+		return nil, ErrMetadataValueType
+	}
+	return json.Marshal(strs)
+}
+
+// Decode return the strings decoded from the json bytes given.
+func (StringsMetadataCodec) Decode(data []byte) (interface{}, error) {
+	strs := make([]string, 0)
+	if err := json.Unmarshal(data, &strs); err != nil {
+		return nil, err
+	}
+	return strs, nil
+}
+
+var _ store.MetadataBook = (*metadataBook)(nil)
+
+// metadataBook is a simple implementation of store.MetadataBook interface.
+// The values are stored encoded by the codecs of their keys.
+type metadataBook struct {
+	mu     sync.RWMutex
+	codecs map[string]store.MetadataCodec
+	meta   map[peer.ID]map[string][]byte
+}
+
+// newMetadataBook create a new *metadataBook instance.
+func newMetadataBook() store.MetadataBook {
+	return &metadataBook{
+		codecs: make(map[string]store.MetadataCodec),
+		meta:   make(map[peer.ID]map[string][]byte),
+	}
+}
+
+// RegisterMetadataKey register a metadata key with the codec of its values.
+// If the key has been registered, the codec will be replaced.
+func (m *metadataBook) RegisterMetadataKey(key string, codec store.MetadataCodec) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codecs[key] = codec
+}
+
+// Put store a metadata value of peer with the key given. If the key exists, the value will be replaced.
+// An error will be returned if the key is not registered or the value could not be encoded by its codec.
+func (m *metadataBook) Put(pid peer.ID, key string, val interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	codec, ok := m.codecs[key]
+	if !ok {
+		return ErrMetadataKeyNotRegistered
+	}
+	data, err := codec.Encode(val)
+	if err != nil {
+		return err
+	}
+	kv, ok := m.meta[pid]
+	if !ok {
+		kv = make(map[string][]byte)
+		m.meta[pid] = kv
+	}
+	kv[key] = data
+	return nil
+}
+
+// decode return the value decoded by the codec of the key, and whether it succeeded.
+// This method should be called with the lock held.
+func (m *metadataBook) decode(key string, data []byte) (interface{}, bool) {
+	codec, ok := m.codecs[key]
+	if !ok {
+		return nil, false
+	}
+	val, err := codec.Decode(data)
+	if err != nil {
+		return nil, false
+	}
+	return val, true
+}
+
+// Get return the metadata value of peer with the key given decoded by its codec, and whether it exists.
+func (m *metadataBook) Get(pid peer.ID, key string) (interface{}, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.meta[pid][key]
+	if !ok {
+		return nil, false
+	}
+	return m.decode(key, data)
+}
+
+// Delete remove the metadata value of peer with the key given.
+func (m *metadataBook) Delete(pid peer.ID, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kv, ok := m.meta[pid]
+	if !ok {
+		return
+	}
+	delete(kv, key)
+	if len(kv) == 0 {
+		delete(m.meta, pid)
+	}
+}
+
+// ListKeys return all metadata keys of peer.
+func (m *metadataBook) ListKeys(pid peer.ID) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	kv := m.meta[pid]
+	res := make([]string, 0, len(kv))
+	for key := range kv {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// PeersWithKey return the metadata values with the key given of all peers who have it.
+func (m *metadataBook) PeersWithKey(key string) map[peer.ID]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	res := make(map[peer.ID]interface{})
+	for pid, kv := range m.meta {
+		data, ok := kv[key]
+		if !ok {
+			continue
+		}
+		if val, ok := m.decode(key, data); ok {
+			res[pid] = val
+		}
+	}
+	return res
+}
+
+// RemovePeerMetadata remove all metadata of peer.
+func (m *metadataBook) RemovePeerMetadata(pid peer.ID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.meta, pid)
+}
+
 var _ store.PeerStore = (*SimplePeerStore)(nil)
 
 // SimplePeerStore is a simple implementation of store.PeerStore interface.
-// It wrapped with a *protocolBook and a *addrBook.
+// It wrapped with a *protocolBook, a *addrBook, a *metricsBook, a *identifyBook and a *metadataBook.
 type SimplePeerStore struct {
 	store.ProtocolBook
 	store.AddrBook
+	store.MetricsBook
+	store.IdentifyBook
+	store.MetadataBook
+
+	hookMu sync.RWMutex
+	hooks  []store.PeerForgottenHook
 }
 
 // NewSimplePeerStore create a simple store.PeerStore instance.
 func NewSimplePeerStore(localPid peer.ID) store.PeerStore {
-	return &SimplePeerStore{ProtocolBook: newProtocolBook(localPid), AddrBook: newAddrBook()}
+	return &SimplePeerStore{
+		ProtocolBook: newProtocolBook(localPid),
+		AddrBook:     newAddrBook(),
+		MetricsBook:  newMetricsBook(),
+		IdentifyBook: newIdentifyBook(),
+		MetadataBook: newMetadataBook(),
+	}
+}
+
+// ForgetPeer remove all records of peer in all books,
+// then call all the hooks registered with OnPeerForgotten.
+func (s *SimplePeerStore) ForgetPeer(pid peer.ID) {
+	s.SetAddrs(pid, nil)
+	s.ClearProtocol(pid)
+	s.RemovePeerMetrics(pid)
+	s.RemovePeerIdentify(pid)
+	s.RemovePeerMetadata(pid)
+	s.hookMu.RLock()
+	hooks := make([]store.PeerForgottenHook, len(s.hooks))
+	copy(hooks, s.hooks)
+	s.hookMu.RUnlock()
+	for i := range hooks {
+		hooks[i](pid)
+	}
+}
+
+// OnPeerForgotten register a hook that will be called when a peer forgotten.
+func (s *SimplePeerStore) OnPeerForgotten(hook store.PeerForgottenHook) {
+	s.hookMu.Lock()
+	defer s.hookMu.Unlock()
+	s.hooks = append(s.hooks, hook)
 }
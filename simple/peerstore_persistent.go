@@ -0,0 +1,519 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// DefaultPeerStoreRetention is the default duration that a peer record will be kept since last seen or updated.
+	DefaultPeerStoreRetention = 7 * 24 * time.Hour
+
+	// peerStoreCompactMin is the min count of records appended that will trigger a compaction.
+	peerStoreCompactMin = 1024
+	// peerStoreCompactFactor decides a compaction will be triggered
+	// when the count of records appended is greater than factor * count of peers.
+	peerStoreCompactFactor = 4
+)
+
+// addrRecord is a net address of peer persisted with its source and expiration time.
+type addrRecord struct {
+	Addr    string           `json:"addr"`
+	Source  store.AddrSource `json:"source,omitempty"`
+	Expires int64            `json:"expires,omitempty"` // unix nano, 0 means never expire
+}
+
+// peerRecord is the record of a peer persisted in the append log file.
+// The last record of a peer in the file wins.
+type peerRecord struct {
+	Pid       string        `json:"pid"`
+	Forget    bool          `json:"forget,omitempty"`
+	Addrs     []*addrRecord `json:"addrs,omitempty"`
+	Protocols []string      `json:"protocols,omitempty"`
+	Latency   int64         `json:"latency,omitempty"`
+	LastSeen  int64         `json:"last_seen,omitempty"`
+	Updated   int64         `json:"updated"`
+}
+
+func (r *peerRecord) expired(retention time.Duration, now time.Time) bool {
+	last := r.Updated
+	if r.LastSeen > last {
+		last = r.LastSeen
+	}
+	return now.Sub(time.Unix(0, last)) > retention
+}
+
+// addrRecords return the records of the addresses given which could be persisted.
+// The remote addresses of inbound connections will be skipped, for their ports are ephemeral.
+func addrRecords(infos []*store.AddrInfo) []*addrRecord {
+	res := make([]*addrRecord, 0, len(infos))
+	for _, info := range infos {
+		if info.Source == store.AddrSourceInbound {
+			continue
+		}
+		r := &addrRecord{Addr: info.Addr.String(), Source: info.Source}
+		if !info.Expires.IsZero() {
+			r.Expires = info.Expires.UnixNano()
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+func sameAddrRecords(a, b []*addrRecord) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(l []string, s string) bool {
+	for i := range l {
+		if l[i] == s {
+			return true
+		}
+	}
+	return false
+}
+
+var _ store.PeerStore = (*PersistentPeerStore)(nil)
+
+// PersistentPeerStore is an implementation of store.PeerStore interface persisted to a local append log file.
+// The net addresses with their sources and expiration time, the protocols supported, the latency
+// and the last seen time of peers will be persisted. The remote addresses of inbound connections will not be persisted.
+// Changes of addresses (including SetAddrTTL, RemoveAddr and GCAddrs) reach the file, while the other records
+// removed on disconnection (ClearProtocol, RemovePeerMetrics and RemovePeerIdentify) only affect the view in memory,
+// the persisted record keeps the last known values until it expired by retention policy or forgotten.
+// When loading, the addresses and the latency persisted will be restored into the store in memory,
+// and the protocols persisted could be queried with KnownProtocols method.
+// The time while the store closed is not counted in the ttl of addresses restored.
+type PersistentPeerStore struct {
+	store.PeerStore
+
+	mu        sync.Mutex
+	path      string
+	retention time.Duration
+	file      *os.File
+	records   map[peer.ID]*peerRecord
+	appended  int
+
+	logger api.Logger
+}
+
+// NewPersistentPeerStore create a new *PersistentPeerStore instance with the append log file path given.
+// Records that have not been seen or updated within retention will be dropped.
+// If retention is not greater than 0, DefaultPeerStoreRetention will be used.
+func NewPersistentPeerStore(localPid peer.ID, path string, retention time.Duration,
+	logger api.Logger) (*PersistentPeerStore, error) {
+	if retention <= 0 {
+		retention = DefaultPeerStoreRetention
+	}
+	s := &PersistentPeerStore{
+		PeerStore: NewSimplePeerStore(localPid),
+		path:      path,
+		retention: retention,
+		records:   make(map[peer.ID]*peerRecord),
+		logger:    logger,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.Compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load replay the append log file and restore the records into memory.
+func (s *PersistentPeerStore) load() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		r := &peerRecord{}
+		if e := json.Unmarshal(scanner.Bytes(), r); e != nil {
+			// the last line may be broken if process crashed when writing, skip it
+			s.logger.Warnf("[PersistentPeerStore] skip broken record, %s", e.Error())
+			continue
+		}
+		if r.Forget {
+			delete(s.records, peer.ID(r.Pid))
+			continue
+		}
+		s.records[peer.ID(r.Pid)] = r
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	// the file was modified last when the store closed, the addresses will expire as if no time passed since then
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	closedAt := info.ModTime()
+	now := time.Now()
+	for pid, r := range s.records {
+		if r.expired(s.retention, now) {
+			delete(s.records, pid)
+			continue
+		}
+		s.restoreAddrs(pid, r, closedAt)
+		if r.Latency > 0 {
+			s.PeerStore.RecordLatency(pid, time.Duration(r.Latency))
+		}
+	}
+	return nil
+}
+
+// restoreAddrs add the addresses persisted into the store in memory.
+// The addresses never expire except the ones configured were valid only while peer connected,
+// so they will expire after store.RecentlyConnectedAddrTTL.
+func (s *PersistentPeerStore) restoreAddrs(pid peer.ID, r *peerRecord, closedAt time.Time) {
+	for _, a := range r.Addrs {
+		addr, err := ma.NewMultiaddr(a.Addr)
+		if err != nil {
+			continue
+		}
+		ttl := store.PermanentAddrTTL
+		switch {
+		case a.Expires != 0:
+			ttl = time.Unix(0, a.Expires).Sub(closedAt)
+			if ttl <= 0 {
+				continue
+			}
+		case a.Source != store.AddrSourceConfig && a.Source != store.AddrSourceUnknown:
+			ttl = store.RecentlyConnectedAddrTTL
+		}
+		s.PeerStore.AddAddrWithTTL(pid, a.Source, ttl, addr)
+	}
+	// expiration time restored differs from the one persisted, persist the view in memory when compacting
+	r.Addrs = addrRecords(s.PeerStore.AddrInfos(pid))
+}
+
+// Compact rewrite the append log file with one record for each peer and drop the records expired.
+func (s *PersistentPeerStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.compact()
+}
+
+func (s *PersistentPeerStore) compact() error {
+	now := time.Now()
+	tmpPath := s.path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, r := range s.records {
+		// the records expired will be forgotten by GCAddrs, just drop them from the file
+		if r.expired(s.retention, now) {
+			continue
+		}
+		bytes, e := json.Marshal(r)
+		if e != nil {
+			_ = f.Close()
+			return e
+		}
+		_, _ = w.Write(bytes)
+		_ = w.WriteByte('\n')
+	}
+	if err = w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if s.file != nil {
+		_ = s.file.Close()
+		s.file = nil
+	}
+	if err = os.Rename(tmpPath, s.path); err != nil {
+		return err
+	}
+	s.file, err = os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	s.appended = 0
+	return nil
+}
+
+// Close compact the append log file and close it.
+func (s *PersistentPeerStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.compact(); err != nil {
+		return err
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// update modify the record of peer with the function given and append it to the log file.
+func (s *PersistentPeerStore) update(pid peer.ID, f func(r *peerRecord)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[pid]
+	if !ok {
+		r = &peerRecord{Pid: pid.ToString()}
+		s.records[pid] = r
+	}
+	f(r)
+	r.Updated = time.Now().UnixNano()
+	s.appendRecord(r)
+}
+
+// syncAddrs persist the addresses of peer in memory if changed.
+// A record will not be created for the peer who has no address could be persisted.
+// If touch is false, the updated time of record will be kept, e.g. when addresses expired.
+func (s *PersistentPeerStore) syncAddrs(pid peer.ID, touch bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	addrs := addrRecords(s.PeerStore.AddrInfos(pid))
+	r, ok := s.records[pid]
+	if !ok {
+		if len(addrs) == 0 {
+			return
+		}
+		r = &peerRecord{Pid: pid.ToString()}
+		s.records[pid] = r
+	} else if sameAddrRecords(r.Addrs, addrs) {
+		return
+	}
+	r.Addrs = addrs
+	if touch || r.Updated == 0 {
+		r.Updated = time.Now().UnixNano()
+	}
+	s.appendRecord(r)
+}
+
+func (s *PersistentPeerStore) appendRecord(r *peerRecord) {
+	if s.file == nil {
+		return
+	}
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		s.logger.Errorf("[PersistentPeerStore] marshal record failed, %s", err.Error())
+		return
+	}
+	if _, err = s.file.Write(append(bytes, '\n')); err != nil {
+		s.logger.Errorf("[PersistentPeerStore] append record failed, %s", err.Error())
+		return
+	}
+	s.appended++
+	if s.appended > peerStoreCompactMin && s.appended > peerStoreCompactFactor*len(s.records) {
+		if err = s.compact(); err != nil {
+			s.logger.Errorf("[PersistentPeerStore] compact failed, %s", err.Error())
+		}
+	}
+}
+
+// AddAddr append some net addresses of peer.
+func (s *PersistentPeerStore) AddAddr(pid peer.ID, addr ...ma.Multiaddr) {
+	s.PeerStore.AddAddr(pid, addr...)
+	s.syncAddrs(pid, true)
+}
+
+// AddAddrWithTTL append some net addresses of peer learned from the source given, which expire after ttl.
+func (s *PersistentPeerStore) AddAddrWithTTL(pid peer.ID, source store.AddrSource, ttl time.Duration,
+	addr ...ma.Multiaddr) {
+	s.PeerStore.AddAddrWithTTL(pid, source, ttl, addr...)
+	s.syncAddrs(pid, true)
+}
+
+// SetAddrs record some addresses of peer.
+// This function will clean all addresses that not in list.
+func (s *PersistentPeerStore) SetAddrs(pid peer.ID, addrs []ma.Multiaddr) {
+	s.PeerStore.SetAddrs(pid, addrs)
+	s.syncAddrs(pid, true)
+}
+
+// SetAddrTTL reset the ttl of some net addresses of peer. If no address given, all addresses of peer will be reset.
+func (s *PersistentPeerStore) SetAddrTTL(pid peer.ID, ttl time.Duration, addr ...ma.Multiaddr) {
+	s.PeerStore.SetAddrTTL(pid, ttl, addr...)
+	s.syncAddrs(pid, true)
+}
+
+// RemoveAddr remove some net addresses of peer.
+func (s *PersistentPeerStore) RemoveAddr(pid peer.ID, addr ...ma.Multiaddr) {
+	s.PeerStore.RemoveAddr(pid, addr...)
+	s.syncAddrs(pid, true)
+}
+
+// GCAddrs remove all addresses expired,
+// and forget the peers whose records have not been seen or updated within retention.
+func (s *PersistentPeerStore) GCAddrs() {
+	for _, pid := range s.expiredPeers() {
+		s.ForgetPeer(pid)
+	}
+	s.PeerStore.GCAddrs()
+	s.mu.Lock()
+	pids := make([]peer.ID, 0, len(s.records))
+	for pid := range s.records {
+		pids = append(pids, pid)
+	}
+	s.mu.Unlock()
+	for _, pid := range pids {
+		s.syncAddrs(pid, false)
+	}
+}
+
+// expiredPeers return the list of peers whose records expired by retention policy.
+func (s *PersistentPeerStore) expiredPeers() []peer.ID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	res := make([]peer.ID, 0)
+	for pid, r := range s.records {
+		if r.expired(s.retention, now) {
+			res = append(res, pid)
+		}
+	}
+	return res
+}
+
+// AddProtocol append some protocols supported by peer.
+func (s *PersistentPeerStore) AddProtocol(pid peer.ID, protocols ...protocol.ID) {
+	s.PeerStore.AddProtocol(pid, protocols...)
+	s.update(pid, func(r *peerRecord) {
+		for _, p := range protocol.ParseIDsToStrings(protocols) {
+			if !containsString(r.Protocols, p) {
+				r.Protocols = append(r.Protocols, p)
+			}
+		}
+	})
+}
+
+// SetProtocols record some protocols supported by peer.
+// This function will clean all protocols that not in list.
+func (s *PersistentPeerStore) SetProtocols(pid peer.ID, protocols []protocol.ID) {
+	s.PeerStore.SetProtocols(pid, protocols)
+	s.update(pid, func(r *peerRecord) {
+		r.Protocols = protocol.ParseIDsToStrings(protocols)
+	})
+}
+
+// DeleteProtocol remove some protocols of peer.
+func (s *PersistentPeerStore) DeleteProtocol(pid peer.ID, protocols ...protocol.ID) {
+	s.PeerStore.DeleteProtocol(pid, protocols...)
+	s.update(pid, func(r *peerRecord) {
+		deleted := protocol.ParseIDsToStrings(protocols)
+		res := make([]string, 0, len(r.Protocols))
+		for i := range r.Protocols {
+			if !containsString(deleted, r.Protocols[i]) {
+				res = append(res, r.Protocols[i])
+			}
+		}
+		r.Protocols = res
+	})
+}
+
+// RecordLatency record a new latency measured of peer, the EWMA latency of peer will be updated.
+func (s *PersistentPeerStore) RecordLatency(pid peer.ID, latency time.Duration) {
+	s.PeerStore.RecordLatency(pid, latency)
+	ewma := s.PeerStore.LatencyEWMA(pid)
+	s.update(pid, func(r *peerRecord) {
+		r.Latency = int64(ewma)
+	})
+}
+
+// MarkSeen update the last seen time of peer to now.
+// It should be called when the peer connected or disconnected.
+func (s *PersistentPeerStore) MarkSeen(pid peer.ID) {
+	s.update(pid, func(r *peerRecord) {
+		r.LastSeen = time.Now().UnixNano()
+	})
+}
+
+// LastSeen return the last seen time of peer. If the peer has never been seen, return zero time.
+func (s *PersistentPeerStore) LastSeen(pid peer.ID) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[pid]
+	if !ok || r.LastSeen == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, r.LastSeen)
+}
+
+// KnownProtocols return the protocols supported by peer persisted.
+func (s *PersistentPeerStore) KnownProtocols(pid peer.ID) []protocol.ID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[pid]
+	if !ok {
+		return nil
+	}
+	return protocol.ParseStringsToIDs(r.Protocols)
+}
+
+// RecentPeers return the list of peers that have been seen and have addresses persisted,
+// ordered by the last seen time from the latest. At most max peers will be returned.
+func (s *PersistentPeerStore) RecentPeers(max int) []peer.ID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	records := make([]*peerRecord, 0, len(s.records))
+	for _, r := range s.records {
+		if r.LastSeen > 0 && len(r.Addrs) > 0 {
+			records = append(records, r)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].LastSeen > records[j].LastSeen
+	})
+	if max < len(records) {
+		records = records[:max]
+	}
+	res := make([]peer.ID, 0, len(records))
+	for i := range records {
+		res = append(res, peer.ID(records[i].Pid))
+	}
+	return res
+}
+
+// ForgetPeer remove all records of peer in memory and the persisted record,
+// then call all the hooks registered with OnPeerForgotten.
+func (s *PersistentPeerStore) ForgetPeer(pid peer.ID) {
+	s.mu.Lock()
+	if _, ok := s.records[pid]; ok {
+		delete(s.records, pid)
+		s.appendRecord(&peerRecord{Pid: pid.ToString(), Forget: true})
+	}
+	s.mu.Unlock()
+	s.PeerStore.ForgetPeer(pid)
+}
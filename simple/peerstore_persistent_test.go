@@ -0,0 +1,138 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
+	"chainmaker.org/chainmaker/net-liquid/logger"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistentPeerStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peerstore", "peers.log")
+	var pid1, pid2, pid3 peer.ID = "pid1", "pid2", "pid3"
+	addr1 := ma.StringCast("/ip4/127.0.0.1/tcp/8081")
+	addr2 := ma.StringCast("/ip4/127.0.0.1/tcp/8082")
+	var p1, p2 protocol.ID = "/p1", "/p2"
+
+	s, err := NewPersistentPeerStore("local", path, time.Hour, logger.NilLogger)
+	require.Nil(t, err)
+	s.AddAddr(pid1, addr1, addr2)
+	s.SetProtocols(pid1, []protocol.ID{p1, p2})
+	s.RecordLatency(pid1, 10*time.Millisecond)
+	s.MarkSeen(pid1)
+	s.AddAddr(pid2, addr2)
+	s.MarkSeen(pid2)
+	s.AddAddr(pid3, addr2)
+	s.ForgetPeer(pid3)
+
+	// addresses removed will be persisted, while other records removed on disconnection will be kept in file
+	s.RemoveAddr(pid1, addr1)
+	s.ClearProtocol(pid1)
+	s.RemovePeerMetrics(pid1)
+	require.Nil(t, s.Close())
+
+	s, err = NewPersistentPeerStore("local", path, time.Hour, logger.NilLogger)
+	require.Nil(t, err)
+	require.Equal(t, []ma.Multiaddr{addr2}, s.GetAddrs(pid1))
+	require.Equal(t, 10*time.Millisecond, s.LatencyEWMA(pid1))
+	require.ElementsMatch(t, []protocol.ID{p1, p2}, s.KnownProtocols(pid1))
+	require.False(t, s.LastSeen(pid1).IsZero())
+	require.Equal(t, []peer.ID{pid2, pid1}, s.RecentPeers(10))
+	require.Equal(t, []peer.ID{pid2}, s.RecentPeers(1))
+	require.Nil(t, s.GetAddrs(pid3))
+	require.Nil(t, s.Close())
+
+	// records expired will be dropped
+	time.Sleep(10 * time.Millisecond)
+	s, err = NewPersistentPeerStore("local", path, time.Millisecond, logger.NilLogger)
+	require.Nil(t, err)
+	require.Nil(t, s.GetAddrs(pid1))
+	require.Empty(t, s.RecentPeers(10))
+	require.Nil(t, s.Close())
+}
+
+func TestPersistentPeerStoreAddrs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peers.log")
+	var pid1, pid2 peer.ID = "pid1", "pid2"
+	configAddr := ma.StringCast("/ip4/127.0.0.1/tcp/8081")
+	identifyAddr := ma.StringCast("/ip4/127.0.0.1/tcp/8082")
+	discoveryAddr := ma.StringCast("/ip4/127.0.0.1/tcp/8083")
+	inboundAddr := ma.StringCast("/ip4/127.0.0.1/tcp/50001")
+	expiredAddr := ma.StringCast("/ip4/127.0.0.1/tcp/8084")
+
+	s, err := NewPersistentPeerStore("local", path, time.Hour, logger.NilLogger)
+	require.Nil(t, err)
+	s.AddAddrWithTTL(pid1, store.AddrSourceConfig, store.PermanentAddrTTL, configAddr)
+	s.AddAddrWithTTL(pid1, store.AddrSourceIdentify, store.ConnectedAddrTTL, identifyAddr)
+	s.AddAddrWithTTL(pid1, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, discoveryAddr)
+	s.AddAddrWithTTL(pid1, store.AddrSourceInbound, store.ConnectedAddrTTL, inboundAddr)
+	s.AddAddrWithTTL(pid1, store.AddrSourceDiscovery, time.Millisecond, expiredAddr)
+	s.AddAddrWithTTL(pid2, store.AddrSourceInbound, store.ConnectedAddrTTL, inboundAddr)
+	time.Sleep(10 * time.Millisecond)
+	s.GCAddrs()
+	// peer disconnected
+	s.SetAddrTTL(pid1, store.RecentlyConnectedAddrTTL)
+	require.Nil(t, s.Close())
+
+	s, err = NewPersistentPeerStore("local", path, time.Hour, logger.NilLogger)
+	require.Nil(t, err)
+	infos := s.AddrInfos(pid1)
+	require.Equal(t, 3, len(infos))
+	sources := make(map[string]store.AddrSource)
+	for _, info := range infos {
+		sources[info.Addr.String()] = info.Source
+		if info.Source == store.AddrSourceConfig {
+			require.True(t, info.Expires.IsZero())
+			continue
+		}
+		require.False(t, info.Expires.IsZero())
+		// the time while store closed not counted
+		require.InDelta(t, store.RecentlyConnectedAddrTTL, time.Until(info.Expires), float64(time.Second))
+	}
+	require.Equal(t, map[string]store.AddrSource{
+		configAddr.String():    store.AddrSourceConfig,
+		identifyAddr.String():  store.AddrSourceIdentify,
+		discoveryAddr.String(): store.AddrSourceDiscovery,
+	}, sources)
+	// peer known only by inbound address not persisted
+	require.Empty(t, s.GetAddrs(pid2))
+	require.Empty(t, s.LastSeen(pid2))
+	require.Nil(t, s.Close())
+}
+
+func TestPersistentPeerStoreForgetExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peers.log")
+	var pid peer.ID = "pid1"
+	s, err := NewPersistentPeerStore("local", path, 10*time.Millisecond, logger.NilLogger)
+	require.Nil(t, err)
+	forgotten := make([]peer.ID, 0)
+	s.OnPeerForgotten(func(pid peer.ID) {
+		forgotten = append(forgotten, pid)
+	})
+	s.AddAddr(pid, ma.StringCast("/ip4/127.0.0.1/tcp/8081"))
+	s.RegisterMetadataKey("cert", BytesMetadataCodec{})
+	require.Nil(t, s.Put(pid, "cert", []byte("cert")))
+	s.GCAddrs()
+	require.Empty(t, forgotten)
+
+	// peer forgotten when its record expired by retention policy
+	time.Sleep(20 * time.Millisecond)
+	s.GCAddrs()
+	require.Equal(t, []peer.ID{pid}, forgotten)
+	require.Empty(t, s.GetAddrs(pid))
+	require.Empty(t, s.ListKeys(pid))
+	require.Empty(t, s.RecentPeers(10))
+	require.Nil(t, s.Close())
+}
@@ -8,9 +8,11 @@ package simple
 
 import (
 	"testing"
+	"time"
 
 	"chainmaker.org/chainmaker/net-liquid/core/peer"
 	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/store"
 	ma "github.com/multiformats/go-multiaddr"
 	"github.com/stretchr/testify/require"
 )
@@ -59,3 +61,86 @@ func TestSimplePeerStore(t *testing.T) {
 	require.False(t, ps.ContainsProtocol(pid, proto2))
 	require.False(t, ps.ContainsProtocol(pid, proto3))
 }
+
+func TestSimplePeerStoreMetrics(t *testing.T) {
+	ps := NewSimplePeerStore("QmeyNRs2DwWjcHTpcVHoUSaDAAif4VQZ2wQDQAUNDP33gH")
+	var pid peer.ID = "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4"
+	require.Equal(t, time.Duration(0), ps.LatencyEWMA(pid))
+	ps.RecordLatency(pid, 100*time.Millisecond)
+	require.Equal(t, 100*time.Millisecond, ps.LatencyEWMA(pid))
+	ps.RecordLatency(pid, 200*time.Millisecond)
+	require.Equal(t, 110*time.Millisecond, ps.LatencyEWMA(pid))
+	ps.RemovePeerMetrics(pid)
+	require.Equal(t, time.Duration(0), ps.LatencyEWMA(pid))
+}
+
+func TestSimplePeerStoreAddrRanking(t *testing.T) {
+	ps := NewSimplePeerStore("QmeyNRs2DwWjcHTpcVHoUSaDAAif4VQZ2wQDQAUNDP33gH")
+	var pid peer.ID = "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4"
+	inbound := ma.StringCast("/ip4/127.0.0.1/tcp/53124")
+	found := ma.StringCast("/ip4/192.168.1.2/tcp/8080")
+	listen := ma.StringCast("/ip4/127.0.0.1/tcp/8080")
+	configured := ma.StringCast("/ip4/10.0.0.1/tcp/8080")
+
+	ps.AddAddrWithTTL(pid, store.AddrSourceInbound, store.ConnectedAddrTTL, inbound)
+	ps.AddAddrWithTTL(pid, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, found)
+	ps.AddAddrWithTTL(pid, store.AddrSourceIdentify, store.ConnectedAddrTTL, listen)
+	require.Equal(t, []ma.Multiaddr{listen, found, inbound}, ps.GetAddrs(pid))
+
+	// more trusted source replaces the old one
+	ps.AddAddrWithTTL(pid, store.AddrSourceConfig, store.PermanentAddrTTL, configured)
+	ps.AddAddrWithTTL(pid, store.AddrSourceDiscovery, store.DiscoveryAddrTTL, configured)
+	require.Equal(t, configured, ps.GetFirstAddr(pid))
+	require.Equal(t, store.AddrSourceConfig, ps.AddrInfos(pid)[0].Source)
+
+	// dialing history decides first
+	ps.RecordDialResult(pid, configured, false)
+	ps.RecordDialResult(pid, found, true)
+	require.Equal(t, []ma.Multiaddr{found, listen, inbound, configured}, ps.GetAddrs(pid))
+	info := ps.AddrInfos(pid)[0]
+	require.Equal(t, 1, info.DialSuccess)
+	require.False(t, info.LastSuccess.IsZero())
+
+	// expired addresses will not be returned and will be collected
+	ps.SetAddrTTL(pid, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	require.Equal(t, []ma.Multiaddr{configured}, ps.GetAddrs(pid))
+	ps.GCAddrs()
+	require.Len(t, ps.AddrInfos(pid), 1)
+
+	ps.AddAddrWithTTL(pid, store.AddrSourceDiscovery, 0, found)
+	require.Len(t, ps.GetAddrs(pid), 1)
+}
+
+func TestSimplePeerStoreMetadata(t *testing.T) {
+	ps := NewSimplePeerStore("QmeyNRs2DwWjcHTpcVHoUSaDAAif4VQZ2wQDQAUNDP33gH")
+	var pid peer.ID = "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4"
+	require.Equal(t, ErrMetadataKeyNotRegistered, ps.Put(pid, "cert", []byte("cert")))
+	ps.RegisterMetadataKey("cert", BytesMetadataCodec{})
+	ps.RegisterMetadataKey("chain-ids", StringsMetadataCodec{})
+	require.Equal(t, ErrMetadataValueType, ps.Put(pid, "cert", "cert"))
+	require.Nil(t, ps.Put(pid, "chain-ids", []string{"chain1", "chain2"}))
+	require.Nil(t, ps.Put(pid, "cert", []byte("cert")))
+	val, ok := ps.Get(pid, "chain-ids")
+	require.True(t, ok)
+	require.Equal(t, []string{"chain1", "chain2"}, val)
+	require.Equal(t, []string{"cert", "chain-ids"}, ps.ListKeys(pid))
+	require.Equal(t, map[peer.ID]interface{}{pid: []byte("cert")}, ps.PeersWithKey("cert"))
+	ps.Delete(pid, "cert")
+	_, ok = ps.Get(pid, "cert")
+	require.False(t, ok)
+	require.Empty(t, ps.PeersWithKey("cert"))
+
+	// all records will be removed and hooks will be called when peer forgotten
+	forgotten := make([]peer.ID, 0)
+	ps.OnPeerForgotten(func(pid peer.ID) {
+		forgotten = append(forgotten, pid)
+	})
+	ps.AddAddr(pid, ma.StringCast("/ip4/127.0.0.1/tcp/8080"))
+	ps.SetAgentVersion(pid, "test")
+	ps.ForgetPeer(pid)
+	require.Equal(t, []peer.ID{pid}, forgotten)
+	require.Empty(t, ps.ListKeys(pid))
+	require.Empty(t, ps.GetAddrs(pid))
+	require.Equal(t, "", ps.AgentVersion(pid))
+}
@@ -0,0 +1,244 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"chainmaker.org/chainmaker/net-liquid/core/handler"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/network"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/simple/pb"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// PingProtocolID is the protocol.ID for ping service.
+	PingProtocolID protocol.ID = "/ping/v0.0.1"
+	// DefaultPingInterval is the default interval of the background prober pinging all peers.
+	DefaultPingInterval = 15 * time.Second
+	// DefaultPingTimeout is the default timeout of each ping sent by the background prober.
+	DefaultPingTimeout = 10 * time.Second
+
+	pingPayloadSize = 32
+)
+
+var (
+	// ErrPingPayloadMismatch will be returned if the payload of pong received mismatch the ping sent.
+	ErrPingPayloadMismatch = errors.New("ping payload mismatch")
+)
+
+type pingWaiter struct {
+	pid     peer.ID
+	payload []byte
+	pongC   chan []byte
+}
+
+// PingService provides a ping protocol measuring the round-trip time between hosts.
+// Latency measured will be recorded in store.MetricsBook of the host.
+// It also runs a background prober that pings all peers connected periodically.
+type PingService struct {
+	host     host.Host
+	seq      uint64
+	waiters  sync.Map // map[uint64]*pingWaiter
+	interval time.Duration
+	timeout  time.Duration
+
+	// send the ping and return the connection sent with, nil if unknown
+	send      func(protocolID protocol.ID, pid peer.ID, payload []byte) (network.Conn, error)
+	onLatency func(pid peer.ID, conn network.Conn, rtt time.Duration)
+
+	closeC chan struct{}
+	once   sync.Once
+
+	logger api.Logger
+}
+
+// NewPingService create a new *PingService instance.
+// If interval is not greater than 0, the background prober will not run.
+func NewPingService(h host.Host, interval time.Duration, logger api.Logger) *PingService {
+	timeout := DefaultPingTimeout
+	if interval > 0 && interval < timeout {
+		timeout = interval
+	}
+	return &PingService{
+		host:     h,
+		waiters:  sync.Map{},
+		interval: interval,
+		timeout:  timeout,
+		logger:   logger,
+		send: func(protocolID protocol.ID, pid peer.ID, payload []byte) (network.Conn, error) {
+			return nil, h.SendMsg(protocolID, pid, payload)
+		},
+	}
+}
+
+// SetSender set a function sending the pings instead of host.Host.SendMsg,
+// which returns the connection the ping sent with, so that the latency could be attributed to the connection.
+func (s *PingService) SetSender(
+	f func(protocolID protocol.ID, pid peer.ID, payload []byte) (network.Conn, error)) {
+	s.send = f
+}
+
+// OnLatency set a function that will be called back when a latency of peer measured.
+// The conn is the connection the ping sent with, nil if unknown.
+func (s *PingService) OnLatency(f func(pid peer.ID, conn network.Conn, rtt time.Duration)) {
+	s.onLatency = f
+}
+
+// ProtocolID is the protocol.ID of ping service.
+// The protocol id will be registered in host.RegisterMsgPayloadHandler method.
+func (s *PingService) ProtocolID() protocol.ID {
+	return PingProtocolID
+}
+
+// Handle is the msg payload handler of ping service.
+// It will be registered in host.Host.RegisterMsgPayloadHandler method.
+func (s *PingService) Handle() handler.MsgPayloadHandler {
+	return func(senderPID peer.ID, msgPayload []byte) {
+		msg := &pb.PingMsg{}
+		err := proto.Unmarshal(msgPayload, msg)
+		if err != nil {
+			s.logger.Errorf("[PingService] handler msg payload failed, %s (sender id: %s)",
+				err.Error(), senderPID)
+			return
+		}
+		switch msg.MsgType {
+		case pb.PingMsg_PING:
+			msg.MsgType = pb.PingMsg_PONG
+			bytes, e := proto.Marshal(msg)
+			if e != nil {
+				s.logger.Errorf("[PingService] marshal pong msg failed, %s", e.Error())
+				return
+			}
+			if e = s.host.SendMsg(PingProtocolID, senderPID, bytes); e != nil {
+				s.logger.Debugf("[PingService] send pong msg failed, %s (remote pid: %s)", e.Error(), senderPID)
+			}
+		case pb.PingMsg_PONG:
+			v, ok := s.waiters.Load(msg.Seq)
+			if !ok {
+				return
+			}
+			w, _ := v.(*pingWaiter)
+			if w.pid != senderPID {
+				s.logger.Warnf("[PingService] pong sender mismatch, (sender id: %s, expected: %s)",
+					senderPID, w.pid)
+				return
+			}
+			select {
+			case w.pongC <- msg.Payload:
+			default:
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Ping send a ping to the peer connected and return the round-trip time.
+func (s *PingService) Ping(ctx context.Context, pid peer.ID) (time.Duration, error) {
+	payload := make([]byte, pingPayloadSize)
+	if _, err := rand.Read(payload); err != nil {
+		return 0, err
+	}
+	seq := atomic.AddUint64(&s.seq, 1)
+	bytes, err := proto.Marshal(&pb.PingMsg{
+		MsgType: pb.PingMsg_PING,
+		Seq:     seq,
+		Payload: payload,
+	})
+	if err != nil {
+		return 0, err
+	}
+	w := &pingWaiter{pid: pid, payload: payload, pongC: make(chan []byte, 1)}
+	s.waiters.Store(seq, w)
+	defer s.waiters.Delete(seq)
+	start := time.Now()
+	conn, err := s.send(PingProtocolID, pid, bytes)
+	if err != nil {
+		return 0, err
+	}
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case pong := <-w.pongC:
+		rtt := time.Since(start)
+		if string(pong) != string(w.payload) {
+			return 0, ErrPingPayloadMismatch
+		}
+		s.host.PeerStore().RecordLatency(pid, rtt)
+		if s.onLatency != nil {
+			s.onLatency(pid, conn, rtt)
+		}
+		return rtt, nil
+	}
+}
+
+// Start the background prober.
+func (s *PingService) Start() error {
+	if s.interval <= 0 {
+		return nil
+	}
+	s.once.Do(func() {
+		s.closeC = make(chan struct{})
+		go s.probeLoop(s.closeC)
+	})
+	return nil
+}
+
+// Stop the background prober.
+func (s *PingService) Stop() error {
+	if s.closeC == nil {
+		return nil
+	}
+	close(s.closeC)
+	s.closeC = nil
+	s.once = sync.Once{}
+	return nil
+}
+
+func (s *PingService) probeLoop(closeC chan struct{}) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closeC:
+			return
+		case <-ticker.C:
+			s.probe()
+		}
+	}
+}
+
+func (s *PingService) probe() {
+	pids := s.host.ConnMgr().AllPeer()
+	var wg sync.WaitGroup
+	for i := range pids {
+		pid := pids[i]
+		if !s.host.IsPeerSupportProtocol(pid, PingProtocolID) {
+			continue
+		}
+		wg.Add(1)
+		go func(pid peer.ID) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(s.host.Context(), s.timeout)
+			defer cancel()
+			if _, err := s.Ping(ctx, pid); err != nil {
+				s.logger.Debugf("[PingService] ping peer failed, %s (remote pid: %s)", err.Error(), pid)
+			}
+		}(pid)
+	}
+	wg.Wait()
+}
@@ -378,7 +378,8 @@ func (s *simpleProtocolMgr) IsRegistered(protocolID protocol.ID) bool {
 // If the protocol not supported by us, return nil.
 func (s *simpleProtocolMgr) GetHandler(protocolID protocol.ID) handler.MsgPayloadHandler {
 	h, _ := s.protocolHandlers.Load(protocolID)
-	return h.(handler.MsgPayloadHandler)
+	payloadHandler, _ := h.(handler.MsgPayloadHandler)
+	return payloadHandler
 }
 
 // GetSelfSupportedProtocols return a list of protocol.ID that supported by ourself.
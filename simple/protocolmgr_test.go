@@ -0,0 +1,33 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimpleProtocolMgrGetHandler(t *testing.T) {
+	var p protocol.ID = "/p1"
+	m := NewSimpleProtocolMgr("local", NewSimplePeerStore("local"))
+	// no panic for the protocol not registered, e.g. a msg routed to us with an unknown protocol
+	require.Nil(t, m.GetHandler(p))
+
+	called := false
+	require.Nil(t, m.RegisterMsgPayloadHandler(p, func(peer.ID, []byte) {
+		called = true
+	}))
+	h := m.GetHandler(p)
+	require.NotNil(t, h)
+	h("remote", nil)
+	require.True(t, called)
+	require.Nil(t, m.UnregisterMsgPayloadHandler(p))
+	require.Nil(t, m.GetHandler(p))
+}
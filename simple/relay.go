@@ -0,0 +1,754 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"chainmaker.org/chainmaker/net-common/utils"
+	"chainmaker.org/chainmaker/net-liquid/core/host"
+	"chainmaker.org/chainmaker/net-liquid/core/network"
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	"chainmaker.org/chainmaker/net-liquid/core/protocol"
+	"chainmaker.org/chainmaker/net-liquid/core/util"
+	"chainmaker.org/chainmaker/net-liquid/simple/pb"
+	api "chainmaker.org/chainmaker/protocol/v2"
+	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// RelayProtocolID is the protocol.ID for relay service.
+	RelayProtocolID protocol.ID = "/relay/v0.0.1"
+	// DefaultRelayMaxReservations is the default max count of peers keeping reservations with the relay.
+	DefaultRelayMaxReservations = 128
+	// DefaultRelayMaxCircuits is the default max count of circuits relayed at the same time.
+	DefaultRelayMaxCircuits = 64
+	// DefaultRelayMaxCircuitsPerPeer is the default max count of circuits relayed for each peer at the same time.
+	DefaultRelayMaxCircuitsPerPeer = 8
+	// DefaultRelayReservationTTL is the default duration that a reservation will be kept since reserved.
+	DefaultRelayReservationTTL = time.Hour
+	// DefaultRelayCircuitDuration is the default max duration of a circuit.
+	DefaultRelayCircuitDuration = 30 * time.Minute
+	// DefaultRelayCircuitData is the default max bytes relayed in each direction of a circuit.
+	DefaultRelayCircuitData int64 = 256 << 20
+
+	// relayTimeout is the timeout of each relay request and the handshaking of a circuit.
+	relayTimeout = 30 * time.Second
+	// relayRefreshInterval is the interval of checking the reservations with relays.
+	relayRefreshInterval = 30 * time.Second
+	// relayRenewBefore is the duration before a reservation expires that it will be renewed.
+	relayRenewBefore = 5 * time.Minute
+	// maxRelayMsgSize is the max size of a relay message.
+	maxRelayMsgSize = 4 << 10
+	// relayBufSize is the size of the buffer for copying data of a circuit.
+	relayBufSize = 32 << 10
+)
+
+var (
+	// ErrInvalidCircuitAddr will be returned if the circuit address is not like /p2p/<relay>/p2p-circuit/p2p/<target>.
+	ErrInvalidCircuitAddr = errors.New("invalid circuit address")
+	// ErrRelayUnsupported will be returned if the network could not upgrade a relayed circuit to a connection.
+	ErrRelayUnsupported = errors.New("network does not support relayed connection")
+	// ErrRelayMsgTooLarge will be returned if the size of a relay message received is too large.
+	ErrRelayMsgTooLarge = errors.New("relay message too large")
+	// ErrRelayMalformedMsg will be returned if the relay message received is unexpected.
+	ErrRelayMalformedMsg = errors.New("malformed relay message")
+	// ErrRelayPermissionDenied will be returned if the peer refused to act as a relay or to accept a circuit.
+	ErrRelayPermissionDenied = errors.New("relay permission denied")
+	// ErrRelayResourceLimitExceeded will be returned if the resource limits of the relay exceeded.
+	ErrRelayResourceLimitExceeded = errors.New("relay resource limit exceeded")
+	// ErrRelayNoReservation will be returned if the target peer has no reservation with the relay.
+	ErrRelayNoReservation = errors.New("no reservation with relay")
+	// ErrRelayConnectionFailed will be returned if the relay failed to connect to the target peer.
+	ErrRelayConnectionFailed = errors.New("relay failed to connect to target")
+
+	circuitComponent = ma.StringCast("/p2p-circuit")
+)
+
+// IsCircuitAddr return whether the address is a relayed circuit address that contains /p2p-circuit.
+func IsCircuitAddr(addr ma.Multiaddr) bool {
+	if addr == nil {
+		return false
+	}
+	_, err := addr.ValueForProtocol(ma.P_CIRCUIT)
+	return err == nil
+}
+
+// SplitCircuitAddr split a circuit address into the address of the relay and the peer.ID of the target.
+// For example,
+// "/ip4/1.2.3.4/tcp/8080/p2p/QmRelay/p2p-circuit/p2p/QmTarget"
+// -->> "/ip4/1.2.3.4/tcp/8080/p2p/QmRelay" as relay address and "QmTarget" as target.
+// The target will be empty if the address ends with /p2p-circuit.
+func SplitCircuitAddr(addr ma.Multiaddr) (ma.Multiaddr, peer.ID, error) {
+	relayAddr, circuitAddr := ma.SplitFunc(addr, func(component ma.Component) bool {
+		return component.Protocol().Code == ma.P_CIRCUIT
+	})
+	if relayAddr == nil || circuitAddr == nil {
+		return nil, "", ErrInvalidCircuitAddr
+	}
+	if _, relayPID := util.GetNetAddrAndPidFromNormalMultiAddr(relayAddr); relayPID == "" {
+		return nil, "", ErrInvalidCircuitAddr
+	}
+	_, targetAddr := ma.SplitFirst(circuitAddr)
+	if targetAddr == nil {
+		return relayAddr, "", nil
+	}
+	target, err := targetAddr.ValueForProtocol(ma.P_P2P)
+	if err != nil {
+		return nil, "", ErrInvalidCircuitAddr
+	}
+	return relayAddr, peer.ID(target), nil
+}
+
+// CreateCircuitAddr create the address that could be dialed to reach others through the relay.
+// For example,
+// "/ip4/1.2.3.4/tcp/8080" & "QmRelay" -->> "/ip4/1.2.3.4/tcp/8080/p2p/QmRelay/p2p-circuit"
+func CreateCircuitAddr(relayNetAddr ma.Multiaddr, relay peer.ID) ma.Multiaddr {
+	if relayNetAddr == nil {
+		return util.CreateMultiAddrWithPid(relay).Encapsulate(circuitComponent)
+	}
+	return util.CreateMultiAddrWithPidAndNetAddr(relay, relayNetAddr).Encapsulate(circuitComponent)
+}
+
+// RelayLimits is the resource limits of the relay service acting as a relay.
+// The fields not greater than 0 will be set to defaults, except that negative CircuitDuration and CircuitData
+// mean unlimited.
+type RelayLimits struct {
+	// MaxReservations is the max count of peers keeping reservations with the relay.
+	MaxReservations int
+	// MaxCircuits is the max count of circuits relayed at the same time.
+	MaxCircuits int
+	// MaxCircuitsPerPeer is the max count of circuits relayed for each peer at the same time.
+	MaxCircuitsPerPeer int
+	// ReservationTTL is the duration that a reservation will be kept since reserved.
+	ReservationTTL time.Duration
+	// CircuitDuration is the max duration of a circuit, the circuit will be closed after it.
+	CircuitDuration time.Duration
+	// CircuitData is the max bytes relayed in each direction of a circuit, the circuit will be closed beyond it.
+	CircuitData int64
+}
+
+func (l RelayLimits) withDefaults() RelayLimits {
+	if l.MaxReservations <= 0 {
+		l.MaxReservations = DefaultRelayMaxReservations
+	}
+	if l.MaxCircuits <= 0 {
+		l.MaxCircuits = DefaultRelayMaxCircuits
+	}
+	if l.MaxCircuitsPerPeer <= 0 {
+		l.MaxCircuitsPerPeer = DefaultRelayMaxCircuitsPerPeer
+	}
+	if l.ReservationTTL <= 0 {
+		l.ReservationTTL = DefaultRelayReservationTTL
+	}
+	if l.CircuitDuration == 0 {
+		l.CircuitDuration = DefaultRelayCircuitDuration
+	}
+	if l.CircuitData == 0 {
+		l.CircuitData = DefaultRelayCircuitData
+	}
+	return l
+}
+
+// RelayService provides a circuit relay protocol, with which the peers could not be dialed directly
+// (e.g. behind NAT) are reachable through a relay.
+// A peer keeps a reservation with the relay, then others could ask the relay to connect to it, and the relay
+// splices the bidirectional streams on both sides into a circuit. Both ends upgrade the circuit to a connection
+// with the network.Upgrader of their network, so the connection relayed is end-to-end secured and multiplexed
+// like the direct ones, and the relay could not read it.
+// Acting as a relay (hop) is optional, and it is limited by RelayLimits.
+type RelayService struct {
+	host   host.Host
+	hop    bool
+	limits RelayLimits
+
+	mu sync.Mutex
+	// hop side
+	reservations map[peer.ID]time.Time
+	circuits     map[peer.ID]int
+	circuitCount int
+	// client side, relay peer.ID -> expiry of the reservation with it
+	relays map[peer.ID]time.Time
+
+	closeC chan struct{}
+	once   sync.Once
+
+	logger api.Logger
+}
+
+// NewRelayService create a new *RelayService instance.
+// If hop is true, the host will act as a relay for others, with the limits given.
+func NewRelayService(h host.Host, hop bool, limits RelayLimits, logger api.Logger) *RelayService {
+	return &RelayService{
+		host:         h,
+		hop:          hop,
+		limits:       limits.withDefaults(),
+		reservations: make(map[peer.ID]time.Time),
+		circuits:     make(map[peer.ID]int),
+		relays:       make(map[peer.ID]time.Time),
+		logger:       logger,
+	}
+}
+
+// ProtocolID is the protocol.ID of relay service.
+func (s *RelayService) ProtocolID() protocol.ID {
+	return RelayProtocolID
+}
+
+// AddRelay add a relay that the reservation with it will be kept by the background refresher.
+func (s *RelayService) AddRelay(relay peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.relays[relay]; !ok {
+		s.relays[relay] = time.Time{}
+	}
+}
+
+// HandleStream handle a bidirectional stream opened by others for relay protocol.
+func (s *RelayService) HandleStream(stream network.Stream) {
+	msg, err := readRelayMsg(stream)
+	if err != nil {
+		s.logger.Debugf("[RelayService] read relay msg failed, %s (remote pid: %s)",
+			err.Error(), stream.Conn().RemotePeerID())
+		_ = stream.Close()
+		return
+	}
+	switch msg.MsgType {
+	case pb.RelayMsg_RESERVE:
+		s.handleReserve(stream)
+	case pb.RelayMsg_CONNECT:
+		s.handleConnect(stream, msg)
+	case pb.RelayMsg_STOP:
+		s.handleStop(stream, msg)
+	default:
+		s.replyStatus(stream, pb.RelayMsg_MALFORMED_MESSAGE)
+	}
+}
+
+// handleReserve accept or refuse the reservation requested, on the relay side.
+func (s *RelayService) handleReserve(stream network.Stream) {
+	pid := stream.Conn().RemotePeerID()
+	if !s.hop {
+		s.replyStatus(stream, pb.RelayMsg_RESERVATION_REFUSED)
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	for p, expire := range s.reservations {
+		if now.After(expire) {
+			delete(s.reservations, p)
+		}
+	}
+	if _, ok := s.reservations[pid]; !ok && len(s.reservations) >= s.limits.MaxReservations {
+		s.mu.Unlock()
+		s.logger.Debugf("[RelayService] too many reservations, refuse it. (remote pid: %s)", pid)
+		s.replyStatus(stream, pb.RelayMsg_RESOURCE_LIMIT_EXCEEDED)
+		return
+	}
+	expire := now.Add(s.limits.ReservationTTL)
+	s.reservations[pid] = expire
+	s.mu.Unlock()
+	s.logger.Debugf("[RelayService] reservation accepted. (remote pid: %s, expire: %s)", pid, expire)
+	res := s.statusMsg(pb.RelayMsg_OK)
+	res.Expire = expire.Unix()
+	_ = writeRelayMsg(stream, res)
+	_ = stream.Close()
+}
+
+// handleConnect connect to the target requested, then splice the streams into a circuit, on the relay side.
+func (s *RelayService) handleConnect(stream network.Stream, msg *pb.RelayMsg) {
+	src := stream.Conn().RemotePeerID()
+	dst := peer.ID(msg.PeerId)
+	if !s.hop {
+		s.replyStatus(stream, pb.RelayMsg_PERMISSION_DENIED)
+		return
+	}
+	if dst == "" || dst == src || dst == s.host.ID() {
+		s.replyStatus(stream, pb.RelayMsg_MALFORMED_MESSAGE)
+		return
+	}
+	if !s.hasReservation(dst) {
+		s.replyStatus(stream, pb.RelayMsg_NO_RESERVATION)
+		return
+	}
+	dstConn := s.host.ConnMgr().GetPeerConn(dst)
+	if dstConn == nil {
+		s.replyStatus(stream, pb.RelayMsg_CONNECTION_FAILED)
+		return
+	}
+	if !s.acquireCircuit(src, dst) {
+		s.logger.Debugf("[RelayService] too many circuits, refuse it. (src: %s, dst: %s)", src, dst)
+		s.replyStatus(stream, pb.RelayMsg_RESOURCE_LIMIT_EXCEEDED)
+		return
+	}
+	defer s.releaseCircuit(src, dst)
+	dstStream, err := s.stop(dstConn, src)
+	if err != nil {
+		s.logger.Debugf("[RelayService] connect to target failed, %s (src: %s, dst: %s)", err.Error(), src, dst)
+		s.replyStatus(stream, pb.RelayMsg_CONNECTION_FAILED)
+		return
+	}
+	if err = writeRelayMsg(stream, s.statusMsg(pb.RelayMsg_OK)); err != nil {
+		_ = stream.Close()
+		_ = dstStream.Close()
+		return
+	}
+	s.logger.Debugf("[RelayService] circuit opened. (src: %s, dst: %s)", src, dst)
+	s.splice(stream, dstStream)
+	s.logger.Debugf("[RelayService] circuit closed. (src: %s, dst: %s)", src, dst)
+}
+
+// stop open a stream to the target and tell it the source of the circuit, on the relay side.
+func (s *RelayService) stop(dstConn network.Conn, src peer.ID) (network.Stream, error) {
+	stream, err := dstConn.CreateBidirectionalStream()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(s.host.Context(), relayTimeout)
+	defer cancel()
+	res, err := roundTripRelayMsg(ctx, stream, &pb.RelayMsg{
+		MsgType:       pb.RelayMsg_STOP,
+		PeerId:        src.ToString(),
+		LimitDuration: s.limitDuration(),
+		LimitData:     s.limitData(),
+	})
+	if err != nil {
+		_ = stream.Close()
+		return nil, err
+	}
+	if res.Status != pb.RelayMsg_OK {
+		_ = stream.Close()
+		return nil, relayStatusError(res.Status)
+	}
+	return stream, nil
+}
+
+// handleStop accept the circuit relayed and upgrade it to a connection, on the target side.
+func (s *RelayService) handleStop(stream network.Stream, msg *pb.RelayMsg) {
+	relayConn := stream.Conn()
+	relay := relayConn.RemotePeerID()
+	src := peer.ID(msg.PeerId)
+	if !s.isReservedWith(relay) {
+		s.replyStatus(stream, pb.RelayMsg_PERMISSION_DENIED)
+		return
+	}
+	upgrader, ok := relayConn.Network().(network.Upgrader)
+	if !ok || src == "" {
+		s.replyStatus(stream, pb.RelayMsg_CONNECTION_FAILED)
+		return
+	}
+	if err := writeRelayMsg(stream, s.statusMsg(pb.RelayMsg_OK)); err != nil {
+		_ = stream.Close()
+		return
+	}
+	ctx, cancel := context.WithTimeout(s.host.Context(), relayTimeout)
+	defer cancel()
+	laddr, raddr := relayConn.LocalAddr(), CreateCircuitAddr(relayConn.RemoteAddr(), relay)
+	_, err := upgrader.Upgrade(ctx, newStreamConn(stream, laddr, raddr), network.Inbound, laddr, raddr, src)
+	if err != nil {
+		s.logger.Debugf("[RelayService] upgrade relayed connection failed, %s (relay: %s, src: %s)",
+			err.Error(), relay, src)
+		return
+	}
+	s.logger.Debugf("[RelayService] relayed connection accepted. (relay: %s, src: %s)", relay, src)
+}
+
+// Reserve ask the relay to keep a reservation for us, so that others could connect to us through it.
+// The relay will be dialed if not connected. The expiry of the reservation will be returned.
+func (s *RelayService) Reserve(ctx context.Context, relay peer.ID) (time.Time, error) {
+	conn, err := s.host.DialPeer(ctx, relay)
+	if err != nil {
+		return time.Time{}, err
+	}
+	stream, err := conn.CreateBidirectionalStream()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() { _ = stream.Close() }()
+	res, err := roundTripRelayMsg(ctx, stream, &pb.RelayMsg{MsgType: pb.RelayMsg_RESERVE})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if res.Status != pb.RelayMsg_OK {
+		return time.Time{}, relayStatusError(res.Status)
+	}
+	expire := time.Unix(res.Expire, 0)
+	s.mu.Lock()
+	s.relays[relay] = expire
+	s.mu.Unlock()
+	s.logger.Infof("[RelayService] reserved with relay. (relay: %s, expire: %s)", relay, expire)
+	return expire, nil
+}
+
+// Connect ask the relay connected to open a circuit to the target,
+// then upgrade the circuit to a connection with the network of the relay connection.
+func (s *RelayService) Connect(ctx context.Context, relayConn network.Conn, target peer.ID) (network.Conn, error) {
+	upgrader, ok := relayConn.Network().(network.Upgrader)
+	if !ok {
+		return nil, ErrRelayUnsupported
+	}
+	stream, err := relayConn.CreateBidirectionalStream()
+	if err != nil {
+		return nil, err
+	}
+	res, err := roundTripRelayMsg(ctx, stream, &pb.RelayMsg{
+		MsgType: pb.RelayMsg_CONNECT,
+		PeerId:  target.ToString(),
+	})
+	if err != nil {
+		_ = stream.Close()
+		return nil, err
+	}
+	if res.Status != pb.RelayMsg_OK {
+		_ = stream.Close()
+		return nil, relayStatusError(res.Status)
+	}
+	relay := relayConn.RemotePeerID()
+	laddr, raddr := relayConn.LocalAddr(), CreateCircuitAddr(relayConn.RemoteAddr(), relay)
+	return upgrader.Upgrade(ctx, newStreamConn(stream, laddr, raddr), network.Outbound, laddr, raddr, target)
+}
+
+// RelayAddrs return the circuit addresses through the relays reserved with and connected,
+// with which others could reach us.
+func (s *RelayService) RelayAddrs() []ma.Multiaddr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	addrs := make([]ma.Multiaddr, 0, len(s.relays))
+	for relay, expire := range s.relays {
+		if !expire.After(now) {
+			continue
+		}
+		conn := s.host.ConnMgr().GetPeerConn(relay)
+		if conn == nil {
+			continue
+		}
+		addrs = append(addrs, CreateCircuitAddr(conn.RemoteAddr(), relay))
+	}
+	return addrs
+}
+
+// Start the background refresher keeping reservations with relays added.
+func (s *RelayService) Start() error {
+	s.once.Do(func() {
+		s.closeC = make(chan struct{})
+		go s.refreshLoop(s.closeC)
+	})
+	return nil
+}
+
+// Stop the background refresher.
+func (s *RelayService) Stop() error {
+	if s.closeC == nil {
+		return nil
+	}
+	close(s.closeC)
+	s.closeC = nil
+	s.once = sync.Once{}
+	return nil
+}
+
+func (s *RelayService) refreshLoop(closeC chan struct{}) {
+	ticker := time.NewTicker(relayRefreshInterval)
+	defer ticker.Stop()
+	for {
+		s.refreshReservations(closeC)
+		select {
+		case <-closeC:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshReservations renew the reservations going to expire or with the relays disconnected.
+func (s *RelayService) refreshReservations(closeC chan struct{}) {
+	s.mu.Lock()
+	relays := make([]peer.ID, 0, len(s.relays))
+	renewAt := time.Now().Add(relayRenewBefore)
+	for relay, expire := range s.relays {
+		if expire.Before(renewAt) || !s.host.ConnMgr().IsConnected(relay) {
+			relays = append(relays, relay)
+		}
+	}
+	s.mu.Unlock()
+	for _, relay := range relays {
+		select {
+		case <-closeC:
+			return
+		default:
+		}
+		ctx, cancel := context.WithTimeout(s.host.Context(), relayTimeout)
+		if _, err := s.Reserve(ctx, relay); err != nil {
+			s.logger.Debugf("[RelayService] reserve failed, %s (relay: %s)", err.Error(), relay)
+		}
+		cancel()
+	}
+}
+
+func (s *RelayService) hasReservation(pid peer.ID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expire, ok := s.reservations[pid]
+	return ok && time.Now().Before(expire)
+}
+
+func (s *RelayService) isReservedWith(relay peer.ID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expire, ok := s.relays[relay]
+	return ok && time.Now().Before(expire)
+}
+
+func (s *RelayService) acquireCircuit(src, dst peer.ID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.circuitCount >= s.limits.MaxCircuits ||
+		s.circuits[src] >= s.limits.MaxCircuitsPerPeer || s.circuits[dst] >= s.limits.MaxCircuitsPerPeer {
+		return false
+	}
+	s.circuitCount++
+	s.circuits[src]++
+	s.circuits[dst]++
+	return true
+}
+
+func (s *RelayService) releaseCircuit(src, dst peer.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.circuitCount--
+	for _, pid := range []peer.ID{src, dst} {
+		s.circuits[pid]--
+		if s.circuits[pid] <= 0 {
+			delete(s.circuits, pid)
+		}
+	}
+}
+
+// splice copy data between the streams of both sides until any side closed or the limits exceeded.
+func (s *RelayService) splice(src, dst network.Stream) {
+	var once sync.Once
+	closeBoth := func() {
+		once.Do(func() {
+			_ = src.Close()
+			_ = dst.Close()
+		})
+	}
+	if s.limits.CircuitDuration > 0 {
+		timer := time.AfterFunc(s.limits.CircuitDuration, closeBoth)
+		defer timer.Stop()
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		relayCopy(dst, src, s.limits.CircuitData)
+		closeBoth()
+	}()
+	go func() {
+		defer wg.Done()
+		relayCopy(src, dst, s.limits.CircuitData)
+		closeBoth()
+	}()
+	wg.Wait()
+}
+
+func (s *RelayService) limitDuration() int64 {
+	if s.limits.CircuitDuration <= 0 {
+		return 0
+	}
+	return int64(s.limits.CircuitDuration / time.Second)
+}
+
+func (s *RelayService) limitData() int64 {
+	if s.limits.CircuitData <= 0 {
+		return 0
+	}
+	return s.limits.CircuitData
+}
+
+func (s *RelayService) statusMsg(status pb.RelayMsg_RelayStatus) *pb.RelayMsg {
+	return &pb.RelayMsg{
+		MsgType:       pb.RelayMsg_STATUS,
+		Status:        status,
+		LimitDuration: s.limitDuration(),
+		LimitData:     s.limitData(),
+	}
+}
+
+// replyStatus send a status msg to the stream, then close it.
+func (s *RelayService) replyStatus(stream network.Stream, status pb.RelayMsg_RelayStatus) {
+	_ = writeRelayMsg(stream, s.statusMsg(status))
+	_ = stream.Close()
+}
+
+// relayCopy copy data from src to dst until EOF or error, or limit bytes copied if limit is greater than 0.
+func relayCopy(dst io.Writer, src io.Reader, limit int64) {
+	buf := make([]byte, relayBufSize)
+	var copied int64
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			copied += int64(n)
+			if limit > 0 && copied > limit {
+				return
+			}
+			if _, e := dst.Write(buf[:n]); e != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func relayStatusError(status pb.RelayMsg_RelayStatus) error {
+	switch status {
+	case pb.RelayMsg_RESERVATION_REFUSED, pb.RelayMsg_PERMISSION_DENIED:
+		return ErrRelayPermissionDenied
+	case pb.RelayMsg_RESOURCE_LIMIT_EXCEEDED:
+		return ErrRelayResourceLimitExceeded
+	case pb.RelayMsg_NO_RESERVATION:
+		return ErrRelayNoReservation
+	case pb.RelayMsg_CONNECTION_FAILED:
+		return ErrRelayConnectionFailed
+	default:
+		return ErrRelayMalformedMsg
+	}
+}
+
+// roundTripRelayMsg send a relay msg to the stream, then wait for the status msg as response.
+// The stream will be closed if ctx done before the response received.
+func roundTripRelayMsg(ctx context.Context, stream network.Stream, msg *pb.RelayMsg) (*pb.RelayMsg, error) {
+	doneC := make(chan struct{})
+	defer close(doneC)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = stream.Close()
+		case <-doneC:
+		}
+	}()
+	if err := writeRelayMsg(stream, msg); err != nil {
+		return nil, err
+	}
+	res, err := readRelayMsg(stream)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res.MsgType != pb.RelayMsg_STATUS {
+		return nil, ErrRelayMalformedMsg
+	}
+	return res, nil
+}
+
+func writeRelayMsg(stream network.Stream, msg *pb.RelayMsg) error {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	pkgData, err := protocol.NewPackage(RelayProtocolID, payload).ToBytes(false)
+	if err != nil {
+		return err
+	}
+	_, err = stream.Write(append(utils.Uint64ToBytes(uint64(len(pkgData))), pkgData...))
+	return err
+}
+
+func readRelayMsg(stream network.Stream) (*pb.RelayMsg, error) {
+	length, _, err := util.ReadPackageLength(stream)
+	if err != nil {
+		return nil, err
+	}
+	if length > maxRelayMsgSize {
+		return nil, ErrRelayMsgTooLarge
+	}
+	pkgData, err := util.ReadPackageData(stream, length)
+	if err != nil {
+		return nil, err
+	}
+	pkg := protocol.Package{}
+	if err = pkg.FromBytes(pkgData); err != nil {
+		return nil, err
+	}
+	if pkg.ProtocolID() != RelayProtocolID {
+		return nil, ErrRelayMalformedMsg
+	}
+	msg := &pb.RelayMsg{}
+	if err = proto.Unmarshal(pkg.Payload(), msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// circuitNetAddr is the net.Addr of a relayed circuit.
+type circuitNetAddr struct {
+	addr ma.Multiaddr
+}
+
+// Network return the name of the network.
+func (a *circuitNetAddr) Network() string {
+	return "p2p-circuit"
+}
+
+// String return the string of the multi-address.
+func (a *circuitNetAddr) String() string {
+	return a.addr.String()
+}
+
+var _ net.Conn = (*streamConn)(nil)
+
+// streamConn wraps a relayed stream as a net.Conn, so that it could be upgraded by a network.Upgrader.
+// Deadlines are not supported by streams, the methods setting them do nothing.
+type streamConn struct {
+	network.Stream
+	laddr net.Addr
+	raddr net.Addr
+}
+
+func newStreamConn(stream network.Stream, laddr, raddr ma.Multiaddr) *streamConn {
+	return &streamConn{
+		Stream: stream,
+		laddr:  &circuitNetAddr{addr: laddr},
+		raddr:  &circuitNetAddr{addr: raddr},
+	}
+}
+
+// LocalAddr return the local address.
+func (c *streamConn) LocalAddr() net.Addr {
+	return c.laddr
+}
+
+// RemoteAddr return the remote address.
+func (c *streamConn) RemoteAddr() net.Addr {
+	return c.raddr
+}
+
+// SetDeadline does nothing.
+func (c *streamConn) SetDeadline(_ time.Time) error {
+	return nil
+}
+
+// SetReadDeadline does nothing.
+func (c *streamConn) SetReadDeadline(_ time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline does nothing.
+func (c *streamConn) SetWriteDeadline(_ time.Time) error {
+	return nil
+}
@@ -0,0 +1,38 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"testing"
+
+	"chainmaker.org/chainmaker/net-liquid/core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCircuitAddr(t *testing.T) {
+	relay := peer.ID("QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4")
+	target := peer.ID("QmXf6mnQDBR9aHauRmViKzSuZgpumkn7x6rNxw1oqqRr45")
+	relayNetAddr := ma.StringCast("/ip4/1.2.3.4/tcp/8080")
+	circuitAddr := CreateCircuitAddr(relayNetAddr, relay)
+	require.Equal(t, "/ip4/1.2.3.4/tcp/8080/p2p/"+relay.ToString()+"/p2p-circuit", circuitAddr.String())
+	require.True(t, IsCircuitAddr(circuitAddr))
+	require.False(t, IsCircuitAddr(relayNetAddr))
+
+	relayAddr, pid, err := SplitCircuitAddr(ma.StringCast(circuitAddr.String() + "/p2p/" + target.ToString()))
+	require.Nil(t, err)
+	require.Equal(t, "/ip4/1.2.3.4/tcp/8080/p2p/"+relay.ToString(), relayAddr.String())
+	require.Equal(t, target, pid)
+
+	relayAddr, pid, err = SplitCircuitAddr(CreateCircuitAddr(nil, relay))
+	require.Nil(t, err)
+	require.Equal(t, "/p2p/"+relay.ToString(), relayAddr.String())
+	require.Equal(t, peer.ID(""), pid)
+
+	_, _, err = SplitCircuitAddr(ma.StringCast("/ip4/1.2.3.4/tcp/8080/p2p-circuit/p2p/" + target.ToString()))
+	require.Equal(t, ErrInvalidCircuitAddr, err)
+}
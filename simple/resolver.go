@@ -0,0 +1,174 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+const (
+	// dnsAddrTXTPrefix is the prefix of the domain queried for TXT records of a /dnsaddr address.
+	dnsAddrTXTPrefix = "_dnsaddr."
+	// dnsAddrTXTValuePrefix is the prefix of each TXT record value of a /dnsaddr address.
+	dnsAddrTXTValuePrefix = "dnsaddr="
+	// maxDNSAddrDepth is the max depth of /dnsaddr addresses nested in TXT records.
+	maxDNSAddrDepth = 4
+)
+
+var (
+	// ErrNoAddrResolved will be returned if no address resolved from a DNS address.
+	ErrNoAddrResolved = errors.New("no address resolved")
+	// ErrDNSAddrTooDeep will be returned if /dnsaddr addresses nested too deep.
+	ErrDNSAddrTooDeep = errors.New("dnsaddr nested too deep")
+)
+
+// Resolver is a DNS resolver used to resolve DNS multiaddrs.
+// *net.Resolver implements it, and tests could use a local stub instead.
+type Resolver interface {
+	// LookupIPAddr looks up host, returns a slice of its IPv4 and IPv6 addresses.
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	// LookupTXT returns the DNS TXT records for the given domain name.
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// MultiaddrResolver resolves /dns, /dns4 and /dns6 multiaddrs to /ip4 or /ip6 multiaddrs,
+// and resolves /dnsaddr multiaddrs to the lists of multiaddrs in TXT records.
+type MultiaddrResolver struct {
+	resolver Resolver
+}
+
+// NewMultiaddrResolver create a new *MultiaddrResolver instance.
+// If resolver is nil, net.DefaultResolver will be used.
+func NewMultiaddrResolver(resolver Resolver) *MultiaddrResolver {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &MultiaddrResolver{resolver: resolver}
+}
+
+// IsDNSAddr return whether the first component of the address is /dns, /dns4 or /dns6.
+func IsDNSAddr(addr ma.Multiaddr) bool {
+	if addr == nil {
+		return false
+	}
+	first, _ := ma.SplitFirst(addr)
+	if first == nil {
+		return false
+	}
+	switch first.Protocol().Code {
+	case ma.P_DNS, ma.P_DNS4, ma.P_DNS6:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsDNSAddrList return whether the first component of the address is /dnsaddr.
+func IsDNSAddrList(addr ma.Multiaddr) bool {
+	if addr == nil {
+		return false
+	}
+	first, _ := ma.SplitFirst(addr)
+	return first != nil && first.Protocol().Code == ma.P_DNSADDR
+}
+
+// Resolve the first /dns, /dns4 or /dns6 component of the address to /ip4 or /ip6 components,
+// the rest components (e.g. /tcp/8080/p2p/Qm...) will be kept.
+// /dns4 resolves to IPv4 only, /dns6 resolves to IPv6 only, /dns resolves to both.
+// If the address is not a DNS address, it will be returned directly.
+func (r *MultiaddrResolver) Resolve(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, error) {
+	if !IsDNSAddr(addr) {
+		return []ma.Multiaddr{addr}, nil
+	}
+	first, rest := ma.SplitFirst(addr)
+	ipAddrs, err := r.resolver.LookupIPAddr(ctx, first.Value())
+	if err != nil {
+		return nil, err
+	}
+	code := first.Protocol().Code
+	res := make([]ma.Multiaddr, 0, len(ipAddrs))
+	for i := range ipAddrs {
+		var ipComponent *ma.Component
+		if ip4 := ipAddrs[i].IP.To4(); ip4 != nil {
+			if code == ma.P_DNS6 {
+				continue
+			}
+			ipComponent, err = ma.NewComponent("ip4", ip4.String())
+		} else {
+			if code == ma.P_DNS4 {
+				continue
+			}
+			ipComponent, err = ma.NewComponent("ip6", ipAddrs[i].IP.String())
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rest == nil {
+			res = append(res, ipComponent)
+			continue
+		}
+		res = append(res, ipComponent.Encapsulate(rest))
+	}
+	if len(res) == 0 {
+		return nil, ErrNoAddrResolved
+	}
+	return res, nil
+}
+
+// ResolveDNSAddr resolve a /dnsaddr/<domain> address to the list of multiaddrs
+// found in the TXT records of _dnsaddr.<domain>, each record is formatted as "dnsaddr=<multiaddr>".
+// The /dnsaddr addresses nested will be resolved recursively, the ones failed will be skipped,
+// and the DNS addresses in the list (e.g. /dns4/...) will be kept, they should be resolved when dialing.
+// If the address is not a /dnsaddr address, it will be returned directly.
+func (r *MultiaddrResolver) ResolveDNSAddr(ctx context.Context, addr ma.Multiaddr) ([]ma.Multiaddr, error) {
+	return r.resolveDNSAddr(ctx, addr, 0)
+}
+
+func (r *MultiaddrResolver) resolveDNSAddr(ctx context.Context, addr ma.Multiaddr, depth int) (
+	[]ma.Multiaddr, error) {
+	if !IsDNSAddrList(addr) {
+		return []ma.Multiaddr{addr}, nil
+	}
+	if depth >= maxDNSAddrDepth {
+		return nil, ErrDNSAddrTooDeep
+	}
+	first, _ := ma.SplitFirst(addr)
+	records, err := r.resolver.LookupTXT(ctx, dnsAddrTXTPrefix+first.Value())
+	if err != nil {
+		return nil, err
+	}
+	res := make([]ma.Multiaddr, 0, len(records))
+	var nestedErr error
+	for _, record := range records {
+		if !strings.HasPrefix(record, dnsAddrTXTValuePrefix) {
+			continue
+		}
+		a, err := ma.NewMultiaddr(strings.TrimPrefix(record, dnsAddrTXTValuePrefix))
+		if err != nil {
+			continue
+		}
+		addrs, err := r.resolveDNSAddr(ctx, a, depth+1)
+		if err != nil {
+			// a broken nested entry should not fail the others
+			nestedErr = err
+			continue
+		}
+		res = append(res, addrs...)
+	}
+	if len(res) == 0 {
+		if nestedErr != nil {
+			return nil, nestedErr
+		}
+		return nil, ErrNoAddrResolved
+	}
+	return res, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright (C) BABEC. All rights reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package simple
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/stretchr/testify/require"
+)
+
+type stubResolver struct {
+	ips map[string][]net.IPAddr
+	txt map[string][]string
+}
+
+func (r *stubResolver) LookupIPAddr(_ context.Context, host string) ([]net.IPAddr, error) {
+	ips, ok := r.ips[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return ips, nil
+}
+
+func (r *stubResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	txt, ok := r.txt[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return txt, nil
+}
+
+func TestMultiaddrResolver(t *testing.T) {
+	pid := "QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4"
+	r := NewMultiaddrResolver(&stubResolver{
+		ips: map[string][]net.IPAddr{
+			"node1.example.com": {{IP: net.ParseIP("10.0.0.1")}, {IP: net.ParseIP("fd00::1")}},
+		},
+		txt: map[string][]string{
+			"_dnsaddr.seeds.example.com": {
+				"dnsaddr=/dns4/node1.example.com/tcp/11301/p2p/" + pid,
+				"dnsaddr=/dnsaddr/more.example.com",
+				"dnsaddr=/dnsaddr/unknown.example.com",
+				"v=spf1 -all",
+			},
+			"_dnsaddr.more.example.com": {"dnsaddr=/ip4/10.0.0.2/tcp/11301/p2p/" + pid},
+			"_dnsaddr.loop.example.com": {"dnsaddr=/dnsaddr/loop.example.com"},
+		},
+	})
+	ctx := context.Background()
+
+	addrs, err := r.Resolve(ctx, ma.StringCast("/dns4/node1.example.com/tcp/11301/p2p/"+pid))
+	require.Nil(t, err)
+	require.Equal(t, []ma.Multiaddr{ma.StringCast("/ip4/10.0.0.1/tcp/11301/p2p/" + pid)}, addrs)
+	addrs, err = r.Resolve(ctx, ma.StringCast("/dns6/node1.example.com/tcp/11301"))
+	require.Nil(t, err)
+	require.Equal(t, []ma.Multiaddr{ma.StringCast("/ip6/fd00::1/tcp/11301")}, addrs)
+	addrs, err = r.Resolve(ctx, ma.StringCast("/dns/node1.example.com/tcp/11301"))
+	require.Nil(t, err)
+	require.Len(t, addrs, 2)
+	_, err = r.Resolve(ctx, ma.StringCast("/dns4/unknown.example.com/tcp/11301"))
+	require.NotNil(t, err)
+	ip := ma.StringCast("/ip4/127.0.0.1/tcp/11301")
+	addrs, err = r.Resolve(ctx, ip)
+	require.Nil(t, err)
+	require.Equal(t, []ma.Multiaddr{ip}, addrs)
+
+	addrs, err = r.ResolveDNSAddr(ctx, ma.StringCast("/dnsaddr/seeds.example.com"))
+	require.Nil(t, err)
+	require.Equal(t, []ma.Multiaddr{
+		ma.StringCast("/dns4/node1.example.com/tcp/11301/p2p/" + pid),
+		ma.StringCast("/ip4/10.0.0.2/tcp/11301/p2p/" + pid),
+	}, addrs)
+	_, err = r.ResolveDNSAddr(ctx, ma.StringCast("/dnsaddr/loop.example.com"))
+	require.Equal(t, ErrDNSAddrTooDeep, err)
+}
@@ -14,6 +14,7 @@ import (
 
 	"errors"
 	"sync"
+	"time"
 )
 
 var (
@@ -26,8 +27,9 @@ var (
 var _ mgr.SendStreamPoolManager = (*sendStreamPoolManager)(nil)
 
 type sendStreamPoolManager struct {
-	mu    sync.RWMutex
-	poolM map[peer.ID]map[network.Conn]mgr.SendStreamPool
+	mu       sync.RWMutex
+	poolM    map[peer.ID]map[network.Conn]mgr.SendStreamPool
+	latencyM map[network.Conn]time.Duration
 
 	connMgr mgr.ConnMgr
 	log     api.Logger
@@ -36,9 +38,10 @@ type sendStreamPoolManager struct {
 // NewSendStreamPoolManager create a simple implementation instance of mgr.SendStreamPoolManager interface.
 func NewSendStreamPoolManager(connMgr mgr.ConnMgr, log api.Logger) mgr.SendStreamPoolManager {
 	return &sendStreamPoolManager{
-		poolM:   make(map[peer.ID]map[network.Conn]mgr.SendStreamPool),
-		connMgr: connMgr,
-		log:     log}
+		poolM:    make(map[peer.ID]map[network.Conn]mgr.SendStreamPool),
+		latencyM: make(map[network.Conn]time.Duration),
+		connMgr:  connMgr,
+		log:      log}
 }
 
 // Reset the manager.
@@ -46,6 +49,7 @@ func (s *sendStreamPoolManager) Reset() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.poolM = make(map[peer.ID]map[network.Conn]mgr.SendStreamPool)
+	s.latencyM = make(map[network.Conn]time.Duration)
 }
 
 // AddPeerConnSendStreamPool append a stream pool for a connection of peer.
@@ -79,6 +83,7 @@ func (s *sendStreamPoolManager) RemovePeerConnAndCloseSendStreamPool(pid peer.ID
 		return ErrConnNotExist
 	}
 	delete(m, conn)
+	delete(s.latencyM, conn)
 	if len(m) <= 0 {
 		delete(s.poolM, pid)
 	}
@@ -86,6 +91,7 @@ func (s *sendStreamPoolManager) RemovePeerConnAndCloseSendStreamPool(pid peer.ID
 }
 
 // GetPeerBestConnSendStreamPool return a stream pool for the best connection of peer.
+// The pools with idle streams are preferred, then the ones of the connections with lower latency.
 func (s *sendStreamPoolManager) GetPeerBestConnSendStreamPool(pid peer.ID) mgr.SendStreamPool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -93,13 +99,18 @@ func (s *sendStreamPoolManager) GetPeerBestConnSendStreamPool(pid peer.ID) mgr.S
 	if !ok {
 		return nil
 	}
-	var res mgr.SendStreamPool
-	var idleSize int
-	for _, pool := range m {
+	var (
+		res      mgr.SendStreamPool
+		idleSize int
+		latency  time.Duration
+	)
+	for conn, pool := range m {
 		idle := pool.IdleSize()
-		if idleSize <= idle {
+		l := s.latencyM[conn]
+		if res == nil || betterPool(idle, l, idleSize, latency) {
 			res = pool
 			idleSize = idle
+			latency = l
 		}
 	}
 	if res == nil {
@@ -111,3 +122,31 @@ func (s *sendStreamPoolManager) GetPeerBestConnSendStreamPool(pid peer.ID) mgr.S
 	}
 	return res
 }
+
+// betterPool return whether a pool with the idle size and connection latency given is better than the best one found.
+// The connections whose latency unknown yet are preferred, so that they will be measured soon.
+func betterPool(idle int, latency time.Duration, bestIdle int, bestLatency time.Duration) bool {
+	if (idle > 0) != (bestIdle > 0) {
+		return idle > 0
+	}
+	if latency != bestLatency {
+		return latency < bestLatency
+	}
+	return idle >= bestIdle
+}
+
+// RecordConnLatency record a round-trip time measured on a connection of peer,
+// the EWMA latency of the connection will be updated.
+func (s *sendStreamPoolManager) RecordConnLatency(pid peer.ID, conn network.Conn, rtt time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.poolM[pid][conn]; !ok {
+		return
+	}
+	old, ok := s.latencyM[conn]
+	if !ok {
+		s.latencyM[conn] = rtt
+		return
+	}
+	s.latencyM[conn] = time.Duration(LatencyEWMASmoothing*float64(rtt) + (1-LatencyEWMASmoothing)*float64(old))
+}
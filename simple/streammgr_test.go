@@ -196,9 +196,44 @@ func TestUseStreamPoolDynamic(t *testing.T) {
 		"stream pool current size shold be [%d], actual: [%d]", poolCapacity, streamPool.CurrentSize())
 }
 
+func TestSendStreamPoolManagerPreferLowLatency(t *testing.T) {
+	pid := peer.ID("QmcQHCuAXaFkbcsPUj7e37hXXfZ9DdN7bozseo5oX4qiC4")
+	mgr := NewSendStreamPoolManager(nil, logger.NilLogger)
+	fast, slow := &connStub{id: 1}, &connStub{id: 2}
+	for _, conn := range []network.Conn{fast, slow} {
+		pool, err := NewSimpleStreamPool(2, 10, conn, nil, logger.NilLogger)
+		require.Nil(t, err)
+		require.Nil(t, pool.InitStreams())
+		require.Nil(t, mgr.AddPeerConnSendStreamPool(pid, conn, pool))
+	}
+	defer mgr.Reset()
+
+	mgr.RecordConnLatency(pid, slow, 50*time.Millisecond)
+	// the connection whose latency unknown is preferred to get measured
+	require.Equal(t, network.Conn(fast), mgr.GetPeerBestConnSendStreamPool(pid).Conn())
+	mgr.RecordConnLatency(pid, fast, 80*time.Millisecond)
+	require.Equal(t, network.Conn(slow), mgr.GetPeerBestConnSendStreamPool(pid).Conn())
+	// EWMA latency updated
+	for i := 0; i < 30; i++ {
+		mgr.RecordConnLatency(pid, fast, 10*time.Millisecond)
+	}
+	require.Equal(t, network.Conn(fast), mgr.GetPeerBestConnSendStreamPool(pid).Conn())
+
+	// the pools with idle streams are preferred to the ones of lower latency
+	require.True(t, betterPool(1, 50*time.Millisecond, 0, 10*time.Millisecond))
+	require.False(t, betterPool(0, 10*time.Millisecond, 1, 50*time.Millisecond))
+	require.True(t, betterPool(2, 10*time.Millisecond, 1, 10*time.Millisecond))
+
+	// latency of connections removed will be dropped
+	require.Nil(t, mgr.RemovePeerConnAndCloseSendStreamPool(pid, fast))
+	mgr.RecordConnLatency(pid, fast, time.Millisecond)
+	require.NotContains(t, mgr.(*sendStreamPoolManager).latencyM, network.Conn(fast))
+}
+
 var _ network.Conn = (*connStub)(nil)
 
 type connStub struct {
+	id int
 }
 
 func (stub *connStub) LocalNetAddr() net.Addr {